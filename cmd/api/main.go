@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	_ "time/tzdata" // Venue time zones; the runtime image has no zoneinfo
 
 	"team-manager-leagues/internal/config"
 	"team-manager-leagues/internal/repository"
+	"team-manager-leagues/internal/repository/memory"
 	"team-manager-leagues/internal/service"
 	transporthttp "team-manager-leagues/internal/transport/http"
 )
@@ -14,13 +17,44 @@ func main() {
 	cfg := config.Load()
 
 	ctx := context.Background()
-	pool, err := repository.NewPool(ctx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Unable to connect to database: %v\n", err)
+	var store repository.Repository
+	if cfg.StorageDriver == "memory" {
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatal("migrate: the memory storage driver has no schema to migrate")
+		}
+		mem := memory.NewStore()
+		if cfg.MemorySeedFile != "" {
+			if err := mem.LoadSeedFile(cfg.MemorySeedFile); err != nil {
+				log.Fatalf("Unable to load memory seed file: %v", err)
+			}
+		}
+		log.Println("warning: using in-memory storage; data is lost on restart")
+		store = mem
+	} else {
+		pool, err := repository.NewPool(ctx, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Unable to connect to database: %v\n", err)
+		}
+		defer pool.Close()
+
+		migrator, err := repository.NewMigrator(pool, repository.Migrations)
+		if err != nil {
+			log.Fatalf("Invalid migrations: %v", err)
+		}
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(ctx, migrator, os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+			return
+		}
+		if cfg.AutoMigrate {
+			if err := migrator.Up(ctx); err != nil {
+				log.Fatalf("Unable to migrate database: %v", err)
+			}
+		}
+		store = repository.NewStore(pool)
 	}
-	defer pool.Close()
 
-	store := repository.NewStore(pool)
 	svc := service.NewLeaguesService(store)
 
 	r := transporthttp.NewRouter(cfg, svc)
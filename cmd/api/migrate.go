@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"team-manager-leagues/internal/repository"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [n]        revert the last n applied migrations (default 1)
+  status          list migrations and when they were applied
+  to <version>    migrate up or down to exactly <version> (0 reverts all)`
+
+func runMigrate(ctx context.Context, m *repository.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		return m.Down(ctx, steps)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return m.To(ctx, version)
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range list {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return w.Flush()
+	}
+	return errors.New(migrateUsage)
+}
@@ -16,6 +16,9 @@ type Config struct {
 	RefreshTokenTTL     time.Duration
 	AllowInsecureCookie bool
 	RequireEmailVerify  bool
+	AutoMigrate         bool   // Apply pending migrations when the API starts
+	StorageDriver       string // "postgres" or "memory"
+	MemorySeedFile      string // Teams and memberships to load into the memory store
 }
 
 func getenv(key, def string) string {
@@ -45,6 +48,11 @@ func Load() Config {
 
 	insecureCookie := getenv("ALLOW_INSECURE_COOKIE", "false") == "true"
 	requireVerify := getenv("REQUIRE_EMAIL_VERIFICATION", "false") == "true"
+	autoMigrate := getenv("AUTO_MIGRATE", "true") == "true"
+	storageDriver := getenv("STORAGE_DRIVER", "postgres")
+	if storageDriver != "postgres" && storageDriver != "memory" {
+		log.Fatalf("invalid STORAGE_DRIVER %q: want postgres or memory", storageDriver)
+	}
 
 	if secret == "dev-secret-change-me" {
 		log.Println("warning: using default JWT secret; set JWT_SECRET in production")
@@ -58,5 +66,8 @@ func Load() Config {
 		RefreshTokenTTL:     time.Duration(refreshDays) * 24 * time.Hour,
 		AllowInsecureCookie: insecureCookie,
 		RequireEmailVerify:  requireVerify,
+		AutoMigrate:         autoMigrate,
+		StorageDriver:       storageDriver,
+		MemorySeedFile:      getenv("MEMORY_SEED_FILE", ""),
 	}
 }
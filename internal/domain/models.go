@@ -12,23 +12,366 @@ type League struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-type Series struct {
+// SearchResult is a league, series or registered team matching a search.
+type SearchResult struct {
+	Type       string  `json:"type"` // "league", "series" or "team"
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Region     string  `json:"region,omitempty"`     // Of the league, for leagues and series
+	LeagueID   string  `json:"leagueId,omitempty"`   // For series
+	LeagueName string  `json:"leagueName,omitempty"` // For series
+	ClubID     string  `json:"clubId,omitempty"`     // For teams
+	Rank       float64 `json:"rank"`                 // Relevance, higher is better
+}
+
+// LeagueMember grants a user a role in a league.
+type LeagueMember struct {
+	LeagueID  string    `json:"leagueId"`
+	UserID    string    `json:"userId"`
+	Role      string    `json:"role"` // "owner", "admin", "scheduler", "referee_coordinator", "viewer"
+	AddedBy   string    `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Season is one edition of a league, e.g. the 2025 season. Series belong to
+// a season, so their names only need to be unique within it.
+type Season struct {
 	ID        string    `json:"id"`
 	LeagueID  string    `json:"leagueId"`
-	Name      string    `json:"name"`   // e.g., "Series A", "Golden"
-	Format    string    `json:"format"` // e.g., "baby", "7", "11" - should match Team format ideally
+	Name      string    `json:"name"`     // e.g., "2025", "2025/26"
+	StartsOn  time.Time `json:"startsOn"` // Dates, at midnight UTC
+	EndsOn    time.Time `json:"endsOn"`
+	Status    string    `json:"status"` // "planning", "registration", "in_progress", "finished"
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Venue is a ground a league books its matches into. Availability windows
+// and blackouts are in the venue's local time.
+type Venue struct {
+	ID        string   `json:"id"`
+	LeagueID  string   `json:"leagueId"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Timezone  string   `json:"timezone"` // IANA name, e.g. "America/Santiago"
+	Pitches   []Pitch  `json:"pitches"`
+	// Weekly hours the venue can be booked; none means it always can
+	Availability []AvailabilityWindow `json:"availability"`
+	Blackouts    []Blackout           `json:"blackouts"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	UpdatedAt    time.Time            `json:"updatedAt"`
+}
+
+type Pitch struct {
+	Number  int      `json:"number"`
+	Surface string   `json:"surface"` // "grass", "artificial", "hybrid" or "indoor"
+	Formats []string `json:"formats"` // Series formats it hosts, e.g. "7"; empty hosts any
+}
+
+// AvailabilityWindow opens pitches of a venue on one day of every week.
+type AvailabilityWindow struct {
+	Weekday int    `json:"weekday"`           // 0 for Sunday through 6 for Saturday
+	Opens   string `json:"opens"`             // "HH:MM"
+	Closes  string `json:"closes"`            // "HH:MM", up to "24:00"
+	Pitches []int  `json:"pitches,omitempty"` // Empty opens every pitch
+}
+
+// Blackout closes pitches of a venue for a whole day.
+type Blackout struct {
+	Date    string `json:"date"`              // "YYYY-MM-DD"
+	Pitches []int  `json:"pitches,omitempty"` // Empty closes every pitch
+	Reason  string `json:"reason,omitempty"`
+}
+
+type Series struct {
+	ID       string `json:"id"`
+	LeagueID string `json:"leagueId"`
+	SeasonID string `json:"seasonId"`
+	Name     string `json:"name"`   // e.g., "Series A", "Golden"
+	Format   string `json:"format"` // e.g., "baby", "7", "11" - should match Team format ideally
+	// Points awarded in the standings per outcome
+	PointsWin  int `json:"pointsWin"`
+	PointsDraw int `json:"pointsDraw"`
+	PointsLoss int `json:"pointsLoss"`
+	// Ordered criteria that separate teams level on points, e.g. "head_to_head_points"
+	Tiebreakers []string `json:"tiebreakers"`
+	LotsSeed    *int64   `json:"lotsSeed,omitempty"` // Seed used when the "lots" tiebreaker draws
+	Mode        string   `json:"mode"`               // "league", "knockout" or "groups_knockout"
+	// Registration limits; registrations past MaxTeams are waitlisted
+	MaxTeams             *int       `json:"maxTeams,omitempty"`
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty"`
+	// Eligibility: empty categories accept any team
+	AgeCategory       string `json:"ageCategory"`       // e.g. "U12", "open"
+	GenderCategory    string `json:"genderCategory"`    // "male", "female" or "mixed"
+	EligibilityPolicy string `json:"eligibilityPolicy"` // "reject" or "flag" ineligible registrations
+	// Rank among the divisions of the season, 1 for the top one; teams move
+	// between adjacent divisions by promotion and relegation
+	Division *int `json:"division,omitempty"`
+	// Minutes a match holds its pitch, from kickoff
+	MatchMinutes int `json:"matchMinutes"`
+	// Roster limits; after RosterLocksAt only league organizers change rosters
+	MaxRosterSize *int       `json:"maxRosterSize,omitempty"`
+	RosterLocksAt *time.Time `json:"rosterLocksAt,omitempty"`
+	// Bans handed out automatically for cards, at most one rule per card type
+	DisciplineRules []SuspensionRule `json:"disciplineRules"`
+	// Lowest certification level of the officials of its matches; 0 for none
+	MinRefereeLevel int       `json:"minRefereeLevel"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// SuspensionRule bans a player for Matches matches at every Count-th card
+// of a type they collect in the series, e.g. every 5th yellow.
+type SuspensionRule struct {
+	Card    string `json:"card"` // "yellow", "second_yellow" or "red"
+	Count   int    `json:"count"`
+	Matches int    `json:"matches"`
+}
+
 type TeamRegistration struct {
+	ID       string `json:"id"`
+	TeamID   string `json:"teamId"`
+	SeriesID string `json:"seriesId"`
+	Status   string `json:"status"` // "invited", "pending", "waitlisted", "approved", "rejected", "withdrawn", "archived"
+	// Categories declared by the club when registering
+	AgeCategory       string             `json:"ageCategory"`
+	GenderCategory    string             `json:"genderCategory"`
+	EligibilityIssues []EligibilityIssue `json:"eligibilityIssues"` // Rules the team fails, flagged for review
+	CreatedAt         time.Time          `json:"createdAt"`
+}
+
+// EligibilityIssue is an eligibility rule a registered team does not meet.
+type EligibilityIssue struct {
+	Rule       string `json:"rule"` // "format", "age_category" or "gender_category"
+	Expected   string `json:"expected"`
+	Actual     string `json:"actual"`
+	Overridden bool   `json:"overridden"`
+}
+
+// EligibilityOverride lets a team into a series despite failing a rule.
+type EligibilityOverride struct {
 	ID        string    `json:"id"`
-	TeamID    string    `json:"teamId"`
 	SeriesID  string    `json:"seriesId"`
-	Status    string    `json:"status"` // e.g., "active", "pending", "archived"
+	TeamID    string    `json:"teamId"`
+	Rule      string    `json:"rule"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RegistrationEvent records a status change of a registration, who made it and why.
+type RegistrationEvent struct {
+	ID             string    `json:"id"`
+	RegistrationID string    `json:"registrationId"`
+	FromStatus     string    `json:"fromStatus"` // Empty for the initial status
+	ToStatus       string    `json:"toStatus"`
+	ActorID        string    `json:"actorId"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// RosterPlayer is a player on the roster a team registered for a series. A
+// player is on at most one roster per series.
+type RosterPlayer struct {
+	RegistrationID string    `json:"registrationId"`
+	SeriesID       string    `json:"seriesId"`
+	TeamID         string    `json:"teamId"`
+	PlayerID       string    `json:"playerId"` // The player's ID in the teams service
+	Name           string    `json:"name"`
+	BirthDate      time.Time `json:"birthDate"` // Midnight UTC
+	ShirtNumber    int       `json:"shirtNumber"`
+	Late           bool      `json:"late"` // Added after the roster locked
+	AddedBy        string    `json:"addedBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// RosterChange records a change to a roster, who made it and why.
+type RosterChange struct {
+	ID             string    `json:"id"`
+	RegistrationID string    `json:"registrationId"`
+	SeriesID       string    `json:"seriesId"`
+	PlayerID       string    `json:"playerId"`
+	Action         string    `json:"action"` // "added", "updated" or "removed"
+	ShirtNumber    int       `json:"shirtNumber"`
+	Late           bool      `json:"late"` // Made after the roster locked
+	ActorID        string    `json:"actorId"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// TransferWindow is a period of a season in which players may move between
+// the rosters of its series.
+type TransferWindow struct {
+	ID       string    `json:"id"`
+	LeagueID string    `json:"leagueId"`
+	SeasonID string    `json:"seasonId"`
+	OpensAt  time.Time `json:"opensAt"`
+	ClosesAt time.Time `json:"closesAt"`
+	// Completed transfers in or out of a team during the window
+	MaxTransfersPerTeam *int      `json:"maxTransfersPerTeam,omitempty"`
+	CreatedBy           string    `json:"createdBy"`
+	CreatedAt           time.Time `json:"createdAt"`
+}
+
+// Transfer moves a player from one roster to another of the same season once
+// the owners of both clubs approve it.
+type Transfer struct {
+	ID                 string `json:"id"`
+	SeasonID           string `json:"seasonId"`
+	WindowID           string `json:"windowId"`
+	PlayerID           string `json:"playerId"`
+	FromRegistrationID string `json:"fromRegistrationId"`
+	ToRegistrationID   string `json:"toRegistrationId"`
+	FromTeamID         string `json:"fromTeamId"`
+	ToTeamID           string `json:"toTeamId"`
+	ShirtNumber        int    `json:"shirtNumber"` // On the new roster
+	Status             string `json:"status"`      // "pending", "completed", "declined", "cancelled", "expired"
+	// Owners who approved on behalf of each club; empty until they do
+	FromApprovedBy string    `json:"fromApprovedBy,omitempty"`
+	ToApprovedBy   string    `json:"toApprovedBy,omitempty"`
+	RequestedBy    string    `json:"requestedBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TransferEvent records a step of a transfer, who took it and why.
+type TransferEvent struct {
+	ID         string    `json:"id"`
+	TransferID string    `json:"transferId"`
+	Action     string    `json:"action"`         // "requested", "approved", "declined", "cancelled", "completed", "expired"
+	Club       string    `json:"club,omitempty"` // "from" or "to", for approvals
+	ActorID    string    `json:"actorId"`        // Empty when the transfer expired
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Card is a caution or sending-off shown to a player in a match.
+type Card struct {
+	ID         string    `json:"id"`
+	MatchID    string    `json:"matchId"`
+	SeriesID   string    `json:"seriesId"`
+	TeamID     string    `json:"teamId"`
+	PlayerID   string    `json:"playerId"`
+	Type       string    `json:"type"`             // "yellow", "second_yellow" or "red"
+	Minute     int       `json:"minute,omitempty"` // 0 when not known
+	Reason     string    `json:"reason,omitempty"`
+	RecordedBy string    `json:"recordedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Suspension bans a player from the next matches of the team they were
+// suspended with, counted from the match of the offence.
+type Suspension struct {
+	ID       string `json:"id"`
+	SeriesID string `json:"seriesId"`
+	PlayerID string `json:"playerId"`
+	TeamID   string `json:"teamId"`
+	MatchID  string `json:"matchId"`          // Match of the offence
+	CardID   string `json:"cardId,omitempty"` // Card that triggered a rule; empty for committee decisions
+	Matches  int    `json:"matches"`
+	Reason   string `json:"reason"`
+	// Matches the team has completed since the offence; worked out, not stored
+	Served    int       `json:"served"`
+	CreatedBy string    `json:"createdBy"` // Empty for automatic suspensions
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// MatchSheet lists the players a team fields in a match.
+type MatchSheet struct {
+	MatchID     string    `json:"matchId"`
+	TeamID      string    `json:"teamId"`
+	PlayerIDs   []string  `json:"playerIds"`
+	SubmittedBy string    `json:"submittedBy"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// MatchEvent is a moment of a match posted live by a match official. Seq
+// numbers the events of a match from 1 and doubles as the SSE event ID.
+type MatchEvent struct {
+	MatchID string `json:"matchId"`
+	Seq     int64  `json:"seq"`
+	// "period_start", "period_end", "goal", "card", "substitution" or "retraction"
+	Type        string `json:"type"`
+	Period      int    `json:"period"`
+	Minute      int    `json:"minute,omitempty"` // 0 when not known
+	TeamID      string `json:"teamId,omitempty"`
+	PlayerID    string `json:"playerId,omitempty"`    // Scorer, carded player or player coming on
+	PlayerOffID string `json:"playerOffId,omitempty"` // Player going off in a substitution
+	Card        string `json:"card,omitempty"`        // "yellow", "second_yellow" or "red"
+	OwnGoal     bool   `json:"ownGoal,omitempty"`     // Counts for the other team
+	Final       bool   `json:"final,omitempty"`       // A period_end that is the final whistle
+	// Shoot-out score given with the final whistle of a level knockout tie
+	HomePenalties *int   `json:"homePenalties,omitempty"`
+	AwayPenalties *int   `json:"awayPenalties,omitempty"`
+	Retracts      int64  `json:"retracts,omitempty"` // Seq of the event a retraction withdraws
+	Note          string `json:"note,omitempty"`
+	// Score once the event is taken into account
+	HomeScore  int       `json:"homeScore"`
+	AwayScore  int       `json:"awayScore"`
+	RecordedBy string    `json:"recordedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Referee is a match official on a league's register. UserID is the
+// referee's account, whose club memberships rule out matches of those clubs.
+type Referee struct {
+	ID       string   `json:"id"`
+	LeagueID string   `json:"leagueId"`
+	UserID   string   `json:"userId"`
+	Name     string   `json:"name"`
+	Level    int      `json:"level"`   // Certification level, higher is more senior
+	Formats  []string `json:"formats"` // Formats officiated; empty for any
+	Timezone string   `json:"timezone"`
+	// Weekly hours the referee can officiate, in Timezone; none means always
+	Availability []AvailabilityWindow `json:"availability"`
+	Blackouts    []Blackout           `json:"blackouts"` // Days off
+	CreatedAt    time.Time            `json:"createdAt"`
+	UpdatedAt    time.Time            `json:"updatedAt"`
+}
+
+// RefereeAssignment appoints a referee to officiate a match.
+type RefereeAssignment struct {
+	ID         string    `json:"id"`
+	LeagueID   string    `json:"leagueId"`
+	MatchID    string    `json:"matchId"`
+	RefereeID  string    `json:"refereeId"`
+	Role       string    `json:"role"` // "referee" or "assistant"
+	AssignedBy string    `json:"assignedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// The match, read with the assignment
+	SeriesID  string     `json:"seriesId"`
+	KickoffAt *time.Time `json:"kickoffAt,omitempty"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+}
+
+// RefereeSuggestion proposes a referee for a match. Load counts the
+// referee's matches in the season, this one and the earlier proposals
+// included.
+type RefereeSuggestion struct {
+	MatchID   string    `json:"matchId"`
+	KickoffAt time.Time `json:"kickoffAt"`
+	RefereeID string    `json:"refereeId"`
+	Name      string    `json:"name"`
+	Load      int       `json:"load"`
+}
+
+// LiveMatch is the state of a match worked out from its events.
+type LiveMatch struct {
+	MatchID    string `json:"matchId"`
+	HomeTeamID string `json:"homeTeamId"`
+	AwayTeamID string `json:"awayTeamId"`
+	State      string `json:"state"`  // "not_started", "in_play", "interval" or "finished"
+	Period     int    `json:"period"` // Current or last period; 0 before kickoff
+	HomeScore  int    `json:"homeScore"`
+	AwayScore  int    `json:"awayScore"`
+	LastSeq    int64  `json:"lastSeq"`
+}
+
 // Read-only models for validation
 type Team struct {
 	ID        string    `json:"id"`
@@ -47,3 +390,232 @@ type Membership struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+type Match struct {
+	ID         string    `json:"id"`
+	SeriesID   string    `json:"seriesId"`
+	StageID    string    `json:"stageId,omitempty"` // Set for matches of a staged series
+	GroupID    string    `json:"groupId,omitempty"` // Set for group-stage matches
+	TieID      string    `json:"tieId,omitempty"`   // Set for knockout matches
+	Round      int       `json:"round"`
+	Leg        int       `json:"leg"` // 1 for the first leg, 2 for the return leg
+	HomeTeamID string    `json:"homeTeamId"`
+	AwayTeamID string    `json:"awayTeamId"`
+	Status     string    `json:"status"` // "scheduled", "played", "walkover", "abandoned"
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+
+	// Slot the match is booked into; empty until it is scheduled
+	VenueID   string     `json:"venueId,omitempty"`
+	Pitch     int        `json:"pitch,omitempty"`
+	KickoffAt *time.Time `json:"kickoffAt,omitempty"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"` // Kickoff plus the series' match minutes
+	// Revision of the booking, bumped whenever the slot or status changes
+	Sequence int `json:"sequence"`
+
+	Result *MatchResult `json:"result,omitempty"`
+}
+
+// TeamBlackout is a day a team cannot play, e.g. a school trip.
+type TeamBlackout struct {
+	TeamID    string    `json:"teamId"`
+	Date      time.Time `json:"date"` // Midnight UTC
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ScheduleReport is the outcome of scheduling a series automatically: the
+// matches given a slot and the constraints that could not be satisfied.
+type ScheduleReport struct {
+	Seed      int64           `json:"seed"` // Scheduling again with it gives the same slots
+	DryRun    bool            `json:"dryRun"`
+	Feasible  bool            `json:"feasible"` // Every match got a slot and no constraint is broken
+	Scheduled []Match         `json:"scheduled"`
+	Issues    []ScheduleIssue `json:"issues"`
+	// Officials who can no longer take a match that moved
+	Released []RefereeAssignment `json:"released"`
+}
+
+// ScheduleIssue is a constraint a schedule does not satisfy, for a match
+// left without a slot or a team.
+type ScheduleIssue struct {
+	Constraint string `json:"constraint"` // e.g. "rest_days", "club_clash"
+	MatchID    string `json:"matchId,omitempty"`
+	TeamID     string `json:"teamId,omitempty"`
+	Message    string `json:"message"`
+}
+
+// RescheduleRequest asks to move a match to another slot. A club owner of
+// one of the teams proposes it, the opponent's club owner accepts it and a
+// scheduler of the league confirms it, which books the slot.
+type RescheduleRequest struct {
+	ID          string    `json:"id"`
+	MatchID     string    `json:"matchId"`
+	SeriesID    string    `json:"seriesId"`
+	TeamID      string    `json:"teamId"` // The requesting team
+	VenueID     string    `json:"venueId"`
+	Pitch       int       `json:"pitch"`
+	KickoffAt   time.Time `json:"kickoffAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Reason      string    `json:"reason,omitempty"`
+	Status      string    `json:"status"`    // "pending", "accepted", "confirmed", "declined", "rejected", "withdrawn", "expired"
+	ExpiresAt   time.Time `json:"expiresAt"` // Unless settled by then
+	RequestedBy string    `json:"requestedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// RescheduleEvent records a status change of a reschedule request.
+type RescheduleEvent struct {
+	ID         string    `json:"id"`
+	RequestID  string    `json:"requestId"`
+	FromStatus string    `json:"fromStatus"` // Empty for the initial status
+	ToStatus   string    `json:"toStatus"`
+	ActorID    string    `json:"actorId"` // Empty when the request expired
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// CalendarFeed gives access to the iCalendar feed of a team, series or
+// venue. Only the SHA-256 hash of its token is stored.
+type CalendarFeed struct {
+	Kind      string    `json:"kind"` // "team", "series" or "venue"
+	TargetID  string    `json:"targetId"`
+	Token     string    `json:"token,omitempty"` // Returned once, when the feed is issued
+	URL       string    `json:"url,omitempty"`   // Path of the feed, token included
+	TokenHash string    `json:"-"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Calendar is the content of a feed: the booked matches of a team, series or
+// venue, and those whose booking was cancelled.
+type Calendar struct {
+	Name   string          `json:"name"`
+	Events []CalendarEvent `json:"events"`
+}
+
+// CalendarEvent is a match as a calendar event. UID stays the same through
+// every change of the booking, and Sequence grows with each.
+type CalendarEvent struct {
+	UID         string    `json:"uid"`
+	Sequence    int       `json:"sequence"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Cancelled   bool      `json:"cancelled"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Bye records a team that sits out a round when the team count is odd.
+type Bye struct {
+	Round  int    `json:"round"`
+	Leg    int    `json:"leg"`
+	TeamID string `json:"teamId"`
+}
+
+type MatchResult struct {
+	MatchID          string `json:"matchId"`
+	Outcome          string `json:"outcome"` // "played", "walkover", "abandoned"
+	HomeScore        int    `json:"homeScore"`
+	AwayScore        int    `json:"awayScore"`
+	WalkoverWinnerID string `json:"walkoverWinnerId,omitempty"`
+	HomeFairPlay     int    `json:"homeFairPlay"` // Fair-play penalty points, lower is better
+	AwayFairPlay     int    `json:"awayFairPlay"`
+	// Shoot-out score deciding a knockout tie level on aggregate
+	HomePenalties *int      `json:"homePenalties,omitempty"`
+	AwayPenalties *int      `json:"awayPenalties,omitempty"`
+	RecordedBy    string    `json:"recordedBy"`
+	RecordedAt    time.Time `json:"recordedAt"`
+}
+
+// KnockoutTie is one pairing of a single-elimination bracket, played over
+// one or two legs. Ties of later rounds are filled in as winners advance.
+type KnockoutTie struct {
+	ID           string    `json:"id"`
+	SeriesID     string    `json:"seriesId"`
+	StageID      string    `json:"stageId,omitempty"`
+	Round        int       `json:"round"`    // 1 for the first round
+	Position     int       `json:"position"` // Slot within the round, top of the bracket first
+	HomeTeamID   string    `json:"homeTeamId,omitempty"`
+	AwayTeamID   string    `json:"awayTeamId,omitempty"`
+	HomeSeed     int       `json:"homeSeed,omitempty"`
+	AwaySeed     int       `json:"awaySeed,omitempty"`
+	Legs         int       `json:"legs"`
+	WinnerTeamID string    `json:"winnerTeamId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+
+	Matches []Match `json:"matches,omitempty"`
+}
+
+// Stage is a phase of a series played as groups followed by a knockout.
+type Stage struct {
+	ID                 string    `json:"id"`
+	SeriesID           string    `json:"seriesId"`
+	Position           int       `json:"position"` // Order of play, starting at 1
+	Kind               string    `json:"kind"`     // "groups" or "knockout"
+	QualifiersPerGroup int       `json:"qualifiersPerGroup,omitempty"`
+	DrawSeed           *int64    `json:"drawSeed,omitempty"` // Seed used to draw teams into groups
+	CreatedAt          time.Time `json:"createdAt"`
+
+	Groups []Group       `json:"groups,omitempty"`
+	Ties   []KnockoutTie `json:"ties,omitempty"`
+}
+
+type Group struct {
+	ID      string   `json:"id"`
+	StageID string   `json:"stageId"`
+	Name    string   `json:"name"` // e.g., "A", "B"
+	TeamIDs []string `json:"teamIds"`
+
+	Standings []StandingRow `json:"standings,omitempty"`
+}
+
+// DivisionMove is where a team goes next season after finishing a division
+// in Position.
+type DivisionMove struct {
+	TeamID       string `json:"teamId"`
+	FromSeriesID string `json:"fromSeriesId"`
+	FromDivision int    `json:"fromDivision"`
+	Position     int    `json:"position"`
+	Outcome      string `json:"outcome"`           // "promoted", "relegated", "stayed" or "playoff" while undecided
+	Playoff      bool   `json:"playoff,omitempty"` // The outcome was decided by a playoff
+	ToSeriesID   string `json:"toSeriesId,omitempty"`
+	ToDivision   int    `json:"toDivision,omitempty"`
+}
+
+// StandingRow is one team's line in a computed league table.
+type StandingRow struct {
+	Position       int    `json:"position"`
+	TeamID         string `json:"teamId"`
+	Played         int    `json:"played"`
+	Won            int    `json:"won"`
+	Drawn          int    `json:"drawn"`
+	Lost           int    `json:"lost"`
+	GoalsFor       int    `json:"goalsFor"`
+	GoalsAgainst   int    `json:"goalsAgainst"`
+	GoalDifference int    `json:"goalDifference"`
+	Points         int    `json:"points"`
+	FairPlay       int    `json:"fairPlay"`
+	// Tiebreaker that separated this team from others level on points
+	DecidedBy string `json:"decidedBy,omitempty"`
+}
+
+// FairPlayRow is a team's disciplinary record in a series. Points add up
+// the cards and the fair-play points recorded with results; fewer is better.
+type FairPlayRow struct {
+	Position     int    `json:"position"`
+	TeamID       string `json:"teamId"`
+	Played       int    `json:"played"`
+	Yellow       int    `json:"yellow"`
+	SecondYellow int    `json:"secondYellow"`
+	Red          int    `json:"red"`
+	ResultPoints int    `json:"resultPoints"` // Recorded with results, e.g. for officials' conduct
+	Points       int    `json:"points"`
+}
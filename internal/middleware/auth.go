@@ -15,13 +15,13 @@ func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Missing authorization header"})
+			AbortWithProblem(c, http.StatusUnauthorized, "unauthorized", "Missing authorization header")
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authorization header format"})
+			AbortWithProblem(c, http.StatusUnauthorized, "unauthorized", "Invalid authorization header format")
 			return
 		}
 
@@ -34,19 +34,19 @@ func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
+			AbortWithProblem(c, http.StatusUnauthorized, "unauthorized", "Invalid token")
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid token claims"})
+			AbortWithProblem(c, http.StatusUnauthorized, "unauthorized", "Invalid token claims")
 			return
 		}
 
 		sub, ok := claims["sub"].(string)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid token subject"})
+			AbortWithProblem(c, http.StatusUnauthorized, "unauthorized", "Invalid token subject")
 			return
 		}
 
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"team-manager-leagues/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Problem is an RFC 9457 problem details body. Code is a stable,
+// machine-readable name for the kind of error.
+type Problem struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail,omitempty"`
+	Instance string               `json:"instance,omitempty"`
+	Code     string               `json:"code"`
+	Errors   []service.FieldError `json:"errors,omitempty"`
+}
+
+func newProblem(status int, code, detail string) Problem {
+	return Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail, Code: code}
+}
+
+// problemFor maps an error to its problem. Internal errors are logged by the
+// caller and never shown to clients.
+func problemFor(err error) Problem {
+	var p Problem
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		p = newProblem(http.StatusBadRequest, "validation", err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		p = newProblem(http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		p = newProblem(http.StatusForbidden, "forbidden", err.Error())
+	case errors.Is(err, service.ErrConflict):
+		p = newProblem(http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, service.ErrUnavailable):
+		return newProblem(http.StatusServiceUnavailable, "unavailable", "the service is temporarily unavailable, try again later")
+	default:
+		return newProblem(http.StatusInternalServerError, "internal", "an unexpected error occurred")
+	}
+	var svcErr *service.Error
+	if errors.As(err, &svcErr) {
+		p.Errors = svcErr.Fields
+	}
+	return p
+}
+
+func writeProblem(c *gin.Context, p Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	body, _ := json.Marshal(p)
+	if p.Status == http.StatusServiceUnavailable {
+		c.Header("Retry-After", "5")
+	}
+	c.Data(p.Status, "application/problem+json", body)
+}
+
+// AbortWithProblem aborts the request with a problem of the given status.
+func AbortWithProblem(c *gin.Context, status int, code, detail string) {
+	c.Abort()
+	writeProblem(c, newProblem(status, code, detail))
+}
+
+// Errors renders the last error a handler attached with c.Error as a
+// problem+json response.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		p := problemFor(err)
+		if p.Status >= http.StatusInternalServerError {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+		writeProblem(c, p)
+	}
+}
+
+// Recovery turns a panic into an internal error problem.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		AbortWithProblem(c, http.StatusInternalServerError, "internal", "an unexpected error occurred")
+	})
+}
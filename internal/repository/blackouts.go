@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Team Blackouts
+
+// SaveTeamBlackout records a day the team cannot play, or updates its reason.
+func (s *Store) SaveTeamBlackout(ctx context.Context, b *domain.TeamBlackout) error {
+	err := s.Pool.QueryRow(ctx, QUpsertTeamBlackout, b.TeamID, b.Date, b.Reason, b.CreatedBy).Scan(&b.CreatedBy, &b.CreatedAt)
+	return dbError(err)
+}
+
+// ListTeamBlackouts returns the blackouts of the teams between from and to,
+// both inclusive, by date.
+func (s *Store) ListTeamBlackouts(ctx context.Context, teamIDs []string, from, to time.Time) ([]domain.TeamBlackout, error) {
+	rows, err := s.Pool.Query(ctx, QSelectTeamBlackouts, teamIDs, from, to)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.TeamBlackout{}
+	for rows.Next() {
+		var b domain.TeamBlackout
+		if err := rows.Scan(&b.TeamID, &b.Date, &b.Reason, &b.CreatedBy, &b.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, b)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) DeleteTeamBlackout(ctx context.Context, teamID string, date time.Time) error {
+	_, err := s.Pool.Exec(ctx, QDeleteTeamBlackout, teamID, date)
+	return dbError(err)
+}
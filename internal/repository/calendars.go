@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Calendar Feeds
+
+// ListMatchesByTeam returns the booked matches of a team in every league,
+// earliest first.
+func (s *Store) ListMatchesByTeam(ctx context.Context, teamID string) ([]domain.Match, error) {
+	return s.listMatches(ctx, QSelectMatchesByTeam, teamID)
+}
+
+// ListCancelledMatches returns the bookings freed or deleted from the feed
+// of kind ("team", "series" or "venue"), as matches with status
+// "cancelled" and UpdatedAt set to the time of the cancellation.
+func (s *Store) ListCancelledMatches(ctx context.Context, kind, targetID string) ([]domain.Match, error) {
+	var query string
+	switch kind {
+	case "team":
+		query = QSelectCancellationsByTeam
+	case "series":
+		query = QSelectCancellationsBySeries
+	case "venue":
+		query = QSelectCancellationsByVenue
+	default:
+		return nil, fmt.Errorf("unknown calendar kind %q", kind)
+	}
+	rows, err := s.Pool.Query(ctx, query, targetID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Match{}
+	for rows.Next() {
+		m := domain.Match{Status: "cancelled"}
+		if err := rows.Scan(&m.ID, &m.SeriesID, &m.VenueID, &m.Pitch, &m.HomeTeamID, &m.AwayTeamID, &m.Round, &m.KickoffAt, &m.EndsAt, &m.Sequence, &m.UpdatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, m)
+	}
+	return out, dbError(rows.Err())
+}
+
+// SaveCalendarFeed issues the feed, replacing the token of an existing one.
+func (s *Store) SaveCalendarFeed(ctx context.Context, f *domain.CalendarFeed) error {
+	err := s.Pool.QueryRow(ctx, QUpsertCalendarFeed, f.Kind, f.TargetID, f.TokenHash, f.CreatedBy).Scan(&f.CreatedAt)
+	return dbError(err)
+}
+
+func (s *Store) GetCalendarFeed(ctx context.Context, kind, targetID string) (*domain.CalendarFeed, error) {
+	var f domain.CalendarFeed
+	if err := s.Pool.QueryRow(ctx, QSelectCalendarFeed, kind, targetID).Scan(&f.Kind, &f.TargetID, &f.TokenHash, &f.CreatedBy, &f.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &f, nil
+}
+
+func (s *Store) DeleteCalendarFeed(ctx context.Context, kind, targetID string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteCalendarFeed, kind, targetID)
+	return dbError(err)
+}
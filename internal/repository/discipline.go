@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Discipline
+
+func scanCard(row pgx.Row, c *domain.Card) error {
+	return row.Scan(&c.ID, &c.MatchID, &c.SeriesID, &c.TeamID, &c.PlayerID, &c.Type, &c.Minute, &c.Reason, &c.RecordedBy, &c.CreatedAt)
+}
+
+func scanSuspension(row pgx.Row, su *domain.Suspension) error {
+	return row.Scan(&su.ID, &su.SeriesID, &su.PlayerID, &su.TeamID, &su.MatchID, &su.CardID, &su.Matches, &su.Reason, &su.CreatedBy, &su.CreatedAt)
+}
+
+// syncSuspensions inserts the card suspensions now due, skipping those
+// already stored, and deletes those triggered by the cards in drop.
+func syncSuspensions(ctx context.Context, tx pgx.Tx, add []domain.Suspension, drop []string) error {
+	if len(drop) > 0 {
+		if _, err := tx.Exec(ctx, QDeleteCardSuspensions, drop); err != nil {
+			return err
+		}
+	}
+	for _, su := range add {
+		if _, err := tx.Exec(ctx, QInsertSuspension, su.ID, su.SeriesID, su.PlayerID, su.TeamID, su.MatchID, nullable(su.CardID), su.Matches, su.Reason, su.CreatedBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) SaveCard(ctx context.Context, c *domain.Card, add []domain.Suspension, drop []string) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	err = tx.QueryRow(ctx, QInsertCard, c.ID, c.MatchID, c.SeriesID, c.TeamID, c.PlayerID, c.Type, c.Minute, c.Reason, c.RecordedBy).Scan(&c.CreatedAt)
+	if err != nil {
+		return dbError(err)
+	}
+	if err := syncSuspensions(ctx, tx, add, drop); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) DeleteCard(ctx context.Context, id string, add []domain.Suspension, drop []string) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QDeleteCard, id); err != nil {
+		return dbError(err)
+	}
+	if err := syncSuspensions(ctx, tx, add, drop); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) SyncSuspensions(ctx context.Context, add []domain.Suspension, drop []string) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if err := syncSuspensions(ctx, tx, add, drop); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetCard(ctx context.Context, id string) (*domain.Card, error) {
+	var c domain.Card
+	if err := scanCard(s.Pool.QueryRow(ctx, QSelectCard, id), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &c, nil
+}
+
+func (s *Store) listCards(ctx context.Context, query string, args ...any) ([]domain.Card, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Card{}
+	for rows.Next() {
+		var c domain.Card
+		if err := scanCard(rows, &c); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, c)
+	}
+	return out, dbError(rows.Err())
+}
+
+// ListMatchCards returns the cards shown in the match, in the order they
+// were recorded.
+func (s *Store) ListMatchCards(ctx context.Context, matchID string) ([]domain.Card, error) {
+	return s.listCards(ctx, QSelectMatchCards, matchID)
+}
+
+// ListSeriesCards returns the cards shown in the series, in the order they
+// were recorded; with a playerID only those of that player.
+func (s *Store) ListSeriesCards(ctx context.Context, seriesID, playerID string) ([]domain.Card, error) {
+	return s.listCards(ctx, QSelectSeriesCards, seriesID, playerID)
+}
+
+func (s *Store) CreateSuspension(ctx context.Context, su *domain.Suspension) error {
+	err := s.Pool.QueryRow(ctx, QInsertSuspension, su.ID, su.SeriesID, su.PlayerID, su.TeamID, su.MatchID, nullable(su.CardID), su.Matches, su.Reason, su.CreatedBy).Scan(&su.CreatedAt)
+	return dbError(err)
+}
+
+func (s *Store) GetSuspension(ctx context.Context, id string) (*domain.Suspension, error) {
+	var su domain.Suspension
+	if err := scanSuspension(s.Pool.QueryRow(ctx, QSelectSuspension, id), &su); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &su, nil
+}
+
+// ListSuspensions returns the suspensions of the series, oldest first; with
+// a playerID only those of that player.
+func (s *Store) ListSuspensions(ctx context.Context, seriesID, playerID string) ([]domain.Suspension, error) {
+	rows, err := s.Pool.Query(ctx, QSelectSuspensions, seriesID, playerID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Suspension{}
+	for rows.Next() {
+		var su domain.Suspension
+		if err := scanSuspension(rows, &su); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, su)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) DeleteSuspension(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteSuspension, id)
+	return dbError(err)
+}
+
+func (s *Store) SaveMatchSheet(ctx context.Context, sh *domain.MatchSheet) error {
+	err := s.Pool.QueryRow(ctx, QUpsertMatchSheet, sh.MatchID, sh.TeamID, sh.PlayerIDs, sh.SubmittedBy).Scan(&sh.UpdatedAt)
+	return dbError(err)
+}
+
+func (s *Store) ListMatchSheets(ctx context.Context, matchID string) ([]domain.MatchSheet, error) {
+	rows, err := s.Pool.Query(ctx, QSelectMatchSheets, matchID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.MatchSheet{}
+	for rows.Next() {
+		var sh domain.MatchSheet
+		if err := rows.Scan(&sh.MatchID, &sh.TeamID, &sh.PlayerIDs, &sh.SubmittedBy, &sh.UpdatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, sh)
+	}
+	return out, dbError(rows.Err())
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Eligibility overrides
+
+// SaveEligibilityOverride records an organizer override and, when the team is
+// already registered, stores the registration's re-evaluated issues with it.
+func (s *Store) SaveEligibilityOverride(ctx context.Context, o *domain.EligibilityOverride, reg *domain.TeamRegistration) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QUpsertEligibilityOverride, o.ID, o.SeriesID, o.TeamID, o.Rule, o.Reason, o.CreatedBy); err != nil {
+		return dbError(err)
+	}
+	if reg != nil {
+		if _, err := tx.Exec(ctx, QUpdateEligibilityIssues, reg.ID, reg.EligibilityIssues); err != nil {
+			return dbError(err)
+		}
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListEligibilityOverrides(ctx context.Context, seriesID string) ([]domain.EligibilityOverride, error) {
+	return s.listEligibilityOverrides(ctx, QSelectEligibilityOverrides, seriesID)
+}
+
+func (s *Store) ListTeamEligibilityOverrides(ctx context.Context, seriesID, teamID string) ([]domain.EligibilityOverride, error) {
+	return s.listEligibilityOverrides(ctx, QSelectTeamOverrides, seriesID, teamID)
+}
+
+func (s *Store) listEligibilityOverrides(ctx context.Context, query string, args ...any) ([]domain.EligibilityOverride, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.EligibilityOverride{}
+	for rows.Next() {
+		var o domain.EligibilityOverride
+		if err := rows.Scan(&o.ID, &o.SeriesID, &o.TeamID, &o.Rule, &o.Reason, &o.CreatedBy, &o.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, o)
+	}
+	return out, dbError(rows.Err())
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrConflict is wrapped by writes rejected by a unique or foreign key
+	// constraint.
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is wrapped by failures to reach the database.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// ConstraintError reports a write rejected by a database constraint.
+type ConstraintError struct {
+	Constraint string
+	Message    string
+}
+
+func (e *ConstraintError) Error() string { return e.Message }
+func (e *ConstraintError) Unwrap() error { return ErrConflict }
+
+// constraintMessages describes the constraints a client can run into.
+var constraintMessages = map[string]string{
+	"leagues_name_key":                           "a league with this name already exists",
+	"leagues_slug_key":                           "a league with this slug already exists",
+	"series_league_id_name_key":                  "a series with this name already exists in the league",
+	"series_league_name_lower_uidx":              "a series with this name already exists in the league",
+	"series_season_name_lower_uidx":              "a series with this name already exists in the season",
+	"series_season_division_uidx":                "the season already has a series at this division",
+	"seasons_league_name_lower_uidx":             "a season with this name already exists in the league",
+	"team_registrations_team_id_series_id_key":   "the team is already registered for this series",
+	"knockout_ties_series_id_round_position_key": "the bracket already has a tie in this slot",
+	"series_stages_series_id_position_key":       "the series already has a stage at this position",
+	"stage_groups_stage_id_name_key":             "the stage already has a group with this name",
+	"venues_league_name_lower_uidx":              "a venue with this name already exists in the league",
+	"matches_pitch_booking_excl":                 "the pitch is already booked at this time",
+	"reschedule_requests_open_uidx":              "the match already has an open reschedule request",
+	"roster_players_series_player_uidx":          "the player is already on a roster in this series; players move between rosters by transfer",
+	"roster_players_series_player_active_uidx":   "the player is already on a roster in this series; players move between rosters by transfer",
+	"roster_players_shirt_uidx":                  "the shirt number is already taken on this roster",
+	"transfers_pending_uidx":                     "the player already has a pending transfer",
+	"referees_league_user_uidx":                  "the user is already a referee in this league",
+	"referee_assignments_match_referee_uidx":     "the referee is already assigned to this match",
+	"referee_assignments_main_uidx":              "the match already has a referee",
+	"match_events_pkey":                          "another event was posted for the match at the same time; reload and try again",
+}
+
+// UniqueViolation is the error for a write rejected by a unique constraint.
+func UniqueViolation(constraint string) error {
+	msg, ok := constraintMessages[constraint]
+	if !ok {
+		msg = fmt.Sprintf("duplicate value violates %s", constraint)
+	}
+	return &ConstraintError{Constraint: constraint, Message: msg}
+}
+
+// ReferenceViolation is the error for a write referencing a missing row,
+// usually one deleted concurrently.
+func ReferenceViolation(constraint string) error {
+	return &ConstraintError{Constraint: constraint, Message: fmt.Sprintf("referenced record no longer exists (%s)", constraint)}
+}
+
+// dbError translates driver errors into the errors above and passes any
+// other error through unchanged.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		// Exclusion constraints reject overlapping values, e.g. double bookings
+		case pgErr.Code == "23505", pgErr.Code == "23P01":
+			return UniqueViolation(pgErr.ConstraintName)
+		case pgErr.Code == "23503":
+			return ReferenceViolation(pgErr.ConstraintName)
+		// Connection exceptions, insufficient resources and shutdowns
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57P"):
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		return err
+	}
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connErr) || errors.As(err, &netErr) || pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
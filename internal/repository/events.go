@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Match events
+
+func (s *Store) AppendMatchEvent(ctx context.Context, e *domain.MatchEvent) error {
+	err := s.Pool.QueryRow(ctx, QInsertMatchEvent, e.MatchID, e.Seq, e.Type, e.Period, e.Minute, e.TeamID, e.PlayerID, e.PlayerOffID, e.Card, e.OwnGoal, e.Final, e.HomePenalties, e.AwayPenalties, e.Retracts, e.Note, e.HomeScore, e.AwayScore, e.RecordedBy).Scan(&e.CreatedAt)
+	return dbError(err)
+}
+
+func (s *Store) FinishMatch(ctx context.Context, e *domain.MatchEvent, r *domain.MatchResult) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.QueryRow(ctx, QInsertMatchEvent, e.MatchID, e.Seq, e.Type, e.Period, e.Minute, e.TeamID, e.PlayerID, e.PlayerOffID, e.Card, e.OwnGoal, e.Final, e.HomePenalties, e.AwayPenalties, e.Retracts, e.Note, e.HomeScore, e.AwayScore, e.RecordedBy).Scan(&e.CreatedAt); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QUpsertMatchResult, r.MatchID, r.Outcome, r.HomeScore, r.AwayScore, nullable(r.WalkoverWinnerID), r.HomeFairPlay, r.AwayFairPlay, r.HomePenalties, r.AwayPenalties, r.RecordedBy); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QUpdateMatchStatus, r.MatchID, r.Outcome); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListMatchEvents(ctx context.Context, matchID string, afterSeq int64) ([]domain.MatchEvent, error) {
+	rows, err := s.Pool.Query(ctx, QSelectMatchEvents, matchID, afterSeq)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.MatchEvent{}
+	for rows.Next() {
+		var e domain.MatchEvent
+		if err := rows.Scan(&e.MatchID, &e.Seq, &e.Type, &e.Period, &e.Minute, &e.TeamID, &e.PlayerID, &e.PlayerOffID, &e.Card, &e.OwnGoal, &e.Final, &e.HomePenalties, &e.AwayPenalties, &e.Retracts, &e.Note, &e.HomeScore, &e.AwayScore, &e.RecordedBy, &e.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, e)
+	}
+	return out, dbError(rows.Err())
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Knockout Ties
+
+func scanKnockoutTie(row pgx.Row, t *domain.KnockoutTie) error {
+	return row.Scan(&t.ID, &t.SeriesID, &t.StageID, &t.Round, &t.Position, &t.HomeTeamID, &t.AwayTeamID, &t.HomeSeed, &t.AwaySeed, &t.Legs, &t.WinnerTeamID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// nullableSeed maps the zero "unseeded" value to SQL NULL.
+func nullableSeed(seed int) *int {
+	if seed == 0 {
+		return nil
+	}
+	return &seed
+}
+
+// CreateBracket inserts the ties of every round and the matches of the ties
+// that are ready to be played in a single transaction.
+func (s *Store) CreateBracket(ctx context.Context, ties []domain.KnockoutTie, matches []domain.Match) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	for _, t := range ties {
+		if _, err := tx.Exec(ctx, QInsertKnockoutTie, t.ID, t.SeriesID, nullable(t.StageID), t.Round, t.Position, nullable(t.HomeTeamID), nullable(t.AwayTeamID), nullableSeed(t.HomeSeed), nullableSeed(t.AwaySeed), t.Legs, nullable(t.WinnerTeamID)); err != nil {
+			return dbError(err)
+		}
+	}
+	if err := insertMatches(ctx, tx, matches); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListKnockoutTiesBySeries(ctx context.Context, seriesID string) ([]domain.KnockoutTie, error) {
+	rows, err := s.Pool.Query(ctx, QSelectKnockoutTiesBySeries, seriesID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.KnockoutTie{}
+	for rows.Next() {
+		var t domain.KnockoutTie
+		if err := scanKnockoutTie(rows, &t); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, t)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) GetKnockoutTieByID(ctx context.Context, id string) (*domain.KnockoutTie, error) {
+	return s.getKnockoutTie(ctx, QSelectKnockoutTieByID, id)
+}
+
+// GetKnockoutTieBySlot finds the tie at a round and position of a bracket.
+func (s *Store) GetKnockoutTieBySlot(ctx context.Context, seriesID string, round, position int) (*domain.KnockoutTie, error) {
+	return s.getKnockoutTie(ctx, QSelectKnockoutTieBySlot, seriesID, round, position)
+}
+
+func (s *Store) getKnockoutTie(ctx context.Context, query string, args ...any) (*domain.KnockoutTie, error) {
+	var t domain.KnockoutTie
+	if err := scanKnockoutTie(s.Pool.QueryRow(ctx, query, args...), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &t, nil
+}
+
+// AdvanceKnockoutTie records the winner of a tie and, when there is a next
+// round, updates the tie it feeds: its previous matches are replaced by
+// nextMatches, which is empty until both of its teams are known.
+func (s *Store) AdvanceKnockoutTie(ctx context.Context, tie, next *domain.KnockoutTie, nextMatches []domain.Match) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QUpdateKnockoutTie, tie.ID, nullable(tie.HomeTeamID), nullable(tie.AwayTeamID), nullable(tie.WinnerTeamID)); err != nil {
+		return dbError(err)
+	}
+	if next != nil {
+		if _, err := tx.Exec(ctx, QUpdateKnockoutTie, next.ID, nullable(next.HomeTeamID), nullable(next.AwayTeamID), nullable(next.WinnerTeamID)); err != nil {
+			return dbError(err)
+		}
+		if _, err := tx.Exec(ctx, QDeleteMatchesByTie, next.ID); err != nil {
+			return dbError(err)
+		}
+		if err := insertMatches(ctx, tx, nextMatches); err != nil {
+			return dbError(err)
+		}
+	}
+	return dbError(tx.Commit(ctx))
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// nullable maps an empty string to SQL NULL.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Matches
+
+func scanMatch(row pgx.Row, m *domain.Match) error {
+	return row.Scan(&m.ID, &m.SeriesID, &m.StageID, &m.GroupID, &m.TieID, &m.Round, &m.Leg, &m.HomeTeamID, &m.AwayTeamID, &m.Status, &m.CreatedAt, &m.UpdatedAt, &m.VenueID, &m.Pitch, &m.KickoffAt, &m.EndsAt, &m.Sequence)
+}
+
+func insertMatches(ctx context.Context, tx pgx.Tx, matches []domain.Match) error {
+	for _, m := range matches {
+		if _, err := tx.Exec(ctx, QInsertMatch, m.ID, m.SeriesID, nullable(m.StageID), nullable(m.GroupID), nullable(m.TieID), m.Round, m.Leg, m.HomeTeamID, m.AwayTeamID, m.Status); err != nil {
+			return dbError(err)
+		}
+	}
+	return nil
+}
+
+// CreateFixtures inserts the matches of a series in a single transaction
+// unless the series already has matches, and reports whether it did. The
+// series row stays locked until commit, so concurrent calls take turns.
+func (s *Store) CreateFixtures(ctx context.Context, seriesID string, matches []domain.Match) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QLockSeries, seriesID); err != nil {
+		return false, dbError(err)
+	}
+	var exists bool
+	if err := tx.QueryRow(ctx, QSeriesHasMatches, seriesID).Scan(&exists); err != nil {
+		return false, dbError(err)
+	}
+	if exists {
+		return false, nil
+	}
+	if err := insertMatches(ctx, tx, matches); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) listMatches(ctx context.Context, query string, args ...any) ([]domain.Match, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Match{}
+	for rows.Next() {
+		var m domain.Match
+		if err := scanMatch(rows, &m); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, m)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) ListMatchesBySeries(ctx context.Context, seriesID string) ([]domain.Match, error) {
+	return s.listMatches(ctx, QSelectMatchesBySeries, seriesID)
+}
+
+func (s *Store) ListMatchesByTie(ctx context.Context, tieID string) ([]domain.Match, error) {
+	return s.listMatches(ctx, QSelectMatchesByTie, tieID)
+}
+
+// DeleteMatchesBySeries removes every match of the series, bracket and
+// stages included. Locking the matches holds off results and events, whose
+// inserts reference them, until the check is done.
+func (s *Store) DeleteMatchesBySeries(ctx context.Context, seriesID string) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QLockSeriesMatches, seriesID); err != nil {
+		return false, dbError(err)
+	}
+	var played bool
+	if err := tx.QueryRow(ctx, QSeriesHasPlayed, seriesID).Scan(&played); err != nil {
+		return false, dbError(err)
+	}
+	if played {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, QDeleteMatchesBySeries, seriesID); err != nil {
+		return false, dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QDeleteKnockoutTiesBySeries, seriesID); err != nil {
+		return false, dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QDeleteStagesBySeries, seriesID); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetMatchByID(ctx context.Context, id string) (*domain.Match, error) {
+	var m domain.Match
+	if err := scanMatch(s.Pool.QueryRow(ctx, QSelectMatchByID, id), &m); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &m, nil
+}
+
+// ListMatchesByVenue returns the matches booked at the venue whose slot
+// overlaps [from, to), earliest first.
+func (s *Store) ListMatchesByVenue(ctx context.Context, venueID string, from, to time.Time) ([]domain.Match, error) {
+	return s.listMatches(ctx, QSelectMatchesByVenue, venueID, from, to)
+}
+
+// ListMatchesByLeague returns the matches of every series of the league
+// whose slot overlaps [from, to), earliest first.
+func (s *Store) ListMatchesByLeague(ctx context.Context, leagueID string, from, to time.Time) ([]domain.Match, error) {
+	return s.listMatches(ctx, QSelectMatchesByLeague, leagueID, from, to)
+}
+
+// UpdateMatchSlot books the match into its venue, pitch and kickoff, or
+// clears the booking when VenueID is empty.
+func (s *Store) UpdateMatchSlot(ctx context.Context, m *domain.Match, released []string) error {
+	return s.UpdateMatchSlots(ctx, []domain.Match{*m}, released)
+}
+
+// UpdateMatchSlots books or clears the slots of several matches, and sets
+// which of their teams is at home, in a single transaction. The referee
+// assignments released by the moves are deleted in it too.
+func (s *Store) UpdateMatchSlots(ctx context.Context, matches []domain.Match, released []string) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	for _, m := range matches {
+		var pitch *int
+		if m.VenueID != "" {
+			pitch = &m.Pitch
+		}
+		if _, err := tx.Exec(ctx, QUpdateMatchSlot, m.ID, nullable(m.VenueID), pitch, m.KickoffAt, m.EndsAt); err != nil {
+			return dbError(err)
+		}
+		if _, err := tx.Exec(ctx, QUpdateMatchSides, m.ID, m.HomeTeamID, m.AwayTeamID); err != nil {
+			return dbError(err)
+		}
+	}
+	if _, err := tx.Exec(ctx, QDeleteRefereeAssignments, released); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+// Match Results
+
+func scanMatchResult(row pgx.Row, r *domain.MatchResult) error {
+	return row.Scan(&r.MatchID, &r.Outcome, &r.HomeScore, &r.AwayScore, &r.WalkoverWinnerID, &r.HomeFairPlay, &r.AwayFairPlay, &r.HomePenalties, &r.AwayPenalties, &r.RecordedBy, &r.RecordedAt)
+}
+
+// SaveMatchResult stores the result and moves the match to the matching status.
+func (s *Store) SaveMatchResult(ctx context.Context, r *domain.MatchResult) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QUpsertMatchResult, r.MatchID, r.Outcome, r.HomeScore, r.AwayScore, nullable(r.WalkoverWinnerID), r.HomeFairPlay, r.AwayFairPlay, r.HomePenalties, r.AwayPenalties, r.RecordedBy); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QUpdateMatchStatus, r.MatchID, r.Outcome); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetMatchResult(ctx context.Context, matchID string) (*domain.MatchResult, error) {
+	var r domain.MatchResult
+	if err := scanMatchResult(s.Pool.QueryRow(ctx, QSelectMatchResult, matchID), &r); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &r, nil
+}
+
+func (s *Store) ListMatchResultsBySeries(ctx context.Context, seriesID string) ([]domain.MatchResult, error) {
+	rows, err := s.Pool.Query(ctx, QSelectMatchResultsBySeries, seriesID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.MatchResult{}
+	for rows.Next() {
+		var r domain.MatchResult
+		if err := scanMatchResult(rows, &r); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, r)
+	}
+	return out, dbError(rows.Err())
+}
+
+// DeleteMatchResult removes the result and puts the match back to "scheduled".
+func (s *Store) DeleteMatchResult(ctx context.Context, matchID string) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QDeleteMatchResult, matchID); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QUpdateMatchStatus, matchID, "scheduled"); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
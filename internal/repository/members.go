@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// League members
+
+func (s *Store) GetLeagueMember(ctx context.Context, leagueID, userID string) (*domain.LeagueMember, error) {
+	row := s.Pool.QueryRow(ctx, QSelectLeagueMember, leagueID, userID)
+	var m domain.LeagueMember
+	if err := row.Scan(&m.LeagueID, &m.UserID, &m.Role, &m.AddedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &m, nil
+}
+
+func (s *Store) ListLeagueMembers(ctx context.Context, leagueID string) ([]domain.LeagueMember, error) {
+	rows, err := s.Pool.Query(ctx, QSelectLeagueMembers, leagueID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.LeagueMember{}
+	for rows.Next() {
+		var m domain.LeagueMember
+		if err := rows.Scan(&m.LeagueID, &m.UserID, &m.Role, &m.AddedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, m)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) SaveLeagueMember(ctx context.Context, m *domain.LeagueMember) error {
+	_, err := s.Pool.Exec(ctx, QUpsertLeagueMember, m.LeagueID, m.UserID, m.Role, m.AddedBy)
+	return dbError(err)
+}
+
+func (s *Store) DeleteLeagueMember(ctx context.Context, leagueID, userID string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteLeagueMember, leagueID, userID)
+	return dbError(err)
+}
+
+func (s *Store) CountLeagueOwners(ctx context.Context, leagueID string) (int, error) {
+	var n int
+	err := s.Pool.QueryRow(ctx, QCountLeagueOwners, leagueID).Scan(&n)
+	return n, dbError(err)
+}
+
+func (s *Store) IsLeagueParticipant(ctx context.Context, leagueID, userID string) (bool, error) {
+	var ok bool
+	err := s.Pool.QueryRow(ctx, QLeagueParticipantExists, leagueID, userID).Scan(&ok)
+	return ok, dbError(err)
+}
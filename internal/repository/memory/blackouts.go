@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Team Blackouts
+
+func (s *Store) SaveTeamBlackout(ctx context.Context, b *domain.TeamBlackout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.blackouts, func(x *domain.TeamBlackout) bool { return x.TeamID == b.TeamID && x.Date.Equal(b.Date) })
+	if i >= 0 {
+		s.blackouts[i].Reason = b.Reason
+		b.CreatedBy, b.CreatedAt = s.blackouts[i].CreatedBy, s.blackouts[i].CreatedAt
+		return nil
+	}
+	b.CreatedAt = time.Now()
+	s.blackouts = append(s.blackouts, *b)
+	return nil
+}
+
+func (s *Store) ListTeamBlackouts(ctx context.Context, teamIDs []string, from, to time.Time) ([]domain.TeamBlackout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.blackouts, func(x *domain.TeamBlackout) bool {
+		return slices.Contains(teamIDs, x.TeamID) && !x.Date.Before(from) && !x.Date.After(to)
+	})
+	slices.SortStableFunc(out, func(a, b domain.TeamBlackout) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TeamID, b.TeamID)
+	})
+	return out, nil
+}
+
+func (s *Store) DeleteTeamBlackout(ctx context.Context, teamID string, date time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blackouts = filter(s.blackouts, func(x *domain.TeamBlackout) bool { return x.TeamID != teamID || !x.Date.Equal(date) })
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Calendar Feeds
+
+func (s *Store) ListMatchesByTeam(ctx context.Context, teamID string) ([]domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.matches, func(x *domain.Match) bool {
+		return x.KickoffAt != nil && (x.HomeTeamID == teamID || x.AwayTeamID == teamID)
+	})
+	sortBookings(out)
+	return out, nil
+}
+
+func (s *Store) ListCancelledMatches(ctx context.Context, kind, targetID string) ([]domain.Match, error) {
+	var match func(*domain.Match) bool
+	switch kind {
+	case "team":
+		match = func(x *domain.Match) bool { return x.HomeTeamID == targetID || x.AwayTeamID == targetID }
+	case "series":
+		match = func(x *domain.Match) bool { return x.SeriesID == targetID }
+	case "venue":
+		match = func(x *domain.Match) bool { return x.VenueID == targetID }
+	default:
+		return nil, fmt.Errorf("unknown calendar kind %q", kind)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.cancellations, match)
+	sortBookings(out)
+	return out, nil
+}
+
+func (s *Store) SaveCalendarFeed(ctx context.Context, f *domain.CalendarFeed) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f.CreatedAt = time.Now()
+	rec := *f
+	rec.Token, rec.URL = "", ""
+	if i := index(s.feeds, func(x *domain.CalendarFeed) bool { return x.Kind == f.Kind && x.TargetID == f.TargetID }); i >= 0 {
+		s.feeds[i] = rec
+		return nil
+	}
+	s.feeds = append(s.feeds, rec)
+	return nil
+}
+
+func (s *Store) GetCalendarFeed(ctx context.Context, kind, targetID string) (*domain.CalendarFeed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.feeds, func(x *domain.CalendarFeed) bool { return x.Kind == kind && x.TargetID == targetID })
+	if i < 0 {
+		return nil, nil
+	}
+	f := s.feeds[i]
+	return &f, nil
+}
+
+func (s *Store) DeleteCalendarFeed(ctx context.Context, kind, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeds = filter(s.feeds, func(x *domain.CalendarFeed) bool { return x.Kind != kind || x.TargetID != targetID })
+	return nil
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Discipline
+
+// syncSuspensions mirrors the Postgres helper: a card triggers at most one
+// suspension.
+func (s *Store) syncSuspensions(add []domain.Suspension, drop []string) {
+	s.suspensions = filter(s.suspensions, func(x *domain.Suspension) bool { return !slices.Contains(drop, x.CardID) })
+	for _, su := range add {
+		if index(s.suspensions, func(x *domain.Suspension) bool { return su.CardID != "" && x.CardID == su.CardID }) >= 0 {
+			continue
+		}
+		su.CreatedAt = time.Now()
+		s.suspensions = append(s.suspensions, su)
+	}
+}
+
+func (s *Store) SaveCard(ctx context.Context, c *domain.Card, add []domain.Suspension, drop []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == c.MatchID }) < 0 {
+		return errReference("cards_match_id_fkey")
+	}
+	if index(s.cards, func(x *domain.Card) bool { return x.ID == c.ID }) >= 0 {
+		return errUnique("cards_pkey")
+	}
+	c.CreatedAt = time.Now()
+	s.cards = append(s.cards, *c)
+	s.syncSuspensions(add, drop)
+	return nil
+}
+
+func (s *Store) DeleteCard(ctx context.Context, id string, add []domain.Suspension, drop []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards = filter(s.cards, func(x *domain.Card) bool { return x.ID != id })
+	s.syncSuspensions(add, append(drop, id))
+	return nil
+}
+
+func (s *Store) SyncSuspensions(ctx context.Context, add []domain.Suspension, drop []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncSuspensions(add, drop)
+	return nil
+}
+
+func (s *Store) GetCard(ctx context.Context, id string) (*domain.Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.cards, func(x *domain.Card) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	c := s.cards[i]
+	return &c, nil
+}
+
+func (s *Store) ListMatchCards(ctx context.Context, matchID string) ([]domain.Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.cards, func(x *domain.Card) bool { return x.MatchID == matchID }), nil
+}
+
+func (s *Store) ListSeriesCards(ctx context.Context, seriesID, playerID string) ([]domain.Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.cards, func(x *domain.Card) bool {
+		return x.SeriesID == seriesID && (playerID == "" || x.PlayerID == playerID)
+	}), nil
+}
+
+func (s *Store) CreateSuspension(ctx context.Context, su *domain.Suspension) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == su.MatchID }) < 0 {
+		return errReference("suspensions_match_id_fkey")
+	}
+	if index(s.suspensions, func(x *domain.Suspension) bool { return x.ID == su.ID }) >= 0 {
+		return errUnique("suspensions_pkey")
+	}
+	su.CreatedAt = time.Now()
+	s.suspensions = append(s.suspensions, *su)
+	return nil
+}
+
+func (s *Store) GetSuspension(ctx context.Context, id string) (*domain.Suspension, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.suspensions, func(x *domain.Suspension) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	su := s.suspensions[i]
+	return &su, nil
+}
+
+func (s *Store) ListSuspensions(ctx context.Context, seriesID, playerID string) ([]domain.Suspension, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.suspensions, func(x *domain.Suspension) bool {
+		return x.SeriesID == seriesID && (playerID == "" || x.PlayerID == playerID)
+	}), nil
+}
+
+func (s *Store) DeleteSuspension(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suspensions = filter(s.suspensions, func(x *domain.Suspension) bool { return x.ID != id })
+	return nil
+}
+
+func (s *Store) SaveMatchSheet(ctx context.Context, sh *domain.MatchSheet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == sh.MatchID }) < 0 {
+		return errReference("match_sheets_match_id_fkey")
+	}
+	sh.UpdatedAt = time.Now()
+	rec := *sh
+	rec.PlayerIDs = slices.Clone(sh.PlayerIDs)
+	if i := index(s.sheets, func(x *domain.MatchSheet) bool { return x.MatchID == sh.MatchID && x.TeamID == sh.TeamID }); i >= 0 {
+		s.sheets[i] = rec
+		return nil
+	}
+	s.sheets = append(s.sheets, rec)
+	return nil
+}
+
+func (s *Store) ListMatchSheets(ctx context.Context, matchID string) ([]domain.MatchSheet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.sheets, func(x *domain.MatchSheet) bool { return x.MatchID == matchID })
+	for i := range out {
+		out[i].PlayerIDs = slices.Clone(out[i].PlayerIDs)
+	}
+	slices.SortStableFunc(out, func(a, b domain.MatchSheet) int { return strings.Compare(a.TeamID, b.TeamID) })
+	return out, nil
+}
+
+// deleteDiscipline removes the cards, suspensions and match sheets of the
+// matches.
+func (s *Store) deleteDiscipline(gone []domain.Match) {
+	inGone := func(id string) bool {
+		return index(gone, func(m *domain.Match) bool { return m.ID == id }) >= 0
+	}
+	s.cards = filter(s.cards, func(x *domain.Card) bool { return !inGone(x.MatchID) })
+	s.suspensions = filter(s.suspensions, func(x *domain.Suspension) bool { return !inGone(x.MatchID) })
+	s.sheets = filter(s.sheets, func(x *domain.MatchSheet) bool { return !inGone(x.MatchID) })
+}
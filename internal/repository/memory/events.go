@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Match events
+
+func cloneMatchEvent(e domain.MatchEvent) domain.MatchEvent {
+	e.HomePenalties = clonePtr(e.HomePenalties)
+	e.AwayPenalties = clonePtr(e.AwayPenalties)
+	return e
+}
+
+func (s *Store) AppendMatchEvent(ctx context.Context, e *domain.MatchEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == e.MatchID }) < 0 {
+		return errReference("match_events_match_id_fkey")
+	}
+	if index(s.matchEvents, func(x *domain.MatchEvent) bool { return x.MatchID == e.MatchID && x.Seq == e.Seq }) >= 0 {
+		return errUnique("match_events_pkey")
+	}
+	e.CreatedAt = time.Now()
+	s.matchEvents = append(s.matchEvents, cloneMatchEvent(*e))
+	return nil
+}
+
+func (s *Store) FinishMatch(ctx context.Context, e *domain.MatchEvent, r *domain.MatchResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == e.MatchID }) < 0 {
+		return errReference("match_events_match_id_fkey")
+	}
+	if index(s.matchEvents, func(x *domain.MatchEvent) bool { return x.MatchID == e.MatchID && x.Seq == e.Seq }) >= 0 {
+		return errUnique("match_events_pkey")
+	}
+	now := time.Now()
+	e.CreatedAt = now
+	s.matchEvents = append(s.matchEvents, cloneMatchEvent(*e))
+	rec := cloneResult(*r)
+	rec.RecordedAt = now
+	if i := index(s.results, func(x *domain.MatchResult) bool { return x.MatchID == r.MatchID }); i >= 0 {
+		s.results[i] = rec
+	} else {
+		s.results = append(s.results, rec)
+	}
+	s.setMatchStatus(r.MatchID, r.Outcome)
+	return nil
+}
+
+// ListMatchEvents relies on events being appended in Seq order, which the
+// service guarantees by numbering each one after the last it has read.
+func (s *Store) ListMatchEvents(ctx context.Context, matchID string, afterSeq int64) ([]domain.MatchEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.MatchEvent{}
+	for _, e := range s.matchEvents {
+		if e.MatchID == matchID && e.Seq > afterSeq {
+			out = append(out, cloneMatchEvent(e))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,529 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Matches
+
+// checkMatches validates a batch before any of it is stored, so a failing
+// batch leaves nothing behind, as a rolled back transaction would.
+func (s *Store) checkMatches(matches []domain.Match) error {
+	for i, m := range matches {
+		if index(s.series, func(x *domain.Series) bool { return x.ID == m.SeriesID }) < 0 {
+			return errReference("matches_series_id_fkey")
+		}
+		if m.StageID != "" && index(s.stages, func(x *domain.Stage) bool { return x.ID == m.StageID }) < 0 {
+			return errReference("matches_stage_id_fkey")
+		}
+		if m.GroupID != "" && index(s.groups, func(x *domain.Group) bool { return x.ID == m.GroupID }) < 0 {
+			return errReference("matches_group_id_fkey")
+		}
+		if m.TieID != "" && index(s.ties, func(x *domain.KnockoutTie) bool { return x.ID == m.TieID }) < 0 {
+			return errReference("matches_tie_id_fkey")
+		}
+		dup := func(x *domain.Match) bool { return x.ID == m.ID }
+		if index(s.matches, dup) >= 0 || index(matches[:i], dup) >= 0 {
+			return errUnique("matches_pkey")
+		}
+	}
+	return nil
+}
+
+func (s *Store) insertMatches(matches []domain.Match) {
+	now := time.Now()
+	for _, m := range matches {
+		m.Result = nil
+		m.CreatedAt, m.UpdatedAt = now, now
+		s.matches = append(s.matches, m)
+	}
+}
+
+func (s *Store) CreateFixtures(ctx context.Context, seriesID string, matches []domain.Match) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.SeriesID == seriesID }) >= 0 {
+		return false, nil
+	}
+	if err := s.checkMatches(matches); err != nil {
+		return false, err
+	}
+	s.insertMatches(matches)
+	return true, nil
+}
+
+func (s *Store) ListMatchesBySeries(ctx context.Context, seriesID string) ([]domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.matches, func(x *domain.Match) bool { return x.SeriesID == seriesID })
+	slices.SortStableFunc(out, func(a, b domain.Match) int {
+		if a.Round != b.Round {
+			return a.Round - b.Round
+		}
+		return a.Leg - b.Leg
+	})
+	return out, nil
+}
+
+func (s *Store) ListMatchesByTie(ctx context.Context, tieID string) ([]domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.matches, func(x *domain.Match) bool { return x.TieID == tieID })
+	slices.SortStableFunc(out, func(a, b domain.Match) int { return a.Leg - b.Leg })
+	return out, nil
+}
+
+// DeleteMatchesBySeries removes every match of the series, bracket and
+// stages included.
+func (s *Store) DeleteMatchesBySeries(ctx context.Context, seriesID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.matches {
+		if m.SeriesID != seriesID {
+			continue
+		}
+		if m.Status != "scheduled" || index(s.results, func(r *domain.MatchResult) bool { return r.MatchID == m.ID }) >= 0 ||
+			index(s.matchEvents, func(e *domain.MatchEvent) bool { return e.MatchID == m.ID }) >= 0 {
+			return false, nil
+		}
+	}
+	s.deleteMatches(func(m *domain.Match) bool { return m.SeriesID == seriesID })
+	s.deleteTies(func(t *domain.KnockoutTie) bool { return t.SeriesID == seriesID })
+	s.deleteStages(func(st *domain.Stage) bool { return st.SeriesID == seriesID })
+	return true, nil
+}
+
+// deleteMatches removes the matching matches and their results. Booked
+// matches leave a cancellation behind.
+func (s *Store) deleteMatches(match func(*domain.Match) bool) {
+	gone := filter(s.matches, match)
+	for _, m := range gone {
+		if m.KickoffAt != nil {
+			s.cancellations = cancelBooking(s.cancellations, m, m.Sequence+1)
+		}
+	}
+	s.deleteRescheduleRequests(func(r *domain.RescheduleRequest) bool {
+		return index(gone, func(m *domain.Match) bool { return m.ID == r.MatchID }) >= 0
+	})
+	s.results = filter(s.results, func(r *domain.MatchResult) bool {
+		return index(gone, func(m *domain.Match) bool { return m.ID == r.MatchID }) < 0
+	})
+	s.deleteDiscipline(gone)
+	s.matchEvents = filter(s.matchEvents, func(e *domain.MatchEvent) bool {
+		return index(gone, func(m *domain.Match) bool { return m.ID == e.MatchID }) < 0
+	})
+	s.assignments = filter(s.assignments, func(a *domain.RefereeAssignment) bool {
+		return index(gone, func(m *domain.Match) bool { return m.ID == a.MatchID }) < 0
+	})
+	s.matches = filter(s.matches, func(m *domain.Match) bool { return !match(m) })
+}
+
+func (s *Store) GetMatchByID(ctx context.Context, id string) (*domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.matches, func(x *domain.Match) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	m := s.matches[i]
+	return &m, nil
+}
+
+func (s *Store) ListMatchesByVenue(ctx context.Context, venueID string, from, to time.Time) ([]domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.matches, func(x *domain.Match) bool {
+		return x.VenueID == venueID && x.KickoffAt.Before(to) && x.EndsAt.After(from)
+	})
+	sortBookings(out)
+	return out, nil
+}
+
+func (s *Store) ListMatchesByLeague(ctx context.Context, leagueID string, from, to time.Time) ([]domain.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.matches, func(x *domain.Match) bool {
+		if x.KickoffAt == nil || !x.KickoffAt.Before(to) || !x.EndsAt.After(from) {
+			return false
+		}
+		return index(s.series, func(ser *domain.Series) bool { return ser.ID == x.SeriesID && ser.LeagueID == leagueID }) >= 0
+	})
+	sortBookings(out)
+	return out, nil
+}
+
+func sortBookings(matches []domain.Match) {
+	slices.SortStableFunc(matches, func(a, b domain.Match) int {
+		if c := a.KickoffAt.Compare(*b.KickoffAt); c != 0 {
+			return c
+		}
+		if a.Pitch != b.Pitch {
+			return a.Pitch - b.Pitch
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+func (s *Store) UpdateMatchSlot(ctx context.Context, m *domain.Match, released []string) error {
+	return s.UpdateMatchSlots(ctx, []domain.Match{*m}, released)
+}
+
+// UpdateMatchSlots books or clears the slots of several matches, and sets
+// which of their teams is at home. The batch is checked as a whole, so an
+// overlap with another match on the same pitch stores nothing, as the
+// exclusion constraint does. The released referee assignments are deleted
+// only when the batch is stored.
+func (s *Store) UpdateMatchSlots(ctx context.Context, matches []domain.Match, released []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateMatchSlots(matches, released)
+}
+
+func (s *Store) updateMatchSlots(matches []domain.Match, released []string) error {
+	staged := slices.Clone(s.matches)
+	cancellations := slices.Clone(s.cancellations)
+	now := time.Now()
+	for _, m := range matches {
+		i := index(staged, func(x *domain.Match) bool { return x.ID == m.ID })
+		if i < 0 {
+			continue
+		}
+		cur := &staged[i]
+		old := *cur
+		cur.VenueID, cur.Pitch, cur.KickoffAt, cur.EndsAt = "", 0, nil, nil
+		if m.VenueID != "" {
+			if index(s.venues, func(x *domain.Venue) bool { return x.ID == m.VenueID }) < 0 {
+				return errReference("matches_venue_id_fkey")
+			}
+			cur.VenueID, cur.Pitch, cur.KickoffAt, cur.EndsAt = m.VenueID, m.Pitch, clonePtr(m.KickoffAt), clonePtr(m.EndsAt)
+		}
+		cur.HomeTeamID, cur.AwayTeamID = m.HomeTeamID, m.AwayTeamID
+		if !sameSlot(&old, cur) || old.HomeTeamID != cur.HomeTeamID {
+			cur.Sequence++
+		}
+		switch {
+		case old.KickoffAt != nil && cur.KickoffAt == nil:
+			cancellations = cancelBooking(cancellations, old, cur.Sequence)
+		case old.KickoffAt == nil && cur.KickoffAt != nil:
+			cancellations = filter(cancellations, func(x *domain.Match) bool { return x.ID != m.ID })
+		}
+		cur.UpdatedAt = now
+	}
+	for i, a := range staged {
+		if a.VenueID == "" {
+			continue
+		}
+		clash := index(staged[i+1:], func(b *domain.Match) bool {
+			return b.VenueID == a.VenueID && b.Pitch == a.Pitch && b.KickoffAt.Before(*a.EndsAt) && b.EndsAt.After(*a.KickoffAt)
+		})
+		if clash >= 0 {
+			return errUnique("matches_pitch_booking_excl")
+		}
+	}
+	s.matches, s.cancellations = staged, cancellations
+	s.assignments = filter(s.assignments, func(a *domain.RefereeAssignment) bool { return !slices.Contains(released, a.ID) })
+	return nil
+}
+
+func sameSlot(a, b *domain.Match) bool {
+	sameTime := func(x, y *time.Time) bool { return x == nil && y == nil || x != nil && y != nil && x.Equal(*y) }
+	return a.VenueID == b.VenueID && a.Pitch == b.Pitch && sameTime(a.KickoffAt, b.KickoffAt) && sameTime(a.EndsAt, b.EndsAt)
+}
+
+// cancelBooking records the cancellation of a booked match, once.
+func cancelBooking(cancellations []domain.Match, m domain.Match, sequence int) []domain.Match {
+	if index(cancellations, func(x *domain.Match) bool { return x.ID == m.ID }) >= 0 {
+		return cancellations
+	}
+	c := domain.Match{
+		ID: m.ID, SeriesID: m.SeriesID, VenueID: m.VenueID, Pitch: m.Pitch,
+		HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID, Round: m.Round,
+		KickoffAt: clonePtr(m.KickoffAt), EndsAt: clonePtr(m.EndsAt),
+		Status: "cancelled", Sequence: sequence, UpdatedAt: time.Now(),
+	}
+	return append(cancellations, c)
+}
+
+// Match Results
+
+func cloneResult(r domain.MatchResult) domain.MatchResult {
+	r.HomePenalties = clonePtr(r.HomePenalties)
+	r.AwayPenalties = clonePtr(r.AwayPenalties)
+	return r
+}
+
+func (s *Store) setMatchStatus(matchID, status string) {
+	if i := index(s.matches, func(x *domain.Match) bool { return x.ID == matchID }); i >= 0 {
+		if s.matches[i].Status != status {
+			s.matches[i].Sequence++
+		}
+		s.matches[i].Status, s.matches[i].UpdatedAt = status, time.Now()
+	}
+}
+
+// SaveMatchResult stores the result and moves the match to the matching status.
+func (s *Store) SaveMatchResult(ctx context.Context, r *domain.MatchResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == r.MatchID }) < 0 {
+		return errReference("match_results_match_id_fkey")
+	}
+	rec := cloneResult(*r)
+	rec.RecordedAt = time.Now()
+	if i := index(s.results, func(x *domain.MatchResult) bool { return x.MatchID == r.MatchID }); i >= 0 {
+		s.results[i] = rec
+	} else {
+		s.results = append(s.results, rec)
+	}
+	s.setMatchStatus(r.MatchID, r.Outcome)
+	return nil
+}
+
+func (s *Store) GetMatchResult(ctx context.Context, matchID string) (*domain.MatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.results, func(x *domain.MatchResult) bool { return x.MatchID == matchID })
+	if i < 0 {
+		return nil, nil
+	}
+	r := cloneResult(s.results[i])
+	return &r, nil
+}
+
+func (s *Store) ListMatchResultsBySeries(ctx context.Context, seriesID string) ([]domain.MatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.MatchResult{}
+	for _, r := range s.results {
+		if index(s.matches, func(m *domain.Match) bool { return m.ID == r.MatchID && m.SeriesID == seriesID }) >= 0 {
+			out = append(out, cloneResult(r))
+		}
+	}
+	return out, nil
+}
+
+// DeleteMatchResult removes the result and puts the match back to "scheduled".
+func (s *Store) DeleteMatchResult(ctx context.Context, matchID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = filter(s.results, func(x *domain.MatchResult) bool { return x.MatchID != matchID })
+	s.setMatchStatus(matchID, "scheduled")
+	return nil
+}
+
+// Knockout Ties
+
+func (s *Store) CreateBracket(ctx context.Context, ties []domain.KnockoutTie, matches []domain.Match) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range ties {
+		if index(s.series, func(x *domain.Series) bool { return x.ID == t.SeriesID }) < 0 {
+			return errReference("knockout_ties_series_id_fkey")
+		}
+		if t.StageID != "" && index(s.stages, func(x *domain.Stage) bool { return x.ID == t.StageID }) < 0 {
+			return errReference("knockout_ties_stage_id_fkey")
+		}
+		if index(s.ties, func(x *domain.KnockoutTie) bool { return x.ID == t.ID }) >= 0 ||
+			index(ties[:i], func(x *domain.KnockoutTie) bool { return x.ID == t.ID }) >= 0 {
+			return errUnique("knockout_ties_pkey")
+		}
+		slot := func(x *domain.KnockoutTie) bool {
+			return x.SeriesID == t.SeriesID && x.Round == t.Round && x.Position == t.Position
+		}
+		if index(s.ties, slot) >= 0 || index(ties[:i], slot) >= 0 {
+			return errUnique("knockout_ties_series_id_round_position_key")
+		}
+	}
+	// The new ties must be visible to the match checks, so roll them back
+	// if the matches are rejected.
+	n := len(s.ties)
+	now := time.Now()
+	for _, t := range ties {
+		t.Matches = nil
+		t.CreatedAt, t.UpdatedAt = now, now
+		s.ties = append(s.ties, t)
+	}
+	if err := s.checkMatches(matches); err != nil {
+		s.ties = s.ties[:n]
+		return err
+	}
+	s.insertMatches(matches)
+	return nil
+}
+
+func (s *Store) ListKnockoutTiesBySeries(ctx context.Context, seriesID string) ([]domain.KnockoutTie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.ties, func(x *domain.KnockoutTie) bool { return x.SeriesID == seriesID })
+	slices.SortStableFunc(out, func(a, b domain.KnockoutTie) int {
+		if a.Round != b.Round {
+			return a.Round - b.Round
+		}
+		return a.Position - b.Position
+	})
+	return out, nil
+}
+
+func (s *Store) getKnockoutTie(match func(*domain.KnockoutTie) bool) *domain.KnockoutTie {
+	i := index(s.ties, match)
+	if i < 0 {
+		return nil
+	}
+	t := s.ties[i]
+	return &t
+}
+
+func (s *Store) GetKnockoutTieByID(ctx context.Context, id string) (*domain.KnockoutTie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getKnockoutTie(func(x *domain.KnockoutTie) bool { return x.ID == id }), nil
+}
+
+// GetKnockoutTieBySlot finds the tie at a round and position of a bracket.
+func (s *Store) GetKnockoutTieBySlot(ctx context.Context, seriesID string, round, position int) (*domain.KnockoutTie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getKnockoutTie(func(x *domain.KnockoutTie) bool {
+		return x.SeriesID == seriesID && x.Round == round && x.Position == position
+	}), nil
+}
+
+func (s *Store) updateTie(t *domain.KnockoutTie) {
+	if i := index(s.ties, func(x *domain.KnockoutTie) bool { return x.ID == t.ID }); i >= 0 {
+		cur := &s.ties[i]
+		cur.HomeTeamID, cur.AwayTeamID, cur.WinnerTeamID, cur.UpdatedAt = t.HomeTeamID, t.AwayTeamID, t.WinnerTeamID, time.Now()
+	}
+}
+
+// AdvanceKnockoutTie records the winner of a tie and, when there is a next
+// round, replaces the matches of the tie it feeds with nextMatches.
+func (s *Store) AdvanceKnockoutTie(ctx context.Context, tie, next *domain.KnockoutTie, nextMatches []domain.Match) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if next != nil {
+		// The replaced matches must not count as duplicates of the new ones.
+		kept := filter(s.matches, func(m *domain.Match) bool { return m.TieID != next.ID })
+		all := s.matches
+		s.matches = kept
+		err := s.checkMatches(nextMatches)
+		s.matches = all
+		if err != nil {
+			return err
+		}
+	}
+	s.updateTie(tie)
+	if next != nil {
+		s.updateTie(next)
+		s.deleteMatches(func(m *domain.Match) bool { return m.TieID == next.ID })
+		s.insertMatches(nextMatches)
+	}
+	return nil
+}
+
+// deleteTies removes the matching ties and their matches.
+func (s *Store) deleteTies(match func(*domain.KnockoutTie) bool) {
+	for _, t := range filter(s.ties, match) {
+		s.deleteMatches(func(m *domain.Match) bool { return m.TieID == t.ID })
+	}
+	s.ties = filter(s.ties, func(t *domain.KnockoutTie) bool { return !match(t) })
+}
+
+// Stages
+
+// CreateStages inserts the stages of a series along with their groups, the
+// teams drawn into each group and the group matches.
+func (s *Store) CreateStages(ctx context.Context, stages []domain.Stage, matches []domain.Match) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var groups []domain.Group
+	for i, st := range stages {
+		if index(s.series, func(x *domain.Series) bool { return x.ID == st.SeriesID }) < 0 {
+			return errReference("series_stages_series_id_fkey")
+		}
+		if index(s.stages, func(x *domain.Stage) bool { return x.ID == st.ID }) >= 0 ||
+			index(stages[:i], func(x *domain.Stage) bool { return x.ID == st.ID }) >= 0 {
+			return errUnique("series_stages_pkey")
+		}
+		slot := func(x *domain.Stage) bool { return x.SeriesID == st.SeriesID && x.Position == st.Position }
+		if index(s.stages, slot) >= 0 || index(stages[:i], slot) >= 0 {
+			return errUnique("series_stages_series_id_position_key")
+		}
+		for _, g := range st.Groups {
+			if index(s.groups, func(x *domain.Group) bool { return x.ID == g.ID }) >= 0 ||
+				index(groups, func(x *domain.Group) bool { return x.ID == g.ID }) >= 0 {
+				return errUnique("stage_groups_pkey")
+			}
+			if index(groups, func(x *domain.Group) bool { return x.StageID == st.ID && x.Name == g.Name }) >= 0 {
+				return errUnique("stage_groups_stage_id_name_key")
+			}
+			if len(slices.Compact(slices.Sorted(slices.Values(g.TeamIDs)))) != len(g.TeamIDs) {
+				return errUnique("stage_group_teams_pkey")
+			}
+			groups = append(groups, domain.Group{ID: g.ID, StageID: st.ID, Name: g.Name, TeamIDs: slices.Clone(g.TeamIDs)})
+		}
+	}
+	nStages, nGroups := len(s.stages), len(s.groups)
+	now := time.Now()
+	for _, st := range stages {
+		st.DrawSeed = clonePtr(st.DrawSeed)
+		st.Groups, st.Ties = nil, nil
+		st.CreatedAt = now
+		s.stages = append(s.stages, st)
+	}
+	s.groups = append(s.groups, groups...)
+	if err := s.checkMatches(matches); err != nil {
+		s.stages, s.groups = s.stages[:nStages], s.groups[:nGroups]
+		return err
+	}
+	s.insertMatches(matches)
+	return nil
+}
+
+func (s *Store) ListStagesBySeries(ctx context.Context, seriesID string) ([]domain.Stage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.Stage{}
+	for _, st := range s.stages {
+		if st.SeriesID == seriesID {
+			st.DrawSeed = clonePtr(st.DrawSeed)
+			out = append(out, st)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b domain.Stage) int { return a.Position - b.Position })
+	return out, nil
+}
+
+// ListGroupsBySeries returns the groups of every stage of a series with
+// their teams in draw order.
+func (s *Store) ListGroupsBySeries(ctx context.Context, seriesID string) ([]domain.Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.Group{}
+	for _, g := range s.groups {
+		if index(s.stages, func(st *domain.Stage) bool { return st.ID == g.StageID && st.SeriesID == seriesID }) >= 0 {
+			g.TeamIDs = slices.Clone(g.TeamIDs)
+			if g.TeamIDs == nil {
+				g.TeamIDs = []string{}
+			}
+			out = append(out, g)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b domain.Group) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+// deleteStages removes the matching stages with their groups, ties and
+// matches.
+func (s *Store) deleteStages(match func(*domain.Stage) bool) {
+	for _, st := range filter(s.stages, match) {
+		s.deleteMatches(func(m *domain.Match) bool { return m.StageID == st.ID })
+		s.deleteTies(func(t *domain.KnockoutTie) bool { return t.StageID == st.ID })
+		s.groups = filter(s.groups, func(g *domain.Group) bool { return g.StageID != st.ID })
+	}
+	s.stages = filter(s.stages, func(st *domain.Stage) bool { return !match(st) })
+}
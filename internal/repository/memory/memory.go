@@ -0,0 +1,827 @@
+// Package memory implements repository.Repository in memory. It enforces the
+// same uniqueness rules, references and cascading deletes as the Postgres
+// schema, so the service and the HTTP API can run without a database.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+)
+
+// Store keeps every table as a slice in insertion order, which stands in for
+// created_at ordering. A single mutex makes each method atomic, like the
+// transactions of the Postgres store.
+type Store struct {
+	mu sync.Mutex
+
+	leagues       []domain.League
+	members       []domain.LeagueMember
+	seasons       []domain.Season
+	venues        []domain.Venue
+	series        []domain.Series
+	registrations []domain.TeamRegistration
+	events        []domain.RegistrationEvent
+	overrides     []domain.EligibilityOverride
+	matches       []domain.Match
+	results       []domain.MatchResult
+	ties          []domain.KnockoutTie
+	stages        []domain.Stage
+	groups        []domain.Group // TeamIDs in draw order
+	blackouts     []domain.TeamBlackout
+	cancellations []domain.Match // Freed or deleted bookings, status "cancelled"
+	feeds         []domain.CalendarFeed
+	reschedules   []domain.RescheduleRequest
+	rescheduleLog []domain.RescheduleEvent
+	roster        []domain.RosterPlayer
+	rosterLog     []domain.RosterChange
+	windows       []domain.TransferWindow
+	transfers     []domain.Transfer
+	transferLog   []domain.TransferEvent
+	cards         []domain.Card
+	suspensions   []domain.Suspension
+	sheets        []domain.MatchSheet
+	matchEvents   []domain.MatchEvent
+	referees      []domain.Referee
+	assignments   []domain.RefereeAssignment
+
+	// Owned by the teams service; seeded with Seed.
+	teams       []domain.Team
+	memberships []domain.Membership
+}
+
+var _ repository.Repository = (*Store)(nil)
+
+func NewStore() *Store { return &Store{} }
+
+// Seed loads teams and club memberships, which this service only reads.
+func (s *Store) Seed(teams []domain.Team, memberships []domain.Membership) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams = append(s.teams, teams...)
+	s.memberships = append(s.memberships, memberships...)
+}
+
+// SeedFile is the JSON layout read by LoadSeedFile.
+type SeedFile struct {
+	Teams       []domain.Team       `json:"teams"`
+	Memberships []domain.Membership `json:"memberships"`
+}
+
+// LoadSeedFile seeds the store from a JSON file of teams and memberships.
+func (s *Store) LoadSeedFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var f SeedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.Seed(f.Teams, f.Memberships)
+	return nil
+}
+
+var (
+	errUnique    = repository.UniqueViolation
+	errReference = repository.ReferenceViolation
+)
+
+func index[T any](items []T, match func(*T) bool) int {
+	for i := range items {
+		if match(&items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func filter[T any](items []T, match func(*T) bool) []T {
+	out := []T{}
+	for i := range items {
+		if match(&items[i]) {
+			out = append(out, items[i])
+		}
+	}
+	return out
+}
+
+// page sorts items by the page's sort key and ID and cuts the page out of
+// them. fields returns the ID, name and creation time of an item.
+func page[T any](items []T, p repository.Page, fields func(*T) (id, name string, createdAt time.Time)) ([]T, error) {
+	var key func(*T) string
+	switch p.Sort {
+	case repository.SortCreatedAt:
+		key = func(x *T) string { _, _, t := fields(x); return repository.TimeKey(t) }
+	case repository.SortName:
+		key = func(x *T) string { _, name, _ := fields(x); return name }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", p.Sort)
+	}
+	id := func(x *T) string { id, _, _ := fields(x); return id }
+	cmp := func(a, b *T) int {
+		if c := strings.Compare(key(a), key(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(id(a), id(b))
+	}
+	if p.Desc {
+		asc := cmp
+		cmp = func(a, b *T) int { return asc(b, a) }
+	}
+	slices.SortStableFunc(items, func(a, b T) int { return cmp(&a, &b) })
+	if p.After != nil {
+		after := func(x *T) bool {
+			c := strings.Compare(key(x), p.After.Key)
+			if c == 0 {
+				c = strings.Compare(id(x), p.After.ID)
+			}
+			if p.Desc {
+				return c < 0
+			}
+			return c > 0
+		}
+		items = filter(items, after)
+	}
+	if p.Limit > 0 && len(items) > p.Limit {
+		items = items[:p.Limit]
+	}
+	return items, nil
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// Leagues
+
+func (s *Store) CreateLeague(ctx context.Context, l *domain.League) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkLeagueUnique(l.ID, l.Name, l.Slug); err != nil {
+		return err
+	}
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == l.ID }) >= 0 {
+		return errUnique("leagues_pkey")
+	}
+	now := time.Now()
+	rec := *l
+	rec.CreatedAt, rec.UpdatedAt = now, now
+	s.leagues = append(s.leagues, rec)
+	s.members = append(s.members, domain.LeagueMember{LeagueID: l.ID, UserID: l.CreatedBy, Role: "owner", AddedBy: l.CreatedBy, CreatedAt: now, UpdatedAt: now})
+	return nil
+}
+
+func (s *Store) checkLeagueUnique(id, name, slug string) error {
+	for _, x := range s.leagues {
+		if x.ID == id {
+			continue
+		}
+		if x.Name == name {
+			return errUnique("leagues_name_key")
+		}
+		if x.Slug == slug {
+			return errUnique("leagues_slug_key")
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetLeagueByID(ctx context.Context, id string) (*domain.League, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.leagues, func(x *domain.League) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	l := s.leagues[i]
+	return &l, nil
+}
+
+func (s *Store) ListLeagues(ctx context.Context, f repository.LeagueFilter, p repository.Page) ([]domain.League, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := strings.ToLower(f.NamePrefix)
+	out := filter(s.leagues, func(x *domain.League) bool {
+		return (f.Region == "" || x.Region == f.Region) && strings.HasPrefix(strings.ToLower(x.Name), prefix)
+	})
+	return page(out, p, func(x *domain.League) (string, string, time.Time) { return x.ID, x.Name, x.CreatedAt })
+}
+
+func (s *Store) UpdateLeague(ctx context.Context, id, name, slug, region string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.leagues, func(x *domain.League) bool { return x.ID == id })
+	if i < 0 {
+		return false, nil
+	}
+	if err := s.checkLeagueUnique(id, name, slug); err != nil {
+		return false, err
+	}
+	l := &s.leagues[i]
+	l.Name, l.Slug, l.Region, l.UpdatedAt = name, slug, region, time.Now()
+	return true, nil
+}
+
+func (s *Store) DeleteLeague(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ser := range filter(s.series, func(x *domain.Series) bool { return x.LeagueID == id }) {
+		s.deleteSeries(ser.ID)
+	}
+	s.deleteTransfers(func(t *domain.Transfer) bool {
+		return index(s.windows, func(w *domain.TransferWindow) bool { return w.ID == t.WindowID && w.LeagueID == id }) >= 0
+	})
+	s.windows = filter(s.windows, func(x *domain.TransferWindow) bool { return x.LeagueID != id })
+	s.seasons = filter(s.seasons, func(x *domain.Season) bool { return x.LeagueID != id })
+	s.venues = filter(s.venues, func(x *domain.Venue) bool { return x.LeagueID != id })
+	s.referees = filter(s.referees, func(x *domain.Referee) bool { return x.LeagueID != id })
+	s.members = filter(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID != id })
+	s.leagues = filter(s.leagues, func(x *domain.League) bool { return x.ID != id })
+	return nil
+}
+
+// League members
+
+func (s *Store) GetLeagueMember(ctx context.Context, leagueID, userID string) (*domain.LeagueMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID == leagueID && x.UserID == userID })
+	if i < 0 {
+		return nil, nil
+	}
+	m := s.members[i]
+	return &m, nil
+}
+
+func (s *Store) ListLeagueMembers(ctx context.Context, leagueID string) ([]domain.LeagueMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID == leagueID }), nil
+}
+
+func (s *Store) SaveLeagueMember(ctx context.Context, m *domain.LeagueMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == m.LeagueID }) < 0 {
+		return errReference("league_members_league_id_fkey")
+	}
+	now := time.Now()
+	if i := index(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID == m.LeagueID && x.UserID == m.UserID }); i >= 0 {
+		s.members[i].Role, s.members[i].AddedBy, s.members[i].UpdatedAt = m.Role, m.AddedBy, now
+		return nil
+	}
+	rec := *m
+	rec.CreatedAt, rec.UpdatedAt = now, now
+	s.members = append(s.members, rec)
+	return nil
+}
+
+func (s *Store) DeleteLeagueMember(ctx context.Context, leagueID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = filter(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID != leagueID || x.UserID != userID })
+	return nil
+}
+
+func (s *Store) CountLeagueOwners(ctx context.Context, leagueID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(filter(s.members, func(x *domain.LeagueMember) bool { return x.LeagueID == leagueID && x.Role == "owner" })), nil
+}
+
+func (s *Store) IsLeagueParticipant(ctx context.Context, leagueID, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.referees, func(x *domain.Referee) bool { return x.LeagueID == leagueID && x.UserID == userID }) >= 0 {
+		return true, nil
+	}
+	for _, r := range s.registrations {
+		if r.Status != "pending" && r.Status != "waitlisted" && r.Status != "approved" {
+			continue
+		}
+		ser := index(s.series, func(x *domain.Series) bool { return x.ID == r.SeriesID && x.LeagueID == leagueID })
+		team := index(s.teams, func(x *domain.Team) bool { return x.ID == r.TeamID })
+		if ser < 0 || team < 0 {
+			continue
+		}
+		clubID := s.teams[team].ClubID
+		if index(s.memberships, func(x *domain.Membership) bool {
+			return x.UserID == userID && x.ClubID == clubID && x.Status == "active"
+		}) >= 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Seasons
+
+// CreateSeason adds a season with its series and invitations. Everything is
+// checked before anything is stored, so a rejected season leaves no trace.
+func (s *Store) CreateSeason(ctx context.Context, se *domain.Season, series []domain.Series, regs []domain.TeamRegistration, events []domain.RegistrationEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == se.LeagueID }) < 0 {
+		return errReference("seasons_league_id_fkey")
+	}
+	if index(s.seasons, func(x *domain.Season) bool { return x.ID == se.ID }) >= 0 {
+		return errUnique("seasons_pkey")
+	}
+	if err := s.checkSeasonUnique(se.ID, se.LeagueID, se.Name); err != nil {
+		return err
+	}
+	// Stage the rows on a copy so the checks see the earlier ones.
+	staged := &Store{leagues: s.leagues, seasons: s.seasons, series: s.series, registrations: s.registrations}
+	staged.appendSeason(se)
+	for i := range series {
+		if err := staged.checkNewSeries(&series[i]); err != nil {
+			return err
+		}
+		staged.appendSeries(&series[i])
+	}
+	for i := range regs {
+		if err := staged.checkNewRegistration(&regs[i]); err != nil {
+			return err
+		}
+		staged.appendRegistration(&regs[i])
+	}
+	s.seasons, s.series, s.registrations = staged.seasons, staged.series, staged.registrations
+	for i := range events {
+		s.appendEvent(&events[i])
+	}
+	return nil
+}
+
+func (s *Store) checkSeasonUnique(id, leagueID, name string) error {
+	for _, x := range s.seasons {
+		if x.ID != id && x.LeagueID == leagueID && strings.EqualFold(x.Name, name) {
+			return errUnique("seasons_league_name_lower_uidx")
+		}
+	}
+	return nil
+}
+
+func (s *Store) appendSeason(se *domain.Season) {
+	rec := *se
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = rec.CreatedAt
+	s.seasons = append(s.seasons, rec)
+}
+
+func (s *Store) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.seasons, func(x *domain.Season) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	se := s.seasons[i]
+	return &se, nil
+}
+
+func (s *Store) ListSeasons(ctx context.Context, leagueID string) ([]domain.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.seasons, func(x *domain.Season) bool { return x.LeagueID == leagueID })
+	slices.SortStableFunc(out, func(a, b domain.Season) int {
+		if c := b.StartsOn.Compare(a.StartsOn); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+func (s *Store) UpdateSeason(ctx context.Context, se *domain.Season) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.seasons, func(x *domain.Season) bool { return x.ID == se.ID })
+	if i < 0 {
+		return nil
+	}
+	if err := s.checkSeasonUnique(se.ID, s.seasons[i].LeagueID, se.Name); err != nil {
+		return err
+	}
+	cur := &s.seasons[i]
+	cur.Name, cur.StartsOn, cur.EndsOn, cur.Status, cur.UpdatedAt = se.Name, se.StartsOn, se.EndsOn, se.Status, time.Now()
+	return nil
+}
+
+func (s *Store) DeleteSeason(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ser := range filter(s.series, func(x *domain.Series) bool { return x.SeasonID == id }) {
+		s.deleteSeries(ser.ID)
+	}
+	s.deleteTransfers(func(t *domain.Transfer) bool { return t.SeasonID == id })
+	s.windows = filter(s.windows, func(x *domain.TransferWindow) bool { return x.SeasonID != id })
+	s.seasons = filter(s.seasons, func(x *domain.Season) bool { return x.ID != id })
+	return nil
+}
+
+// Series
+
+func cloneSeries(ser domain.Series) domain.Series {
+	ser.Tiebreakers = slices.Clone(ser.Tiebreakers)
+	ser.LotsSeed = clonePtr(ser.LotsSeed)
+	ser.MaxTeams = clonePtr(ser.MaxTeams)
+	ser.RegistrationOpensAt = clonePtr(ser.RegistrationOpensAt)
+	ser.RegistrationClosesAt = clonePtr(ser.RegistrationClosesAt)
+	ser.Division = clonePtr(ser.Division)
+	ser.MaxRosterSize = clonePtr(ser.MaxRosterSize)
+	ser.RosterLocksAt = clonePtr(ser.RosterLocksAt)
+	ser.DisciplineRules = slices.Clone(ser.DisciplineRules)
+	return ser
+}
+
+func (s *Store) checkSeriesUnique(id, seasonID, name string, division *int) error {
+	for _, x := range s.series {
+		if x.ID == id || x.SeasonID != seasonID {
+			continue
+		}
+		if strings.EqualFold(x.Name, name) {
+			return errUnique("series_season_name_lower_uidx")
+		}
+		if division != nil && x.Division != nil && *x.Division == *division {
+			return errUnique("series_season_division_uidx")
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateSeries(ctx context.Context, ser *domain.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNewSeries(ser); err != nil {
+		return err
+	}
+	s.appendSeries(ser)
+	return nil
+}
+
+func (s *Store) checkNewSeries(ser *domain.Series) error {
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == ser.LeagueID }) < 0 {
+		return errReference("series_league_id_fkey")
+	}
+	if index(s.seasons, func(x *domain.Season) bool { return x.ID == ser.SeasonID }) < 0 {
+		return errReference("series_season_id_fkey")
+	}
+	if index(s.series, func(x *domain.Series) bool { return x.ID == ser.ID }) >= 0 {
+		return errUnique("series_pkey")
+	}
+	return s.checkSeriesUnique(ser.ID, ser.SeasonID, ser.Name, ser.Division)
+}
+
+func (s *Store) appendSeries(ser *domain.Series) {
+	rec := cloneSeries(*ser)
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = rec.CreatedAt
+	s.series = append(s.series, rec)
+}
+
+func (s *Store) ListSeries(ctx context.Context, f repository.SeriesFilter, p repository.Page) ([]domain.Series, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.Series{}
+	for _, x := range s.series {
+		if x.LeagueID == f.LeagueID && (f.SeasonID == "" || x.SeasonID == f.SeasonID) && (f.Format == "" || x.Format == f.Format) {
+			out = append(out, cloneSeries(x))
+		}
+	}
+	return page(out, p, func(x *domain.Series) (string, string, time.Time) { return x.ID, x.Name, x.CreatedAt })
+}
+
+func (s *Store) GetSeriesByID(ctx context.Context, id string) (*domain.Series, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.series, func(x *domain.Series) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	ser := cloneSeries(s.series[i])
+	return &ser, nil
+}
+
+func (s *Store) UpdateSeries(ctx context.Context, ser *domain.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.series, func(x *domain.Series) bool { return x.ID == ser.ID })
+	if i < 0 {
+		return nil
+	}
+	if err := s.checkSeriesUnique(ser.ID, s.series[i].SeasonID, ser.Name, ser.Division); err != nil {
+		return err
+	}
+	cur := s.series[i]
+	rec := cloneSeries(*ser)
+	rec.ID, rec.LeagueID, rec.SeasonID, rec.CreatedAt, rec.UpdatedAt = cur.ID, cur.LeagueID, cur.SeasonID, cur.CreatedAt, time.Now()
+	s.series[i] = rec
+	return nil
+}
+
+func (s *Store) DeleteSeries(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteSeries(id)
+	return nil
+}
+
+// deleteSeries removes a series and cascades to everything referencing it.
+func (s *Store) deleteSeries(id string) {
+	regs := filter(s.registrations, func(x *domain.TeamRegistration) bool { return x.SeriesID == id })
+	s.events = filter(s.events, func(x *domain.RegistrationEvent) bool {
+		return index(regs, func(r *domain.TeamRegistration) bool { return r.ID == x.RegistrationID }) < 0
+	})
+	s.deleteTransfers(func(t *domain.Transfer) bool {
+		return index(regs, func(r *domain.TeamRegistration) bool {
+			return r.ID == t.FromRegistrationID || r.ID == t.ToRegistrationID
+		}) >= 0
+	})
+	s.registrations = filter(s.registrations, func(x *domain.TeamRegistration) bool { return x.SeriesID != id })
+	s.roster = filter(s.roster, func(x *domain.RosterPlayer) bool { return x.SeriesID != id })
+	s.rosterLog = filter(s.rosterLog, func(x *domain.RosterChange) bool { return x.SeriesID != id })
+	s.overrides = filter(s.overrides, func(x *domain.EligibilityOverride) bool { return x.SeriesID != id })
+	s.deleteMatches(func(m *domain.Match) bool { return m.SeriesID == id })
+	s.deleteTies(func(t *domain.KnockoutTie) bool { return t.SeriesID == id })
+	s.deleteStages(func(st *domain.Stage) bool { return st.SeriesID == id })
+	s.series = filter(s.series, func(x *domain.Series) bool { return x.ID != id })
+}
+
+// Team Registrations
+
+func cloneRegistration(tr domain.TeamRegistration) domain.TeamRegistration {
+	tr.EligibilityIssues = slices.Clone(tr.EligibilityIssues)
+	if tr.EligibilityIssues == nil {
+		tr.EligibilityIssues = []domain.EligibilityIssue{}
+	}
+	return tr
+}
+
+func (s *Store) appendEvent(ev *domain.RegistrationEvent) {
+	rec := *ev
+	rec.CreatedAt = time.Now()
+	s.events = append(s.events, rec)
+}
+
+func takesSpot(status string) bool {
+	return status == "pending" || status == "approved"
+}
+
+// seriesFull reports whether the pending and approved registrations of a
+// series have reached its max teams.
+func (s *Store) seriesFull(seriesID string) bool {
+	i := index(s.series, func(x *domain.Series) bool { return x.ID == seriesID })
+	if i < 0 || s.series[i].MaxTeams == nil {
+		return false
+	}
+	taken := filter(s.registrations, func(x *domain.TeamRegistration) bool {
+		return x.SeriesID == seriesID && takesSpot(x.Status)
+	})
+	return len(taken) >= *s.series[i].MaxTeams
+}
+
+func (s *Store) CreateTeamRegistration(ctx context.Context, tr *domain.TeamRegistration, ev *domain.RegistrationEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNewRegistration(tr); err != nil {
+		return err
+	}
+	if s.seriesFull(tr.SeriesID) {
+		tr.Status = "waitlisted"
+		ev.ToStatus = tr.Status
+	}
+	s.appendRegistration(tr)
+	s.appendEvent(ev)
+	return nil
+}
+
+func (s *Store) checkNewRegistration(tr *domain.TeamRegistration) error {
+	if index(s.series, func(x *domain.Series) bool { return x.ID == tr.SeriesID }) < 0 {
+		return errReference("team_registrations_series_id_fkey")
+	}
+	if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == tr.ID }) >= 0 {
+		return errUnique("team_registrations_pkey")
+	}
+	if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.TeamID == tr.TeamID && x.SeriesID == tr.SeriesID }) >= 0 {
+		return errUnique("team_registrations_team_id_series_id_key")
+	}
+	return nil
+}
+
+func (s *Store) appendRegistration(tr *domain.TeamRegistration) {
+	rec := cloneRegistration(*tr)
+	rec.CreatedAt = time.Now()
+	s.registrations = append(s.registrations, rec)
+}
+
+func (s *Store) PromoteWaitlisted(ctx context.Context, seriesID string, newEvent func(tr *domain.TeamRegistration) *domain.RegistrationEvent) ([]domain.TeamRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.TeamRegistration{}
+	for !s.seriesFull(seriesID) {
+		i := index(s.registrations, func(x *domain.TeamRegistration) bool { return x.SeriesID == seriesID && x.Status == "waitlisted" })
+		if i < 0 {
+			break
+		}
+		tr := cloneRegistration(s.registrations[i])
+		ev := newEvent(&tr)
+		s.registrations[i].Status = ev.ToStatus
+		s.appendEvent(ev)
+		tr.Status = ev.ToStatus
+		out = append(out, tr)
+	}
+	return out, nil
+}
+
+func (s *Store) getRegistration(match func(*domain.TeamRegistration) bool) *domain.TeamRegistration {
+	i := index(s.registrations, match)
+	if i < 0 {
+		return nil
+	}
+	tr := cloneRegistration(s.registrations[i])
+	return &tr
+}
+
+func (s *Store) GetRegistrationByID(ctx context.Context, id string) (*domain.TeamRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getRegistration(func(x *domain.TeamRegistration) bool { return x.ID == id }), nil
+}
+
+func (s *Store) GetRegistrationBySlot(ctx context.Context, seriesID, teamID string) (*domain.TeamRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getRegistration(func(x *domain.TeamRegistration) bool { return x.SeriesID == seriesID && x.TeamID == teamID }), nil
+}
+
+func (s *Store) listRegistrations(match func(*domain.TeamRegistration) bool) []domain.TeamRegistration {
+	out := []domain.TeamRegistration{}
+	for _, x := range s.registrations {
+		if match(&x) {
+			out = append(out, cloneRegistration(x))
+		}
+	}
+	return out
+}
+
+func (s *Store) ListRegistrations(ctx context.Context, f repository.RegistrationFilter, p repository.Page) ([]domain.TeamRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.listRegistrations(func(x *domain.TeamRegistration) bool {
+		return (f.TeamID == "" || x.TeamID == f.TeamID) &&
+			(f.SeriesID == "" || x.SeriesID == f.SeriesID) &&
+			(len(f.Statuses) == 0 || slices.Contains(f.Statuses, x.Status)) &&
+			(f.CreatedFrom.IsZero() || !x.CreatedAt.Before(f.CreatedFrom)) &&
+			(f.CreatedTo.IsZero() || x.CreatedAt.Before(f.CreatedTo))
+	})
+	return page(out, p, func(x *domain.TeamRegistration) (string, string, time.Time) { return x.ID, "", x.CreatedAt })
+}
+
+func (s *Store) ListRegistrationsBySeries(ctx context.Context, seriesID string) ([]domain.TeamRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listRegistrations(func(x *domain.TeamRegistration) bool { return x.SeriesID == seriesID }), nil
+}
+
+func (s *Store) UpdateRegistrationStatus(ctx context.Context, id, from, to string, ev *domain.RegistrationEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == id && x.Status == from })
+	if i < 0 {
+		return false, nil
+	}
+	if !takesSpot(from) && takesSpot(to) && s.seriesFull(s.registrations[i].SeriesID) {
+		to = "waitlisted"
+		ev.ToStatus = to
+	}
+	s.registrations[i].Status = to
+	s.appendEvent(ev)
+	return true, nil
+}
+
+func (s *Store) MoveRegistrations(ctx context.Context, changes []domain.RegistrationEvent, regs []domain.TeamRegistration, events []domain.RegistrationEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ev := range changes {
+		if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == ev.RegistrationID && x.Status == ev.FromStatus }) < 0 {
+			return false, nil
+		}
+	}
+	staged := &Store{series: s.series, registrations: s.registrations}
+	for i := range regs {
+		if err := staged.checkNewRegistration(&regs[i]); err != nil {
+			return false, err
+		}
+		staged.appendRegistration(&regs[i])
+	}
+	for i := range changes {
+		j := index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == changes[i].RegistrationID })
+		s.registrations[j].Status = changes[i].ToStatus
+		s.appendEvent(&changes[i])
+	}
+	s.registrations = append(s.registrations, staged.registrations[len(s.registrations):]...)
+	for i := range events {
+		s.appendEvent(&events[i])
+	}
+	return true, nil
+}
+
+func (s *Store) ListRegistrationEvents(ctx context.Context, registrationID string) ([]domain.RegistrationEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.events, func(x *domain.RegistrationEvent) bool { return x.RegistrationID == registrationID }), nil
+}
+
+// Eligibility overrides
+
+func (s *Store) SaveEligibilityOverride(ctx context.Context, o *domain.EligibilityOverride, reg *domain.TeamRegistration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.series, func(x *domain.Series) bool { return x.ID == o.SeriesID }) < 0 {
+		return errReference("eligibility_overrides_series_id_fkey")
+	}
+	now := time.Now()
+	if i := index(s.overrides, func(x *domain.EligibilityOverride) bool {
+		return x.SeriesID == o.SeriesID && x.TeamID == o.TeamID && x.Rule == o.Rule
+	}); i >= 0 {
+		// Like ON CONFLICT DO UPDATE, the override keeps its place and ID.
+		s.overrides[i].Reason, s.overrides[i].CreatedBy, s.overrides[i].CreatedAt = o.Reason, o.CreatedBy, now
+	} else {
+		rec := *o
+		rec.CreatedAt = now
+		s.overrides = append(s.overrides, rec)
+	}
+	if reg != nil {
+		if i := index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == reg.ID }); i >= 0 {
+			s.registrations[i].EligibilityIssues = slices.Clone(reg.EligibilityIssues)
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListEligibilityOverrides(ctx context.Context, seriesID string) ([]domain.EligibilityOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedOverrides(func(x *domain.EligibilityOverride) bool { return x.SeriesID == seriesID }), nil
+}
+
+func (s *Store) ListTeamEligibilityOverrides(ctx context.Context, seriesID, teamID string) ([]domain.EligibilityOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedOverrides(func(x *domain.EligibilityOverride) bool { return x.SeriesID == seriesID && x.TeamID == teamID }), nil
+}
+
+// sortedOverrides orders by created_at, which an upsert refreshes.
+func (s *Store) sortedOverrides(match func(*domain.EligibilityOverride) bool) []domain.EligibilityOverride {
+	out := filter(s.overrides, match)
+	slices.SortStableFunc(out, func(a, b domain.EligibilityOverride) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out
+}
+
+// Read-only directory
+
+func (s *Store) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.teams, func(x *domain.Team) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	t := s.teams[i]
+	return &t, nil
+}
+
+func (s *Store) ListMemberClubs(ctx context.Context, userID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []string{}
+	for _, m := range s.memberships {
+		if m.UserID == userID && m.Status == "active" && !slices.Contains(out, m.ClubID) {
+			out = append(out, m.ClubID)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func (s *Store) IsOwner(ctx context.Context, userID, clubID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.memberships, func(x *domain.Membership) bool {
+		return x.UserID == userID && x.ClubID == clubID && x.Role == "owner" && x.Status == "active"
+	})
+	return i >= 0, nil
+}
@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+)
+
+// checkConstraint fails unless err is the Postgres store's error for the
+// named constraint.
+func checkConstraint(t *testing.T, err error, constraint string) {
+	t.Helper()
+	if constraint == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var ce *repository.ConstraintError
+	if !errors.As(err, &ce) || !errors.Is(err, repository.ErrConflict) || ce.Constraint != constraint {
+		t.Fatalf("got error %v, want %s", err, constraint)
+	}
+}
+
+// newLeague stores league l1 with season s1 and series a1 in it.
+func newLeague(t *testing.T) *Store {
+	t.Helper()
+	ctx := context.Background()
+	s := NewStore()
+	s.Seed([]domain.Team{{ID: "t1", ClubID: "c1", Name: "Team 1"}, {ID: "t2", ClubID: "c2", Name: "Team 2"}}, nil)
+	checkConstraint(t, s.CreateLeague(ctx, &domain.League{ID: "l1", Name: "North", Slug: "north", CreatedBy: "org"}), "")
+	checkConstraint(t, s.CreateSeason(ctx, &domain.Season{ID: "s1", LeagueID: "l1", Name: "2027"}, nil, nil, nil), "")
+	checkConstraint(t, s.CreateSeries(ctx, &domain.Series{ID: "a1", LeagueID: "l1", SeasonID: "s1", Name: "Gold"}), "")
+	return s
+}
+
+func TestLeagueUnique(t *testing.T) {
+	tests := []struct {
+		name       string
+		league     domain.League
+		constraint string
+	}{
+		{"new", domain.League{ID: "l2", Name: "South", Slug: "south"}, ""},
+		{"same id", domain.League{ID: "l1", Name: "South", Slug: "south"}, "leagues_pkey"},
+		{"same name", domain.League{ID: "l2", Name: "North", Slug: "south"}, "leagues_name_key"},
+		{"same slug", domain.League{ID: "l2", Name: "South", Slug: "north"}, "leagues_slug_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newLeague(t)
+			checkConstraint(t, s.CreateLeague(ctx, &tt.league), tt.constraint)
+
+			// Renaming another league runs into the same constraints.
+			s = newLeague(t)
+			checkConstraint(t, s.CreateLeague(ctx, &domain.League{ID: "l3", Name: "East", Slug: "east"}), "")
+			if tt.league.ID == "l1" {
+				return
+			}
+			ok, err := s.UpdateLeague(ctx, "l3", tt.league.Name, tt.league.Slug, "")
+			checkConstraint(t, err, tt.constraint)
+			if ok != (tt.constraint == "") {
+				t.Fatalf("updated %v", ok)
+			}
+		})
+	}
+}
+
+func TestSeriesUnique(t *testing.T) {
+	tests := []struct {
+		name       string
+		series     domain.Series
+		constraint string
+	}{
+		{"new", domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "s1", Name: "Silver"}, ""},
+		{"same id", domain.Series{ID: "a1", LeagueID: "l1", SeasonID: "s1", Name: "Silver"}, "series_pkey"},
+		{"same name", domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "s1", Name: "Gold"}, "series_season_name_lower_uidx"},
+		{"same name in another case", domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "s1", Name: "GOLD"}, "series_season_name_lower_uidx"},
+		{"same name next season", domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "s2", Name: "gold"}, ""},
+		{"missing league", domain.Series{ID: "a2", LeagueID: "nope", SeasonID: "s1", Name: "Silver"}, "series_league_id_fkey"},
+		{"missing season", domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "nope", Name: "Silver"}, "series_season_id_fkey"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newLeague(t)
+			checkConstraint(t, s.CreateSeason(ctx, &domain.Season{ID: "s2", LeagueID: "l1", Name: "2028"}, nil, nil, nil), "")
+			checkConstraint(t, s.CreateSeries(ctx, &tt.series), tt.constraint)
+			list, err := s.ListSeries(ctx, repository.SeriesFilter{LeagueID: "l1"}, repository.Page{Sort: repository.SortCreatedAt})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := 1
+			if tt.constraint == "" {
+				want = 2
+			}
+			if len(list) != want {
+				t.Fatalf("%d series stored, want %d", len(list), want)
+			}
+		})
+	}
+}
+
+func TestRegistrationUnique(t *testing.T) {
+	ctx := context.Background()
+	s := newLeague(t)
+	reg := func(id, seriesID string) error {
+		ev := &domain.RegistrationEvent{ID: "e" + id, RegistrationID: id, ToStatus: "pending"}
+		return s.CreateTeamRegistration(ctx, &domain.TeamRegistration{ID: id, TeamID: "t1", SeriesID: seriesID, Status: "pending"}, ev)
+	}
+	checkConstraint(t, reg("r1", "a1"), "")
+	checkConstraint(t, reg("r2", "a1"), "team_registrations_team_id_series_id_key")
+	checkConstraint(t, reg("r3", "nope"), "team_registrations_series_id_fkey")
+}
+
+func TestCascadeDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(s *Store) error
+		league bool // The league is left
+	}{
+		{"league", func(s *Store) error { return s.DeleteLeague(context.Background(), "l1") }, false},
+		{"season", func(s *Store) error { return s.DeleteSeason(context.Background(), "s1") }, true},
+		{"series", func(s *Store) error { return s.DeleteSeries(context.Background(), "a1") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newLeague(t)
+			for _, id := range []string{"t1", "t2"} {
+				ev := &domain.RegistrationEvent{ID: "e" + id, RegistrationID: "r" + id, ToStatus: "approved"}
+				checkConstraint(t, s.CreateTeamRegistration(ctx, &domain.TeamRegistration{ID: "r" + id, TeamID: id, SeriesID: "a1", Status: "approved"}, ev), "")
+			}
+			ok, err := s.CreateFixtures(ctx, "a1", []domain.Match{{ID: "m1", SeriesID: "a1", Round: 1, Leg: 1, HomeTeamID: "t1", AwayTeamID: "t2", Status: "scheduled"}})
+			if !ok || err != nil {
+				t.Fatalf("create fixtures: %v", err)
+			}
+			checkConstraint(t, s.AppendMatchEvent(ctx, &domain.MatchEvent{MatchID: "m1", Seq: 1, Type: "period_start", Period: 1}), "")
+
+			if err := tt.delete(s); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := s.GetSeriesByID(ctx, "a1"); got != nil {
+				t.Errorf("series left")
+			}
+			if got, _ := s.GetRegistrationByID(ctx, "rt1"); got != nil {
+				t.Errorf("registration left")
+			}
+			if got, _ := s.ListRegistrationEvents(ctx, "rt1"); len(got) > 0 {
+				t.Errorf("registration events left")
+			}
+			if got, _ := s.GetMatchByID(ctx, "m1"); got != nil {
+				t.Errorf("match left")
+			}
+			if got, _ := s.ListMatchEvents(ctx, "m1", 0); len(got) > 0 {
+				t.Errorf("match events left")
+			}
+			if got, _ := s.GetLeagueByID(ctx, "l1"); (got != nil) != tt.league {
+				t.Errorf("league left %v, want %v", got != nil, tt.league)
+			}
+			if got, _ := s.GetLeagueMember(ctx, "l1", "org"); (got != nil) != tt.league {
+				t.Errorf("league owner left %v, want %v", got != nil, tt.league)
+			}
+
+			// The freed names can be taken again.
+			if !tt.league {
+				checkConstraint(t, s.CreateLeague(ctx, &domain.League{ID: "l2", Name: "North", Slug: "north"}), "")
+			} else if tt.name == "series" {
+				checkConstraint(t, s.CreateSeries(ctx, &domain.Series{ID: "a2", LeagueID: "l1", SeasonID: "s1", Name: "Gold"}), "")
+			}
+		})
+	}
+}
+
+func TestReferenceAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newLeague(t)
+	if err := s.DeleteLeague(ctx, "l1"); err != nil {
+		t.Fatal(err)
+	}
+	checkConstraint(t, s.SaveLeagueMember(ctx, &domain.LeagueMember{LeagueID: "l1", UserID: "u"}), "league_members_league_id_fkey")
+	checkConstraint(t, s.CreateSeason(ctx, &domain.Season{ID: "s2", LeagueID: "l1", Name: "2028"}, nil, nil, nil), "seasons_league_id_fkey")
+	ok, err := s.UpdateLeague(ctx, "l1", "North", "north", "")
+	if ok || err != nil {
+		t.Fatalf("updated a deleted league: %v %v", ok, err)
+	}
+}
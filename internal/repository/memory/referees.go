@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Referees
+
+func cloneReferee(r domain.Referee) domain.Referee {
+	r.Formats = slices.Clone(r.Formats)
+	r.Availability = slices.Clone(r.Availability)
+	r.Blackouts = slices.Clone(r.Blackouts)
+	return r
+}
+
+func (s *Store) CreateReferee(ctx context.Context, r *domain.Referee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == r.LeagueID }) < 0 {
+		return errReference("referees_league_id_fkey")
+	}
+	if index(s.referees, func(x *domain.Referee) bool { return x.ID == r.ID }) >= 0 {
+		return errUnique("referees_pkey")
+	}
+	if index(s.referees, func(x *domain.Referee) bool { return x.LeagueID == r.LeagueID && x.UserID == r.UserID }) >= 0 {
+		return errUnique("referees_league_user_uidx")
+	}
+	rec := cloneReferee(*r)
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = rec.CreatedAt
+	s.referees = append(s.referees, rec)
+	return nil
+}
+
+func (s *Store) GetReferee(ctx context.Context, id string) (*domain.Referee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.referees, func(x *domain.Referee) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	r := cloneReferee(s.referees[i])
+	return &r, nil
+}
+
+func (s *Store) ListReferees(ctx context.Context, leagueID string) ([]domain.Referee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.Referee{}
+	for _, r := range s.referees {
+		if r.LeagueID == leagueID {
+			out = append(out, cloneReferee(r))
+		}
+	}
+	slices.SortStableFunc(out, func(a, b domain.Referee) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+func (s *Store) UpdateReferee(ctx context.Context, r *domain.Referee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.referees, func(x *domain.Referee) bool { return x.ID == r.ID })
+	if i < 0 {
+		return nil
+	}
+	cur := s.referees[i]
+	rec := cloneReferee(*r)
+	rec.ID, rec.LeagueID, rec.UserID, rec.CreatedAt, rec.UpdatedAt = cur.ID, cur.LeagueID, cur.UserID, cur.CreatedAt, time.Now()
+	s.referees[i] = rec
+	return nil
+}
+
+func (s *Store) DeleteReferee(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignments = filter(s.assignments, func(x *domain.RefereeAssignment) bool { return x.RefereeID != id })
+	s.referees = filter(s.referees, func(x *domain.Referee) bool { return x.ID != id })
+	return nil
+}
+
+func (s *Store) CreateRefereeAssignment(ctx context.Context, a *domain.RefereeAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == a.MatchID }) < 0 {
+		return errReference("referee_assignments_match_id_fkey")
+	}
+	if index(s.referees, func(x *domain.Referee) bool { return x.ID == a.RefereeID }) < 0 {
+		return errReference("referee_assignments_referee_id_fkey")
+	}
+	if index(s.assignments, func(x *domain.RefereeAssignment) bool { return x.MatchID == a.MatchID && x.RefereeID == a.RefereeID }) >= 0 {
+		return errUnique("referee_assignments_match_referee_uidx")
+	}
+	if a.Role == "referee" && index(s.assignments, func(x *domain.RefereeAssignment) bool { return x.MatchID == a.MatchID && x.Role == "referee" }) >= 0 {
+		return errUnique("referee_assignments_main_uidx")
+	}
+	a.CreatedAt = time.Now()
+	rec := *a
+	rec.SeriesID, rec.KickoffAt, rec.EndsAt = "", nil, nil
+	s.assignments = append(s.assignments, rec)
+	return nil
+}
+
+// withSlot fills in the slot of the assignment's match, as the Postgres
+// store reads it with a join.
+func (s *Store) withSlot(a domain.RefereeAssignment) domain.RefereeAssignment {
+	if i := index(s.matches, func(x *domain.Match) bool { return x.ID == a.MatchID }); i >= 0 {
+		m := s.matches[i]
+		a.SeriesID, a.KickoffAt, a.EndsAt = m.SeriesID, clonePtr(m.KickoffAt), clonePtr(m.EndsAt)
+	}
+	return a
+}
+
+func (s *Store) GetRefereeAssignment(ctx context.Context, id string) (*domain.RefereeAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.assignments, func(x *domain.RefereeAssignment) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	a := s.withSlot(s.assignments[i])
+	return &a, nil
+}
+
+func (s *Store) ListMatchAssignments(ctx context.Context, matchID string) ([]domain.RefereeAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.RefereeAssignment{}
+	for _, a := range s.assignments {
+		if a.MatchID == matchID {
+			out = append(out, s.withSlot(a))
+		}
+	}
+	// The main referee first: "referee" sorts after "assistant"
+	slices.SortStableFunc(out, func(a, b domain.RefereeAssignment) int {
+		return cmp.Or(
+			strings.Compare(b.Role, a.Role),
+			a.CreatedAt.Compare(b.CreatedAt),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+	return out, nil
+}
+
+func (s *Store) ListRefereeAssignments(ctx context.Context, leagueID, refereeID string) ([]domain.RefereeAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.RefereeAssignment{}
+	for _, a := range s.assignments {
+		if a.LeagueID == leagueID && (refereeID == "" || a.RefereeID == refereeID) {
+			out = append(out, s.withSlot(a))
+		}
+	}
+	// Unbooked matches last, as NULLS LAST does
+	slices.SortStableFunc(out, func(a, b domain.RefereeAssignment) int {
+		switch {
+		case a.KickoffAt == nil && b.KickoffAt == nil:
+		case a.KickoffAt == nil:
+			return 1
+		case b.KickoffAt == nil:
+			return -1
+		default:
+			if c := a.KickoffAt.Compare(*b.KickoffAt); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+func (s *Store) DeleteRefereeAssignment(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignments = filter(s.assignments, func(x *domain.RefereeAssignment) bool { return x.ID != id })
+	return nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Reschedule Requests
+
+func openReschedule(status string) bool {
+	return status == "pending" || status == "accepted"
+}
+
+func (s *Store) CreateRescheduleRequest(ctx context.Context, r *domain.RescheduleRequest, ev *domain.RescheduleEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.ID == r.MatchID }) < 0 {
+		return errReference("reschedule_requests_match_id_fkey")
+	}
+	if index(s.venues, func(x *domain.Venue) bool { return x.ID == r.VenueID }) < 0 {
+		return errReference("reschedule_requests_venue_id_fkey")
+	}
+	if index(s.reschedules, func(x *domain.RescheduleRequest) bool { return x.ID == r.ID }) >= 0 {
+		return errUnique("reschedule_requests_pkey")
+	}
+	if openReschedule(r.Status) && index(s.reschedules, func(x *domain.RescheduleRequest) bool {
+		return x.MatchID == r.MatchID && openReschedule(x.Status)
+	}) >= 0 {
+		return errUnique("reschedule_requests_open_uidx")
+	}
+	now := time.Now()
+	r.CreatedAt, r.UpdatedAt = now, now
+	s.reschedules = append(s.reschedules, *r)
+	s.appendRescheduleEvent(ev)
+	return nil
+}
+
+func (s *Store) appendRescheduleEvent(ev *domain.RescheduleEvent) {
+	rec := *ev
+	rec.CreatedAt = time.Now()
+	s.rescheduleLog = append(s.rescheduleLog, rec)
+}
+
+func (s *Store) GetRescheduleRequest(ctx context.Context, id string) (*domain.RescheduleRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.reschedules, func(x *domain.RescheduleRequest) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	r := s.reschedules[i]
+	return &r, nil
+}
+
+func (s *Store) ListRescheduleRequestsByMatch(ctx context.Context, matchID string) ([]domain.RescheduleRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.reschedules, func(x *domain.RescheduleRequest) bool { return x.MatchID == matchID }), nil
+}
+
+func (s *Store) ListRescheduleRequestsByLeague(ctx context.Context, leagueID string, statuses []string) ([]domain.RescheduleRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.reschedules, func(x *domain.RescheduleRequest) bool {
+		if len(statuses) > 0 && !slices.Contains(statuses, x.Status) {
+			return false
+		}
+		return index(s.series, func(ser *domain.Series) bool { return ser.ID == x.SeriesID && ser.LeagueID == leagueID }) >= 0
+	}), nil
+}
+
+// UpdateRescheduleStatus books the slot, if any, before changing the status,
+// so an overlapping booking leaves the request as it was.
+func (s *Store) UpdateRescheduleStatus(ctx context.Context, id, from, to string, ev *domain.RescheduleEvent, slot *domain.Match, released []string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.reschedules, func(x *domain.RescheduleRequest) bool { return x.ID == id && x.Status == from })
+	if i < 0 {
+		return false, nil
+	}
+	if slot != nil {
+		if err := s.updateMatchSlots([]domain.Match{*slot}, released); err != nil {
+			return false, err
+		}
+	}
+	s.reschedules[i].Status, s.reschedules[i].UpdatedAt = to, time.Now()
+	s.appendRescheduleEvent(ev)
+	return true, nil
+}
+
+func (s *Store) ListRescheduleEvents(ctx context.Context, requestID string) ([]domain.RescheduleEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.rescheduleLog, func(x *domain.RescheduleEvent) bool { return x.RequestID == requestID }), nil
+}
+
+// deleteRescheduleRequests removes the matching requests and their history.
+func (s *Store) deleteRescheduleRequests(match func(*domain.RescheduleRequest) bool) {
+	gone := filter(s.reschedules, match)
+	s.rescheduleLog = filter(s.rescheduleLog, func(ev *domain.RescheduleEvent) bool {
+		return index(gone, func(r *domain.RescheduleRequest) bool { return r.ID == ev.RequestID }) < 0
+	})
+	s.reschedules = filter(s.reschedules, func(r *domain.RescheduleRequest) bool { return !match(r) })
+}
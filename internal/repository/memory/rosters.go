@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Rosters
+
+func (s *Store) SaveRosterPlayer(ctx context.Context, p *domain.RosterPlayer, ch *domain.RosterChange, maxSize int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == p.RegistrationID }) < 0 {
+		return false, errReference("roster_players_registration_id_fkey")
+	}
+	others := filter(s.roster, func(x *domain.RosterPlayer) bool {
+		return x.RegistrationID == p.RegistrationID && x.PlayerID != p.PlayerID
+	})
+	if maxSize > 0 && len(others) >= maxSize {
+		return false, nil
+	}
+	if index(s.roster, func(x *domain.RosterPlayer) bool {
+		return x.SeriesID == p.SeriesID && x.PlayerID == p.PlayerID && x.RegistrationID != p.RegistrationID && !s.rosterReleased(x.RegistrationID)
+	}) >= 0 {
+		return false, errUnique("roster_players_series_player_active_uidx")
+	}
+	if index(others, func(x *domain.RosterPlayer) bool { return x.ShirtNumber == p.ShirtNumber }) >= 0 {
+		return false, errUnique("roster_players_shirt_uidx")
+	}
+	now := time.Now()
+	if i := index(s.roster, func(x *domain.RosterPlayer) bool {
+		return x.RegistrationID == p.RegistrationID && x.PlayerID == p.PlayerID
+	}); i >= 0 {
+		cur := &s.roster[i]
+		cur.Name, cur.BirthDate, cur.ShirtNumber, cur.UpdatedAt = p.Name, p.BirthDate, p.ShirtNumber, now
+		*p = *cur
+	} else {
+		p.CreatedAt, p.UpdatedAt = now, now
+		s.roster = append(s.roster, *p)
+	}
+	s.appendRosterChange(ch)
+	return true, nil
+}
+
+// rosterReleased reports whether the registration was ever withdrawn or
+// rejected, which frees its roster's players as the active flag does.
+func (s *Store) rosterReleased(registrationID string) bool {
+	released := func(status string) bool { return status == "withdrawn" || status == "rejected" }
+	if i := index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == registrationID }); i >= 0 && released(s.registrations[i].Status) {
+		return true
+	}
+	return index(s.events, func(x *domain.RegistrationEvent) bool {
+		return x.RegistrationID == registrationID && released(x.ToStatus)
+	}) >= 0
+}
+
+func (s *Store) appendRosterChange(ch *domain.RosterChange) {
+	rec := *ch
+	rec.CreatedAt = time.Now()
+	s.rosterLog = append(s.rosterLog, rec)
+}
+
+func (s *Store) GetRosterPlayer(ctx context.Context, registrationID, playerID string) (*domain.RosterPlayer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.roster, func(x *domain.RosterPlayer) bool { return x.RegistrationID == registrationID && x.PlayerID == playerID })
+	if i < 0 {
+		return nil, nil
+	}
+	p := s.roster[i]
+	return &p, nil
+}
+
+func (s *Store) ListRosterPlayers(ctx context.Context, registrationID string) ([]domain.RosterPlayer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.roster, func(x *domain.RosterPlayer) bool { return x.RegistrationID == registrationID })
+	slices.SortFunc(out, func(a, b domain.RosterPlayer) int { return cmp.Compare(a.ShirtNumber, b.ShirtNumber) })
+	return out, nil
+}
+
+func (s *Store) DeleteRosterPlayer(ctx context.Context, registrationID, playerID string, ch *domain.RosterChange) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	match := func(x *domain.RosterPlayer) bool { return x.RegistrationID == registrationID && x.PlayerID == playerID }
+	if index(s.roster, match) < 0 {
+		return false, nil
+	}
+	s.roster = filter(s.roster, func(x *domain.RosterPlayer) bool { return !match(x) })
+	s.appendRosterChange(ch)
+	return true, nil
+}
+
+func (s *Store) ListRosterChanges(ctx context.Context, registrationID string) ([]domain.RosterChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.rosterLog, func(x *domain.RosterChange) bool { return x.RegistrationID == registrationID }), nil
+}
+
+func (s *Store) ListLateRosterChanges(ctx context.Context, seriesID string) ([]domain.RosterChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.rosterLog, func(x *domain.RosterChange) bool { return x.SeriesID == seriesID && x.Late }), nil
+}
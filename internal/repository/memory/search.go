@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+	"team-manager-leagues/internal/util"
+)
+
+// words splits text into lowercase, unaccented words, like to_tsvector with
+// the simple configuration.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(util.Unaccent(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// rank scores a document of weighted fields against the terms, each of
+// which must prefix a word of some field. A term counts with the weight of
+// the best field it matches, as in ts_rank.
+func rank(terms []string, fields map[float64]string) (float64, bool) {
+	var total float64
+	for _, term := range terms {
+		best := 0.0
+		for weight, text := range fields {
+			if weight > best && slices.ContainsFunc(words(text), func(w string) bool { return strings.HasPrefix(w, term) }) {
+				best = weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total / float64(len(terms)), true
+}
+
+const (
+	weightA = 1.0
+	weightB = 0.4
+)
+
+func (s *Store) Search(ctx context.Context, q repository.SearchQuery) ([]domain.SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	terms := make([]string, len(q.Terms))
+	for i, t := range q.Terms {
+		terms[i] = strings.ToLower(util.Unaccent(t))
+	}
+	out := []domain.SearchResult{}
+	if slices.Contains(q.Types, "league") {
+		for _, l := range s.leagues {
+			if r, ok := rank(terms, map[float64]string{weightA: l.Name, weightB: l.Region}); ok {
+				out = append(out, domain.SearchResult{Type: "league", ID: l.ID, Name: l.Name, Region: l.Region, Rank: r})
+			}
+		}
+	}
+	if slices.Contains(q.Types, "series") {
+		for _, ser := range s.series {
+			i := index(s.leagues, func(x *domain.League) bool { return x.ID == ser.LeagueID })
+			l := s.leagues[i]
+			if r, ok := rank(terms, map[float64]string{weightA: ser.Name, weightB: l.Name + " " + l.Region}); ok {
+				out = append(out, domain.SearchResult{Type: "series", ID: ser.ID, Name: ser.Name, Region: l.Region, LeagueID: l.ID, LeagueName: l.Name, Rank: r})
+			}
+		}
+	}
+	if slices.Contains(q.Types, "team") {
+		for _, t := range s.teams {
+			if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.TeamID == t.ID }) < 0 {
+				continue
+			}
+			if r, ok := rank(terms, map[float64]string{weightA: t.Name}); ok {
+				out = append(out, domain.SearchResult{Type: "team", ID: t.ID, Name: t.Name, ClubID: t.ClubID, Rank: r})
+			}
+		}
+	}
+	slices.SortStableFunc(out, func(a, b domain.SearchResult) int {
+		switch {
+		case a.Rank != b.Rank:
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		case a.Name != b.Name:
+			return strings.Compare(a.Name, b.Name)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+)
+
+// Transfers
+
+func (s *Store) CreateTransferWindow(ctx context.Context, w *domain.TransferWindow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.seasons, func(x *domain.Season) bool { return x.ID == w.SeasonID }) < 0 {
+		return errReference("transfer_windows_season_id_fkey")
+	}
+	if index(s.windows, func(x *domain.TransferWindow) bool { return x.ID == w.ID }) >= 0 {
+		return errUnique("transfer_windows_pkey")
+	}
+	w.CreatedAt = time.Now()
+	rec := *w
+	rec.MaxTransfersPerTeam = clonePtr(w.MaxTransfersPerTeam)
+	s.windows = append(s.windows, rec)
+	return nil
+}
+
+func (s *Store) GetTransferWindow(ctx context.Context, id string) (*domain.TransferWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.windows, func(x *domain.TransferWindow) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	w := s.windows[i]
+	w.MaxTransfersPerTeam = clonePtr(w.MaxTransfersPerTeam)
+	return &w, nil
+}
+
+func (s *Store) ListTransferWindows(ctx context.Context, seasonID string) ([]domain.TransferWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := filter(s.windows, func(x *domain.TransferWindow) bool { return x.SeasonID == seasonID })
+	for i := range out {
+		out[i].MaxTransfersPerTeam = clonePtr(out[i].MaxTransfersPerTeam)
+	}
+	slices.SortStableFunc(out, func(a, b domain.TransferWindow) int { return a.OpensAt.Compare(b.OpensAt) })
+	return out, nil
+}
+
+func (s *Store) DeleteTransferWindow(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteTransfers(func(t *domain.Transfer) bool { return t.WindowID == id })
+	s.windows = filter(s.windows, func(x *domain.TransferWindow) bool { return x.ID != id })
+	return nil
+}
+
+// CreateTransfer stores the transfer, completing it in the same step when
+// m.Player is set; a transfer that cannot complete is taken back.
+func (s *Store) CreateTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m repository.RosterMove) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.windows, func(x *domain.TransferWindow) bool { return x.ID == t.WindowID }) < 0 {
+		return false, errReference("transfers_window_id_fkey")
+	}
+	for _, reg := range []string{t.FromRegistrationID, t.ToRegistrationID} {
+		if index(s.registrations, func(x *domain.TeamRegistration) bool { return x.ID == reg }) < 0 {
+			return false, errReference("transfers_registration_id_fkey")
+		}
+	}
+	if index(s.transfers, func(x *domain.Transfer) bool { return x.ID == t.ID }) >= 0 {
+		return false, errUnique("transfers_pkey")
+	}
+	if t.Status == "pending" && index(s.transfers, func(x *domain.Transfer) bool {
+		return x.SeasonID == t.SeasonID && x.PlayerID == t.PlayerID && x.Status == "pending"
+	}) >= 0 {
+		return false, errUnique("transfers_pending_uidx")
+	}
+	if !s.underTransferCap(t, m.MaxPerTeam) {
+		return false, nil
+	}
+	transfers, log := len(s.transfers), len(s.transferLog)
+	now := time.Now()
+	t.CreatedAt, t.UpdatedAt = now, now
+	s.transfers = append(s.transfers, *t)
+	s.appendTransferEvents(events)
+	if m.Player != nil {
+		if ok, err := s.moveTransferredPlayer(t, m); err != nil || !ok {
+			s.transfers, s.transferLog = s.transfers[:transfers], s.transferLog[:log]
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *Store) appendTransferEvents(events []domain.TransferEvent) {
+	for _, ev := range events {
+		ev.CreatedAt = time.Now()
+		s.transferLog = append(s.transferLog, ev)
+	}
+}
+
+func (s *Store) GetTransfer(ctx context.Context, id string) (*domain.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.transfers, func(x *domain.Transfer) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	t := s.transfers[i]
+	return &t, nil
+}
+
+func (s *Store) ListTransfers(ctx context.Context, seasonID, teamID string) ([]domain.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.transfers, func(x *domain.Transfer) bool {
+		return x.SeasonID == seasonID && (teamID == "" || x.FromTeamID == teamID || x.ToTeamID == teamID)
+	}), nil
+}
+
+func (s *Store) UpdateTransferApprovals(ctx context.Context, t *domain.Transfer, ev *domain.TransferEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.transfers, func(x *domain.Transfer) bool { return x.ID == t.ID && x.Status == "pending" })
+	if i < 0 {
+		return false, nil
+	}
+	cur := &s.transfers[i]
+	cur.FromApprovedBy, cur.ToApprovedBy, cur.UpdatedAt = t.FromApprovedBy, t.ToApprovedBy, time.Now()
+	s.appendTransferEvents([]domain.TransferEvent{*ev})
+	return true, nil
+}
+
+func (s *Store) UpdateTransferStatus(ctx context.Context, id, from, to string, ev *domain.TransferEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.transfers, func(x *domain.Transfer) bool { return x.ID == id && x.Status == from })
+	if i < 0 {
+		return false, nil
+	}
+	s.transfers[i].Status, s.transfers[i].UpdatedAt = to, time.Now()
+	s.appendTransferEvents([]domain.TransferEvent{*ev})
+	return true, nil
+}
+
+func (s *Store) CountWindowTransfers(ctx context.Context, windowID, teamID, exceptID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.countWindowTransfers(windowID, teamID, exceptID), nil
+}
+
+func (s *Store) countWindowTransfers(windowID, teamID, exceptID string) int {
+	return len(filter(s.transfers, func(x *domain.Transfer) bool {
+		return x.WindowID == windowID && (x.Status == "pending" || x.Status == "completed") &&
+			(x.FromTeamID == teamID || x.ToTeamID == teamID) && x.ID != exceptID
+	}))
+}
+
+func (s *Store) underTransferCap(t *domain.Transfer, max int) bool {
+	return max == 0 || s.countWindowTransfers(t.WindowID, t.FromTeamID, t.ID) < max && s.countWindowTransfers(t.WindowID, t.ToTeamID, t.ID) < max
+}
+
+func (s *Store) CompleteTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m repository.RosterMove) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.underTransferCap(t, m.MaxPerTeam) {
+		return false, nil
+	}
+	if ok, err := s.moveTransferredPlayer(t, m); err != nil || !ok {
+		return false, err
+	}
+	s.appendTransferEvents(events)
+	return true, nil
+}
+
+// moveTransferredPlayer completes the pending transfer and moves the player,
+// checking everything before it changes anything.
+func (s *Store) moveTransferredPlayer(t *domain.Transfer, m repository.RosterMove) (bool, error) {
+	p := m.Player
+	others := filter(s.roster, func(x *domain.RosterPlayer) bool {
+		return x.RegistrationID == p.RegistrationID && x.PlayerID != p.PlayerID
+	})
+	if m.MaxRoster > 0 && len(others) >= m.MaxRoster {
+		return false, nil
+	}
+	i := index(s.transfers, func(x *domain.Transfer) bool { return x.ID == t.ID && x.Status == "pending" })
+	out := func(x *domain.RosterPlayer) bool {
+		return x.RegistrationID == t.FromRegistrationID && x.PlayerID == t.PlayerID
+	}
+	if i < 0 || index(s.roster, out) < 0 {
+		return false, nil
+	}
+	// Checked as the new listing would be once the old one is gone.
+	if index(s.roster, func(x *domain.RosterPlayer) bool {
+		return x.SeriesID == p.SeriesID && x.PlayerID == p.PlayerID && !out(x) && !s.rosterReleased(x.RegistrationID)
+	}) >= 0 {
+		return false, errUnique("roster_players_series_player_active_uidx")
+	}
+	if index(others, func(x *domain.RosterPlayer) bool { return x.ShirtNumber == p.ShirtNumber }) >= 0 {
+		return false, errUnique("roster_players_shirt_uidx")
+	}
+	now := time.Now()
+	cur := &s.transfers[i]
+	cur.Status, cur.FromApprovedBy, cur.ToApprovedBy, cur.UpdatedAt = "completed", t.FromApprovedBy, t.ToApprovedBy, now
+	s.roster = filter(s.roster, func(x *domain.RosterPlayer) bool { return !out(x) })
+	p.CreatedAt, p.UpdatedAt = now, now
+	s.roster = append(s.roster, *p)
+	s.appendRosterChange(m.Out)
+	s.appendRosterChange(m.In)
+	return true, nil
+}
+
+func (s *Store) ListTransferEvents(ctx context.Context, transferID string) ([]domain.TransferEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.transferLog, func(x *domain.TransferEvent) bool { return x.TransferID == transferID }), nil
+}
+
+// deleteTransfers removes the matching transfers and their history.
+func (s *Store) deleteTransfers(match func(*domain.Transfer) bool) {
+	gone := filter(s.transfers, match)
+	s.transferLog = filter(s.transferLog, func(ev *domain.TransferEvent) bool {
+		return index(gone, func(t *domain.Transfer) bool { return t.ID == ev.TransferID }) < 0
+	})
+	s.transfers = filter(s.transfers, func(t *domain.Transfer) bool { return !match(t) })
+}
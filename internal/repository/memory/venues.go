@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Venues
+
+func cloneVenue(v domain.Venue) domain.Venue {
+	v.Latitude = clonePtr(v.Latitude)
+	v.Longitude = clonePtr(v.Longitude)
+	v.Pitches = slices.Clone(v.Pitches)
+	for i := range v.Pitches {
+		v.Pitches[i].Formats = slices.Clone(v.Pitches[i].Formats)
+	}
+	v.Availability = slices.Clone(v.Availability)
+	for i := range v.Availability {
+		v.Availability[i].Pitches = slices.Clone(v.Availability[i].Pitches)
+	}
+	v.Blackouts = slices.Clone(v.Blackouts)
+	for i := range v.Blackouts {
+		v.Blackouts[i].Pitches = slices.Clone(v.Blackouts[i].Pitches)
+	}
+	return v
+}
+
+func (s *Store) checkVenueUnique(id, leagueID, name string) error {
+	for _, x := range s.venues {
+		if x.ID != id && x.LeagueID == leagueID && strings.EqualFold(x.Name, name) {
+			return errUnique("venues_league_name_lower_uidx")
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateVenue(ctx context.Context, v *domain.Venue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.leagues, func(x *domain.League) bool { return x.ID == v.LeagueID }) < 0 {
+		return errReference("venues_league_id_fkey")
+	}
+	if index(s.venues, func(x *domain.Venue) bool { return x.ID == v.ID }) >= 0 {
+		return errUnique("venues_pkey")
+	}
+	if err := s.checkVenueUnique(v.ID, v.LeagueID, v.Name); err != nil {
+		return err
+	}
+	rec := cloneVenue(*v)
+	rec.CreatedAt = time.Now()
+	rec.UpdatedAt = rec.CreatedAt
+	s.venues = append(s.venues, rec)
+	return nil
+}
+
+func (s *Store) GetVenueByID(ctx context.Context, id string) (*domain.Venue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.venues, func(x *domain.Venue) bool { return x.ID == id })
+	if i < 0 {
+		return nil, nil
+	}
+	v := cloneVenue(s.venues[i])
+	return &v, nil
+}
+
+func (s *Store) ListVenues(ctx context.Context, leagueID string) ([]domain.Venue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []domain.Venue{}
+	for _, v := range s.venues {
+		if v.LeagueID == leagueID {
+			out = append(out, cloneVenue(v))
+		}
+	}
+	slices.SortStableFunc(out, func(a, b domain.Venue) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+func (s *Store) UpdateVenue(ctx context.Context, v *domain.Venue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := index(s.venues, func(x *domain.Venue) bool { return x.ID == v.ID })
+	if i < 0 {
+		return nil
+	}
+	if err := s.checkVenueUnique(v.ID, s.venues[i].LeagueID, v.Name); err != nil {
+		return err
+	}
+	cur := s.venues[i]
+	rec := cloneVenue(*v)
+	rec.ID, rec.LeagueID, rec.CreatedAt, rec.UpdatedAt = cur.ID, cur.LeagueID, cur.CreatedAt, time.Now()
+	s.venues[i] = rec
+	return nil
+}
+
+// DeleteVenue refuses while matches are booked into the venue, as the
+// foreign key from matches does.
+func (s *Store) DeleteVenue(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index(s.matches, func(x *domain.Match) bool { return x.VenueID == id }) >= 0 {
+		return errReference("matches_venue_id_fkey")
+	}
+	s.deleteRescheduleRequests(func(r *domain.RescheduleRequest) bool { return r.VenueID == id })
+	s.venues = filter(s.venues, func(x *domain.Venue) bool { return x.ID != id })
+	return nil
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migration is one versioned step of the schema. Down reverts Up.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// migrationLockKey identifies the advisory lock held while migrating, so
+// replicas starting together apply each migration once.
+const migrationLockKey = 7215309441
+
+type Migrator struct {
+	Pool       *pgxpool.Pool
+	Migrations []Migration
+}
+
+// NewMigrator checks that the migrations are in strictly ascending order.
+func NewMigrator(pool *pgxpool.Pool, migrations []Migration) (*Migrator, error) {
+	for i, m := range migrations {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive", m.Name)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %d %q: versions must be ascending", m.Version, m.Name)
+		}
+	}
+	return &Migrator{Pool: pool, Migrations: migrations}, nil
+}
+
+// Latest is the version of the newest migration, or 0 when there are none.
+func (m *Migrator) Latest() int {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+	return m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]time.Time) error {
+		for i := len(m.Migrations) - 1; i >= 0 && steps > 0; i-- {
+			if _, ok := applied[m.Migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, m.Migrations[i]); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// To migrates up or down so that exactly the migrations up to version are
+// applied. Version 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version < 0 || (version > 0 && m.find(version) == nil) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]time.Time) error {
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			mig := m.Migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.revert(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+		for _, mig := range m.Migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration with the time it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var out []MigrationStatus
+	err := m.withLock(ctx, func(_ *pgxpool.Conn, applied map[int]time.Time) error {
+		for _, mig := range m.Migrations {
+			st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if at, ok := applied[mig.Version]; ok {
+				st.AppliedAt = &at
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+	return nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock, passing the versions applied so far.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, applied map[int]time.Time) error) error {
+	conn, err := m.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, QAdvisoryLock, migrationLockKey); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), QAdvisoryUnlock, migrationLockKey)
+
+	if _, err := conn.Exec(ctx, QCreateSchemaMigrations); err != nil {
+		return err
+	}
+	rows, err := conn.Query(ctx, QSelectSchemaMigrations)
+	if err != nil {
+		return err
+	}
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var v int
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = at
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	return m.run(ctx, conn, mig, mig.Up, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, QInsertSchemaMigration, mig.Version, mig.Name)
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	return m.run(ctx, conn, mig, mig.Down, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, QDeleteSchemaMigration, mig.Version)
+		return err
+	})
+}
+
+// run executes the statements of one migration and records it in a single
+// transaction, so a failed migration leaves no trace.
+func (m *Migrator) run(ctx context.Context, conn *pgxpool.Conn, mig Migration, stmts []string, record func(pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d %s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestNewMigrator(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int
+		ok       bool
+	}{
+		{"none", nil, true},
+		{"ascending", []int{1, 2, 5}, true},
+		{"zero", []int{0, 1}, false},
+		{"negative", []int{-1}, false},
+		{"duplicate", []int{1, 2, 2}, false},
+		{"descending", []int{2, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var migrations []Migration
+			for _, v := range tt.versions {
+				migrations = append(migrations, Migration{Version: v, Name: "m"})
+			}
+			m, err := NewMigrator(nil, migrations)
+			if (err == nil) != tt.ok {
+				t.Fatalf("got error %v, want ok %v", err, tt.ok)
+			}
+			if err == nil && len(tt.versions) > 0 && m.Latest() != tt.versions[len(tt.versions)-1] {
+				t.Fatalf("latest %d", m.Latest())
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	if _, err := NewMigrator(nil, Migrations); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for i, m := range Migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if m.Name == "" || names[m.Name] {
+			t.Errorf("migration %d has an empty or repeated name %q", m.Version, m.Name)
+		}
+		names[m.Name] = true
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			t.Errorf("migration %d %s has %d up and %d down statements", m.Version, m.Name, len(m.Up), len(m.Down))
+		}
+	}
+}
+
+// TestMigrateUpDown runs the migrations against TEST_DATABASE_URL, a scratch
+// database holding the shared teams and memberships tables.
+func TestMigrateUpDown(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := NewPool(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	m, err := NewMigrator(pool, Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := func() int {
+		t.Helper()
+		list, err := m.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, st := range list {
+			if st.AppliedAt != nil {
+				n++
+			}
+		}
+		return n
+	}
+	hasLeagues := func() bool {
+		t.Helper()
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT to_regclass('leagues') IS NOT NULL`).Scan(&exists); err != nil {
+			t.Fatal(err)
+		}
+		return exists
+	}
+
+	steps := []struct {
+		name    string
+		run     func() error
+		applied int
+		leagues bool
+	}{
+		{"up", func() error { return m.Up(ctx) }, len(Migrations), true},
+		{"up again", func() error { return m.Up(ctx) }, len(Migrations), true},
+		{"down one", func() error { return m.Down(ctx, 1) }, len(Migrations) - 1, true},
+		{"to the first", func() error { return m.To(ctx, 1) }, 1, true},
+		{"to nothing", func() error { return m.To(ctx, 0) }, 0, false},
+		{"up from nothing", func() error { return m.Up(ctx) }, len(Migrations), true},
+	}
+	for _, st := range steps {
+		if err := st.run(); err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		if got := applied(); got != st.applied {
+			t.Fatalf("%s: %d migrations applied, want %d", st.name, got, st.applied)
+		}
+		if got := hasLeagues(); got != st.leagues {
+			t.Fatalf("%s: leagues table exists %v, want %v", st.name, got, st.leagues)
+		}
+	}
+	if err := m.To(ctx, len(Migrations)+1); err == nil {
+		t.Fatal("migrating to an unknown version succeeded")
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Page selects a window of a listing. Rows are ordered by the Sort key and
+// then by ID, so the order is total and a Cursor pins a position in it.
+type Page struct {
+	Limit int    // 0 returns every row
+	Sort  string // SortCreatedAt or SortName
+	Desc  bool
+	After *Cursor // Resume after this row
+}
+
+const (
+	SortCreatedAt = "created_at"
+	SortName      = "name"
+)
+
+// Cursor is the position of a row in a listing: its sort key and ID.
+type Cursor struct {
+	Key string
+	ID  string
+}
+
+// TimeKey formats a timestamp as a sort key. The fixed width keeps keys in
+// time order when compared as strings.
+func TimeKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z")
+}
+
+type LeagueFilter struct {
+	Region     string
+	NamePrefix string // Case-insensitive
+}
+
+type SeriesFilter struct {
+	LeagueID string
+	SeasonID string
+	Format   string
+}
+
+type RegistrationFilter struct {
+	TeamID      string
+	SeriesID    string
+	Statuses    []string
+	CreatedFrom time.Time // Inclusive; zero for no bound
+	CreatedTo   time.Time // Exclusive; zero for no bound
+}
+
+// listQuery builds a filtered, keyset-paginated SELECT.
+type listQuery struct {
+	where []string
+	args  []any
+}
+
+// arg binds v and returns its placeholder.
+func (q *listQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// filter adds a condition; %s in cond is replaced by the placeholder of v.
+func (q *listQuery) filter(cond string, v any) {
+	q.where = append(q.where, fmt.Sprintf(cond, q.arg(v)))
+}
+
+// sortColumns maps sort keys to columns and the cast their cursor key needs.
+var sortColumns = map[string][2]string{
+	SortCreatedAt: {"created_at", "::timestamptz"},
+	SortName:      {"name", ""},
+}
+
+// build appends the conditions and the page to base, a SELECT without WHERE.
+func (q *listQuery) build(base string, p Page) (string, []any, error) {
+	col, ok := sortColumns[p.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown sort key %q", p.Sort)
+	}
+	dir, cmp := "ASC", ">"
+	if p.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if p.After != nil {
+		q.where = append(q.where, fmt.Sprintf("(%s, id) %s (%s%s, %s)", col[0], cmp, q.arg(p.After.Key), col[1], q.arg(p.After.ID)))
+	}
+	var b strings.Builder
+	b.WriteString(base)
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE " + strings.Join(q.where, " AND "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s %s, id %s", col[0], dir, dir)
+	if p.Limit > 0 {
+		b.WriteString(" LIMIT " + q.arg(p.Limit))
+	}
+	return b.String(), q.args, nil
+}
+
+// likePrefix escapes s for use as a LIKE prefix pattern.
+func likePrefix(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s) + "%"
+}
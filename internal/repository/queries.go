@@ -1,66 +1,258 @@
 package repository
 
-// DDL statements for schema creation
-var SchemaStatements = []string{
-	`CREATE TABLE IF NOT EXISTS leagues (
-        id TEXT PRIMARY KEY,
-        name TEXT NOT NULL UNIQUE,
-        slug TEXT NOT NULL UNIQUE,
-        region TEXT NOT NULL,
-        created_by TEXT NOT NULL, -- References users(id) logically
-        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
-    );`,
-
-	// Series: 1:N league -> series
-	`CREATE TABLE IF NOT EXISTS series (
-        id TEXT PRIMARY KEY,
-        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
-        name TEXT NOT NULL,
-        format TEXT NOT NULL,
-        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-        UNIQUE(league_id, name)
-    );`,
-	`CREATE UNIQUE INDEX IF NOT EXISTS series_league_name_lower_uidx ON series (league_id, lower(name));`,
-
-	// Team Registrations: M:N team <-> series
-	// Note: REFERENCES teams(id) assumes teams table exists in the same DB.
-	// Since we assume shared DB, this is fine.
-	`CREATE TABLE IF NOT EXISTS team_registrations (
-        id TEXT PRIMARY KEY,
-        team_id TEXT NOT NULL, -- References teams(id) logically
-        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
-        status TEXT NOT NULL,
-        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-        UNIQUE(team_id, series_id)
-    );`,
-}
+// Column lists shared by queries that scan into the same model
+const (
+	seasonColumns       = `id, league_id, name, starts_on, ends_on, status, created_at, updated_at`
+	venueColumns        = `id, league_id, name, address, latitude, longitude, timezone, pitches, availability, blackouts, created_at, updated_at`
+	seriesColumns       = `id, league_id, season_id, name, format, points_win, points_draw, points_loss, tiebreakers, lots_seed, mode, max_teams, registration_opens_at, registration_closes_at, age_category, gender_category, eligibility_policy, division, match_minutes, max_roster_size, roster_locks_at, discipline_rules, min_referee_level, created_at, updated_at`
+	registrationColumns = `id, team_id, series_id, status, age_category, gender_category, eligibility_issues, created_at`
+	matchColumns        = `id, series_id, COALESCE(stage_id, ''), COALESCE(group_id, ''), COALESCE(tie_id, ''), round, leg, home_team_id, away_team_id, status, created_at, updated_at, COALESCE(venue_id, ''), COALESCE(pitch, 0), kickoff_at, ends_at, sequence`
+	matchResultColumns  = `r.match_id, r.outcome, r.home_score, r.away_score, COALESCE(r.walkover_winner_id, ''), r.home_fair_play, r.away_fair_play, r.home_penalties, r.away_penalties, r.recorded_by, r.recorded_at`
+	cancellationColumns = `match_id, series_id, COALESCE(venue_id, ''), COALESCE(pitch, 0), home_team_id, away_team_id, round, kickoff_at, ends_at, sequence, cancelled_at`
+	rescheduleColumns   = `id, match_id, series_id, team_id, venue_id, pitch, kickoff_at, ends_at, reason, status, expires_at, requested_by, created_at, updated_at`
+	rosterColumns       = `registration_id, series_id, team_id, player_id, name, birth_date, shirt_number, late, added_by, created_at, updated_at`
+	rosterChangeColumns = `id, registration_id, series_id, player_id, action, shirt_number, late, actor_id, reason, created_at`
+	cardColumns         = `id, match_id, series_id, team_id, player_id, type, minute, reason, recorded_by, created_at`
+	suspensionColumns   = `id, series_id, player_id, team_id, match_id, COALESCE(card_id, ''), matches, reason, created_by, created_at`
+	refereeColumns      = `id, league_id, user_id, name, level, formats, timezone, availability, blackouts, created_at, updated_at`
+	assignmentColumns   = `a.id, a.league_id, a.match_id, a.referee_id, a.role, a.assigned_by, a.created_at, m.series_id, m.kickoff_at, m.ends_at`
+	matchEventColumns   = `match_id, seq, type, period, minute, team_id, player_id, player_off_id, card, own_goal, final, home_penalties, away_penalties, retracts, note, home_score, away_score, recorded_by, created_at`
+	transferColumns     = `id, season_id, window_id, player_id, from_registration_id, to_registration_id, from_team_id, to_team_id, shirt_number, status, from_approved_by, to_approved_by, requested_by, created_at, updated_at`
+	knockoutTieColumns  = `id, series_id, COALESCE(stage_id, ''), round, position, COALESCE(home_team_id, ''), COALESCE(away_team_id, ''), COALESCE(home_seed, 0), COALESCE(away_seed, 0), legs, COALESCE(winner_team_id, ''), created_at, updated_at`
+)
 
 // DML queries
 const (
+	// Schema migrations
+	QCreateSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`
+	QSelectSchemaMigrations = `SELECT version, applied_at FROM schema_migrations`
+	QInsertSchemaMigration  = `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1,$2,now())`
+	QDeleteSchemaMigration  = `DELETE FROM schema_migrations WHERE version=$1`
+	QAdvisoryLock           = `SELECT pg_advisory_lock($1)`
+	QAdvisoryUnlock         = `SELECT pg_advisory_unlock($1)`
+
 	// Leagues CRUD
 	QInsertLeague     = `INSERT INTO leagues (id, name, slug, region, created_by, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,now(),now())`
 	QSelectLeagueByID = `SELECT id, name, slug, region, created_by, created_at, updated_at FROM leagues WHERE id=$1`
-	QSelectLeagues    = `SELECT id, name, slug, region, created_by, created_at, updated_at FROM leagues ORDER BY created_at`
+	QSelectLeagues    = `SELECT id, name, slug, region, created_by, created_at, updated_at FROM leagues`
 	QUpdateLeague     = `UPDATE leagues SET name=$2, slug=$3, region=$4, updated_at=now() WHERE id=$1`
 	QDeleteLeague     = `DELETE FROM leagues WHERE id=$1`
 
+	// League members
+	QUpsertLeagueMember  = `INSERT INTO league_members (league_id, user_id, role, added_by, created_at, updated_at) VALUES ($1,$2,$3,$4,now(),now()) ON CONFLICT (league_id, user_id) DO UPDATE SET role=EXCLUDED.role, added_by=EXCLUDED.added_by, updated_at=now()`
+	QSelectLeagueMember  = `SELECT league_id, user_id, role, added_by, created_at, updated_at FROM league_members WHERE league_id=$1 AND user_id=$2`
+	QSelectLeagueMembers = `SELECT league_id, user_id, role, added_by, created_at, updated_at FROM league_members WHERE league_id=$1 ORDER BY created_at, user_id`
+	QDeleteLeagueMember  = `DELETE FROM league_members WHERE league_id=$1 AND user_id=$2`
+	QCountLeagueOwners   = `SELECT count(*) FROM league_members WHERE league_id=$1 AND role='owner'`
+	// Participants: active members of a club with a team registered in the
+	// league, and the league's referees.
+	QLeagueParticipantExists = `SELECT EXISTS (
+            SELECT 1 FROM team_registrations r
+            JOIN series s ON s.id = r.series_id
+            JOIN teams t ON t.id = r.team_id
+            JOIN memberships m ON m.club_id = t.club_id
+            WHERE s.league_id=$1 AND m.user_id=$2 AND m.status='active'
+              AND r.status IN ('pending','waitlisted','approved')
+        ) OR EXISTS (SELECT 1 FROM referees WHERE league_id=$1 AND user_id=$2)`
+
+	// Seasons CRUD
+	QInsertSeason          = `INSERT INTO seasons (id, league_id, name, starts_on, ends_on, status, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,now(),now())`
+	QSelectSeasonByID      = `SELECT ` + seasonColumns + ` FROM seasons WHERE id=$1`
+	QSelectSeasonsByLeague = `SELECT ` + seasonColumns + ` FROM seasons WHERE league_id=$1 ORDER BY starts_on DESC, id`
+	QUpdateSeason          = `UPDATE seasons SET name=$2, starts_on=$3, ends_on=$4, status=$5, updated_at=now() WHERE id=$1`
+	QDeleteSeason          = `DELETE FROM seasons WHERE id=$1`
+
+	// Venues CRUD
+	QInsertVenue          = `INSERT INTO venues (id, league_id, name, address, latitude, longitude, timezone, pitches, availability, blackouts, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now(),now())`
+	QSelectVenueByID      = `SELECT ` + venueColumns + ` FROM venues WHERE id=$1`
+	QSelectVenuesByLeague = `SELECT ` + venueColumns + ` FROM venues WHERE league_id=$1 ORDER BY name, id`
+	QUpdateVenue          = `UPDATE venues SET name=$2, address=$3, latitude=$4, longitude=$5, timezone=$6, pitches=$7, availability=$8, blackouts=$9, updated_at=now() WHERE id=$1`
+	QDeleteVenue          = `DELETE FROM venues WHERE id=$1`
+
 	// Series CRUD
-	QInsertSeries         = `INSERT INTO series (id, league_id, name, format, created_at, updated_at) VALUES ($1,$2,$3,$4,now(),now())`
-	QSelectSeriesByLeague = `SELECT id, league_id, name, format, created_at, updated_at FROM series WHERE league_id=$1 ORDER BY created_at`
-	QSelectSeriesByID     = `SELECT id, league_id, name, format, created_at, updated_at FROM series WHERE id=$1`
-	QUpdateSeries         = `UPDATE series SET name=$2, format=$3, updated_at=now() WHERE id=$1`
-	QDeleteSeries         = `DELETE FROM series WHERE id=$1`
+	QInsertSeries     = `INSERT INTO series (id, league_id, season_id, name, format, points_win, points_draw, points_loss, tiebreakers, lots_seed, mode, max_teams, registration_opens_at, registration_closes_at, age_category, gender_category, eligibility_policy, division, match_minutes, max_roster_size, roster_locks_at, discipline_rules, min_referee_level, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,now(),now())`
+	QSelectSeries     = `SELECT ` + seriesColumns + ` FROM series`
+	QSelectSeriesByID = `SELECT ` + seriesColumns + ` FROM series WHERE id=$1`
+	QUpdateSeries     = `UPDATE series SET name=$2, format=$3, points_win=$4, points_draw=$5, points_loss=$6, tiebreakers=$7, lots_seed=$8, mode=$9, max_teams=$10, registration_opens_at=$11, registration_closes_at=$12, age_category=$13, gender_category=$14, eligibility_policy=$15, division=$16, match_minutes=$17, max_roster_size=$18, roster_locks_at=$19, discipline_rules=$20, min_referee_level=$21, updated_at=now() WHERE id=$1`
+	QDeleteSeries     = `DELETE FROM series WHERE id=$1`
 
 	// Team Registrations
-	QInsertTeamRegistration      = `INSERT INTO team_registrations (id, team_id, series_id, status, created_at) VALUES ($1,$2,$3,$4,now())`
-	QSelectRegistrationsByTeam   = `SELECT id, team_id, series_id, status, created_at FROM team_registrations WHERE team_id=$1`
-	QSelectRegistrationsBySeries = `SELECT id, team_id, series_id, status, created_at FROM team_registrations WHERE series_id=$1`
-	QUpdateRegistrationStatus    = `UPDATE team_registrations SET status=$2 WHERE id=$1`
-	QDeleteRegistration          = `DELETE FROM team_registrations WHERE id=$1`
+	QInsertTeamRegistration      = `INSERT INTO team_registrations (id, team_id, series_id, status, age_category, gender_category, eligibility_issues, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,now())`
+	QSelectRegistrations         = `SELECT ` + registrationColumns + ` FROM team_registrations`
+	QSelectRegistrationsBySeries = `SELECT ` + registrationColumns + ` FROM team_registrations WHERE series_id=$1`
+	QSelectRegistrationByID      = `SELECT ` + registrationColumns + ` FROM team_registrations WHERE id=$1`
+	QSelectRegistrationBySlot    = `SELECT ` + registrationColumns + ` FROM team_registrations WHERE series_id=$1 AND team_id=$2`
+	QUpdateEligibilityIssues     = `UPDATE team_registrations SET eligibility_issues=$2 WHERE id=$1`
+	QUpdateRegistrationStatus    = `UPDATE team_registrations SET status=$2 WHERE id=$1 AND status=$3`
+	QInsertRegistrationEvent     = `INSERT INTO registration_events (id, registration_id, from_status, to_status, actor_id, reason, created_at) VALUES ($1,$2,$3,$4,$5,$6,now())`
+	QSelectRegistrationEvents    = `SELECT id, registration_id, from_status, to_status, actor_id, reason, created_at FROM registration_events WHERE registration_id=$1 ORDER BY created_at, id`
+
+	// Capacity: the series row is locked so concurrent registrations cannot
+	// overfill it. Pending and approved registrations hold a spot.
+	QLockSeriesCapacity   = `SELECT max_teams FROM series WHERE id=$1 FOR UPDATE`
+	QCountSpotsTaken      = `SELECT count(*) FROM team_registrations WHERE series_id=$1 AND status IN ('pending','approved')`
+	QSelectNextWaitlisted = `SELECT ` + registrationColumns + ` FROM team_registrations WHERE series_id=$1 AND status='waitlisted' ORDER BY created_at, id LIMIT 1`
+
+	// Eligibility overrides
+	QUpsertEligibilityOverride  = `INSERT INTO eligibility_overrides (id, series_id, team_id, rule, reason, created_by, created_at) VALUES ($1,$2,$3,$4,$5,$6,now()) ON CONFLICT (series_id, team_id, rule) DO UPDATE SET reason=EXCLUDED.reason, created_by=EXCLUDED.created_by, created_at=now()`
+	QSelectEligibilityOverrides = `SELECT id, series_id, team_id, rule, reason, created_by, created_at FROM eligibility_overrides WHERE series_id=$1 ORDER BY created_at, id`
+	QSelectTeamOverrides        = `SELECT id, series_id, team_id, rule, reason, created_by, created_at FROM eligibility_overrides WHERE series_id=$1 AND team_id=$2 ORDER BY created_at, id`
+
+	// Matches
+	QInsertMatch           = `INSERT INTO matches (id, series_id, stage_id, group_id, tie_id, round, leg, home_team_id, away_team_id, status, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now(),now())`
+	QSelectMatchesBySeries = `SELECT ` + matchColumns + ` FROM matches WHERE series_id=$1 ORDER BY round, leg, created_at`
+	// Fixtures: the series row is locked so concurrent generations cannot
+	// both find it empty.
+	QLockSeries            = `SELECT 1 FROM series WHERE id=$1 FOR UPDATE`
+	QSeriesHasMatches      = `SELECT EXISTS (SELECT 1 FROM matches WHERE series_id=$1)`
+	QSelectMatchesByTie    = `SELECT ` + matchColumns + ` FROM matches WHERE tie_id=$1 ORDER BY leg`
+	QDeleteMatchesBySeries = `DELETE FROM matches WHERE series_id=$1`
+	QLockSeriesMatches     = `SELECT id FROM matches WHERE series_id=$1 FOR UPDATE`
+	QSeriesHasPlayed       = `SELECT EXISTS (SELECT 1 FROM matches m WHERE m.series_id=$1 AND (m.status <> 'scheduled' OR EXISTS (SELECT 1 FROM match_results r WHERE r.match_id=m.id) OR EXISTS (SELECT 1 FROM match_events e WHERE e.match_id=m.id)))`
+	QDeleteMatchesByTie    = `DELETE FROM matches WHERE tie_id=$1`
+	QSelectMatchByID       = `SELECT ` + matchColumns + ` FROM matches WHERE id=$1`
+	QUpdateMatchStatus     = `UPDATE matches SET status=$2, updated_at=now() WHERE id=$1`
+	QUpdateMatchSlot       = `UPDATE matches SET venue_id=$2, pitch=$3, kickoff_at=$4, ends_at=$5, updated_at=now() WHERE id=$1`
+	QUpdateMatchSides      = `UPDATE matches SET home_team_id=$2, away_team_id=$3, updated_at=now() WHERE id=$1 AND (home_team_id, away_team_id) IS DISTINCT FROM ($2, $3)`
+	QSelectMatchesByVenue  = `SELECT ` + matchColumns + ` FROM matches WHERE venue_id=$1 AND kickoff_at < $3 AND ends_at > $2 ORDER BY kickoff_at, pitch, id`
+	QSelectMatchesByLeague = `SELECT ` + matchColumns + ` FROM matches WHERE series_id IN (SELECT id FROM series WHERE league_id=$1) AND kickoff_at < $3 AND ends_at > $2 ORDER BY kickoff_at, pitch, id`
+
+	// Team Blackouts
+	QUpsertTeamBlackout  = `INSERT INTO team_blackouts (team_id, date, reason, created_by, created_at) VALUES ($1,$2,$3,$4,now()) ON CONFLICT (team_id, date) DO UPDATE SET reason=EXCLUDED.reason RETURNING created_by, created_at`
+	QSelectTeamBlackouts = `SELECT team_id, date, reason, created_by, created_at FROM team_blackouts WHERE team_id = ANY($1) AND date >= $2 AND date <= $3 ORDER BY date, team_id`
+	QDeleteTeamBlackout  = `DELETE FROM team_blackouts WHERE team_id=$1 AND date=$2`
+
+	// Reschedule Requests
+	QInsertRescheduleRequest          = `INSERT INTO reschedule_requests (id, match_id, series_id, team_id, venue_id, pitch, kickoff_at, ends_at, reason, status, expires_at, requested_by, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,now(),now()) RETURNING created_at, updated_at`
+	QSelectRescheduleRequestByID      = `SELECT ` + rescheduleColumns + ` FROM reschedule_requests WHERE id=$1`
+	QSelectRescheduleRequestsByMatch  = `SELECT ` + rescheduleColumns + ` FROM reschedule_requests WHERE match_id=$1 ORDER BY created_at, id`
+	QSelectRescheduleRequestsByLeague = `SELECT ` + rescheduleColumns + ` FROM reschedule_requests WHERE series_id IN (SELECT id FROM series WHERE league_id=$1) AND (cardinality($2::text[]) = 0 OR status = ANY($2)) ORDER BY created_at, id`
+	QUpdateRescheduleStatus           = `UPDATE reschedule_requests SET status=$2, updated_at=now() WHERE id=$1 AND status=$3`
+	QInsertRescheduleEvent            = `INSERT INTO reschedule_events (id, request_id, from_status, to_status, actor_id, reason, created_at) VALUES ($1,$2,$3,$4,$5,$6,now())`
+	QSelectRescheduleEvents           = `SELECT id, request_id, from_status, to_status, actor_id, reason, created_at FROM reschedule_events WHERE request_id=$1 ORDER BY created_at, id`
+
+	// Rosters: the registration row is locked so concurrent additions cannot
+	// overfill the roster.
+	QLockRegistration        = `SELECT 1 FROM team_registrations WHERE id=$1 FOR UPDATE`
+	QCountOtherRosterPlayers = `SELECT count(*) FROM roster_players WHERE registration_id=$1 AND player_id<>$2`
+	QUpsertRosterPlayer      = `INSERT INTO roster_players (registration_id, series_id, team_id, player_id, name, birth_date, shirt_number, late, added_by, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,now(),now())
+        ON CONFLICT (registration_id, player_id) DO UPDATE SET name=EXCLUDED.name, birth_date=EXCLUDED.birth_date, shirt_number=EXCLUDED.shirt_number, updated_at=now() RETURNING late, added_by, created_at, updated_at`
+	QSelectRosterPlayer      = `SELECT ` + rosterColumns + ` FROM roster_players WHERE registration_id=$1 AND player_id=$2`
+	QSelectRosterPlayers     = `SELECT ` + rosterColumns + ` FROM roster_players WHERE registration_id=$1 ORDER BY shirt_number`
+	QDeleteRosterPlayer      = `DELETE FROM roster_players WHERE registration_id=$1 AND player_id=$2`
+	QInsertRosterChange      = `INSERT INTO roster_changes (id, registration_id, series_id, player_id, action, shirt_number, late, actor_id, reason, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,now())`
+	QSelectRosterChanges     = `SELECT ` + rosterChangeColumns + ` FROM roster_changes WHERE registration_id=$1 ORDER BY created_at, id`
+	QSelectLateRosterChanges = `SELECT ` + rosterChangeColumns + ` FROM roster_changes WHERE series_id=$1 AND late ORDER BY created_at, id`
+
+	// Transfers; events take clock_timestamp() so the steps recorded in one
+	// transaction keep their order.
+	QInsertTransferWindow    = `INSERT INTO transfer_windows (id, league_id, season_id, opens_at, closes_at, max_transfers_per_team, created_by, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,now()) RETURNING created_at`
+	QSelectTransferWindow    = `SELECT id, league_id, season_id, opens_at, closes_at, max_transfers_per_team, created_by, created_at FROM transfer_windows WHERE id=$1`
+	QSelectTransferWindows   = `SELECT id, league_id, season_id, opens_at, closes_at, max_transfers_per_team, created_by, created_at FROM transfer_windows WHERE season_id=$1 ORDER BY opens_at, id`
+	QDeleteTransferWindow    = `DELETE FROM transfer_windows WHERE id=$1`
+	QInsertTransfer          = `INSERT INTO transfers (id, season_id, window_id, player_id, from_registration_id, to_registration_id, from_team_id, to_team_id, shirt_number, status, from_approved_by, to_approved_by, requested_by, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,now(),now()) RETURNING created_at, updated_at`
+	QSelectTransferByID      = `SELECT ` + transferColumns + ` FROM transfers WHERE id=$1`
+	QSelectTransfers         = `SELECT ` + transferColumns + ` FROM transfers WHERE season_id=$1 AND ($2::text = '' OR from_team_id=$2 OR to_team_id=$2) ORDER BY created_at, id`
+	QUpdateTransferApprovals = `UPDATE transfers SET from_approved_by=$2, to_approved_by=$3, updated_at=now() WHERE id=$1 AND status='pending'`
+	QCompleteTransfer        = `UPDATE transfers SET status='completed', from_approved_by=$2, to_approved_by=$3, updated_at=now() WHERE id=$1 AND status='pending'`
+	QUpdateTransferStatus    = `UPDATE transfers SET status=$2, updated_at=now() WHERE id=$1 AND status=$3`
+	QLockTransferWindow      = `SELECT 1 FROM transfer_windows WHERE id=$1 FOR UPDATE`
+	QCountWindowTransfers    = `SELECT count(*) FROM transfers WHERE window_id=$1 AND status IN ('pending', 'completed') AND (from_team_id=$2 OR to_team_id=$2) AND id<>$3`
+	QInsertTransferEvent     = `INSERT INTO transfer_events (id, transfer_id, action, club, actor_id, reason, created_at) VALUES ($1,$2,$3,$4,$5,$6,clock_timestamp())`
+	QSelectTransferEvents    = `SELECT id, transfer_id, action, club, actor_id, reason, created_at FROM transfer_events WHERE transfer_id=$1 ORDER BY created_at, id`
+
+	// Discipline; suspensions a card triggered are inserted at most once.
+	QInsertCard            = `INSERT INTO cards (id, match_id, series_id, team_id, player_id, type, minute, reason, recorded_by, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,now()) RETURNING created_at`
+	QSelectCard            = `SELECT ` + cardColumns + ` FROM cards WHERE id=$1`
+	QSelectMatchCards      = `SELECT ` + cardColumns + ` FROM cards WHERE match_id=$1 ORDER BY created_at, id`
+	QSelectSeriesCards     = `SELECT ` + cardColumns + ` FROM cards WHERE series_id=$1 AND ($2::text = '' OR player_id=$2) ORDER BY created_at, id`
+	QDeleteCard            = `DELETE FROM cards WHERE id=$1`
+	QInsertSuspension      = `INSERT INTO suspensions (id, series_id, player_id, team_id, match_id, card_id, matches, reason, created_by, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,now()) ON CONFLICT (card_id) DO NOTHING RETURNING created_at`
+	QSelectSuspension      = `SELECT ` + suspensionColumns + ` FROM suspensions WHERE id=$1`
+	QSelectSuspensions     = `SELECT ` + suspensionColumns + ` FROM suspensions WHERE series_id=$1 AND ($2::text = '' OR player_id=$2) ORDER BY created_at, id`
+	QDeleteSuspension      = `DELETE FROM suspensions WHERE id=$1`
+	QDeleteCardSuspensions = `DELETE FROM suspensions WHERE card_id = ANY($1)`
+	QUpsertMatchSheet      = `INSERT INTO match_sheets (match_id, team_id, player_ids, submitted_by, updated_at) VALUES ($1,$2,$3,$4,now()) ON CONFLICT (match_id, team_id) DO UPDATE SET player_ids=EXCLUDED.player_ids, submitted_by=EXCLUDED.submitted_by, updated_at=now() RETURNING updated_at`
+	QSelectMatchSheets     = `SELECT match_id, team_id, player_ids, submitted_by, updated_at FROM match_sheets WHERE match_id=$1 ORDER BY team_id`
+
+	// Referees; assignments are read with the slot of their match
+	QInsertReferee            = `INSERT INTO referees (id, league_id, user_id, name, level, formats, timezone, availability, blackouts, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,now(),now())`
+	QSelectRefereeByID        = `SELECT ` + refereeColumns + ` FROM referees WHERE id=$1`
+	QSelectRefereesByLeague   = `SELECT ` + refereeColumns + ` FROM referees WHERE league_id=$1 ORDER BY name, id`
+	QUpdateReferee            = `UPDATE referees SET name=$2, level=$3, formats=$4, timezone=$5, availability=$6, blackouts=$7, updated_at=now() WHERE id=$1`
+	QDeleteReferee            = `DELETE FROM referees WHERE id=$1`
+	QInsertRefereeAssignment  = `INSERT INTO referee_assignments (id, league_id, match_id, referee_id, role, assigned_by, created_at) VALUES ($1,$2,$3,$4,$5,$6,now()) RETURNING created_at`
+	QSelectRefereeAssignment  = `SELECT ` + assignmentColumns + ` FROM referee_assignments a JOIN matches m ON m.id = a.match_id WHERE a.id=$1`
+	QSelectMatchAssignments   = `SELECT ` + assignmentColumns + ` FROM referee_assignments a JOIN matches m ON m.id = a.match_id WHERE a.match_id=$1 ORDER BY a.role = 'assistant', a.created_at, a.id`
+	QSelectLeagueAssignments  = `SELECT ` + assignmentColumns + ` FROM referee_assignments a JOIN matches m ON m.id = a.match_id WHERE a.league_id=$1 AND ($2::text = '' OR a.referee_id=$2) ORDER BY m.kickoff_at NULLS LAST, a.id`
+	QDeleteRefereeAssignment  = `DELETE FROM referee_assignments WHERE id=$1`
+	QDeleteRefereeAssignments = `DELETE FROM referee_assignments WHERE id = ANY($1)`
+
+	// Match events
+	QInsertMatchEvent  = `INSERT INTO match_events (` + matchEventColumns + `) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,now()) RETURNING created_at`
+	QSelectMatchEvents = `SELECT ` + matchEventColumns + ` FROM match_events WHERE match_id=$1 AND seq > $2 ORDER BY seq`
+
+	// Calendar Feeds
+	QSelectMatchesByTeam         = `SELECT ` + matchColumns + ` FROM matches WHERE (home_team_id=$1 OR away_team_id=$1) AND kickoff_at IS NOT NULL ORDER BY kickoff_at, id`
+	QSelectCancellationsBySeries = `SELECT ` + cancellationColumns + ` FROM match_cancellations WHERE series_id=$1 ORDER BY kickoff_at, match_id`
+	QSelectCancellationsByVenue  = `SELECT ` + cancellationColumns + ` FROM match_cancellations WHERE venue_id=$1 ORDER BY kickoff_at, match_id`
+	QSelectCancellationsByTeam   = `SELECT ` + cancellationColumns + ` FROM match_cancellations WHERE home_team_id=$1 OR away_team_id=$1 ORDER BY kickoff_at, match_id`
+	QUpsertCalendarFeed          = `INSERT INTO calendar_feeds (kind, target_id, token_hash, created_by, created_at) VALUES ($1,$2,$3,$4,now()) ON CONFLICT (kind, target_id) DO UPDATE SET token_hash=EXCLUDED.token_hash, created_by=EXCLUDED.created_by, created_at=now() RETURNING created_at`
+	QSelectCalendarFeed          = `SELECT kind, target_id, token_hash, created_by, created_at FROM calendar_feeds WHERE kind=$1 AND target_id=$2`
+	QDeleteCalendarFeed          = `DELETE FROM calendar_feeds WHERE kind=$1 AND target_id=$2`
+
+	// Match Results
+	QUpsertMatchResult = `INSERT INTO match_results (match_id, outcome, home_score, away_score, walkover_winner_id, home_fair_play, away_fair_play, home_penalties, away_penalties, recorded_by, recorded_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now())
+        ON CONFLICT (match_id) DO UPDATE SET outcome=EXCLUDED.outcome, home_score=EXCLUDED.home_score, away_score=EXCLUDED.away_score, walkover_winner_id=EXCLUDED.walkover_winner_id, home_fair_play=EXCLUDED.home_fair_play, away_fair_play=EXCLUDED.away_fair_play, home_penalties=EXCLUDED.home_penalties, away_penalties=EXCLUDED.away_penalties, recorded_by=EXCLUDED.recorded_by, recorded_at=now()`
+	QSelectMatchResult          = `SELECT ` + matchResultColumns + ` FROM match_results r WHERE r.match_id=$1`
+	QSelectMatchResultsBySeries = `SELECT ` + matchResultColumns + ` FROM match_results r JOIN matches m ON m.id = r.match_id WHERE m.series_id=$1`
+	QDeleteMatchResult          = `DELETE FROM match_results WHERE match_id=$1`
+
+	// Knockout Ties
+	QInsertKnockoutTie          = `INSERT INTO knockout_ties (id, series_id, stage_id, round, position, home_team_id, away_team_id, home_seed, away_seed, legs, winner_team_id, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now(),now())`
+	QSelectKnockoutTiesBySeries = `SELECT ` + knockoutTieColumns + ` FROM knockout_ties WHERE series_id=$1 ORDER BY round, position`
+	QSelectKnockoutTieByID      = `SELECT ` + knockoutTieColumns + ` FROM knockout_ties WHERE id=$1`
+	QSelectKnockoutTieBySlot    = `SELECT ` + knockoutTieColumns + ` FROM knockout_ties WHERE series_id=$1 AND round=$2 AND position=$3`
+	QUpdateKnockoutTie          = `UPDATE knockout_ties SET home_team_id=$2, away_team_id=$3, winner_team_id=$4, updated_at=now() WHERE id=$1`
+	QDeleteKnockoutTiesBySeries = `DELETE FROM knockout_ties WHERE series_id=$1`
+
+	// Stages
+	QInsertStage          = `INSERT INTO series_stages (id, series_id, position, kind, qualifiers_per_group, draw_seed, created_at) VALUES ($1,$2,$3,$4,$5,$6,now())`
+	QSelectStagesBySeries = `SELECT id, series_id, position, kind, qualifiers_per_group, draw_seed, created_at FROM series_stages WHERE series_id=$1 ORDER BY position`
+	QDeleteStagesBySeries = `DELETE FROM series_stages WHERE series_id=$1`
+	QInsertStageGroup     = `INSERT INTO stage_groups (id, stage_id, name) VALUES ($1,$2,$3)`
+	QInsertStageGroupTeam = `INSERT INTO stage_group_teams (group_id, team_id, draw_position) VALUES ($1,$2,$3)`
+	QSelectGroupsBySeries = `SELECT g.id, g.stage_id, g.name, COALESCE(array_agg(t.team_id ORDER BY t.draw_position) FILTER (WHERE t.team_id IS NOT NULL), '{}')
+        FROM stage_groups g JOIN series_stages st ON st.id = g.stage_id LEFT JOIN stage_group_teams t ON t.group_id = g.id
+        WHERE st.series_id=$1 GROUP BY g.id, g.stage_id, g.name ORDER BY g.name`
+
+	// Search: $1 is a tsquery, $2 the result types to include, $3 the limit.
+	// A series matches on its own name (weighted A) and its league's name and
+	// region (B), so "liga santiago sub 12" finds the "Sub 12" series of
+	// "Liga Santiago". Teams are only found once registered in a series.
+	QSearch = `WITH q AS (SELECT to_tsquery('simple', immutable_unaccent($1)) AS query)
+        SELECT type, id, name, region, league_id, league_name, club_id, rank FROM (
+            SELECT 'league' AS type, l.id, l.name, l.region, '' AS league_id, '' AS league_name, '' AS club_id, ts_rank(l.search_vector, q.query) AS rank
+            FROM leagues l CROSS JOIN q
+            WHERE 'league' = ANY($2) AND l.search_vector @@ q.query
+            UNION ALL
+            SELECT 'series', s.id, s.name, l.region, l.id, l.name, '', ts_rank(d.doc, q.query)
+            FROM series s JOIN leagues l ON l.id = s.league_id CROSS JOIN q
+            CROSS JOIN LATERAL (SELECT setweight(s.search_vector, 'A') || setweight(l.search_vector, 'B') AS doc) d
+            WHERE 'series' = ANY($2) AND d.doc @@ q.query
+            UNION ALL
+            SELECT 'team', t.id, t.name, '', '', '', t.club_id, ts_rank(d.doc, q.query)
+            FROM teams t CROSS JOIN q
+            CROSS JOIN LATERAL (SELECT to_tsvector('simple', immutable_unaccent(t.name)) AS doc) d
+            WHERE 'team' = ANY($2) AND d.doc @@ q.query AND EXISTS (SELECT 1 FROM team_registrations r WHERE r.team_id = t.id)
+        ) results ORDER BY rank DESC, name, id LIMIT $3`
 
 	// Read-only queries for validation (assuming shared DB)
 	QSelectTeamByID        = `SELECT id, club_id, name, format, created_at, updated_at FROM teams WHERE id=$1`
 	QOwnerMembershipExists = `SELECT 1 FROM memberships WHERE user_id=$1 AND club_id=$2 AND role='owner' AND status='active' LIMIT 1`
+	QSelectMemberClubs     = `SELECT DISTINCT club_id FROM memberships WHERE user_id=$1 AND status='active' ORDER BY club_id`
 )
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Referees
+
+func scanReferee(row pgx.Row, r *domain.Referee) error {
+	return row.Scan(&r.ID, &r.LeagueID, &r.UserID, &r.Name, &r.Level, &r.Formats, &r.Timezone, &r.Availability, &r.Blackouts, &r.CreatedAt, &r.UpdatedAt)
+}
+
+func scanRefereeAssignment(row pgx.Row, a *domain.RefereeAssignment) error {
+	return row.Scan(&a.ID, &a.LeagueID, &a.MatchID, &a.RefereeID, &a.Role, &a.AssignedBy, &a.CreatedAt, &a.SeriesID, &a.KickoffAt, &a.EndsAt)
+}
+
+func (s *Store) CreateReferee(ctx context.Context, r *domain.Referee) error {
+	_, err := s.Pool.Exec(ctx, QInsertReferee, r.ID, r.LeagueID, r.UserID, r.Name, r.Level, r.Formats, r.Timezone, r.Availability, r.Blackouts)
+	return dbError(err)
+}
+
+func (s *Store) GetReferee(ctx context.Context, id string) (*domain.Referee, error) {
+	var r domain.Referee
+	if err := scanReferee(s.Pool.QueryRow(ctx, QSelectRefereeByID, id), &r); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &r, nil
+}
+
+// ListReferees returns the referees of a league by name.
+func (s *Store) ListReferees(ctx context.Context, leagueID string) ([]domain.Referee, error) {
+	rows, err := s.Pool.Query(ctx, QSelectRefereesByLeague, leagueID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Referee{}
+	for rows.Next() {
+		var r domain.Referee
+		if err := scanReferee(rows, &r); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, r)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) UpdateReferee(ctx context.Context, r *domain.Referee) error {
+	_, err := s.Pool.Exec(ctx, QUpdateReferee, r.ID, r.Name, r.Level, r.Formats, r.Timezone, r.Availability, r.Blackouts)
+	return dbError(err)
+}
+
+func (s *Store) DeleteReferee(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteReferee, id)
+	return dbError(err)
+}
+
+func (s *Store) CreateRefereeAssignment(ctx context.Context, a *domain.RefereeAssignment) error {
+	err := s.Pool.QueryRow(ctx, QInsertRefereeAssignment, a.ID, a.LeagueID, a.MatchID, a.RefereeID, a.Role, a.AssignedBy).Scan(&a.CreatedAt)
+	return dbError(err)
+}
+
+func (s *Store) GetRefereeAssignment(ctx context.Context, id string) (*domain.RefereeAssignment, error) {
+	var a domain.RefereeAssignment
+	if err := scanRefereeAssignment(s.Pool.QueryRow(ctx, QSelectRefereeAssignment, id), &a); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &a, nil
+}
+
+func (s *Store) listRefereeAssignments(ctx context.Context, query string, args ...any) ([]domain.RefereeAssignment, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RefereeAssignment{}
+	for rows.Next() {
+		var a domain.RefereeAssignment
+		if err := scanRefereeAssignment(rows, &a); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, a)
+	}
+	return out, dbError(rows.Err())
+}
+
+// ListMatchAssignments returns the officials of a match, the main referee
+// first.
+func (s *Store) ListMatchAssignments(ctx context.Context, matchID string) ([]domain.RefereeAssignment, error) {
+	return s.listRefereeAssignments(ctx, QSelectMatchAssignments, matchID)
+}
+
+func (s *Store) ListRefereeAssignments(ctx context.Context, leagueID, refereeID string) ([]domain.RefereeAssignment, error) {
+	return s.listRefereeAssignments(ctx, QSelectLeagueAssignments, leagueID, refereeID)
+}
+
+func (s *Store) DeleteRefereeAssignment(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteRefereeAssignment, id)
+	return dbError(err)
+}
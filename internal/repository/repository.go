@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Repository is the storage the service layer depends on. Store implements
+// it on Postgres; package memory implements it in memory with the same
+// semantics for tests and database-less runs.
+type Repository interface {
+	LeagueRepository
+	MemberRepository
+	SeasonRepository
+	VenueRepository
+	SeriesRepository
+	RegistrationRepository
+	EligibilityRepository
+	MatchRepository
+	KnockoutRepository
+	StageRepository
+	BlackoutRepository
+	RosterRepository
+	TransferRepository
+	DisciplineRepository
+	MatchEventRepository
+	RefereeRepository
+	RescheduleRepository
+	CalendarRepository
+	DirectoryRepository
+	SearchRepository
+}
+
+var _ Repository = (*Store)(nil)
+
+// LeagueRepository stores leagues. League names and slugs are unique, and
+// deleting a league deletes its seasons, venues, series and members.
+type LeagueRepository interface {
+	CreateLeague(ctx context.Context, l *domain.League) error
+	GetLeagueByID(ctx context.Context, id string) (*domain.League, error)
+	ListLeagues(ctx context.Context, f LeagueFilter, p Page) ([]domain.League, error)
+	UpdateLeague(ctx context.Context, id, name, slug, region string) (bool, error)
+	DeleteLeague(ctx context.Context, id string) error
+}
+
+type MemberRepository interface {
+	GetLeagueMember(ctx context.Context, leagueID, userID string) (*domain.LeagueMember, error)
+	ListLeagueMembers(ctx context.Context, leagueID string) ([]domain.LeagueMember, error)
+	SaveLeagueMember(ctx context.Context, m *domain.LeagueMember) error
+	DeleteLeagueMember(ctx context.Context, leagueID, userID string) error
+	CountLeagueOwners(ctx context.Context, leagueID string) (int, error)
+	// IsLeagueParticipant reports whether the user is an active member of a
+	// club with a team pending, waitlisted or approved in the league, or one
+	// of its referees.
+	IsLeagueParticipant(ctx context.Context, leagueID, userID string) (bool, error)
+}
+
+// SeasonRepository stores seasons. Names are unique per league regardless of
+// case, and deleting a season deletes its series.
+type SeasonRepository interface {
+	CreateSeason(ctx context.Context, se *domain.Season, series []domain.Series, regs []domain.TeamRegistration, events []domain.RegistrationEvent) error
+	GetSeasonByID(ctx context.Context, id string) (*domain.Season, error)
+	ListSeasons(ctx context.Context, leagueID string) ([]domain.Season, error)
+	UpdateSeason(ctx context.Context, se *domain.Season) error
+	DeleteSeason(ctx context.Context, id string) error
+}
+
+// VenueRepository stores venues. Names are unique per league regardless of
+// case; a venue with matches booked into it cannot be deleted.
+type VenueRepository interface {
+	CreateVenue(ctx context.Context, v *domain.Venue) error
+	GetVenueByID(ctx context.Context, id string) (*domain.Venue, error)
+	ListVenues(ctx context.Context, leagueID string) ([]domain.Venue, error)
+	UpdateVenue(ctx context.Context, v *domain.Venue) error
+	DeleteVenue(ctx context.Context, id string) error
+}
+
+// SeriesRepository stores series. Names are unique per season regardless of
+// case, and deleting a series deletes everything recorded under it.
+type SeriesRepository interface {
+	CreateSeries(ctx context.Context, ser *domain.Series) error
+	ListSeries(ctx context.Context, f SeriesFilter, p Page) ([]domain.Series, error)
+	GetSeriesByID(ctx context.Context, id string) (*domain.Series, error)
+	UpdateSeries(ctx context.Context, ser *domain.Series) error
+	DeleteSeries(ctx context.Context, id string) error
+}
+
+// RegistrationRepository stores registrations, at most one per team and
+// series, with the history of their status changes.
+type RegistrationRepository interface {
+	CreateTeamRegistration(ctx context.Context, tr *domain.TeamRegistration, ev *domain.RegistrationEvent) error
+	PromoteWaitlisted(ctx context.Context, seriesID string, newEvent func(tr *domain.TeamRegistration) *domain.RegistrationEvent) ([]domain.TeamRegistration, error)
+	GetRegistrationByID(ctx context.Context, id string) (*domain.TeamRegistration, error)
+	GetRegistrationBySlot(ctx context.Context, seriesID, teamID string) (*domain.TeamRegistration, error)
+	ListRegistrations(ctx context.Context, f RegistrationFilter, p Page) ([]domain.TeamRegistration, error)
+	ListRegistrationsBySeries(ctx context.Context, seriesID string) ([]domain.TeamRegistration, error)
+	UpdateRegistrationStatus(ctx context.Context, id, from, to string, ev *domain.RegistrationEvent) (bool, error)
+	MoveRegistrations(ctx context.Context, changes []domain.RegistrationEvent, regs []domain.TeamRegistration, events []domain.RegistrationEvent) (bool, error)
+	ListRegistrationEvents(ctx context.Context, registrationID string) ([]domain.RegistrationEvent, error)
+}
+
+type EligibilityRepository interface {
+	SaveEligibilityOverride(ctx context.Context, o *domain.EligibilityOverride, reg *domain.TeamRegistration) error
+	ListEligibilityOverrides(ctx context.Context, seriesID string) ([]domain.EligibilityOverride, error)
+	ListTeamEligibilityOverrides(ctx context.Context, seriesID, teamID string) ([]domain.EligibilityOverride, error)
+}
+
+// MatchRepository stores fixtures, the slots they are booked into and their
+// results. A pitch hosts one match at a time.
+type MatchRepository interface {
+	// CreateFixtures inserts the matches unless the series already has
+	// some, reporting whether it did.
+	CreateFixtures(ctx context.Context, seriesID string, matches []domain.Match) (bool, error)
+	ListMatchesBySeries(ctx context.Context, seriesID string) ([]domain.Match, error)
+	ListMatchesByTie(ctx context.Context, tieID string) ([]domain.Match, error)
+	// DeleteMatchesBySeries removes the matches of the series unless one
+	// of them has been played or has live events, reporting whether it did.
+	DeleteMatchesBySeries(ctx context.Context, seriesID string) (bool, error)
+	GetMatchByID(ctx context.Context, id string) (*domain.Match, error)
+	ListMatchesByVenue(ctx context.Context, venueID string, from, to time.Time) ([]domain.Match, error)
+	ListMatchesByLeague(ctx context.Context, leagueID string, from, to time.Time) ([]domain.Match, error)
+	// UpdateMatchSlot and UpdateMatchSlots delete the released referee
+	// assignments along with the move.
+	UpdateMatchSlot(ctx context.Context, m *domain.Match, released []string) error
+	UpdateMatchSlots(ctx context.Context, matches []domain.Match, released []string) error
+	SaveMatchResult(ctx context.Context, r *domain.MatchResult) error
+	GetMatchResult(ctx context.Context, matchID string) (*domain.MatchResult, error)
+	ListMatchResultsBySeries(ctx context.Context, seriesID string) ([]domain.MatchResult, error)
+	DeleteMatchResult(ctx context.Context, matchID string) error
+}
+
+type KnockoutRepository interface {
+	CreateBracket(ctx context.Context, ties []domain.KnockoutTie, matches []domain.Match) error
+	ListKnockoutTiesBySeries(ctx context.Context, seriesID string) ([]domain.KnockoutTie, error)
+	GetKnockoutTieByID(ctx context.Context, id string) (*domain.KnockoutTie, error)
+	GetKnockoutTieBySlot(ctx context.Context, seriesID string, round, position int) (*domain.KnockoutTie, error)
+	AdvanceKnockoutTie(ctx context.Context, tie, next *domain.KnockoutTie, nextMatches []domain.Match) error
+}
+
+type StageRepository interface {
+	CreateStages(ctx context.Context, stages []domain.Stage, matches []domain.Match) error
+	ListStagesBySeries(ctx context.Context, seriesID string) ([]domain.Stage, error)
+	ListGroupsBySeries(ctx context.Context, seriesID string) ([]domain.Group, error)
+}
+
+// BlackoutRepository stores the days teams cannot play, at most one record
+// per team and day.
+type BlackoutRepository interface {
+	SaveTeamBlackout(ctx context.Context, b *domain.TeamBlackout) error
+	ListTeamBlackouts(ctx context.Context, teamIDs []string, from, to time.Time) ([]domain.TeamBlackout, error)
+	DeleteTeamBlackout(ctx context.Context, teamID string, date time.Time) error
+}
+
+// RosterRepository stores the players each registration lists for its
+// series and the audit trail of roster changes. A player is on at most one
+// roster per series and shirt numbers are unique within a roster.
+type RosterRepository interface {
+	SaveRosterPlayer(ctx context.Context, p *domain.RosterPlayer, ch *domain.RosterChange, maxSize int) (bool, error)
+	GetRosterPlayer(ctx context.Context, registrationID, playerID string) (*domain.RosterPlayer, error)
+	ListRosterPlayers(ctx context.Context, registrationID string) ([]domain.RosterPlayer, error)
+	DeleteRosterPlayer(ctx context.Context, registrationID, playerID string, ch *domain.RosterChange) (bool, error)
+	ListRosterChanges(ctx context.Context, registrationID string) ([]domain.RosterChange, error)
+	ListLateRosterChanges(ctx context.Context, seriesID string) ([]domain.RosterChange, error)
+}
+
+// TransferRepository stores the transfer windows of seasons, the transfers
+// made in them and their history. A player has at most one pending transfer
+// per season.
+type TransferRepository interface {
+	CreateTransferWindow(ctx context.Context, w *domain.TransferWindow) error
+	GetTransferWindow(ctx context.Context, id string) (*domain.TransferWindow, error)
+	ListTransferWindows(ctx context.Context, seasonID string) ([]domain.TransferWindow, error)
+	DeleteTransferWindow(ctx context.Context, id string) error
+	// CreateTransfer reports false, storing nothing, when the transfer would
+	// take a team past m.MaxPerTeam or, with m.Player set, cannot complete.
+	CreateTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m RosterMove) (bool, error)
+	GetTransfer(ctx context.Context, id string) (*domain.Transfer, error)
+	ListTransfers(ctx context.Context, seasonID, teamID string) ([]domain.Transfer, error)
+	UpdateTransferApprovals(ctx context.Context, t *domain.Transfer, ev *domain.TransferEvent) (bool, error)
+	UpdateTransferStatus(ctx context.Context, id, from, to string, ev *domain.TransferEvent) (bool, error)
+	CountWindowTransfers(ctx context.Context, windowID, teamID, exceptID string) (int, error)
+	CompleteTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m RosterMove) (bool, error)
+	ListTransferEvents(ctx context.Context, transferID string) ([]domain.TransferEvent, error)
+}
+
+// RosterMove lists a transferred player on the new roster, with the roster
+// changes recording the move out and in. The limits are checked with both
+// rosters locked; 0 means no limit.
+type RosterMove struct {
+	Player     *domain.RosterPlayer
+	Out, In    *domain.RosterChange
+	MaxPerTeam int // Pending and completed transfers per team in the window
+	MaxRoster  int // Players on the new roster
+}
+
+// DisciplineRepository stores cards, the suspensions they trigger or the
+// league committee decides, and the match sheets suspensions are checked
+// against.
+type DisciplineRepository interface {
+	// SaveCard inserts the card with the suspensions it makes due, and drops
+	// those triggered by the cards in drop that are no longer due.
+	SaveCard(ctx context.Context, c *domain.Card, add []domain.Suspension, drop []string) error
+	// DeleteCard removes the card with its suspension, adjusting the others
+	// like SaveCard.
+	DeleteCard(ctx context.Context, id string, add []domain.Suspension, drop []string) error
+	// SyncSuspensions adjusts the card suspensions after the rules changed.
+	SyncSuspensions(ctx context.Context, add []domain.Suspension, drop []string) error
+	GetCard(ctx context.Context, id string) (*domain.Card, error)
+	ListMatchCards(ctx context.Context, matchID string) ([]domain.Card, error)
+	ListSeriesCards(ctx context.Context, seriesID, playerID string) ([]domain.Card, error)
+	CreateSuspension(ctx context.Context, su *domain.Suspension) error
+	GetSuspension(ctx context.Context, id string) (*domain.Suspension, error)
+	ListSuspensions(ctx context.Context, seriesID, playerID string) ([]domain.Suspension, error)
+	DeleteSuspension(ctx context.Context, id string) error
+	SaveMatchSheet(ctx context.Context, sh *domain.MatchSheet) error
+	ListMatchSheets(ctx context.Context, matchID string) ([]domain.MatchSheet, error)
+}
+
+// RefereeRepository stores the referees of a league and their assignments
+// to matches. A user is a referee once per league, a referee is assigned to
+// a match once and a match has at most one main referee. Deleting a referee
+// or a match deletes its assignments, which are read with the slot of their
+// match.
+type RefereeRepository interface {
+	CreateReferee(ctx context.Context, r *domain.Referee) error
+	GetReferee(ctx context.Context, id string) (*domain.Referee, error)
+	ListReferees(ctx context.Context, leagueID string) ([]domain.Referee, error)
+	UpdateReferee(ctx context.Context, r *domain.Referee) error
+	DeleteReferee(ctx context.Context, id string) error
+	CreateRefereeAssignment(ctx context.Context, a *domain.RefereeAssignment) error
+	GetRefereeAssignment(ctx context.Context, id string) (*domain.RefereeAssignment, error)
+	ListMatchAssignments(ctx context.Context, matchID string) ([]domain.RefereeAssignment, error)
+	// ListRefereeAssignments returns the assignments of the league by
+	// kickoff; with a refereeID only those of that referee.
+	ListRefereeAssignments(ctx context.Context, leagueID, refereeID string) ([]domain.RefereeAssignment, error)
+	DeleteRefereeAssignment(ctx context.Context, id string) error
+}
+
+// MatchEventRepository stores the live events of matches. Events are only
+// appended; a mistake is withdrawn by a retraction event.
+type MatchEventRepository interface {
+	// AppendMatchEvent inserts the event under its Seq; a Seq already taken
+	// is a unique violation.
+	AppendMatchEvent(ctx context.Context, e *domain.MatchEvent) error
+	// FinishMatch appends the final whistle and saves the result it decides
+	// in one transaction.
+	FinishMatch(ctx context.Context, e *domain.MatchEvent, r *domain.MatchResult) error
+	// ListMatchEvents returns the events of the match after afterSeq, in
+	// order.
+	ListMatchEvents(ctx context.Context, matchID string, afterSeq int64) ([]domain.MatchEvent, error)
+}
+
+// RescheduleRepository stores reschedule requests and the history of their
+// statuses. A match has at most one pending or accepted request.
+type RescheduleRepository interface {
+	CreateRescheduleRequest(ctx context.Context, r *domain.RescheduleRequest, ev *domain.RescheduleEvent) error
+	GetRescheduleRequest(ctx context.Context, id string) (*domain.RescheduleRequest, error)
+	ListRescheduleRequestsByMatch(ctx context.Context, matchID string) ([]domain.RescheduleRequest, error)
+	ListRescheduleRequestsByLeague(ctx context.Context, leagueID string, statuses []string) ([]domain.RescheduleRequest, error)
+	UpdateRescheduleStatus(ctx context.Context, id, from, to string, ev *domain.RescheduleEvent, slot *domain.Match, released []string) (bool, error)
+	ListRescheduleEvents(ctx context.Context, requestID string) ([]domain.RescheduleEvent, error)
+}
+
+// CalendarRepository stores the tokens of calendar feeds and reads what
+// they publish. Freed or deleted bookings are kept as cancellations.
+type CalendarRepository interface {
+	ListMatchesByTeam(ctx context.Context, teamID string) ([]domain.Match, error)
+	ListCancelledMatches(ctx context.Context, kind, targetID string) ([]domain.Match, error)
+	SaveCalendarFeed(ctx context.Context, f *domain.CalendarFeed) error
+	GetCalendarFeed(ctx context.Context, kind, targetID string) (*domain.CalendarFeed, error)
+	DeleteCalendarFeed(ctx context.Context, kind, targetID string) error
+}
+
+// DirectoryRepository reads the teams and club memberships owned by the
+// teams service.
+type DirectoryRepository interface {
+	GetTeamByID(ctx context.Context, id string) (*domain.Team, error)
+	IsOwner(ctx context.Context, userID, clubID string) (bool, error)
+	// ListMemberClubs returns the clubs the user is an active member of, in
+	// any role.
+	ListMemberClubs(ctx context.Context, userID string) ([]string, error)
+}
+
+// SearchRepository finds leagues, series and registered teams by name,
+// ignoring case and accents, best matches first.
+type SearchRepository interface {
+	Search(ctx context.Context, q SearchQuery) ([]domain.SearchResult, error)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Reschedule Requests
+
+func scanRescheduleRequest(row pgx.Row, r *domain.RescheduleRequest) error {
+	return row.Scan(&r.ID, &r.MatchID, &r.SeriesID, &r.TeamID, &r.VenueID, &r.Pitch, &r.KickoffAt, &r.EndsAt, &r.Reason, &r.Status, &r.ExpiresAt, &r.RequestedBy, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// CreateRescheduleRequest inserts the request with its initial event.
+func (s *Store) CreateRescheduleRequest(ctx context.Context, r *domain.RescheduleRequest, ev *domain.RescheduleEvent) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	err = tx.QueryRow(ctx, QInsertRescheduleRequest, r.ID, r.MatchID, r.SeriesID, r.TeamID, r.VenueID, r.Pitch, r.KickoffAt, r.EndsAt, r.Reason, r.Status, r.ExpiresAt, r.RequestedBy).Scan(&r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QInsertRescheduleEvent, ev.ID, ev.RequestID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetRescheduleRequest(ctx context.Context, id string) (*domain.RescheduleRequest, error) {
+	var r domain.RescheduleRequest
+	if err := scanRescheduleRequest(s.Pool.QueryRow(ctx, QSelectRescheduleRequestByID, id), &r); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &r, nil
+}
+
+func (s *Store) listRescheduleRequests(ctx context.Context, query string, args ...any) ([]domain.RescheduleRequest, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RescheduleRequest{}
+	for rows.Next() {
+		var r domain.RescheduleRequest
+		if err := scanRescheduleRequest(rows, &r); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, r)
+	}
+	return out, dbError(rows.Err())
+}
+
+// ListRescheduleRequestsByMatch returns every request made for the match,
+// oldest first.
+func (s *Store) ListRescheduleRequestsByMatch(ctx context.Context, matchID string) ([]domain.RescheduleRequest, error) {
+	return s.listRescheduleRequests(ctx, QSelectRescheduleRequestsByMatch, matchID)
+}
+
+// ListRescheduleRequestsByLeague returns the requests for matches of the
+// league in any of statuses, or all of them when statuses is empty, oldest
+// first.
+func (s *Store) ListRescheduleRequestsByLeague(ctx context.Context, leagueID string, statuses []string) ([]domain.RescheduleRequest, error) {
+	if statuses == nil {
+		statuses = []string{}
+	}
+	return s.listRescheduleRequests(ctx, QSelectRescheduleRequestsByLeague, leagueID, statuses)
+}
+
+// UpdateRescheduleStatus moves the request from one status to another and
+// records the event. When slot is set, the match is booked into it and the
+// released referee assignments are deleted in the same transaction. It
+// reports false when the request is no longer in from.
+func (s *Store) UpdateRescheduleStatus(ctx context.Context, id, from, to string, ev *domain.RescheduleEvent, slot *domain.Match, released []string) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, QUpdateRescheduleStatus, id, to, from)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, QInsertRescheduleEvent, ev.ID, ev.RequestID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+		return false, dbError(err)
+	}
+	if slot != nil {
+		if _, err := tx.Exec(ctx, QUpdateMatchSlot, slot.ID, nullable(slot.VenueID), slot.Pitch, slot.KickoffAt, slot.EndsAt); err != nil {
+			return false, dbError(err)
+		}
+		if _, err := tx.Exec(ctx, QDeleteRefereeAssignments, released); err != nil {
+			return false, dbError(err)
+		}
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListRescheduleEvents(ctx context.Context, requestID string) ([]domain.RescheduleEvent, error) {
+	rows, err := s.Pool.Query(ctx, QSelectRescheduleEvents, requestID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RescheduleEvent{}
+	for rows.Next() {
+		var ev domain.RescheduleEvent
+		if err := rows.Scan(&ev.ID, &ev.RequestID, &ev.FromStatus, &ev.ToStatus, &ev.ActorID, &ev.Reason, &ev.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, ev)
+	}
+	return out, dbError(rows.Err())
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Rosters
+
+func scanRosterPlayer(row pgx.Row, p *domain.RosterPlayer) error {
+	return row.Scan(&p.RegistrationID, &p.SeriesID, &p.TeamID, &p.PlayerID, &p.Name, &p.BirthDate, &p.ShirtNumber, &p.Late, &p.AddedBy, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func insertRosterChange(ctx context.Context, tx pgx.Tx, ch *domain.RosterChange) error {
+	_, err := tx.Exec(ctx, QInsertRosterChange, ch.ID, ch.RegistrationID, ch.SeriesID, ch.PlayerID, ch.Action, ch.ShirtNumber, ch.Late, ch.ActorID, ch.Reason)
+	return err
+}
+
+// SaveRosterPlayer adds the player to the registration's roster, or updates
+// the player's details, and records the change. It reports false, saving
+// nothing, when a new player would take the roster past maxSize; 0 means no
+// limit. An updated player keeps when and by whom it was added.
+func (s *Store) SaveRosterPlayer(ctx context.Context, p *domain.RosterPlayer, ch *domain.RosterChange, maxSize int) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QLockRegistration, p.RegistrationID); err != nil {
+		return false, dbError(err)
+	}
+	if maxSize > 0 {
+		var others int
+		if err := tx.QueryRow(ctx, QCountOtherRosterPlayers, p.RegistrationID, p.PlayerID).Scan(&others); err != nil {
+			return false, dbError(err)
+		}
+		if others >= maxSize {
+			return false, nil
+		}
+	}
+	err = tx.QueryRow(ctx, QUpsertRosterPlayer, p.RegistrationID, p.SeriesID, p.TeamID, p.PlayerID, p.Name, p.BirthDate, p.ShirtNumber, p.Late, p.AddedBy).Scan(&p.Late, &p.AddedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if err := insertRosterChange(ctx, tx, ch); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetRosterPlayer(ctx context.Context, registrationID, playerID string) (*domain.RosterPlayer, error) {
+	var p domain.RosterPlayer
+	if err := scanRosterPlayer(s.Pool.QueryRow(ctx, QSelectRosterPlayer, registrationID, playerID), &p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &p, nil
+}
+
+// ListRosterPlayers returns the registration's roster by shirt number.
+func (s *Store) ListRosterPlayers(ctx context.Context, registrationID string) ([]domain.RosterPlayer, error) {
+	rows, err := s.Pool.Query(ctx, QSelectRosterPlayers, registrationID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RosterPlayer{}
+	for rows.Next() {
+		var p domain.RosterPlayer
+		if err := scanRosterPlayer(rows, &p); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, p)
+	}
+	return out, dbError(rows.Err())
+}
+
+// DeleteRosterPlayer takes the player off the roster and records the change.
+// It reports false when the player was not on it.
+func (s *Store) DeleteRosterPlayer(ctx context.Context, registrationID, playerID string, ch *domain.RosterChange) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, QDeleteRosterPlayer, registrationID, playerID)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := insertRosterChange(ctx, tx, ch); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) listRosterChanges(ctx context.Context, query string, args ...any) ([]domain.RosterChange, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RosterChange{}
+	for rows.Next() {
+		var ch domain.RosterChange
+		if err := rows.Scan(&ch.ID, &ch.RegistrationID, &ch.SeriesID, &ch.PlayerID, &ch.Action, &ch.ShirtNumber, &ch.Late, &ch.ActorID, &ch.Reason, &ch.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, ch)
+	}
+	return out, dbError(rows.Err())
+}
+
+// ListRosterChanges returns the changes to the registration's roster,
+// oldest first.
+func (s *Store) ListRosterChanges(ctx context.Context, registrationID string) ([]domain.RosterChange, error) {
+	return s.listRosterChanges(ctx, QSelectRosterChanges, registrationID)
+}
+
+// ListLateRosterChanges returns the changes made to the series' rosters after
+// they locked, oldest first.
+func (s *Store) ListLateRosterChanges(ctx context.Context, seriesID string) ([]domain.RosterChange, error) {
+	return s.listRosterChanges(ctx, QSelectLateRosterChanges, seriesID)
+}
@@ -0,0 +1,875 @@
+package repository
+
+// Migrations is the ordered history of the schema. Released migrations must
+// not be edited; change the schema by appending a new version. Up statements
+// stay idempotent so databases created from the former schema script adopt
+// the history cleanly.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS leagues (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL UNIQUE,
+        slug TEXT NOT NULL UNIQUE,
+        region TEXT NOT NULL,
+        created_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+
+			// Series: 1:N league -> series
+			`CREATE TABLE IF NOT EXISTS series (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        format TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        UNIQUE(league_id, name)
+    );`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS series_league_name_lower_uidx ON series (league_id, lower(name));`,
+
+			// Team Registrations: M:N team <-> series
+			// Note: REFERENCES teams(id) assumes teams table exists in the same DB.
+			// Since we assume shared DB, this is fine.
+			`CREATE TABLE IF NOT EXISTS team_registrations (
+        id TEXT PRIMARY KEY,
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        status TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        UNIQUE(team_id, series_id)
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS team_registrations;`,
+			`DROP TABLE IF EXISTS series;`,
+			`DROP TABLE IF EXISTS leagues;`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "matches",
+		Up: []string{
+			// Matches: fixtures generated for a series
+			`CREATE TABLE IF NOT EXISTS matches (
+        id TEXT PRIMARY KEY,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        round INT NOT NULL,
+        leg INT NOT NULL DEFAULT 1,
+        home_team_id TEXT NOT NULL, -- References teams(id) logically
+        away_team_id TEXT NOT NULL, -- References teams(id) logically
+        status TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS matches_series_round_idx ON matches (series_id, round);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS matches;`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "match_results",
+		Up: []string{
+			// Points awarded per outcome, configurable per series
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS points_win INT NOT NULL DEFAULT 3;`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS points_draw INT NOT NULL DEFAULT 1;`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS points_loss INT NOT NULL DEFAULT 0;`,
+
+			// Match Results: 1:1 match -> result
+			`CREATE TABLE IF NOT EXISTS match_results (
+        match_id TEXT PRIMARY KEY REFERENCES matches(id) ON DELETE CASCADE,
+        outcome TEXT NOT NULL,
+        home_score INT NOT NULL,
+        away_score INT NOT NULL,
+        walkover_winner_id TEXT, -- References teams(id) logically
+        recorded_by TEXT NOT NULL, -- References users(id) logically
+        recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS match_results;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS points_loss;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS points_draw;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS points_win;`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "tiebreakers",
+		Up: []string{
+			// Tiebreakers: ordered criteria applied to teams level on points
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS tiebreakers TEXT[] NOT NULL DEFAULT ARRAY['goal_difference','goals_for']::TEXT[];`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS lots_seed BIGINT;`,
+			`ALTER TABLE match_results ADD COLUMN IF NOT EXISTS home_fair_play INT NOT NULL DEFAULT 0;`,
+			`ALTER TABLE match_results ADD COLUMN IF NOT EXISTS away_fair_play INT NOT NULL DEFAULT 0;`,
+		},
+		Down: []string{
+			`ALTER TABLE match_results DROP COLUMN IF EXISTS away_fair_play;`,
+			`ALTER TABLE match_results DROP COLUMN IF EXISTS home_fair_play;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS lots_seed;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS tiebreakers;`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "knockout",
+		Up: []string{
+			// Knockout: series play either as a league or as a single-elimination bracket
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'league';`,
+			`CREATE TABLE IF NOT EXISTS knockout_ties (
+        id TEXT PRIMARY KEY,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        round INT NOT NULL,
+        position INT NOT NULL,
+        home_team_id TEXT, -- References teams(id) logically
+        away_team_id TEXT, -- References teams(id) logically
+        home_seed INT,
+        away_seed INT,
+        legs INT NOT NULL DEFAULT 1,
+        winner_team_id TEXT,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        UNIQUE(series_id, round, position)
+    );`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS tie_id TEXT REFERENCES knockout_ties(id) ON DELETE CASCADE;`,
+			`ALTER TABLE match_results ADD COLUMN IF NOT EXISTS home_penalties INT;`,
+			`ALTER TABLE match_results ADD COLUMN IF NOT EXISTS away_penalties INT;`,
+		},
+		Down: []string{
+			`ALTER TABLE match_results DROP COLUMN IF EXISTS away_penalties;`,
+			`ALTER TABLE match_results DROP COLUMN IF EXISTS home_penalties;`,
+			`DELETE FROM matches WHERE tie_id IS NOT NULL;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS tie_id;`,
+			`DROP TABLE IF EXISTS knockout_ties;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS mode;`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "stages",
+		Up: []string{
+			// Stages: a group phase followed by a knockout phase under one series
+			`CREATE TABLE IF NOT EXISTS series_stages (
+        id TEXT PRIMARY KEY,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        position INT NOT NULL,
+        kind TEXT NOT NULL,
+        qualifiers_per_group INT NOT NULL DEFAULT 0,
+        draw_seed BIGINT,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        UNIQUE(series_id, position)
+    );`,
+			`CREATE TABLE IF NOT EXISTS stage_groups (
+        id TEXT PRIMARY KEY,
+        stage_id TEXT NOT NULL REFERENCES series_stages(id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        UNIQUE(stage_id, name)
+    );`,
+			`CREATE TABLE IF NOT EXISTS stage_group_teams (
+        group_id TEXT NOT NULL REFERENCES stage_groups(id) ON DELETE CASCADE,
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        draw_position INT NOT NULL,
+        PRIMARY KEY (group_id, team_id)
+    );`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS stage_id TEXT REFERENCES series_stages(id) ON DELETE CASCADE;`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS group_id TEXT REFERENCES stage_groups(id) ON DELETE CASCADE;`,
+			`ALTER TABLE knockout_ties ADD COLUMN IF NOT EXISTS stage_id TEXT REFERENCES series_stages(id) ON DELETE CASCADE;`,
+		},
+		Down: []string{
+			`DELETE FROM matches WHERE stage_id IS NOT NULL;`,
+			`ALTER TABLE knockout_ties DROP COLUMN IF EXISTS stage_id;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS group_id;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS stage_id;`,
+			`DROP TABLE IF EXISTS stage_group_teams;`,
+			`DROP TABLE IF EXISTS stage_groups;`,
+			`DROP TABLE IF EXISTS series_stages;`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "registration_workflow",
+		Up: []string{
+			// Registration workflow: "active" predates approvals and means approved
+			`UPDATE team_registrations SET status='approved' WHERE status='active';`,
+			`CREATE TABLE IF NOT EXISTS registration_events (
+        id TEXT PRIMARY KEY,
+        registration_id TEXT NOT NULL REFERENCES team_registrations(id) ON DELETE CASCADE,
+        from_status TEXT NOT NULL DEFAULT '',
+        to_status TEXT NOT NULL,
+        actor_id TEXT NOT NULL, -- References users(id) logically
+        reason TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS registration_events_registration_idx ON registration_events (registration_id, created_at);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS registration_events;`,
+			`UPDATE team_registrations SET status='active' WHERE status='approved';`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "series_capacity",
+		Up: []string{
+			// Capacity: optional team limit and registration window per series
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS max_teams INT;`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS registration_opens_at TIMESTAMPTZ;`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS registration_closes_at TIMESTAMPTZ;`,
+			`CREATE INDEX IF NOT EXISTS team_registrations_series_status_idx ON team_registrations (series_id, status, created_at);`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS team_registrations_series_status_idx;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS registration_closes_at;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS registration_opens_at;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS max_teams;`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "eligibility",
+		Up: []string{
+			// Eligibility: series categories, the categories a team declares when
+			// registering, and organizer overrides of failed rules
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS age_category TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS gender_category TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS eligibility_policy TEXT NOT NULL DEFAULT 'reject';`,
+			`ALTER TABLE team_registrations ADD COLUMN IF NOT EXISTS age_category TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE team_registrations ADD COLUMN IF NOT EXISTS gender_category TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE team_registrations ADD COLUMN IF NOT EXISTS eligibility_issues JSONB NOT NULL DEFAULT '[]';`,
+			`CREATE TABLE IF NOT EXISTS eligibility_overrides (
+        id TEXT PRIMARY KEY,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        rule TEXT NOT NULL,
+        reason TEXT NOT NULL,
+        created_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        UNIQUE(series_id, team_id, rule)
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS eligibility_overrides;`,
+			`ALTER TABLE team_registrations DROP COLUMN IF EXISTS eligibility_issues;`,
+			`ALTER TABLE team_registrations DROP COLUMN IF EXISTS gender_category;`,
+			`ALTER TABLE team_registrations DROP COLUMN IF EXISTS age_category;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS eligibility_policy;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS gender_category;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS age_category;`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "league_members",
+		Up: []string{
+			// League roles: every league creator starts as its owner
+			`CREATE TABLE IF NOT EXISTS league_members (
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        user_id TEXT NOT NULL, -- References users(id) logically
+        role TEXT NOT NULL,
+        added_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (league_id, user_id)
+    );`,
+			`CREATE INDEX IF NOT EXISTS league_members_user_idx ON league_members (user_id);`,
+			`INSERT INTO league_members (league_id, user_id, role, added_by) SELECT id, created_by, 'owner', created_by FROM leagues ON CONFLICT DO NOTHING;`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS league_members;`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "listing_indexes",
+		Up: []string{
+			// Keyset pagination orders every listing by its sort key and id
+			`CREATE INDEX IF NOT EXISTS leagues_created_at_idx ON leagues (created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS leagues_name_idx ON leagues (name, id);`,
+			`CREATE INDEX IF NOT EXISTS leagues_name_prefix_idx ON leagues (lower(name) text_pattern_ops);`,
+			`CREATE INDEX IF NOT EXISTS series_league_created_at_idx ON series (league_id, created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS series_league_name_idx ON series (league_id, name, id);`,
+			`CREATE INDEX IF NOT EXISTS team_registrations_series_created_at_idx ON team_registrations (series_id, created_at, id);`,
+			`CREATE INDEX IF NOT EXISTS team_registrations_team_created_at_idx ON team_registrations (team_id, created_at, id);`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS team_registrations_team_created_at_idx;`,
+			`DROP INDEX IF EXISTS team_registrations_series_created_at_idx;`,
+			`DROP INDEX IF EXISTS series_league_name_idx;`,
+			`DROP INDEX IF EXISTS series_league_created_at_idx;`,
+			`DROP INDEX IF EXISTS leagues_name_prefix_idx;`,
+			`DROP INDEX IF EXISTS leagues_name_idx;`,
+			`DROP INDEX IF EXISTS leagues_created_at_idx;`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "search",
+		Up: []string{
+			// Full-text search ignoring accents. unaccent() is only stable, so
+			// generated columns and indexes go through an immutable wrapper.
+			`CREATE EXTENSION IF NOT EXISTS unaccent;`,
+			`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text
+        LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+        AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;`,
+			`ALTER TABLE leagues ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', immutable_unaccent(name)), 'A') ||
+        setweight(to_tsvector('simple', immutable_unaccent(region)), 'B')
+    ) STORED;`,
+			`CREATE INDEX IF NOT EXISTS leagues_search_idx ON leagues USING GIN (search_vector);`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('simple', immutable_unaccent(name))
+    ) STORED;`,
+			`CREATE INDEX IF NOT EXISTS series_search_idx ON series USING GIN (search_vector);`,
+		},
+		Down: []string{
+			`ALTER TABLE series DROP COLUMN IF EXISTS search_vector;`,
+			`ALTER TABLE leagues DROP COLUMN IF EXISTS search_vector;`,
+			`DROP FUNCTION IF EXISTS immutable_unaccent(text);`,
+			// The unaccent extension stays; other schemas may use it.
+		},
+	},
+	{
+		Version: 13,
+		Name:    "seasons",
+		Up: []string{
+			// Seasons: 1:N league -> season -> series
+			`CREATE TABLE IF NOT EXISTS seasons (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        starts_on DATE NOT NULL,
+        ends_on DATE NOT NULL,
+        status TEXT NOT NULL DEFAULT 'planning',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        CHECK (ends_on > starts_on)
+    );`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS seasons_league_name_lower_uidx ON seasons (league_id, lower(name));`,
+			`CREATE INDEX IF NOT EXISTS seasons_league_starts_on_idx ON seasons (league_id, starts_on);`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS season_id TEXT REFERENCES seasons(id) ON DELETE CASCADE;`,
+			// Existing series move into one season per league, named after
+			// the year its first series was created.
+			`INSERT INTO seasons (id, league_id, name, starts_on, ends_on, status)
+        SELECT md5('season:' || league_id), league_id, to_char(min(created_at), 'YYYY'), min(created_at)::date, (max(created_at) + interval '1 year')::date, 'in_progress'
+        FROM series WHERE season_id IS NULL GROUP BY league_id
+        ON CONFLICT DO NOTHING;`,
+			`UPDATE series SET season_id = md5('season:' || league_id) WHERE season_id IS NULL;`,
+			`ALTER TABLE series ALTER COLUMN season_id SET NOT NULL;`,
+			// Series names repeat from season to season
+			`ALTER TABLE series DROP CONSTRAINT IF EXISTS series_league_id_name_key;`,
+			`DROP INDEX IF EXISTS series_league_name_lower_uidx;`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS series_season_name_lower_uidx ON series (season_id, lower(name));`,
+			`CREATE INDEX IF NOT EXISTS series_season_created_at_idx ON series (season_id, created_at, id);`,
+		},
+		Down: []string{
+			// Fails while series names repeat across the seasons of a league.
+			`CREATE UNIQUE INDEX IF NOT EXISTS series_league_name_lower_uidx ON series (league_id, lower(name));`,
+			`ALTER TABLE series ADD CONSTRAINT series_league_id_name_key UNIQUE (league_id, name);`,
+			`DROP INDEX IF EXISTS series_season_created_at_idx;`,
+			`DROP INDEX IF EXISTS series_season_name_lower_uidx;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS season_id;`,
+			`DROP TABLE IF EXISTS seasons;`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "divisions",
+		Up: []string{
+			// Divisions rank the series of a season for promotion and relegation
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS division INT;`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS series_season_division_uidx ON series (season_id, division) WHERE division IS NOT NULL;`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS series_season_division_uidx;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS division;`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "venues",
+		Up: []string{
+			// Venues: 1:N league -> venue; pitches, weekly windows and
+			// blackouts are small lists edited with the venue
+			`CREATE TABLE IF NOT EXISTS venues (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        address TEXT NOT NULL DEFAULT '',
+        latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+        longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+        timezone TEXT NOT NULL DEFAULT 'UTC',
+        pitches JSONB NOT NULL DEFAULT '[]',
+        availability JSONB NOT NULL DEFAULT '[]',
+        blackouts JSONB NOT NULL DEFAULT '[]',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS venues_league_name_lower_uidx ON venues (league_id, lower(name));`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS match_minutes INT NOT NULL DEFAULT 90;`,
+			// Booked slots; ends_at is stored so overlaps can be excluded
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS venue_id TEXT REFERENCES venues(id);`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS pitch INT;`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS kickoff_at TIMESTAMPTZ;`,
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ;`,
+			`CREATE INDEX IF NOT EXISTS matches_venue_kickoff_idx ON matches (venue_id, kickoff_at) WHERE venue_id IS NOT NULL;`,
+			// A pitch hosts one match at a time
+			`CREATE EXTENSION IF NOT EXISTS btree_gist;`,
+			`DO $$ BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'matches_pitch_booking_excl') THEN
+            ALTER TABLE matches ADD CONSTRAINT matches_pitch_booking_excl
+                EXCLUDE USING gist (venue_id WITH =, pitch WITH =, tstzrange(kickoff_at, ends_at) WITH &&)
+                WHERE (venue_id IS NOT NULL);
+        END IF;
+    END $$;`,
+		},
+		Down: []string{
+			`ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_pitch_booking_excl;`,
+			`DROP INDEX IF EXISTS matches_venue_kickoff_idx;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS ends_at;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS kickoff_at;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS pitch;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS venue_id;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS match_minutes;`,
+			`DROP TABLE IF EXISTS venues;`,
+			// The btree_gist extension stays; other schemas may use it.
+		},
+	},
+	{
+		Version: 16,
+		Name:    "team_blackouts",
+		Up: []string{
+			// Days a team cannot play, kept by its club for every league
+			`CREATE TABLE IF NOT EXISTS team_blackouts (
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        date DATE NOT NULL,
+        reason TEXT NOT NULL DEFAULT '',
+        created_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (team_id, date)
+    );`,
+			`CREATE INDEX IF NOT EXISTS matches_series_kickoff_idx ON matches (series_id, kickoff_at) WHERE kickoff_at IS NOT NULL;`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS matches_series_kickoff_idx;`,
+			`DROP TABLE IF EXISTS team_blackouts;`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "calendar_feeds",
+		Up: []string{
+			`ALTER TABLE matches ADD COLUMN IF NOT EXISTS sequence INT NOT NULL DEFAULT 0;`,
+			// Bookings that were freed or whose match was deleted, kept so
+			// calendar feeds can publish the cancellation
+			`CREATE TABLE IF NOT EXISTS match_cancellations (
+        match_id TEXT PRIMARY KEY, -- The match may no longer exist
+        series_id TEXT NOT NULL,
+        venue_id TEXT,
+        pitch INT,
+        home_team_id TEXT NOT NULL,
+        away_team_id TEXT NOT NULL,
+        round INT NOT NULL,
+        kickoff_at TIMESTAMPTZ NOT NULL,
+        ends_at TIMESTAMPTZ NOT NULL,
+        sequence INT NOT NULL,
+        cancelled_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS match_cancellations_series_idx ON match_cancellations (series_id);`,
+			`CREATE INDEX IF NOT EXISTS match_cancellations_venue_idx ON match_cancellations (venue_id);`,
+			// Every path that changes or deletes a match, cascades included,
+			// goes through the trigger.
+			`CREATE OR REPLACE FUNCTION matches_calendar() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'DELETE' THEN
+            IF OLD.kickoff_at IS NOT NULL THEN
+                INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+                VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, OLD.sequence + 1)
+                ON CONFLICT (match_id) DO NOTHING;
+            END IF;
+            RETURN OLD;
+        END IF;
+        IF (OLD.venue_id, OLD.pitch, OLD.kickoff_at, OLD.ends_at, OLD.status) IS DISTINCT FROM (NEW.venue_id, NEW.pitch, NEW.kickoff_at, NEW.ends_at, NEW.status) THEN
+            NEW.sequence := OLD.sequence + 1;
+        END IF;
+        IF OLD.kickoff_at IS NOT NULL AND NEW.kickoff_at IS NULL THEN
+            INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+            VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, NEW.sequence)
+            ON CONFLICT (match_id) DO NOTHING;
+        ELSIF OLD.kickoff_at IS NULL AND NEW.kickoff_at IS NOT NULL THEN
+            DELETE FROM match_cancellations WHERE match_id = NEW.id;
+        END IF;
+        RETURN NEW;
+    END $$ LANGUAGE plpgsql;`,
+			`DROP TRIGGER IF EXISTS matches_calendar_trg ON matches;`,
+			`CREATE TRIGGER matches_calendar_trg BEFORE UPDATE OR DELETE ON matches FOR EACH ROW EXECUTE FUNCTION matches_calendar();`,
+			// One feed per team, series or venue; issuing it again replaces the token
+			`CREATE TABLE IF NOT EXISTS calendar_feeds (
+        kind TEXT NOT NULL CHECK (kind IN ('team', 'series', 'venue')),
+        target_id TEXT NOT NULL,
+        token_hash TEXT NOT NULL,
+        created_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (kind, target_id)
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS calendar_feeds;`,
+			`DROP TRIGGER IF EXISTS matches_calendar_trg ON matches;`,
+			`DROP FUNCTION IF EXISTS matches_calendar();`,
+			`DROP TABLE IF EXISTS match_cancellations;`,
+			`ALTER TABLE matches DROP COLUMN IF EXISTS sequence;`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "reschedule_requests",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS reschedule_requests (
+        id TEXT PRIMARY KEY,
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        venue_id TEXT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+        pitch INT NOT NULL,
+        kickoff_at TIMESTAMPTZ NOT NULL,
+        ends_at TIMESTAMPTZ NOT NULL,
+        reason TEXT NOT NULL DEFAULT '',
+        status TEXT NOT NULL,
+        expires_at TIMESTAMPTZ NOT NULL,
+        requested_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			// One open request per match at a time
+			`CREATE UNIQUE INDEX IF NOT EXISTS reschedule_requests_open_uidx ON reschedule_requests (match_id) WHERE status IN ('pending', 'accepted');`,
+			`CREATE INDEX IF NOT EXISTS reschedule_requests_series_idx ON reschedule_requests (series_id, created_at);`,
+			`CREATE TABLE IF NOT EXISTS reschedule_events (
+        id TEXT PRIMARY KEY,
+        request_id TEXT NOT NULL REFERENCES reschedule_requests(id) ON DELETE CASCADE,
+        from_status TEXT NOT NULL DEFAULT '',
+        to_status TEXT NOT NULL,
+        actor_id TEXT NOT NULL DEFAULT '', -- References users(id) logically
+        reason TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS reschedule_events_request_idx ON reschedule_events (request_id, created_at);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS reschedule_events;`,
+			`DROP TABLE IF EXISTS reschedule_requests;`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "rosters",
+		Up: []string{
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS max_roster_size INT;`,
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS roster_locks_at TIMESTAMPTZ;`,
+			// Players registered with a team for a series; series_id and
+			// team_id are copied from the registration for the unique index
+			`CREATE TABLE IF NOT EXISTS roster_players (
+        registration_id TEXT NOT NULL REFERENCES team_registrations(id) ON DELETE CASCADE,
+        series_id TEXT NOT NULL,
+        team_id TEXT NOT NULL, -- References teams(id) logically
+        player_id TEXT NOT NULL, -- References players(id) logically
+        name TEXT NOT NULL,
+        birth_date DATE NOT NULL,
+        shirt_number INT NOT NULL CHECK (shirt_number BETWEEN 1 AND 99),
+        late BOOLEAN NOT NULL DEFAULT false,
+        added_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (registration_id, player_id)
+    );`,
+			// A player plays for one team per series, and shirt numbers are
+			// not shared within a roster
+			`CREATE UNIQUE INDEX IF NOT EXISTS roster_players_series_player_uidx ON roster_players (series_id, player_id);`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS roster_players_shirt_uidx ON roster_players (registration_id, shirt_number);`,
+			`CREATE TABLE IF NOT EXISTS roster_changes (
+        id TEXT PRIMARY KEY,
+        registration_id TEXT NOT NULL REFERENCES team_registrations(id) ON DELETE CASCADE,
+        series_id TEXT NOT NULL,
+        player_id TEXT NOT NULL,
+        action TEXT NOT NULL,
+        shirt_number INT NOT NULL,
+        late BOOLEAN NOT NULL DEFAULT false,
+        actor_id TEXT NOT NULL, -- References users(id) logically
+        reason TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS roster_changes_registration_idx ON roster_changes (registration_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS roster_changes_series_late_idx ON roster_changes (series_id, created_at) WHERE late;`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS roster_changes;`,
+			`DROP TABLE IF EXISTS roster_players;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS roster_locks_at;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS max_roster_size;`,
+		},
+	},
+	{
+		Version: 20,
+		Name:    "transfers",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS transfer_windows (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        season_id TEXT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+        opens_at TIMESTAMPTZ NOT NULL,
+        closes_at TIMESTAMPTZ NOT NULL CHECK (closes_at > opens_at),
+        max_transfers_per_team INT,
+        created_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS transfer_windows_season_idx ON transfer_windows (season_id, opens_at);`,
+			`CREATE TABLE IF NOT EXISTS transfers (
+        id TEXT PRIMARY KEY,
+        season_id TEXT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+        window_id TEXT NOT NULL REFERENCES transfer_windows(id) ON DELETE CASCADE,
+        player_id TEXT NOT NULL, -- References players(id) logically
+        from_registration_id TEXT NOT NULL REFERENCES team_registrations(id) ON DELETE CASCADE,
+        to_registration_id TEXT NOT NULL REFERENCES team_registrations(id) ON DELETE CASCADE,
+        from_team_id TEXT NOT NULL,
+        to_team_id TEXT NOT NULL,
+        shirt_number INT NOT NULL CHECK (shirt_number BETWEEN 1 AND 99),
+        status TEXT NOT NULL,
+        from_approved_by TEXT NOT NULL DEFAULT '',
+        to_approved_by TEXT NOT NULL DEFAULT '',
+        requested_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			// One pending transfer per player at a time
+			`CREATE UNIQUE INDEX IF NOT EXISTS transfers_pending_uidx ON transfers (season_id, player_id) WHERE status = 'pending';`,
+			`CREATE INDEX IF NOT EXISTS transfers_season_idx ON transfers (season_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS transfers_window_completed_idx ON transfers (window_id) WHERE status = 'completed';`,
+			`CREATE TABLE IF NOT EXISTS transfer_events (
+        id TEXT PRIMARY KEY,
+        transfer_id TEXT NOT NULL REFERENCES transfers(id) ON DELETE CASCADE,
+        action TEXT NOT NULL,
+        club TEXT NOT NULL DEFAULT '',
+        actor_id TEXT NOT NULL DEFAULT '', -- References users(id) logically
+        reason TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS transfer_events_transfer_idx ON transfer_events (transfer_id, created_at);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS transfer_events;`,
+			`DROP TABLE IF EXISTS transfers;`,
+			`DROP TABLE IF EXISTS transfer_windows;`,
+		},
+	},
+	{
+		Version: 21,
+		Name:    "discipline",
+		Up: []string{
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS discipline_rules JSONB NOT NULL DEFAULT '[{"card":"yellow","count":5,"matches":1},{"card":"second_yellow","count":1,"matches":1},{"card":"red","count":1,"matches":2}]';`,
+			`CREATE TABLE IF NOT EXISTS cards (
+        id TEXT PRIMARY KEY,
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        team_id TEXT NOT NULL,
+        player_id TEXT NOT NULL, -- References players(id) logically
+        type TEXT NOT NULL CHECK (type IN ('yellow','second_yellow','red')),
+        minute INT NOT NULL DEFAULT 0,
+        reason TEXT NOT NULL DEFAULT '',
+        recorded_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE INDEX IF NOT EXISTS cards_match_idx ON cards (match_id, created_at);`,
+			`CREATE INDEX IF NOT EXISTS cards_series_player_idx ON cards (series_id, player_id);`,
+			`CREATE TABLE IF NOT EXISTS suspensions (
+        id TEXT PRIMARY KEY,
+        series_id TEXT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+        player_id TEXT NOT NULL, -- References players(id) logically
+        team_id TEXT NOT NULL,
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        card_id TEXT REFERENCES cards(id) ON DELETE CASCADE,
+        matches INT NOT NULL CHECK (matches > 0),
+        reason TEXT NOT NULL DEFAULT '',
+        created_by TEXT NOT NULL DEFAULT '', -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			// A card triggers at most one suspension; committee decisions have none
+			`CREATE UNIQUE INDEX IF NOT EXISTS suspensions_card_uidx ON suspensions (card_id);`,
+			`CREATE INDEX IF NOT EXISTS suspensions_series_player_idx ON suspensions (series_id, player_id);`,
+			`CREATE TABLE IF NOT EXISTS match_sheets (
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        team_id TEXT NOT NULL,
+        player_ids TEXT[] NOT NULL,
+        submitted_by TEXT NOT NULL, -- References users(id) logically
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (match_id, team_id)
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS match_sheets;`,
+			`DROP TABLE IF EXISTS suspensions;`,
+			`DROP TABLE IF EXISTS cards;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS discipline_rules;`,
+		},
+	},
+	{
+		Version: 22,
+		Name:    "match_events",
+		Up: []string{
+			// seq is assigned by the service; two officials posting at once
+			// collide on the key instead of interleaving scores
+			`CREATE TABLE IF NOT EXISTS match_events (
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        seq BIGINT NOT NULL CHECK (seq > 0),
+        type TEXT NOT NULL CHECK (type IN ('period_start','period_end','goal','card','substitution','retraction')),
+        period INT NOT NULL DEFAULT 0,
+        minute INT NOT NULL DEFAULT 0,
+        team_id TEXT NOT NULL DEFAULT '',
+        player_id TEXT NOT NULL DEFAULT '', -- References players(id) logically
+        player_off_id TEXT NOT NULL DEFAULT '',
+        card TEXT NOT NULL DEFAULT '',
+        own_goal BOOLEAN NOT NULL DEFAULT false,
+        final BOOLEAN NOT NULL DEFAULT false,
+        home_penalties INT,
+        away_penalties INT,
+        retracts BIGINT NOT NULL DEFAULT 0,
+        note TEXT NOT NULL DEFAULT '',
+        home_score INT NOT NULL DEFAULT 0,
+        away_score INT NOT NULL DEFAULT 0,
+        recorded_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        PRIMARY KEY (match_id, seq)
+    );`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS match_events;`,
+		},
+	},
+	{
+		Version: 23,
+		Name:    "referees",
+		Up: []string{
+			`ALTER TABLE series ADD COLUMN IF NOT EXISTS min_referee_level INT NOT NULL DEFAULT 0;`,
+			`CREATE TABLE IF NOT EXISTS referees (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        user_id TEXT NOT NULL, -- References users(id) logically
+        name TEXT NOT NULL,
+        level INT NOT NULL CHECK (level > 0),
+        formats TEXT[] NOT NULL DEFAULT '{}',
+        timezone TEXT NOT NULL DEFAULT 'UTC',
+        availability JSONB NOT NULL DEFAULT '[]',
+        blackouts JSONB NOT NULL DEFAULT '[]',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS referees_league_user_uidx ON referees (league_id, user_id);`,
+			`CREATE TABLE IF NOT EXISTS referee_assignments (
+        id TEXT PRIMARY KEY,
+        league_id TEXT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+        match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+        referee_id TEXT NOT NULL REFERENCES referees(id) ON DELETE CASCADE,
+        role TEXT NOT NULL CHECK (role IN ('referee','assistant')),
+        assigned_by TEXT NOT NULL, -- References users(id) logically
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS referee_assignments_match_referee_uidx ON referee_assignments (match_id, referee_id);`,
+			// One main referee per match
+			`CREATE UNIQUE INDEX IF NOT EXISTS referee_assignments_main_uidx ON referee_assignments (match_id) WHERE role = 'referee';`,
+			`CREATE INDEX IF NOT EXISTS referee_assignments_referee_idx ON referee_assignments (referee_id);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS referee_assignments;`,
+			`DROP TABLE IF EXISTS referees;`,
+			`ALTER TABLE series DROP COLUMN IF EXISTS min_referee_level;`,
+		},
+	},
+	{
+		Version: 24,
+		Name:    "match_sides_sequence",
+		Up: []string{
+			// The scheduler may swap home and away, which calendar apps
+			// show as a changed event
+			`CREATE OR REPLACE FUNCTION matches_calendar() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'DELETE' THEN
+            IF OLD.kickoff_at IS NOT NULL THEN
+                INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+                VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, OLD.sequence + 1)
+                ON CONFLICT (match_id) DO NOTHING;
+            END IF;
+            RETURN OLD;
+        END IF;
+        IF (OLD.venue_id, OLD.pitch, OLD.kickoff_at, OLD.ends_at, OLD.status, OLD.home_team_id, OLD.away_team_id)
+            IS DISTINCT FROM (NEW.venue_id, NEW.pitch, NEW.kickoff_at, NEW.ends_at, NEW.status, NEW.home_team_id, NEW.away_team_id) THEN
+            NEW.sequence := OLD.sequence + 1;
+        END IF;
+        IF OLD.kickoff_at IS NOT NULL AND NEW.kickoff_at IS NULL THEN
+            INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+            VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, NEW.sequence)
+            ON CONFLICT (match_id) DO NOTHING;
+        ELSIF OLD.kickoff_at IS NULL AND NEW.kickoff_at IS NOT NULL THEN
+            DELETE FROM match_cancellations WHERE match_id = NEW.id;
+        END IF;
+        RETURN NEW;
+    END $$ LANGUAGE plpgsql;`,
+		},
+		Down: []string{
+			`CREATE OR REPLACE FUNCTION matches_calendar() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'DELETE' THEN
+            IF OLD.kickoff_at IS NOT NULL THEN
+                INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+                VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, OLD.sequence + 1)
+                ON CONFLICT (match_id) DO NOTHING;
+            END IF;
+            RETURN OLD;
+        END IF;
+        IF (OLD.venue_id, OLD.pitch, OLD.kickoff_at, OLD.ends_at, OLD.status) IS DISTINCT FROM (NEW.venue_id, NEW.pitch, NEW.kickoff_at, NEW.ends_at, NEW.status) THEN
+            NEW.sequence := OLD.sequence + 1;
+        END IF;
+        IF OLD.kickoff_at IS NOT NULL AND NEW.kickoff_at IS NULL THEN
+            INSERT INTO match_cancellations (match_id, series_id, venue_id, pitch, home_team_id, away_team_id, round, kickoff_at, ends_at, sequence)
+            VALUES (OLD.id, OLD.series_id, OLD.venue_id, OLD.pitch, OLD.home_team_id, OLD.away_team_id, OLD.round, OLD.kickoff_at, OLD.ends_at, NEW.sequence)
+            ON CONFLICT (match_id) DO NOTHING;
+        ELSIF OLD.kickoff_at IS NULL AND NEW.kickoff_at IS NOT NULL THEN
+            DELETE FROM match_cancellations WHERE match_id = NEW.id;
+        END IF;
+        RETURN NEW;
+    END $$ LANGUAGE plpgsql;`,
+		},
+	},
+	{
+		Version: 25,
+		Name:    "active_rosters",
+		Up: []string{
+			// A withdrawn or rejected team's roster stays as its record but
+			// no longer holds its players, who may then join another team
+			`ALTER TABLE roster_players ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true;`,
+			`UPDATE roster_players p SET active = false FROM team_registrations r
+    WHERE r.id = p.registration_id AND (r.status IN ('withdrawn', 'rejected') OR EXISTS (
+        SELECT 1 FROM registration_events e WHERE e.registration_id = r.id AND e.to_status IN ('withdrawn', 'rejected')));`,
+			`DROP INDEX IF EXISTS roster_players_series_player_uidx;`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS roster_players_series_player_active_uidx ON roster_players (series_id, player_id) WHERE active;`,
+			`CREATE OR REPLACE FUNCTION roster_players_release() RETURNS trigger AS $$
+    BEGIN
+        IF NEW.status IN ('withdrawn', 'rejected') THEN
+            UPDATE roster_players SET active = false WHERE registration_id = NEW.id AND active;
+        END IF;
+        RETURN NEW;
+    END $$ LANGUAGE plpgsql;`,
+			`DROP TRIGGER IF EXISTS team_registrations_roster_trg ON team_registrations;`,
+			`CREATE TRIGGER team_registrations_roster_trg AFTER UPDATE OF status ON team_registrations FOR EACH ROW EXECUTE FUNCTION roster_players_release();`,
+		},
+		Down: []string{
+			`DROP TRIGGER IF EXISTS team_registrations_roster_trg ON team_registrations;`,
+			`DROP FUNCTION IF EXISTS roster_players_release();`,
+			`DROP INDEX IF EXISTS roster_players_series_player_active_uidx;`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS roster_players_series_player_uidx ON roster_players (series_id, player_id);`,
+			`ALTER TABLE roster_players DROP COLUMN IF EXISTS active;`,
+		},
+	},
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// SearchQuery is a search for results of the given types matching every
+// term, each as a word prefix.
+type SearchQuery struct {
+	Terms []string // Letters and digits only
+	Types []string // "league", "series", "team"
+	Limit int
+}
+
+func (s *Store) Search(ctx context.Context, q SearchQuery) ([]domain.SearchResult, error) {
+	prefixes := make([]string, len(q.Terms))
+	for i, t := range q.Terms {
+		prefixes[i] = t + ":*"
+	}
+	rows, err := s.Pool.Query(ctx, QSearch, strings.Join(prefixes, " & "), q.Types, q.Limit)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.SearchResult{}
+	for rows.Next() {
+		var r domain.SearchResult
+		var rank float32
+		if err := rows.Scan(&r.Type, &r.ID, &r.Name, &r.Region, &r.LeagueID, &r.LeagueName, &r.ClubID, &rank); err != nil {
+			return nil, dbError(err)
+		}
+		r.Rank = float64(rank)
+		out = append(out, r)
+	}
+	return out, dbError(rows.Err())
+}
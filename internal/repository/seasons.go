@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Seasons
+
+func scanSeason(row pgx.Row, se *domain.Season) error {
+	return row.Scan(&se.ID, &se.LeagueID, &se.Name, &se.StartsOn, &se.EndsOn, &se.Status, &se.CreatedAt, &se.UpdatedAt)
+}
+
+// CreateSeason inserts a season together with its series, and the
+// registrations inviting teams into them with the events recording each
+// invitation, in a single transaction.
+func (s *Store) CreateSeason(ctx context.Context, se *domain.Season, series []domain.Series, regs []domain.TeamRegistration, events []domain.RegistrationEvent) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QInsertSeason, se.ID, se.LeagueID, se.Name, se.StartsOn, se.EndsOn, se.Status); err != nil {
+		return dbError(err)
+	}
+	for _, ser := range series {
+		if _, err := tx.Exec(ctx, QInsertSeries, ser.ID, ser.LeagueID, ser.SeasonID, ser.Name, ser.Format, ser.PointsWin, ser.PointsDraw, ser.PointsLoss, ser.Tiebreakers, ser.LotsSeed, ser.Mode, ser.MaxTeams, ser.RegistrationOpensAt, ser.RegistrationClosesAt, ser.AgeCategory, ser.GenderCategory, ser.EligibilityPolicy, ser.Division, ser.MatchMinutes, ser.MaxRosterSize, ser.RosterLocksAt, ser.DisciplineRules, ser.MinRefereeLevel); err != nil {
+			return dbError(err)
+		}
+	}
+	for _, tr := range regs {
+		if _, err := tx.Exec(ctx, QInsertTeamRegistration, tr.ID, tr.TeamID, tr.SeriesID, tr.Status, tr.AgeCategory, tr.GenderCategory, tr.EligibilityIssues); err != nil {
+			return dbError(err)
+		}
+	}
+	for _, ev := range events {
+		if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+			return dbError(err)
+		}
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) GetSeasonByID(ctx context.Context, id string) (*domain.Season, error) {
+	var se domain.Season
+	if err := scanSeason(s.Pool.QueryRow(ctx, QSelectSeasonByID, id), &se); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &se, nil
+}
+
+// ListSeasons returns the seasons of a league, latest start first.
+func (s *Store) ListSeasons(ctx context.Context, leagueID string) ([]domain.Season, error) {
+	rows, err := s.Pool.Query(ctx, QSelectSeasonsByLeague, leagueID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Season{}
+	for rows.Next() {
+		var se domain.Season
+		if err := scanSeason(rows, &se); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, se)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) UpdateSeason(ctx context.Context, se *domain.Season) error {
+	_, err := s.Pool.Exec(ctx, QUpdateSeason, se.ID, se.Name, se.StartsOn, se.EndsOn, se.Status)
+	return dbError(err)
+}
+
+func (s *Store) DeleteSeason(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteSeason, id)
+	return dbError(err)
+}
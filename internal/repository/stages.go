@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Stages
+
+// CreateStages inserts the stages of a series along with their groups, the
+// teams drawn into each group and the group matches in a single transaction.
+func (s *Store) CreateStages(ctx context.Context, stages []domain.Stage, matches []domain.Match) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	for _, st := range stages {
+		if _, err := tx.Exec(ctx, QInsertStage, st.ID, st.SeriesID, st.Position, st.Kind, st.QualifiersPerGroup, st.DrawSeed); err != nil {
+			return dbError(err)
+		}
+		for _, g := range st.Groups {
+			if _, err := tx.Exec(ctx, QInsertStageGroup, g.ID, st.ID, g.Name); err != nil {
+				return dbError(err)
+			}
+			for i, teamID := range g.TeamIDs {
+				if _, err := tx.Exec(ctx, QInsertStageGroupTeam, g.ID, teamID, i+1); err != nil {
+					return dbError(err)
+				}
+			}
+		}
+	}
+	if err := insertMatches(ctx, tx, matches); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListStagesBySeries(ctx context.Context, seriesID string) ([]domain.Stage, error) {
+	rows, err := s.Pool.Query(ctx, QSelectStagesBySeries, seriesID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Stage{}
+	for rows.Next() {
+		var st domain.Stage
+		if err := rows.Scan(&st.ID, &st.SeriesID, &st.Position, &st.Kind, &st.QualifiersPerGroup, &st.DrawSeed, &st.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, st)
+	}
+	return out, dbError(rows.Err())
+}
+
+// ListGroupsBySeries returns the groups of every stage of a series with
+// their teams in draw order.
+func (s *Store) ListGroupsBySeries(ctx context.Context, seriesID string) ([]domain.Group, error) {
+	rows, err := s.Pool.Query(ctx, QSelectGroupsBySeries, seriesID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Group{}
+	for rows.Next() {
+		var g domain.Group
+		if err := rows.Scan(&g.ID, &g.StageID, &g.Name, &g.TeamIDs); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, g)
+	}
+	return out, dbError(rows.Err())
+}
@@ -17,10 +17,23 @@ type Store struct {
 func NewStore(pool *pgxpool.Pool) *Store { return &Store{Pool: pool} }
 
 // Leagues
+
+// CreateLeague inserts the league with its creator as owner.
 func (s *Store) CreateLeague(ctx context.Context, l *domain.League) error {
-	_, err := s.Pool.Exec(ctx, QInsertLeague, l.ID, l.Name, l.Slug, l.Region, l.CreatedBy)
-	return err
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, QInsertLeague, l.ID, l.Name, l.Slug, l.Region, l.CreatedBy); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QUpsertLeagueMember, l.ID, l.CreatedBy, "owner", l.CreatedBy); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
 }
+
 func (s *Store) GetLeagueByID(ctx context.Context, id string) (*domain.League, error) {
 	row := s.Pool.QueryRow(ctx, QSelectLeagueByID, id)
 	var l domain.League
@@ -28,122 +41,369 @@ func (s *Store) GetLeagueByID(ctx context.Context, id string) (*domain.League, e
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, dbError(err)
 	}
 	return &l, nil
 }
-func (s *Store) ListLeagues(ctx context.Context) ([]domain.League, error) {
-	rows, err := s.Pool.Query(ctx, QSelectLeagues)
+
+// ListLeagues returns a page of the leagues matching f.
+func (s *Store) ListLeagues(ctx context.Context, f LeagueFilter, p Page) ([]domain.League, error) {
+	var q listQuery
+	if f.Region != "" {
+		q.filter("region=%s", f.Region)
+	}
+	if f.NamePrefix != "" {
+		q.filter("lower(name) LIKE lower(%s)", likePrefix(f.NamePrefix))
+	}
+	query, args, err := q.build(QSelectLeagues, p)
 	if err != nil {
 		return nil, err
 	}
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
 	defer rows.Close()
 	out := []domain.League{}
 	for rows.Next() {
 		var l domain.League
 		if err := rows.Scan(&l.ID, &l.Name, &l.Slug, &l.Region, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt); err != nil {
-			return nil, err
+			return nil, dbError(err)
 		}
 		out = append(out, l)
 	}
-	return out, rows.Err()
+	return out, dbError(rows.Err())
 }
-func (s *Store) UpdateLeague(ctx context.Context, id, name, slug, region string) error {
-	_, err := s.Pool.Exec(ctx, QUpdateLeague, id, name, slug, region)
-	return err
+
+// UpdateLeague renames the league and reports false when it does not exist.
+func (s *Store) UpdateLeague(ctx context.Context, id, name, slug, region string) (bool, error) {
+	tag, err := s.Pool.Exec(ctx, QUpdateLeague, id, name, slug, region)
+	if err != nil {
+		return false, dbError(err)
+	}
+	return tag.RowsAffected() > 0, nil
 }
+
 func (s *Store) DeleteLeague(ctx context.Context, id string) error {
 	_, err := s.Pool.Exec(ctx, QDeleteLeague, id)
-	return err
+	return dbError(err)
 }
 
 // Series
+func scanSeries(row pgx.Row, ser *domain.Series) error {
+	return row.Scan(&ser.ID, &ser.LeagueID, &ser.SeasonID, &ser.Name, &ser.Format, &ser.PointsWin, &ser.PointsDraw, &ser.PointsLoss, &ser.Tiebreakers, &ser.LotsSeed, &ser.Mode, &ser.MaxTeams, &ser.RegistrationOpensAt, &ser.RegistrationClosesAt, &ser.AgeCategory, &ser.GenderCategory, &ser.EligibilityPolicy, &ser.Division, &ser.MatchMinutes, &ser.MaxRosterSize, &ser.RosterLocksAt, &ser.DisciplineRules, &ser.MinRefereeLevel, &ser.CreatedAt, &ser.UpdatedAt)
+}
 func (s *Store) CreateSeries(ctx context.Context, ser *domain.Series) error {
-	_, err := s.Pool.Exec(ctx, QInsertSeries, ser.ID, ser.LeagueID, ser.Name, ser.Format)
-	return err
+	_, err := s.Pool.Exec(ctx, QInsertSeries, ser.ID, ser.LeagueID, ser.SeasonID, ser.Name, ser.Format, ser.PointsWin, ser.PointsDraw, ser.PointsLoss, ser.Tiebreakers, ser.LotsSeed, ser.Mode, ser.MaxTeams, ser.RegistrationOpensAt, ser.RegistrationClosesAt, ser.AgeCategory, ser.GenderCategory, ser.EligibilityPolicy, ser.Division, ser.MatchMinutes, ser.MaxRosterSize, ser.RosterLocksAt, ser.DisciplineRules, ser.MinRefereeLevel)
+	return dbError(err)
 }
-func (s *Store) ListSeriesByLeague(ctx context.Context, leagueID string) ([]domain.Series, error) {
-	rows, err := s.Pool.Query(ctx, QSelectSeriesByLeague, leagueID)
+
+// ListSeries returns a page of the series matching f.
+func (s *Store) ListSeries(ctx context.Context, f SeriesFilter, p Page) ([]domain.Series, error) {
+	var q listQuery
+	q.filter("league_id=%s", f.LeagueID)
+	if f.SeasonID != "" {
+		q.filter("season_id=%s", f.SeasonID)
+	}
+	if f.Format != "" {
+		q.filter("format=%s", f.Format)
+	}
+	query, args, err := q.build(QSelectSeries, p)
 	if err != nil {
 		return nil, err
 	}
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
 	defer rows.Close()
 	out := []domain.Series{}
 	for rows.Next() {
 		var ser domain.Series
-		if err := rows.Scan(&ser.ID, &ser.LeagueID, &ser.Name, &ser.Format, &ser.CreatedAt, &ser.UpdatedAt); err != nil {
-			return nil, err
+		if err := scanSeries(rows, &ser); err != nil {
+			return nil, dbError(err)
 		}
 		out = append(out, ser)
 	}
-	return out, rows.Err()
+	return out, dbError(rows.Err())
 }
 func (s *Store) GetSeriesByID(ctx context.Context, id string) (*domain.Series, error) {
 	row := s.Pool.QueryRow(ctx, QSelectSeriesByID, id)
 	var ser domain.Series
-	if err := row.Scan(&ser.ID, &ser.LeagueID, &ser.Name, &ser.Format, &ser.CreatedAt, &ser.UpdatedAt); err != nil {
+	if err := scanSeries(row, &ser); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, dbError(err)
 	}
 	return &ser, nil
 }
-func (s *Store) UpdateSeries(ctx context.Context, id, name, format string) error {
-	_, err := s.Pool.Exec(ctx, QUpdateSeries, id, name, format)
-	return err
+func (s *Store) UpdateSeries(ctx context.Context, ser *domain.Series) error {
+	_, err := s.Pool.Exec(ctx, QUpdateSeries, ser.ID, ser.Name, ser.Format, ser.PointsWin, ser.PointsDraw, ser.PointsLoss, ser.Tiebreakers, ser.LotsSeed, ser.Mode, ser.MaxTeams, ser.RegistrationOpensAt, ser.RegistrationClosesAt, ser.AgeCategory, ser.GenderCategory, ser.EligibilityPolicy, ser.Division, ser.MatchMinutes, ser.MaxRosterSize, ser.RosterLocksAt, ser.DisciplineRules, ser.MinRefereeLevel)
+	return dbError(err)
 }
 func (s *Store) DeleteSeries(ctx context.Context, id string) error {
 	_, err := s.Pool.Exec(ctx, QDeleteSeries, id)
-	return err
+	return dbError(err)
 }
 
 // Team Registrations
-func (s *Store) CreateTeamRegistration(ctx context.Context, tr *domain.TeamRegistration) error {
-	_, err := s.Pool.Exec(ctx, QInsertTeamRegistration, tr.ID, tr.TeamID, tr.SeriesID, tr.Status)
-	return err
+func scanRegistration(row pgx.Row, tr *domain.TeamRegistration) error {
+	return row.Scan(&tr.ID, &tr.TeamID, &tr.SeriesID, &tr.Status, &tr.AgeCategory, &tr.GenderCategory, &tr.EligibilityIssues, &tr.CreatedAt)
+}
+
+// CreateTeamRegistration inserts the registration together with the event
+// recording its submission. When the series is at capacity the registration
+// is waitlisted instead, updating tr and ev accordingly.
+func (s *Store) CreateTeamRegistration(ctx context.Context, tr *domain.TeamRegistration, ev *domain.RegistrationEvent) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	full, err := seriesFull(ctx, tx, tr.SeriesID)
+	if err != nil {
+		return dbError(err)
+	}
+	if full {
+		tr.Status = "waitlisted"
+		ev.ToStatus = tr.Status
+	}
+	if _, err := tx.Exec(ctx, QInsertTeamRegistration, tr.ID, tr.TeamID, tr.SeriesID, tr.Status, tr.AgeCategory, tr.GenderCategory, tr.EligibilityIssues); err != nil {
+		return dbError(err)
+	}
+	if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+		return dbError(err)
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+// takesSpot reports whether a registration in this status counts towards the
+// capacity of its series, as in QCountSpotsTaken.
+func takesSpot(status string) bool {
+	return status == "pending" || status == "approved"
+}
+
+// seriesFull locks the series row for the rest of the transaction and reports
+// whether its pending and approved registrations have reached max_teams.
+func seriesFull(ctx context.Context, tx pgx.Tx, seriesID string) (bool, error) {
+	var maxTeams *int
+	if err := tx.QueryRow(ctx, QLockSeriesCapacity, seriesID).Scan(&maxTeams); err != nil {
+		return false, dbError(err)
+	}
+	if maxTeams == nil {
+		return false, nil
+	}
+	var taken int
+	if err := tx.QueryRow(ctx, QCountSpotsTaken, seriesID).Scan(&taken); err != nil {
+		return false, dbError(err)
+	}
+	return taken >= *maxTeams, nil
+}
+
+// PromoteWaitlisted moves waitlisted registrations of a series, oldest first,
+// to pending while the series has free spots. newEvent builds the event
+// recorded for each promotion.
+func (s *Store) PromoteWaitlisted(ctx context.Context, seriesID string, newEvent func(tr *domain.TeamRegistration) *domain.RegistrationEvent) ([]domain.TeamRegistration, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	out := []domain.TeamRegistration{}
+	for {
+		full, err := seriesFull(ctx, tx, seriesID)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		if full {
+			break
+		}
+		var tr domain.TeamRegistration
+		if err := scanRegistration(tx.QueryRow(ctx, QSelectNextWaitlisted, seriesID), &tr); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				break
+			}
+			return nil, dbError(err)
+		}
+		ev := newEvent(&tr)
+		if _, err := tx.Exec(ctx, QUpdateRegistrationStatus, tr.ID, ev.ToStatus, tr.Status); err != nil {
+			return nil, dbError(err)
+		}
+		if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+			return nil, dbError(err)
+		}
+		tr.Status = ev.ToStatus
+		out = append(out, tr)
+	}
+	return out, dbError(tx.Commit(ctx))
+}
+func (s *Store) GetRegistrationByID(ctx context.Context, id string) (*domain.TeamRegistration, error) {
+	row := s.Pool.QueryRow(ctx, QSelectRegistrationByID, id)
+	var tr domain.TeamRegistration
+	if err := scanRegistration(row, &tr); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &tr, nil
 }
-func (s *Store) ListRegistrationsByTeam(ctx context.Context, teamID string) ([]domain.TeamRegistration, error) {
-	rows, err := s.Pool.Query(ctx, QSelectRegistrationsByTeam, teamID)
+
+// ListRegistrations returns a page of the registrations matching f.
+func (s *Store) ListRegistrations(ctx context.Context, f RegistrationFilter, p Page) ([]domain.TeamRegistration, error) {
+	var q listQuery
+	if f.TeamID != "" {
+		q.filter("team_id=%s", f.TeamID)
+	}
+	if f.SeriesID != "" {
+		q.filter("series_id=%s", f.SeriesID)
+	}
+	if len(f.Statuses) > 0 {
+		q.filter("status = ANY(%s)", f.Statuses)
+	}
+	if !f.CreatedFrom.IsZero() {
+		q.filter("created_at >= %s", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		q.filter("created_at < %s", f.CreatedTo)
+	}
+	query, args, err := q.build(QSelectRegistrations, p)
 	if err != nil {
 		return nil, err
 	}
+	rows, err := s.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
 	defer rows.Close()
 	out := []domain.TeamRegistration{}
 	for rows.Next() {
 		var tr domain.TeamRegistration
-		if err := rows.Scan(&tr.ID, &tr.TeamID, &tr.SeriesID, &tr.Status, &tr.CreatedAt); err != nil {
-			return nil, err
+		if err := scanRegistration(rows, &tr); err != nil {
+			return nil, dbError(err)
 		}
 		out = append(out, tr)
 	}
-	return out, rows.Err()
+	return out, dbError(rows.Err())
 }
 func (s *Store) ListRegistrationsBySeries(ctx context.Context, seriesID string) ([]domain.TeamRegistration, error) {
 	rows, err := s.Pool.Query(ctx, QSelectRegistrationsBySeries, seriesID)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 	out := []domain.TeamRegistration{}
 	for rows.Next() {
 		var tr domain.TeamRegistration
-		if err := rows.Scan(&tr.ID, &tr.TeamID, &tr.SeriesID, &tr.Status, &tr.CreatedAt); err != nil {
-			return nil, err
+		if err := scanRegistration(rows, &tr); err != nil {
+			return nil, dbError(err)
 		}
 		out = append(out, tr)
 	}
-	return out, rows.Err()
+	return out, dbError(rows.Err())
+}
+func (s *Store) GetRegistrationBySlot(ctx context.Context, seriesID, teamID string) (*domain.TeamRegistration, error) {
+	var tr domain.TeamRegistration
+	if err := scanRegistration(s.Pool.QueryRow(ctx, QSelectRegistrationBySlot, seriesID, teamID), &tr); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &tr, nil
 }
 
-func (s *Store) UpdateRegistrationStatus(ctx context.Context, id, status string) error {
-	_, err := s.Pool.Exec(ctx, QUpdateRegistrationStatus, id, status)
-	return err
+// UpdateRegistrationStatus moves a registration from one status to another
+// and records the event. It reports false, changing nothing, when the
+// registration is no longer in the expected status. A registration moving
+// into a spot of a full series is waitlisted instead, updating ev.
+func (s *Store) UpdateRegistrationStatus(ctx context.Context, id, from, to string, ev *domain.RegistrationEvent) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if !takesSpot(from) && takesSpot(to) {
+		var tr domain.TeamRegistration
+		if err := scanRegistration(tx.QueryRow(ctx, QSelectRegistrationByID, id), &tr); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, nil
+			}
+			return false, dbError(err)
+		}
+		full, err := seriesFull(ctx, tx, tr.SeriesID)
+		if err != nil {
+			return false, dbError(err)
+		}
+		if full {
+			to = "waitlisted"
+			ev.ToStatus = to
+		}
+	}
+	tag, err := tx.Exec(ctx, QUpdateRegistrationStatus, id, to, from)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
 }
 
-func (s *Store) DeleteRegistration(ctx context.Context, id string) error {
-	_, err := s.Pool.Exec(ctx, QDeleteRegistration, id)
-	return err
+// MoveRegistrations applies the status changes recorded by changes to
+// existing registrations and inserts new registrations with their events,
+// all in one transaction. It reports false, changing nothing, when one of
+// the registrations is no longer in the expected status.
+func (s *Store) MoveRegistrations(ctx context.Context, changes []domain.RegistrationEvent, regs []domain.TeamRegistration, events []domain.RegistrationEvent) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	for _, ev := range changes {
+		tag, err := tx.Exec(ctx, QUpdateRegistrationStatus, ev.RegistrationID, ev.ToStatus, ev.FromStatus)
+		if err != nil {
+			return false, dbError(err)
+		}
+		if tag.RowsAffected() == 0 {
+			return false, nil
+		}
+		if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+			return false, dbError(err)
+		}
+	}
+	for _, tr := range regs {
+		if _, err := tx.Exec(ctx, QInsertTeamRegistration, tr.ID, tr.TeamID, tr.SeriesID, tr.Status, tr.AgeCategory, tr.GenderCategory, tr.EligibilityIssues); err != nil {
+			return false, dbError(err)
+		}
+	}
+	for _, ev := range events {
+		if _, err := tx.Exec(ctx, QInsertRegistrationEvent, ev.ID, ev.RegistrationID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.Reason); err != nil {
+			return false, dbError(err)
+		}
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+func (s *Store) ListRegistrationEvents(ctx context.Context, registrationID string) ([]domain.RegistrationEvent, error) {
+	rows, err := s.Pool.Query(ctx, QSelectRegistrationEvents, registrationID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.RegistrationEvent{}
+	for rows.Next() {
+		var ev domain.RegistrationEvent
+		if err := rows.Scan(&ev.ID, &ev.RegistrationID, &ev.FromStatus, &ev.ToStatus, &ev.ActorID, &ev.Reason, &ev.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, ev)
+	}
+	return out, dbError(rows.Err())
 }
 
 // Read-only helpers
@@ -154,7 +414,7 @@ func (s *Store) GetTeamByID(ctx context.Context, id string) (*domain.Team, error
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, dbError(err)
 	}
 	return &t, nil
 }
@@ -166,7 +426,24 @@ func (s *Store) IsOwner(ctx context.Context, userID, clubID string) (bool, error
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
-		return false, err
+		return false, dbError(err)
 	}
 	return true, nil
 }
+
+func (s *Store) ListMemberClubs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := s.Pool.Query(ctx, QSelectMemberClubs, userID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, id)
+	}
+	return out, dbError(rows.Err())
+}
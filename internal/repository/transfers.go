@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Transfers
+
+func scanTransferWindow(row pgx.Row, w *domain.TransferWindow) error {
+	return row.Scan(&w.ID, &w.LeagueID, &w.SeasonID, &w.OpensAt, &w.ClosesAt, &w.MaxTransfersPerTeam, &w.CreatedBy, &w.CreatedAt)
+}
+
+func scanTransfer(row pgx.Row, t *domain.Transfer) error {
+	return row.Scan(&t.ID, &t.SeasonID, &t.WindowID, &t.PlayerID, &t.FromRegistrationID, &t.ToRegistrationID, &t.FromTeamID, &t.ToTeamID, &t.ShirtNumber, &t.Status, &t.FromApprovedBy, &t.ToApprovedBy, &t.RequestedBy, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func insertTransferEvents(ctx context.Context, tx pgx.Tx, events []domain.TransferEvent) error {
+	for _, ev := range events {
+		if _, err := tx.Exec(ctx, QInsertTransferEvent, ev.ID, ev.TransferID, ev.Action, ev.Club, ev.ActorID, ev.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateTransferWindow(ctx context.Context, w *domain.TransferWindow) error {
+	err := s.Pool.QueryRow(ctx, QInsertTransferWindow, w.ID, w.LeagueID, w.SeasonID, w.OpensAt, w.ClosesAt, w.MaxTransfersPerTeam, w.CreatedBy).Scan(&w.CreatedAt)
+	return dbError(err)
+}
+
+func (s *Store) GetTransferWindow(ctx context.Context, id string) (*domain.TransferWindow, error) {
+	var w domain.TransferWindow
+	if err := scanTransferWindow(s.Pool.QueryRow(ctx, QSelectTransferWindow, id), &w); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &w, nil
+}
+
+// ListTransferWindows returns the windows of the season, earliest first.
+func (s *Store) ListTransferWindows(ctx context.Context, seasonID string) ([]domain.TransferWindow, error) {
+	rows, err := s.Pool.Query(ctx, QSelectTransferWindows, seasonID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.TransferWindow{}
+	for rows.Next() {
+		var w domain.TransferWindow
+		if err := scanTransferWindow(rows, &w); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, w)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) DeleteTransferWindow(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteTransferWindow, id)
+	return dbError(err)
+}
+
+// CreateTransfer inserts the pending transfer with the events recording its
+// request. The window is locked while the transfers of both teams are
+// counted against m.MaxPerTeam. When m.Player is set the transfer completes
+// in the same transaction, as CompleteTransfer does. It reports false,
+// storing nothing, when a limit would be exceeded or the move cannot be made.
+func (s *Store) CreateTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m RosterMove) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	ok, err := underTransferCap(ctx, tx, t, m.MaxPerTeam)
+	if err != nil || !ok {
+		return false, err
+	}
+	err = tx.QueryRow(ctx, QInsertTransfer, t.ID, t.SeasonID, t.WindowID, t.PlayerID, t.FromRegistrationID, t.ToRegistrationID, t.FromTeamID, t.ToTeamID, t.ShirtNumber, t.Status, t.FromApprovedBy, t.ToApprovedBy, t.RequestedBy).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if err := insertTransferEvents(ctx, tx, events); err != nil {
+		return false, dbError(err)
+	}
+	if m.Player != nil {
+		if ok, err := moveTransferredPlayer(ctx, tx, t, m); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+// underTransferCap locks the transfer's window and reports whether both
+// teams have fewer than max other transfers pending or completed in it;
+// 0 means no limit.
+func underTransferCap(ctx context.Context, tx pgx.Tx, t *domain.Transfer, max int) (bool, error) {
+	if _, err := tx.Exec(ctx, QLockTransferWindow, t.WindowID); err != nil {
+		return false, dbError(err)
+	}
+	if max == 0 {
+		return true, nil
+	}
+	for _, team := range []string{t.FromTeamID, t.ToTeamID} {
+		var n int
+		if err := tx.QueryRow(ctx, QCountWindowTransfers, t.WindowID, team, t.ID).Scan(&n); err != nil {
+			return false, dbError(err)
+		}
+		if n >= max {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *Store) GetTransfer(ctx context.Context, id string) (*domain.Transfer, error) {
+	var t domain.Transfer
+	if err := scanTransfer(s.Pool.QueryRow(ctx, QSelectTransferByID, id), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &t, nil
+}
+
+// ListTransfers returns the transfers of the season, oldest first; with a
+// teamID only those in or out of that team.
+func (s *Store) ListTransfers(ctx context.Context, seasonID, teamID string) ([]domain.Transfer, error) {
+	rows, err := s.Pool.Query(ctx, QSelectTransfers, seasonID, teamID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Transfer{}
+	for rows.Next() {
+		var t domain.Transfer
+		if err := scanTransfer(rows, &t); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, t)
+	}
+	return out, dbError(rows.Err())
+}
+
+// UpdateTransferApprovals stores the approvals of a pending transfer and
+// records the event. It reports false when the transfer is no longer pending.
+func (s *Store) UpdateTransferApprovals(ctx context.Context, t *domain.Transfer, ev *domain.TransferEvent) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, QUpdateTransferApprovals, t.ID, t.FromApprovedBy, t.ToApprovedBy)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := insertTransferEvents(ctx, tx, []domain.TransferEvent{*ev}); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+// UpdateTransferStatus moves the transfer from one status to another and
+// records the event. It reports false when the transfer is no longer in from.
+func (s *Store) UpdateTransferStatus(ctx context.Context, id, from, to string, ev *domain.TransferEvent) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, QUpdateTransferStatus, id, to, from)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := insertTransferEvents(ctx, tx, []domain.TransferEvent{*ev}); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+// CountWindowTransfers counts the transfers in or out of the team pending
+// or completed in the window, leaving out exceptID.
+func (s *Store) CountWindowTransfers(ctx context.Context, windowID, teamID, exceptID string) (int, error) {
+	var n int
+	err := s.Pool.QueryRow(ctx, QCountWindowTransfers, windowID, teamID, exceptID).Scan(&n)
+	return n, dbError(err)
+}
+
+// CompleteTransfer marks a pending transfer completed and moves the player
+// between the rosters, recording the events and roster changes. The window
+// and both rosters are locked while the limits of the move are checked. It
+// reports false, changing nothing, when the transfer is no longer pending,
+// the player has left the old roster or a limit would be exceeded.
+func (s *Store) CompleteTransfer(ctx context.Context, t *domain.Transfer, events []domain.TransferEvent, m RosterMove) (bool, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return false, dbError(err)
+	}
+	defer tx.Rollback(ctx)
+	if ok, err := underTransferCap(ctx, tx, t, m.MaxPerTeam); err != nil || !ok {
+		return false, err
+	}
+	if ok, err := moveTransferredPlayer(ctx, tx, t, m); err != nil || !ok {
+		return false, err
+	}
+	if err := insertTransferEvents(ctx, tx, events); err != nil {
+		return false, dbError(err)
+	}
+	return true, dbError(tx.Commit(ctx))
+}
+
+// moveTransferredPlayer marks the pending transfer completed and moves the
+// player to the new roster with the roster changes, reporting false when
+// the transfer or the player's listing is gone or the new roster is full.
+func moveTransferredPlayer(ctx context.Context, tx pgx.Tx, t *domain.Transfer, m RosterMove) (bool, error) {
+	// Lock in a fixed order so crossing transfers cannot deadlock.
+	regs := []string{t.FromRegistrationID, t.ToRegistrationID}
+	slices.Sort(regs)
+	for _, id := range regs {
+		if _, err := tx.Exec(ctx, QLockRegistration, id); err != nil {
+			return false, dbError(err)
+		}
+	}
+	if m.MaxRoster > 0 {
+		var others int
+		if err := tx.QueryRow(ctx, QCountOtherRosterPlayers, t.ToRegistrationID, t.PlayerID).Scan(&others); err != nil {
+			return false, dbError(err)
+		}
+		if others >= m.MaxRoster {
+			return false, nil
+		}
+	}
+	tag, err := tx.Exec(ctx, QCompleteTransfer, t.ID, t.FromApprovedBy, t.ToApprovedBy)
+	if err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if tag, err = tx.Exec(ctx, QDeleteRosterPlayer, t.FromRegistrationID, t.PlayerID); err != nil {
+		return false, dbError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	p := m.Player
+	err = tx.QueryRow(ctx, QUpsertRosterPlayer, p.RegistrationID, p.SeriesID, p.TeamID, p.PlayerID, p.Name, p.BirthDate, p.ShirtNumber, p.Late, p.AddedBy).Scan(&p.Late, &p.AddedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return false, dbError(err)
+	}
+	for _, ch := range []*domain.RosterChange{m.Out, m.In} {
+		if err := insertRosterChange(ctx, tx, ch); err != nil {
+			return false, dbError(err)
+		}
+	}
+	return true, nil
+}
+
+func (s *Store) ListTransferEvents(ctx context.Context, transferID string) ([]domain.TransferEvent, error) {
+	rows, err := s.Pool.Query(ctx, QSelectTransferEvents, transferID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.TransferEvent{}
+	for rows.Next() {
+		var ev domain.TransferEvent
+		if err := rows.Scan(&ev.ID, &ev.TransferID, &ev.Action, &ev.Club, &ev.ActorID, &ev.Reason, &ev.CreatedAt); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, ev)
+	}
+	return out, dbError(rows.Err())
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Venues
+
+func scanVenue(row pgx.Row, v *domain.Venue) error {
+	return row.Scan(&v.ID, &v.LeagueID, &v.Name, &v.Address, &v.Latitude, &v.Longitude, &v.Timezone, &v.Pitches, &v.Availability, &v.Blackouts, &v.CreatedAt, &v.UpdatedAt)
+}
+
+func (s *Store) CreateVenue(ctx context.Context, v *domain.Venue) error {
+	_, err := s.Pool.Exec(ctx, QInsertVenue, v.ID, v.LeagueID, v.Name, v.Address, v.Latitude, v.Longitude, v.Timezone, v.Pitches, v.Availability, v.Blackouts)
+	return dbError(err)
+}
+
+func (s *Store) GetVenueByID(ctx context.Context, id string) (*domain.Venue, error) {
+	var v domain.Venue
+	if err := scanVenue(s.Pool.QueryRow(ctx, QSelectVenueByID, id), &v); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &v, nil
+}
+
+// ListVenues returns the venues of a league by name.
+func (s *Store) ListVenues(ctx context.Context, leagueID string) ([]domain.Venue, error) {
+	rows, err := s.Pool.Query(ctx, QSelectVenuesByLeague, leagueID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	out := []domain.Venue{}
+	for rows.Next() {
+		var v domain.Venue
+		if err := scanVenue(rows, &v); err != nil {
+			return nil, dbError(err)
+		}
+		out = append(out, v)
+	}
+	return out, dbError(rows.Err())
+}
+
+func (s *Store) UpdateVenue(ctx context.Context, v *domain.Venue) error {
+	_, err := s.Pool.Exec(ctx, QUpdateVenue, v.ID, v.Name, v.Address, v.Latitude, v.Longitude, v.Timezone, v.Pitches, v.Availability, v.Blackouts)
+	return dbError(err)
+}
+
+func (s *Store) DeleteVenue(ctx context.Context, id string) error {
+	_, err := s.Pool.Exec(ctx, QDeleteVenue, id)
+	return dbError(err)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+)
+
+// Team blackouts
+
+// ListTeamBlackouts returns the days a team cannot play, by date. They are
+// shown to the owner of the team's club and to the staff scheduling a
+// league the team is entered in.
+func (s *LeaguesService) ListTeamBlackouts(ctx context.Context, userID, teamID string) ([]domain.TeamBlackout, error) {
+	t, err := s.store.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, NotFound("team not found")
+	}
+	ok, err := s.store.IsOwner(ctx, userID, t.ClubID)
+	if err == nil && !ok {
+		ok, err = s.schedulesTeam(ctx, userID, teamID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, Forbidden("only the club owner and the staff scheduling its leagues can see the team's blackouts")
+	}
+	return s.store.ListTeamBlackouts(ctx, []string{teamID}, time.Time{}, endOfTime)
+}
+
+// schedulesTeam reports whether the user manages the schedule of a league
+// the team is entered in, its registration pending, waitlisted or approved.
+func (s *LeaguesService) schedulesTeam(ctx context.Context, userID, teamID string) (bool, error) {
+	regs, err := s.store.ListRegistrations(ctx, repository.RegistrationFilter{
+		TeamID:   teamID,
+		Statuses: []string{RegistrationPending, RegistrationWaitlisted, RegistrationApproved},
+	}, repository.Page{Sort: repository.SortCreatedAt})
+	if err != nil {
+		return false, err
+	}
+	checked := map[string]bool{}
+	for _, r := range regs {
+		ser, err := s.store.GetSeriesByID(ctx, r.SeriesID)
+		if err != nil {
+			return false, err
+		}
+		if ser == nil || checked[ser.LeagueID] {
+			continue
+		}
+		checked[ser.LeagueID] = true
+		ok, err := s.can(ctx, userID, ser.LeagueID, PermManageSchedule)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// SetTeamBlackout marks a day, as "YYYY-MM-DD", on which the team cannot
+// play. The scheduler keeps the team's matches off it in every league.
+func (s *LeaguesService) SetTeamBlackout(ctx context.Context, userID, teamID, date, reason string) (*domain.TeamBlackout, error) {
+	if err := s.requireTeamOwner(ctx, userID, teamID, "blackouts"); err != nil {
+		return nil, err
+	}
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, Invalid("date", "date must be like 2025-12-25")
+	}
+	b := &domain.TeamBlackout{TeamID: teamID, Date: day, Reason: strings.TrimSpace(reason), CreatedBy: userID}
+	if err := s.store.SaveTeamBlackout(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *LeaguesService) DeleteTeamBlackout(ctx context.Context, userID, teamID, date string) error {
+	if err := s.requireTeamOwner(ctx, userID, teamID, "blackouts"); err != nil {
+		return err
+	}
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return Invalid("date", "date must be like 2025-12-25")
+	}
+	return s.store.DeleteTeamBlackout(ctx, teamID, day)
+}
+
+// requireTeamOwner checks the user owns the team's club before managing
+// what, a part of the team.
+func (s *LeaguesService) requireTeamOwner(ctx context.Context, userID, teamID, what string) error {
+	t, err := s.store.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return NotFound("team not found")
+	}
+	isOwner, err := s.store.IsOwner(ctx, userID, t.ClubID)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return Forbidden("only the club owner can manage the team's %s", what)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestListTeamBlackoutsAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  string
+		status string // Of t1's registration; empty leaves it unregistered
+		team   string
+		err    error
+	}{
+		{name: "club owner", actor: clubOwner},
+		{name: "club member", actor: fan, status: RegistrationApproved, err: ErrForbidden},
+		{name: "stranger", actor: stranger, status: RegistrationApproved, err: ErrForbidden},
+		{name: "organizer, not entered", actor: organizer, err: ErrForbidden},
+		{name: "organizer, pending", actor: organizer, status: RegistrationPending},
+		{name: "organizer, approved", actor: organizer, status: RegistrationApproved},
+		{name: "organizer, withdrawn", actor: organizer, status: RegistrationWithdrawn, err: ErrForbidden},
+		{name: "scheduler", actor: RoleScheduler, status: RegistrationApproved},
+		{name: "viewer", actor: RoleViewer, status: RegistrationApproved, err: ErrForbidden},
+		{name: "missing team", actor: clubOwner, team: "nope", err: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newFanLeague(t)
+			for _, role := range []string{RoleScheduler, RoleViewer} {
+				if err := tl.store.SaveLeagueMember(tl.ctx, &domain.LeagueMember{LeagueID: tl.league.ID, UserID: role, Role: role}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := tl.svc.SetTeamBlackout(tl.ctx, clubOwner, "t1", "2027-03-06", "cup final"); err != nil {
+				t.Fatalf("set blackout: %v", err)
+			}
+			if tt.status != "" {
+				reg := tl.register("t1")[0]
+				if tt.status != reg.Status {
+					// Organizers approve; clubs withdraw.
+					actor := organizer
+					if tt.status == RegistrationWithdrawn {
+						actor = clubOwner
+					}
+					if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, actor, reg.ID, tt.status, ""); err != nil {
+						t.Fatalf("set status %s: %v", tt.status, err)
+					}
+				}
+			}
+			team := tt.team
+			if team == "" {
+				team = "t1"
+			}
+
+			list, err := tl.svc.ListTeamBlackouts(tl.ctx, tt.actor, team)
+			checkErr(t, err, tt.err)
+			if err == nil && (len(list) != 1 || list[0].Reason != "cup final") {
+				t.Fatalf("got %+v", list)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Calendar feeds
+
+// Kinds of calendar feed, each with the path it is served under.
+const (
+	FeedTeam   = "team"
+	FeedSeries = "series"
+	FeedVenue  = "venue"
+)
+
+var feedPaths = map[string]string{FeedTeam: "teams", FeedSeries: "series", FeedVenue: "venues"}
+
+// IssueTeamCalendar creates the team's feed, or replaces its token so the
+// old link stops working. Only the owner of the team's club can.
+func (s *LeaguesService) IssueTeamCalendar(ctx context.Context, userID, teamID string) (*domain.CalendarFeed, error) {
+	if err := s.requireTeamOwner(ctx, userID, teamID, "calendar"); err != nil {
+		return nil, err
+	}
+	return s.issueFeed(ctx, userID, FeedTeam, teamID)
+}
+
+func (s *LeaguesService) RevokeTeamCalendar(ctx context.Context, userID, teamID string) error {
+	if err := s.requireTeamOwner(ctx, userID, teamID, "calendar"); err != nil {
+		return err
+	}
+	return s.store.DeleteCalendarFeed(ctx, FeedTeam, teamID)
+}
+
+// IssueSeriesCalendar creates the series' feed, or replaces its token.
+func (s *LeaguesService) IssueSeriesCalendar(ctx context.Context, userID, leagueID, seriesID string) (*domain.CalendarFeed, error) {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return nil, err
+	}
+	return s.issueFeed(ctx, userID, FeedSeries, seriesID)
+}
+
+func (s *LeaguesService) RevokeSeriesCalendar(ctx context.Context, leagueID, seriesID string) error {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return err
+	}
+	return s.store.DeleteCalendarFeed(ctx, FeedSeries, seriesID)
+}
+
+// IssueVenueCalendar creates the venue's feed, or replaces its token.
+func (s *LeaguesService) IssueVenueCalendar(ctx context.Context, userID, leagueID, venueID string) (*domain.CalendarFeed, error) {
+	if _, err := s.GetVenue(ctx, leagueID, venueID); err != nil {
+		return nil, err
+	}
+	return s.issueFeed(ctx, userID, FeedVenue, venueID)
+}
+
+func (s *LeaguesService) RevokeVenueCalendar(ctx context.Context, leagueID, venueID string) error {
+	if _, err := s.GetVenue(ctx, leagueID, venueID); err != nil {
+		return err
+	}
+	return s.store.DeleteCalendarFeed(ctx, FeedVenue, venueID)
+}
+
+func (s *LeaguesService) leagueSeries(ctx context.Context, leagueID, seriesID string) (*domain.Series, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	return ser, nil
+}
+
+// issueFeed stores the hash of a new token and returns the token, which
+// cannot be recovered later, with the feed's URL.
+func (s *LeaguesService) issueFeed(ctx context.Context, userID, kind, targetID string) (*domain.CalendarFeed, error) {
+	token := util.RandToken()
+	f := &domain.CalendarFeed{Kind: kind, TargetID: targetID, TokenHash: util.HashToken(token), CreatedBy: userID}
+	if err := s.store.SaveCalendarFeed(ctx, f); err != nil {
+		return nil, err
+	}
+	f.Token = token
+	f.URL = fmt.Sprintf("/calendars/%s/%s.ics?token=%s", feedPaths[kind], url.PathEscape(targetID), token)
+	return f, nil
+}
+
+// Calendar returns the content of a feed to whoever holds its token: the
+// booked matches, earliest first, and the cancelled bookings. A missing
+// feed and a wrong token are both not found, so feeds cannot be probed.
+func (s *LeaguesService) Calendar(ctx context.Context, kind, targetID, token string) (*domain.Calendar, error) {
+	f, err := s.store.GetCalendarFeed(ctx, kind, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil || token == "" || !util.ConstantTimeEquals(f.TokenHash, util.HashToken(token)) {
+		return nil, NotFound("calendar not found")
+	}
+	cb := &calendarBuilder{s: s, teams: map[string]*domain.Team{}, series: map[string]*domain.Series{}, leagues: map[string]*domain.League{}, venues: map[string]*domain.Venue{}}
+	cal := &domain.Calendar{Events: []domain.CalendarEvent{}}
+	var matches []domain.Match
+	switch kind {
+	case FeedTeam:
+		t, err := cb.team(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		cal.Name = t.Name
+		if matches, err = s.store.ListMatchesByTeam(ctx, targetID); err != nil {
+			return nil, err
+		}
+	case FeedSeries:
+		ser, err := cb.seriesByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if ser == nil {
+			return nil, NotFound("calendar not found")
+		}
+		cal.Name = ser.Name
+		if l, err := cb.league(ctx, ser.LeagueID); err != nil {
+			return nil, err
+		} else if l != nil {
+			cal.Name = l.Name + " - " + ser.Name
+		}
+		all, err := s.store.ListMatchesBySeries(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		matches = slices.DeleteFunc(all, func(m domain.Match) bool { return m.KickoffAt == nil })
+	case FeedVenue:
+		v, err := cb.venue(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, NotFound("calendar not found")
+		}
+		cal.Name = v.Name
+		if matches, err = s.store.ListMatchesByVenue(ctx, targetID, time.Time{}, endOfTime); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, NotFound("calendar not found")
+	}
+	cancelled, err := s.store.ListCancelledMatches(ctx, kind, targetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range append(matches, cancelled...) {
+		ev, err := cb.event(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		cal.Events = append(cal.Events, ev)
+	}
+	slices.SortStableFunc(cal.Events, func(a, b domain.CalendarEvent) int { return a.Start.Compare(b.Start) })
+	return cal, nil
+}
+
+// calendarBuilder turns matches into events, loading each team, series,
+// league and venue once.
+type calendarBuilder struct {
+	s       *LeaguesService
+	teams   map[string]*domain.Team
+	series  map[string]*domain.Series
+	leagues map[string]*domain.League
+	venues  map[string]*domain.Venue
+}
+
+// team loads a team; one no longer in the directory goes by its ID.
+func (cb *calendarBuilder) team(ctx context.Context, id string) (*domain.Team, error) {
+	if t, ok := cb.teams[id]; ok {
+		return t, nil
+	}
+	t, err := cb.s.store.GetTeamByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		t = &domain.Team{ID: id, Name: id}
+	}
+	cb.teams[id] = t
+	return t, nil
+}
+
+func (cb *calendarBuilder) seriesByID(ctx context.Context, id string) (*domain.Series, error) {
+	if ser, ok := cb.series[id]; ok {
+		return ser, nil
+	}
+	ser, err := cb.s.store.GetSeriesByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cb.series[id] = ser
+	return ser, nil
+}
+
+func (cb *calendarBuilder) league(ctx context.Context, id string) (*domain.League, error) {
+	if l, ok := cb.leagues[id]; ok {
+		return l, nil
+	}
+	l, err := cb.s.store.GetLeagueByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cb.leagues[id] = l
+	return l, nil
+}
+
+func (cb *calendarBuilder) venue(ctx context.Context, id string) (*domain.Venue, error) {
+	if v, ok := cb.venues[id]; ok {
+		return v, nil
+	}
+	v, err := cb.s.store.GetVenueByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cb.venues[id] = v
+	return v, nil
+}
+
+// event describes a match; the series, league and venue may have been
+// deleted since a cancelled booking was made.
+func (cb *calendarBuilder) event(ctx context.Context, m domain.Match) (domain.CalendarEvent, error) {
+	ev := domain.CalendarEvent{
+		UID:       m.ID + "@team-manager-leagues",
+		Sequence:  m.Sequence,
+		Start:     *m.KickoffAt,
+		End:       *m.EndsAt,
+		Cancelled: m.Status == "cancelled" || m.Status == "abandoned",
+		UpdatedAt: m.UpdatedAt,
+	}
+	home, err := cb.team(ctx, m.HomeTeamID)
+	if err != nil {
+		return ev, err
+	}
+	away, err := cb.team(ctx, m.AwayTeamID)
+	if err != nil {
+		return ev, err
+	}
+	ev.Summary = home.Name + " v " + away.Name
+	ser, err := cb.seriesByID(ctx, m.SeriesID)
+	if err != nil {
+		return ev, err
+	}
+	if ser != nil {
+		desc := fmt.Sprintf("%s, round %d", ser.Name, m.Round)
+		l, err := cb.league(ctx, ser.LeagueID)
+		if err != nil {
+			return ev, err
+		}
+		if l != nil {
+			desc = l.Name + " - " + desc
+		}
+		ev.Description = desc
+	}
+	if m.VenueID != "" {
+		v, err := cb.venue(ctx, m.VenueID)
+		if err != nil {
+			return ev, err
+		}
+		if v != nil {
+			parts := []string{v.Name}
+			if v.Address != "" {
+				parts = append(parts, v.Address)
+			}
+			if len(v.Pitches) > 1 {
+				parts = append(parts, fmt.Sprintf("pitch %d", m.Pitch))
+			}
+			ev.Location = strings.Join(parts, ", ")
+			ev.Latitude, ev.Longitude = v.Latitude, v.Longitude
+		}
+	}
+	return ev, nil
+}
@@ -0,0 +1,576 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Discipline
+
+const (
+	CardYellow       = "yellow"
+	CardSecondYellow = "second_yellow" // Sends the player off; the first yellow stays on record
+	CardRed          = "red"
+)
+
+// defaultDisciplineRules are the suspension rules of a new series.
+var defaultDisciplineRules = []domain.SuspensionRule{
+	{Card: CardYellow, Count: 5, Matches: 1},
+	{Card: CardSecondYellow, Count: 1, Matches: 1},
+	{Card: CardRed, Count: 1, Matches: 2},
+}
+
+// fairPlayPoints are the penalty points of each card in the fair-play table.
+var fairPlayPoints = map[string]int{
+	CardYellow:       1,
+	CardSecondYellow: 3,
+	CardRed:          3,
+}
+
+func validateDisciplineRules(rules []domain.SuspensionRule) error {
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if _, ok := fairPlayPoints[r.Card]; !ok {
+			return Invalid("disciplineRules", "unknown card %q", r.Card)
+		}
+		if seen[r.Card] {
+			return Invalid("disciplineRules", "more than one rule for %s cards", r.Card)
+		}
+		seen[r.Card] = true
+		if r.Count < 1 || r.Matches < 1 {
+			return Invalid("disciplineRules", "count and matches must be at least 1")
+		}
+	}
+	return nil
+}
+
+// compareMatches orders matches by kickoff when both are booked, otherwise
+// by round and leg.
+func compareMatches(a, b *domain.Match) int {
+	if a.KickoffAt != nil && b.KickoffAt != nil {
+		if c := a.KickoffAt.Compare(*b.KickoffAt); c != 0 {
+			return c
+		}
+	}
+	return cmp.Or(cmp.Compare(a.Round, b.Round), cmp.Compare(a.Leg, b.Leg), strings.Compare(a.ID, b.ID))
+}
+
+// completed reports whether the match was played or awarded; these are the
+// matches a suspension is served in.
+func completed(m *domain.Match) bool {
+	return m.Status == "played" || m.Status == "walkover"
+}
+
+// served counts the matches the suspension's team completed after the
+// match of the offence.
+func served(su *domain.Suspension, matches []domain.Match) int {
+	i := slices.IndexFunc(matches, func(m domain.Match) bool { return m.ID == su.MatchID })
+	if i < 0 {
+		return 0
+	}
+	n := 0
+	for j := range matches {
+		m := &matches[j]
+		if (m.HomeTeamID == su.TeamID || m.AwayTeamID == su.TeamID) && completed(m) && compareMatches(&matches[i], m) < 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// cardLabel is how a card type reads in messages, e.g. "second yellow".
+func cardLabel(card string) string {
+	return strings.ReplaceAll(card, "_", " ")
+}
+
+// dueSuspensions works out the suspensions the series' rules hand out for a
+// player's cards, and compares them with those stored: add holds the ones
+// missing, drop the cards whose suspensions are no longer due.
+func dueSuspensions(ser *domain.Series, cards []domain.Card, matches []domain.Match, stored []domain.Suspension) (add []domain.Suspension, drop []string) {
+	byID := make(map[string]*domain.Match, len(matches))
+	for i := range matches {
+		byID[matches[i].ID] = &matches[i]
+	}
+	due := map[string]bool{}
+	for _, rule := range ser.DisciplineRules {
+		var shown []domain.Card
+		for _, c := range cards {
+			if c.Type == rule.Card && byID[c.MatchID] != nil {
+				shown = append(shown, c)
+			}
+		}
+		slices.SortStableFunc(shown, func(a, b domain.Card) int {
+			return cmp.Or(compareMatches(byID[a.MatchID], byID[b.MatchID]), a.CreatedAt.Compare(b.CreatedAt))
+		})
+		for i := rule.Count - 1; i < len(shown); i += rule.Count {
+			c := shown[i]
+			due[c.ID] = true
+			if slices.ContainsFunc(stored, func(su domain.Suspension) bool { return su.CardID == c.ID }) {
+				continue
+			}
+			reason := cardLabel(c.Type) + " card"
+			if rule.Count > 1 {
+				reason = fmt.Sprintf("%d %s cards", i+1, cardLabel(c.Type))
+			}
+			add = append(add, domain.Suspension{
+				ID:       util.RandID(),
+				SeriesID: ser.ID,
+				PlayerID: c.PlayerID,
+				TeamID:   c.TeamID,
+				MatchID:  c.MatchID,
+				CardID:   c.ID,
+				Matches:  rule.Matches,
+				Reason:   reason,
+			})
+		}
+	}
+	for _, su := range stored {
+		if su.CardID != "" && !due[su.CardID] {
+			drop = append(drop, su.CardID)
+		}
+	}
+	return add, drop
+}
+
+// playerSuspensions loads what dueSuspensions needs for a player.
+func (s *LeaguesService) playerSuspensions(ctx context.Context, seriesID, playerID string) ([]domain.Card, []domain.Match, []domain.Suspension, error) {
+	cards, err := s.store.ListSeriesCards(ctx, seriesID, playerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stored, err := s.store.ListSuspensions(ctx, seriesID, playerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cards, matches, stored, nil
+}
+
+// resyncSuspensions applies changed rules to the cards already shown in the
+// series.
+func (s *LeaguesService) resyncSuspensions(ctx context.Context, ser *domain.Series) error {
+	cards, err := s.store.ListSeriesCards(ctx, ser.ID, "")
+	if err != nil {
+		return err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, ser.ID)
+	if err != nil {
+		return err
+	}
+	stored, err := s.store.ListSuspensions(ctx, ser.ID, "")
+	if err != nil {
+		return err
+	}
+	add, drop := dueSuspensions(ser, cards, matches, stored)
+	if len(add) == 0 && len(drop) == 0 {
+		return nil
+	}
+	return s.store.SyncSuspensions(ctx, add, drop)
+}
+
+// rosteredPlayer checks that the player is on the team's roster in the
+// series.
+func (s *LeaguesService) rosteredPlayer(ctx context.Context, seriesID, teamID, playerID string) error {
+	reg, err := s.store.GetRegistrationBySlot(ctx, seriesID, teamID)
+	if err != nil {
+		return err
+	}
+	if reg != nil {
+		p, err := s.store.GetRosterPlayer(ctx, reg.ID, playerID)
+		if err != nil || p != nil {
+			return err
+		}
+	}
+	return Invalid("playerId", "player %s is not on the team's roster", playerID)
+}
+
+// CardInput records a card shown in a match.
+type CardInput struct {
+	TeamID   string `json:"teamId" binding:"required"`
+	PlayerID string `json:"playerId" binding:"required"`
+	Type     string `json:"type" binding:"required"` // "yellow", "second_yellow" or "red"
+	Minute   int    `json:"minute"`
+	Reason   string `json:"reason"`
+}
+
+// RecordCard records a card shown in a played or abandoned match, and the
+// suspension it triggers under the series' rules, if any.
+func (s *LeaguesService) RecordCard(ctx context.Context, userID, leagueID, seriesID, matchID string, in CardInput) (*domain.Card, *domain.Suspension, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ser, err := s.leagueSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.Status != "played" && m.Status != "abandoned" {
+		return nil, nil, Conflict("the match is %s; cards are recorded once it has been played", m.Status)
+	}
+	return s.saveCard(ctx, userID, ser, m, in)
+}
+
+// saveCard checks a card shown in the match and stores it with the
+// suspension it triggers.
+func (s *LeaguesService) saveCard(ctx context.Context, userID string, ser *domain.Series, m *domain.Match, in CardInput) (*domain.Card, *domain.Suspension, error) {
+	if in.TeamID != m.HomeTeamID && in.TeamID != m.AwayTeamID {
+		return nil, nil, Invalid("teamId", "the team does not play in this match")
+	}
+	if _, ok := fairPlayPoints[in.Type]; !ok {
+		return nil, nil, Invalid("type", "type must be yellow, second_yellow or red")
+	}
+	if in.Minute < 0 || in.Minute > 150 {
+		return nil, nil, Invalid("minute", "minute must be between 0 and 150")
+	}
+	if err := s.rosteredPlayer(ctx, ser.ID, in.TeamID, in.PlayerID); err != nil {
+		return nil, nil, err
+	}
+	shown, err := s.store.ListMatchCards(ctx, m.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCardSequence(shown, in.PlayerID, in.Type, "type"); err != nil {
+		return nil, nil, err
+	}
+
+	c := &domain.Card{
+		ID:         util.RandID(),
+		MatchID:    m.ID,
+		SeriesID:   ser.ID,
+		TeamID:     in.TeamID,
+		PlayerID:   in.PlayerID,
+		Type:       in.Type,
+		Minute:     in.Minute,
+		Reason:     strings.TrimSpace(in.Reason),
+		RecordedBy: userID,
+	}
+	cards, matches, stored, err := s.playerSuspensions(ctx, ser.ID, in.PlayerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	add, drop := dueSuspensions(ser, append(cards, *c), matches, stored)
+	if err := s.store.SaveCard(ctx, c, add, drop); err != nil {
+		return nil, nil, err
+	}
+	for i := range add {
+		if add[i].CardID == c.ID {
+			add[i].Served = served(&add[i], matches)
+			return c, &add[i], nil
+		}
+	}
+	return c, nil, nil
+}
+
+// checkCardSequence checks a card against those the player was already shown
+// in the match: a second yellow follows one yellow, and a sent-off player
+// gets no more cards. field names the input holding the card type.
+func checkCardSequence(shown []domain.Card, playerID, card, field string) error {
+	var yellow bool
+	for _, c := range shown {
+		if c.PlayerID != playerID {
+			continue
+		}
+		switch c.Type {
+		case CardYellow:
+			yellow = true
+		default:
+			return Conflict("the player was already sent off in this match")
+		}
+	}
+	switch {
+	case card == CardYellow && yellow:
+		return Invalid(field, "the player already has a yellow card in this match; record a second_yellow")
+	case card == CardSecondYellow && !yellow:
+		return Invalid(field, "a second yellow needs a yellow card in the same match")
+	}
+	return nil
+}
+
+// ListMatchCards returns the cards shown in a match.
+func (s *LeaguesService) ListMatchCards(ctx context.Context, leagueID, seriesID, matchID string) ([]domain.Card, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListMatchCards(ctx, m.ID)
+}
+
+// DeleteCard removes a card recorded in error, with the suspension it
+// triggered; later cards of the player may then trigger suspensions instead.
+func (s *LeaguesService) DeleteCard(ctx context.Context, leagueID, seriesID, matchID, id string) error {
+	ser, err := s.leagueSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return err
+	}
+	c, err := s.store.GetCard(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.MatchID != matchID || c.SeriesID != seriesID {
+		return NotFound("card not found")
+	}
+	cards, matches, stored, err := s.playerSuspensions(ctx, seriesID, c.PlayerID)
+	if err != nil {
+		return err
+	}
+	cards = slices.DeleteFunc(cards, func(x domain.Card) bool { return x.ID == c.ID })
+	add, drop := dueSuspensions(ser, cards, matches, stored)
+	return s.store.DeleteCard(ctx, c.ID, add, drop)
+}
+
+// SuspensionQuery filters the suspensions of a series.
+type SuspensionQuery struct {
+	PlayerID string `form:"playerId"`
+	Active   bool   `form:"active"` // Only those not yet served
+}
+
+// ListSuspensions returns the suspensions of a series, oldest first, with
+// the matches served so far.
+func (s *LeaguesService) ListSuspensions(ctx context.Context, leagueID, seriesID string, q SuspensionQuery) ([]domain.Suspension, error) {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return nil, err
+	}
+	list, err := s.store.ListSuspensions(ctx, seriesID, q.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Served = served(&list[i], matches)
+	}
+	if q.Active {
+		list = slices.DeleteFunc(list, func(su domain.Suspension) bool { return su.Served >= su.Matches })
+	}
+	return list, nil
+}
+
+// SuspensionInput records a suspension decided by the league committee.
+type SuspensionInput struct {
+	PlayerID string `json:"playerId" binding:"required"`
+	TeamID   string `json:"teamId" binding:"required"`
+	MatchID  string `json:"matchId" binding:"required"` // Match of the offence
+	Matches  int    `json:"matches"`
+	Reason   string `json:"reason"`
+}
+
+// CreateSuspension records a suspension the league committee decided, e.g.
+// for conduct after a match.
+func (s *LeaguesService) CreateSuspension(ctx context.Context, userID, leagueID, seriesID string, in SuspensionInput) (*domain.Suspension, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, in.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	if in.TeamID != m.HomeTeamID && in.TeamID != m.AwayTeamID {
+		return nil, Invalid("teamId", "the team does not play in this match")
+	}
+	if in.Matches < 1 {
+		return nil, Invalid("matches", "matches must be at least 1")
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" {
+		return nil, Invalid("reason", "a reason is required")
+	}
+	if err := s.rosteredPlayer(ctx, seriesID, in.TeamID, in.PlayerID); err != nil {
+		return nil, err
+	}
+	su := &domain.Suspension{
+		ID:        util.RandID(),
+		SeriesID:  seriesID,
+		PlayerID:  in.PlayerID,
+		TeamID:    in.TeamID,
+		MatchID:   m.ID,
+		Matches:   in.Matches,
+		Reason:    reason,
+		CreatedBy: userID,
+	}
+	if err := s.store.CreateSuspension(ctx, su); err != nil {
+		return nil, err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	su.Served = served(su, matches)
+	return su, nil
+}
+
+// DeleteSuspension lifts a suspension the league committee decided. Those
+// triggered by cards follow the cards.
+func (s *LeaguesService) DeleteSuspension(ctx context.Context, leagueID, seriesID, id string) error {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return err
+	}
+	su, err := s.store.GetSuspension(ctx, id)
+	if err != nil {
+		return err
+	}
+	if su == nil || su.SeriesID != seriesID {
+		return NotFound("suspension not found")
+	}
+	if su.CardID != "" {
+		return Conflict("the suspension was triggered by a card; delete the card instead")
+	}
+	return s.store.DeleteSuspension(ctx, id)
+}
+
+// MatchSheetInput lists the players a team fields in a match.
+type MatchSheetInput struct {
+	PlayerIDs []string `json:"playerIds" binding:"required"`
+}
+
+// SaveMatchSheet stores the players a team fields in a match still to be
+// played. They must be on the team's roster and not serving a suspension.
+func (s *LeaguesService) SaveMatchSheet(ctx context.Context, userID, leagueID, seriesID, matchID, teamID string, in MatchSheetInput) (*domain.MatchSheet, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if teamID != m.HomeTeamID && teamID != m.AwayTeamID {
+		return nil, NotFound("the team does not play in this match")
+	}
+	owns, err := s.ownsTeam(ctx, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if !owns {
+		recorder, err := s.can(ctx, userID, leagueID, PermManageResults)
+		if err != nil {
+			return nil, err
+		}
+		if !recorder {
+			return nil, Forbidden("only the club owner or league organizers can submit the match sheet")
+		}
+	}
+	if m.Status != "scheduled" {
+		return nil, Conflict("the match is %s; its match sheets are closed", m.Status)
+	}
+	suspensions, err := s.store.ListSuspensions(ctx, seriesID, "")
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	players := []string{}
+	for _, id := range in.PlayerIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || slices.Contains(players, id) {
+			return nil, Invalid("playerIds", "player IDs must be unique and not empty")
+		}
+		if err := s.rosteredPlayer(ctx, seriesID, teamID, id); err != nil {
+			return nil, err
+		}
+		for i := range suspensions {
+			su := &suspensions[i]
+			if su.PlayerID != id || su.MatchID == m.ID {
+				continue
+			}
+			offence := slices.IndexFunc(matches, func(x domain.Match) bool { return x.ID == su.MatchID })
+			if offence < 0 || compareMatches(&matches[offence], m) > 0 {
+				continue
+			}
+			if n := served(su, matches); n < su.Matches {
+				return nil, Conflict("player %s is suspended (%s) and has served %d of %d matches", id, su.Reason, n, su.Matches)
+			}
+		}
+		players = append(players, id)
+	}
+	sh := &domain.MatchSheet{MatchID: m.ID, TeamID: teamID, PlayerIDs: players, SubmittedBy: userID}
+	if err := s.store.SaveMatchSheet(ctx, sh); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// ListMatchSheets returns the match sheets submitted for a match.
+func (s *LeaguesService) ListMatchSheets(ctx context.Context, leagueID, seriesID, matchID string) ([]domain.MatchSheet, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListMatchSheets(ctx, m.ID)
+}
+
+// FairPlayTable ranks the teams of a series by fair-play points, fewest
+// first, then by fewer red cards and more matches played.
+func (s *LeaguesService) FairPlayTable(ctx context.Context, leagueID, seriesID string) ([]domain.FairPlayRow, error) {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return nil, err
+	}
+	regs, err := s.store.ListRegistrationsBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.loadMatchesWithResults(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := s.store.ListSeriesCards(ctx, seriesID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rows := map[string]*domain.FairPlayRow{}
+	row := func(teamID string) *domain.FairPlayRow {
+		r, ok := rows[teamID]
+		if !ok {
+			r = &domain.FairPlayRow{TeamID: teamID}
+			rows[teamID] = r
+		}
+		return r
+	}
+	for _, r := range regs {
+		if r.Status == RegistrationApproved {
+			row(r.TeamID)
+		}
+	}
+	for i := range matches {
+		m := &matches[i]
+		if completed(m) {
+			row(m.HomeTeamID).Played++
+			row(m.AwayTeamID).Played++
+		}
+		if m.Result != nil {
+			row(m.HomeTeamID).ResultPoints += m.Result.HomeFairPlay
+			row(m.AwayTeamID).ResultPoints += m.Result.AwayFairPlay
+		}
+	}
+	for _, c := range cards {
+		r := row(c.TeamID)
+		switch c.Type {
+		case CardYellow:
+			r.Yellow++
+		case CardSecondYellow:
+			r.SecondYellow++
+		case CardRed:
+			r.Red++
+		}
+		r.Points += fairPlayPoints[c.Type]
+	}
+
+	table := make([]domain.FairPlayRow, 0, len(rows))
+	for _, r := range rows {
+		r.Points += r.ResultPoints
+		table = append(table, *r)
+	}
+	slices.SortFunc(table, func(a, b domain.FairPlayRow) int {
+		return cmp.Or(cmp.Compare(a.Points, b.Points), cmp.Compare(a.Red, b.Red), cmp.Compare(b.Played, a.Played), strings.Compare(a.TeamID, b.TeamID))
+	})
+	for i := range table {
+		table[i].Position = i + 1
+	}
+	return table, nil
+}
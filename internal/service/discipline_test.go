@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestDueSuspensions(t *testing.T) {
+	var matches []domain.Match
+	for r := 1; r <= 10; r++ {
+		matches = append(matches, domain.Match{ID: fmt.Sprintf("m%d", r), Round: r, Leg: 1, HomeTeamID: "t1", AwayTeamID: "t2"})
+	}
+	created := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	// card shows a card of the type in match m<round>; later ones are
+	// created later.
+	card := func(id, typ string, round int) domain.Card {
+		created = created.Add(time.Minute)
+		return domain.Card{ID: id, MatchID: fmt.Sprintf("m%d", round), TeamID: "t1", PlayerID: "p1", Type: typ, CreatedAt: created}
+	}
+	yellows := func(n int) []domain.Card {
+		var out []domain.Card
+		for i := 1; i <= n; i++ {
+			out = append(out, card(fmt.Sprintf("y%d", i), CardYellow, i))
+		}
+		return out
+	}
+
+	type due struct {
+		CardID  string
+		Matches int
+		Reason  string
+	}
+	tests := []struct {
+		name   string
+		cards  []domain.Card
+		stored []domain.Suspension
+		add    []due
+		drop   []string
+	}{
+		{name: "four yellows", cards: yellows(4)},
+		{name: "fifth yellow", cards: yellows(5), add: []due{{"y5", 1, "5 yellow cards"}}},
+		{name: "tenth yellow", cards: yellows(10), add: []due{{"y5", 1, "5 yellow cards"}, {"y10", 1, "10 yellow cards"}}},
+		{name: "second yellow", cards: []domain.Card{card("y", CardYellow, 1), card("sy", CardSecondYellow, 1)}, add: []due{{"sy", 1, "second yellow card"}}},
+		{name: "red", cards: []domain.Card{card("r", CardRed, 2)}, add: []due{{"r", 2, "red card"}}},
+		{
+			name:   "already stored",
+			cards:  []domain.Card{card("r", CardRed, 2)},
+			stored: []domain.Suspension{{CardID: "r", Matches: 2}},
+		},
+		{
+			name:   "card withdrawn",
+			cards:  yellows(4),
+			stored: []domain.Suspension{{CardID: "y5", Matches: 1}, {Matches: 3, Reason: "committee"}},
+			drop:   []string{"y5"},
+		},
+		{
+			name: "counted in match order",
+			cards: []domain.Card{
+				card("late", CardYellow, 9),
+				card("a", CardYellow, 1), card("b", CardYellow, 2), card("c", CardYellow, 3), card("d", CardYellow, 4),
+			},
+			add: []due{{"late", 1, "5 yellow cards"}},
+		},
+		{
+			name:  "other matches ignored",
+			cards: append(yellows(4), domain.Card{ID: "elsewhere", MatchID: "other", PlayerID: "p1", Type: CardYellow}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ser := &domain.Series{ID: "s1", DisciplineRules: defaultDisciplineRules}
+			add, drop := dueSuspensions(ser, tt.cards, matches, tt.stored)
+			var got []due
+			for _, su := range add {
+				got = append(got, due{su.CardID, su.Matches, su.Reason})
+				if su.SeriesID != ser.ID || su.PlayerID != "p1" || su.TeamID != "t1" {
+					t.Errorf("suspension %+v is not for p1 of t1 in s1", su)
+				}
+			}
+			if !slices.Equal(got, tt.add) {
+				t.Errorf("got add %v, want %v", got, tt.add)
+			}
+			if !slices.Equal(drop, tt.drop) {
+				t.Errorf("got drop %v, want %v", drop, tt.drop)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+	"team-manager-leagues/internal/util"
+)
+
+// Promotion and relegation
+
+const (
+	MovePromoted  = "promoted"
+	MoveRelegated = "relegated"
+	MoveStayed    = "stayed"
+	MovePlayoff   = "playoff"
+)
+
+// DivisionMovesInput moves teams between the divisions of a season at its
+// end. At each boundary between adjacent divisions the bottom Spots teams of
+// the upper division go down and the top Spots of the lower one go up. With
+// PlayoffSpots the next teams on each side play off: the PlayoffWinners take
+// or keep the places in the upper division.
+type DivisionMovesInput struct {
+	TargetSeasonID string   `json:"targetSeasonId"`
+	Spots          int      `json:"spots"`
+	PlayoffSpots   int      `json:"playoffSpots"`
+	PlayoffWinners []string `json:"playoffWinners"`
+	// DryRun previews the moves without registering anyone.
+	DryRun bool `json:"dryRun"`
+}
+
+// division is a ranked series of the season being closed.
+type division struct {
+	ser    domain.Series
+	level  int
+	target *domain.Series // Same division in the target season
+	table  []domain.StandingRow
+}
+
+// MoveDivisions works out, from the final standings of each division of
+// season id, where every team plays in the target season. Unless it is a dry
+// run, each team is invited into its division of the target season; any
+// registration it holds in another division of that season, e.g. from a
+// roll-over, is withdrawn.
+func (s *LeaguesService) MoveDivisions(ctx context.Context, userID, leagueID, id string, in DivisionMovesInput) ([]domain.DivisionMove, []domain.TeamRegistration, error) {
+	if in.Spots < 0 || in.PlayoffSpots < 0 || in.Spots+in.PlayoffSpots == 0 {
+		return nil, nil, Invalid("spots", "spots and playoffSpots cannot be negative, and at least one must be set")
+	}
+	season, err := s.GetSeason(ctx, leagueID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in.TargetSeasonID == "" || in.TargetSeasonID == id {
+		return nil, nil, Invalid("targetSeasonId", "name the season teams move into")
+	}
+	target, err := s.GetSeason(ctx, leagueID, in.TargetSeasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target.Status == SeasonFinished {
+		return nil, nil, Conflict("season %s is finished", target.Name)
+	}
+
+	divs, err := s.divisions(ctx, leagueID, season, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range divs {
+		need := 0
+		if i > 0 {
+			need += in.Spots + in.PlayoffSpots
+		}
+		if i < len(divs)-1 {
+			need += in.Spots + in.PlayoffSpots
+		}
+		if len(divs[i].table) < need {
+			return nil, nil, Conflict("division %d has %d teams, too few for %d promotion and %d playoff spots", divs[i].level, len(divs[i].table), in.Spots, in.PlayoffSpots)
+		}
+	}
+
+	moves, playoffs := planDivisionMoves(divs, in.Spots, in.PlayoffSpots)
+	if err := settlePlayoffs(moves, playoffs, in.PlayoffSpots, in.PlayoffWinners, in.DryRun); err != nil {
+		return nil, nil, err
+	}
+	if in.DryRun {
+		return moves, []domain.TeamRegistration{}, nil
+	}
+	invites, err := s.applyDivisionMoves(ctx, userID, target, divs, moves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return moves, invites, nil
+}
+
+// divisions loads the ranked series of season with their final tables and
+// pairs each with the series of the same division in target.
+func (s *LeaguesService) divisions(ctx context.Context, leagueID string, season, target *domain.Season) ([]division, error) {
+	series, err := s.seasonSeries(ctx, leagueID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	next, err := s.seasonSeries(ctx, leagueID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	divs := []division{}
+	for _, ser := range series {
+		if ser.Division != nil {
+			divs = append(divs, division{ser: ser, level: *ser.Division})
+		}
+	}
+	if len(divs) < 2 {
+		return nil, Conflict("season %s has fewer than two divisions", season.Name)
+	}
+	slices.SortFunc(divs, func(a, b division) int { return a.level - b.level })
+	for i := range divs {
+		d := &divs[i]
+		j := slices.IndexFunc(next, func(x domain.Series) bool { return x.Division != nil && *x.Division == d.level })
+		if j < 0 {
+			return nil, Conflict("season %s has no division %d", target.Name, d.level)
+		}
+		d.target = &next[j]
+
+		matches, err := s.store.ListMatchesBySeries(ctx, d.ser.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, Conflict("division %d (%s) has no fixtures", d.level, d.ser.Name)
+		}
+		if slices.ContainsFunc(matches, func(m domain.Match) bool { return m.Status == "scheduled" }) {
+			return nil, Conflict("division %d (%s) has matches still to play", d.level, d.ser.Name)
+		}
+		if d.table, err = s.ComputeStandings(ctx, leagueID, d.ser.ID); err != nil {
+			return nil, err
+		}
+	}
+	return divs, nil
+}
+
+// playoff is played at the boundary between two adjacent divisions by the
+// teams just above the upper one's relegation places and those just below
+// the lower one's promotion places.
+type playoff struct {
+	upper, lower *division
+	entrants     []int // Indexes into the moves
+}
+
+// planDivisionMoves assigns every team its outcome by final position.
+// Playoff places are left undecided.
+func planDivisionMoves(divs []division, spots, playoffSpots int) ([]domain.DivisionMove, []playoff) {
+	moves := []domain.DivisionMove{}
+	playoffs := make([]playoff, len(divs)-1)
+	for i := range playoffs {
+		playoffs[i].upper, playoffs[i].lower = &divs[i], &divs[i+1]
+	}
+	for i, d := range divs {
+		n := len(d.table)
+		for p, row := range d.table {
+			m := domain.DivisionMove{
+				TeamID:       row.TeamID,
+				FromSeriesID: d.ser.ID,
+				FromDivision: d.level,
+				Position:     row.Position,
+				Outcome:      MoveStayed,
+				ToSeriesID:   d.target.ID,
+				ToDivision:   d.level,
+			}
+			switch {
+			case i > 0 && p < spots:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MovePromoted, divs[i-1].target.ID, divs[i-1].level
+			case i > 0 && p < spots+playoffSpots:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MovePlayoff, "", 0
+				playoffs[i-1].entrants = append(playoffs[i-1].entrants, len(moves))
+			case i < len(divs)-1 && p >= n-spots:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MoveRelegated, divs[i+1].target.ID, divs[i+1].level
+			case i < len(divs)-1 && p >= n-spots-playoffSpots:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MovePlayoff, "", 0
+				playoffs[i].entrants = append(playoffs[i].entrants, len(moves))
+			}
+			moves = append(moves, m)
+		}
+	}
+	return moves, playoffs
+}
+
+// settlePlayoffs decides the playoff places from the winners. A dry run may
+// leave them undecided; otherwise every playoff needs its winners.
+func settlePlayoffs(moves []domain.DivisionMove, playoffs []playoff, playoffSpots int, winners []string, dryRun bool) error {
+	if playoffSpots == 0 {
+		if len(winners) > 0 {
+			return Invalid("playoffWinners", "there are no playoff spots")
+		}
+		return nil
+	}
+	for _, w := range winners {
+		if !slices.ContainsFunc(moves, func(m domain.DivisionMove) bool { return m.TeamID == w && m.Outcome == MovePlayoff }) {
+			return Invalid("playoffWinners", "team %s is not in a playoff", w)
+		}
+	}
+	if len(winners) == 0 && dryRun {
+		return nil
+	}
+	for _, po := range playoffs {
+		won := 0
+		for _, j := range po.entrants {
+			if slices.Contains(winners, moves[j].TeamID) {
+				won++
+			}
+		}
+		if won != playoffSpots {
+			return Invalid("playoffWinners", "name %d winner(s) of the playoff between divisions %d and %d", playoffSpots, po.upper.level, po.lower.level)
+		}
+	}
+	for _, po := range playoffs {
+		for _, j := range po.entrants {
+			m := &moves[j]
+			m.Playoff = true
+			win := slices.Contains(winners, m.TeamID)
+			switch {
+			case m.FromDivision == po.upper.level && win:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MoveStayed, po.upper.target.ID, po.upper.level
+			case m.FromDivision == po.upper.level:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MoveRelegated, po.lower.target.ID, po.lower.level
+			case win:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MovePromoted, po.upper.target.ID, po.upper.level
+			default:
+				m.Outcome, m.ToSeriesID, m.ToDivision = MoveStayed, po.lower.target.ID, po.lower.level
+			}
+		}
+	}
+	return nil
+}
+
+// applyDivisionMoves invites every team into its division of the target
+// season and withdraws its registrations in the others.
+func (s *LeaguesService) applyDivisionMoves(ctx context.Context, userID string, target *domain.Season, divs []division, moves []domain.DivisionMove) ([]domain.TeamRegistration, error) {
+	targetSeries := map[string]*domain.Series{}
+	prevRegs := map[string]domain.TeamRegistration{} // By series and team
+	for _, d := range divs {
+		targetSeries[d.target.ID] = d.target
+		regs, err := s.store.ListRegistrationsBySeries(ctx, d.ser.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range regs {
+			prevRegs[r.SeriesID+"/"+r.TeamID] = r
+		}
+	}
+
+	var changes, events []domain.RegistrationEvent
+	invites := []domain.TeamRegistration{}
+	freed := map[string]bool{} // Target series where a spot frees up
+	for _, m := range moves {
+		reason := fmt.Sprintf("%s from division %d", m.Outcome, m.FromDivision)
+		if m.Outcome == MoveStayed {
+			reason = fmt.Sprintf("stayed in division %d", m.FromDivision)
+		}
+		current, err := s.store.ListRegistrations(ctx, repository.RegistrationFilter{TeamID: m.TeamID}, repository.Page{Sort: repository.SortCreatedAt})
+		if err != nil {
+			return nil, err
+		}
+		registered := false
+		for _, r := range current {
+			if targetSeries[r.SeriesID] == nil {
+				continue
+			}
+			if r.SeriesID == m.ToSeriesID {
+				registered = true
+				continue
+			}
+			if _, ok := registrationTransitions[r.Status][RegistrationWithdrawn]; ok {
+				changes = append(changes, domain.RegistrationEvent{
+					ID:             util.RandID(),
+					RegistrationID: r.ID,
+					FromStatus:     r.Status,
+					ToStatus:       RegistrationWithdrawn,
+					ActorID:        userID,
+					Reason:         fmt.Sprintf("%s; plays division %d in season %s", reason, m.ToDivision, target.Name),
+				})
+				if holdsSpot(r.Status) {
+					freed[r.SeriesID] = true
+				}
+			}
+		}
+		if registered {
+			continue
+		}
+		t, err := s.store.GetTeamByID(ctx, m.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			continue
+		}
+		prev := prevRegs[m.FromSeriesID+"/"+m.TeamID]
+		reg := domain.TeamRegistration{
+			ID:             util.RandID(),
+			TeamID:         m.TeamID,
+			SeriesID:       m.ToSeriesID,
+			Status:         RegistrationInvited,
+			AgeCategory:    prev.AgeCategory,
+			GenderCategory: prev.GenderCategory,
+		}
+		reg.EligibilityIssues = evaluateEligibility(targetSeries[m.ToSeriesID], t, &reg, nil)
+		invites = append(invites, reg)
+		events = append(events, domain.RegistrationEvent{ID: util.RandID(), RegistrationID: reg.ID, ToStatus: reg.Status, ActorID: userID, Reason: reason})
+	}
+
+	applied, err := s.store.MoveRegistrations(ctx, changes, invites, events)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, Conflict("registrations changed concurrently; try again")
+	}
+	for seriesID := range freed {
+		if _, err := s.promoteWaitlisted(ctx, userID, seriesID); err != nil {
+			return nil, err
+		}
+	}
+	return invites, nil
+}
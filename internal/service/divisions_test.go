@@ -0,0 +1,233 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// divisionLeague is a season with two divisions of four teams, t1 to t4 in
+// division 1 and t5 to t8 in division 2, played out so each finishes in
+// number order, and the series of the next season.
+type divisionLeague struct {
+	*testLeague
+	lower  *domain.Series
+	target string // Next season
+	// Series of the next season by division
+	next map[int]string
+}
+
+func newDivisionLeague(t *testing.T, rollover bool) *divisionLeague {
+	t.Helper()
+	tl := newTestLeague(t, SeriesSettings{Division: ptr(1)})
+	dl := &divisionLeague{testLeague: tl, next: map[int]string{}}
+	var err error
+	if dl.lower, err = tl.svc.CreateSeries(tl.ctx, tl.league.ID, tl.series.SeasonID, "B", "11", SeriesSettings{Division: ptr(2)}); err != nil {
+		t.Fatalf("create series: %v", err)
+	}
+	tl.approve("t1", "t2", "t3", "t4")
+	for _, id := range []string{"t5", "t6", "t7", "t8"} {
+		reg, err := tl.svc.RegisterTeam(tl.ctx, clubOwner, RegistrationInput{TeamID: id, SeriesID: dl.lower.ID})
+		if err == nil {
+			_, err = tl.svc.UpdateRegistrationStatus(tl.ctx, organizer, reg.ID, RegistrationApproved, "")
+		}
+		if err != nil {
+			t.Fatalf("approve %s: %v", id, err)
+		}
+	}
+	// The lower numbered team wins every match.
+	for _, ser := range []*domain.Series{tl.series, dl.lower} {
+		matches, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, ser.ID, false)
+		if err != nil {
+			t.Fatalf("generate fixtures: %v", err)
+		}
+		for _, m := range matches {
+			in := ResultInput{Outcome: "played", HomeScore: 1}
+			if m.AwayTeamID < m.HomeTeamID {
+				in = ResultInput{Outcome: "played", AwayScore: 1}
+			}
+			if _, err := tl.svc.RecordResult(tl.ctx, organizer, tl.league.ID, ser.ID, m.ID, in); err != nil {
+				t.Fatalf("record result: %v", err)
+			}
+		}
+	}
+
+	var series []domain.Series
+	if rollover {
+		// Everyone is invited back into their old division.
+		se, copies, _, err := tl.svc.RollOverSeason(tl.ctx, organizer, tl.league.ID, tl.series.SeasonID, RollOverInput{SeasonInput: SeasonInput{"2028", "2028-03-01", "2028-06-30"}, ReinviteTeams: true})
+		if err != nil {
+			t.Fatalf("roll over: %v", err)
+		}
+		dl.target, series = se.ID, copies
+	} else {
+		se, err := tl.svc.CreateSeason(tl.ctx, tl.league.ID, SeasonInput{"2028", "2028-03-01", "2028-06-30"})
+		if err != nil {
+			t.Fatalf("create season: %v", err)
+		}
+		dl.target = se.ID
+		for div := 1; div <= 2; div++ {
+			ser, err := tl.svc.CreateSeries(tl.ctx, tl.league.ID, se.ID, fmt.Sprintf("Division %d", div), "11", SeriesSettings{Division: ptr(div)})
+			if err != nil {
+				t.Fatalf("create series: %v", err)
+			}
+			series = append(series, *ser)
+		}
+	}
+	for _, ser := range series {
+		dl.next[*ser.Division] = ser.ID
+	}
+	return dl
+}
+
+// placed returns the division each team holds a live registration in for
+// the next season.
+func (dl *divisionLeague) placed() map[string]int {
+	dl.t.Helper()
+	out := map[string]int{}
+	for div, id := range dl.next {
+		regs, err := dl.store.ListRegistrationsBySeries(dl.ctx, id)
+		if err != nil {
+			dl.t.Fatalf("list registrations: %v", err)
+		}
+		for _, r := range regs {
+			if r.Status != RegistrationWithdrawn {
+				if out[r.TeamID] != 0 {
+					dl.t.Fatalf("%s placed in divisions %d and %d", r.TeamID, out[r.TeamID], div)
+				}
+				out[r.TeamID] = div
+			}
+		}
+	}
+	return out
+}
+
+func TestMoveDivisions(t *testing.T) {
+	stay := map[string]int{"t1": 1, "t2": 1, "t3": 1, "t4": 1, "t5": 2, "t6": 2, "t7": 2, "t8": 2}
+	tests := []struct {
+		name     string
+		rollover bool
+		in       DivisionMovesInput
+		want     map[string]int // Division of each team; 0 for an undecided playoff
+		invites  int
+		err      error
+		previews bool // A dry run goes through despite err
+	}{
+		{name: "one up one down", in: DivisionMovesInput{Spots: 1}, want: map[string]int{"t4": 2, "t5": 1}, invites: 8},
+		{name: "two up two down", in: DivisionMovesInput{Spots: 2}, want: map[string]int{"t3": 2, "t4": 2, "t5": 1, "t6": 1}, invites: 8},
+		// Teams invited back into the right division keep that invitation.
+		{name: "after rollover", rollover: true, in: DivisionMovesInput{Spots: 1}, want: map[string]int{"t4": 2, "t5": 1}, invites: 2},
+		{
+			name: "playoff undecided",
+			in:   DivisionMovesInput{Spots: 1, PlayoffSpots: 1, DryRun: true},
+			want: map[string]int{"t3": 0, "t4": 2, "t5": 1, "t6": 0},
+		},
+		{
+			name:    "playoff won from below",
+			in:      DivisionMovesInput{Spots: 1, PlayoffSpots: 1, PlayoffWinners: []string{"t6"}},
+			want:    map[string]int{"t3": 2, "t4": 2, "t5": 1, "t6": 1},
+			invites: 8,
+		},
+		{
+			name:    "playoff won from above",
+			in:      DivisionMovesInput{Spots: 1, PlayoffSpots: 1, PlayoffWinners: []string{"t3"}},
+			want:    map[string]int{"t4": 2, "t5": 1},
+			invites: 8,
+		},
+		{name: "playoff without winners", in: DivisionMovesInput{Spots: 1, PlayoffSpots: 1}, want: map[string]int{"t3": 0, "t4": 2, "t5": 1, "t6": 0}, err: ErrValidation, previews: true},
+		{name: "winner not in the playoff", in: DivisionMovesInput{Spots: 1, PlayoffSpots: 1, PlayoffWinners: []string{"t5"}}, err: ErrValidation},
+		{name: "no spots", in: DivisionMovesInput{}, err: ErrValidation},
+		{name: "too many spots", in: DivisionMovesInput{Spots: 5}, err: ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := newDivisionLeague(t, tt.rollover)
+			before := dl.placed()
+			want := map[string]int{}
+			for id, div := range stay {
+				want[id] = div
+			}
+			for id, div := range tt.want {
+				want[id] = div
+			}
+
+			// A dry run previews the moves and registers nobody.
+			in := tt.in
+			in.TargetSeasonID = dl.target
+			in.DryRun = true
+			preview, invites, err := dl.svc.MoveDivisions(dl.ctx, organizer, dl.league.ID, dl.series.SeasonID, in)
+			if tt.previews {
+				checkErr(t, err, nil)
+			} else {
+				checkErr(t, err, tt.err)
+			}
+			if len(invites) != 0 {
+				t.Fatalf("dry run invited %d teams", len(invites))
+			}
+			if after := dl.placed(); !maps.Equal(after, before) {
+				t.Fatalf("dry run placed %v, before %v", after, before)
+			}
+			if err == nil {
+				checkMoves(t, dl, preview, want)
+			}
+			if tt.in.DryRun {
+				return
+			}
+
+			in.DryRun = false
+			moves, invites, err := dl.svc.MoveDivisions(dl.ctx, organizer, dl.league.ID, dl.series.SeasonID, in)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				if after := dl.placed(); !maps.Equal(after, before) {
+					t.Fatalf("failed move placed %v, before %v", after, before)
+				}
+				return
+			}
+			if !slices.Equal(moves, preview) {
+				t.Fatalf("applied %+v, previewed %+v", moves, preview)
+			}
+			checkMoves(t, dl, moves, want)
+			if got := dl.placed(); !maps.Equal(got, want) {
+				t.Fatalf("placed %v, want %v", got, want)
+			}
+			if len(invites) != tt.invites {
+				t.Fatalf("%d invitations, want %d", len(invites), tt.invites)
+			}
+			for _, reg := range invites {
+				if reg.Status != RegistrationInvited {
+					t.Errorf("%s %s", reg.TeamID, reg.Status)
+				}
+			}
+		})
+	}
+}
+
+// checkMoves compares the moves with the division each team should end up
+// in.
+func checkMoves(t *testing.T, dl *divisionLeague, moves []domain.DivisionMove, want map[string]int) {
+	t.Helper()
+	if len(moves) != len(want) {
+		t.Fatalf("%d moves, want %d", len(moves), len(want))
+	}
+	for _, m := range moves {
+		div := want[m.TeamID]
+		outcome := MoveStayed
+		switch {
+		case div == 0:
+			outcome = MovePlayoff
+		case div < m.FromDivision:
+			outcome = MovePromoted
+		case div > m.FromDivision:
+			outcome = MoveRelegated
+		}
+		if m.Outcome != outcome || m.ToDivision != div || m.ToSeriesID != dl.next[div] {
+			t.Errorf("%s %s to division %d (%s), want %s to %d", m.TeamID, m.Outcome, m.ToDivision, m.ToSeriesID, outcome, div)
+		}
+		if pos := int(m.TeamID[1]-'0') - 4*(m.FromDivision-1); m.Position != pos {
+			t.Errorf("%s finished %d, want %d", m.TeamID, m.Position, pos)
+		}
+	}
+}
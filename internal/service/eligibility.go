@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Eligibility
+
+const (
+	EligibilityReject = "reject"
+	EligibilityFlag   = "flag"
+
+	RuleFormat         = "format"
+	RuleAgeCategory    = "age_category"
+	RuleGenderCategory = "gender_category"
+
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderMixed  = "mixed"
+)
+
+// eligibilityRule checks one property of a registering team against its
+// series, returning the expected and actual values and whether they match.
+type eligibilityRule struct {
+	name  string
+	check func(ser *domain.Series, t *domain.Team, reg *domain.TeamRegistration) (expected, actual string, ok bool)
+}
+
+var eligibilityRules = []eligibilityRule{
+	{RuleFormat, func(ser *domain.Series, t *domain.Team, _ *domain.TeamRegistration) (string, string, bool) {
+		return ser.Format, t.Format, ser.Format == "" || strings.EqualFold(ser.Format, t.Format)
+	}},
+	{RuleAgeCategory, func(ser *domain.Series, _ *domain.Team, reg *domain.TeamRegistration) (string, string, bool) {
+		return ser.AgeCategory, reg.AgeCategory, ageEligible(ser.AgeCategory, reg.AgeCategory)
+	}},
+	{RuleGenderCategory, func(ser *domain.Series, _ *domain.Team, reg *domain.TeamRegistration) (string, string, bool) {
+		ok := ser.GenderCategory == "" || ser.GenderCategory == GenderMixed || ser.GenderCategory == reg.GenderCategory
+		return ser.GenderCategory, reg.GenderCategory, ok
+	}},
+}
+
+func validRule(name string) bool {
+	for _, r := range eligibilityRules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ageLimit parses an "U<n>" age category.
+func ageLimit(category string) (int, bool) {
+	if len(category) < 2 || (category[0] != 'U' && category[0] != 'u') {
+		return 0, false
+	}
+	n, err := strconv.Atoi(category[1:])
+	return n, err == nil && n > 0
+}
+
+// ageEligible allows a team into its own age category or, for "U<n>"
+// categories, an older one (a U10 team may play up in a U12 series).
+func ageEligible(series, team string) bool {
+	if series == "" || strings.EqualFold(series, team) {
+		return true
+	}
+	s, okS := ageLimit(series)
+	t, okT := ageLimit(team)
+	return okS && okT && t <= s
+}
+
+func validAgeCategory(c string) bool {
+	_, ok := ageLimit(c)
+	return c == "" || c == "open" || ok
+}
+
+func validGenderCategory(c string) bool {
+	switch c {
+	case "", GenderMale, GenderFemale, GenderMixed:
+		return true
+	}
+	return false
+}
+
+// evaluateEligibility runs every rule and returns the failed ones, marking
+// those covered by an organizer override.
+func evaluateEligibility(ser *domain.Series, t *domain.Team, reg *domain.TeamRegistration, overrides []domain.EligibilityOverride) []domain.EligibilityIssue {
+	issues := []domain.EligibilityIssue{}
+	for _, r := range eligibilityRules {
+		expected, actual, ok := r.check(ser, t, reg)
+		if ok {
+			continue
+		}
+		issue := domain.EligibilityIssue{Rule: r.name, Expected: expected, Actual: actual}
+		for _, o := range overrides {
+			if o.Rule == r.name {
+				issue.Overridden = true
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// unresolvedIssues returns the issues not covered by an override.
+func unresolvedIssues(issues []domain.EligibilityIssue) []domain.EligibilityIssue {
+	var out []domain.EligibilityIssue
+	for _, i := range issues {
+		if !i.Overridden {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func describeIssues(issues []domain.EligibilityIssue) string {
+	parts := make([]string, len(issues))
+	for i, is := range issues {
+		actual := is.Actual
+		if actual == "" {
+			actual = "not declared"
+		}
+		parts[i] = fmt.Sprintf("%s: series requires %q, team is %q", is.Rule, is.Expected, actual)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// issueFields names the registration input behind each rule.
+var issueFields = map[string]string{
+	RuleFormat:         "teamId",
+	RuleAgeCategory:    "ageCategory",
+	RuleGenderCategory: "genderCategory",
+}
+
+// ineligible is the validation error turning a team away, with one field
+// error per failed rule.
+func ineligible(issues []domain.EligibilityIssue) error {
+	e := &Error{Kind: ErrValidation, Message: "team is not eligible for this series: " + describeIssues(issues)}
+	for _, is := range issues {
+		e.Fields = append(e.Fields, FieldError{Field: issueFields[is.Rule], Message: describeIssues([]domain.EligibilityIssue{is})})
+	}
+	return e
+}
+
+// OverrideEligibility lets a team into a series despite failing a rule. The
+// reason is kept for the record. If the team is already registered its issues
+// are re-evaluated.
+func (s *LeaguesService) OverrideEligibility(ctx context.Context, userID, leagueID, seriesID, teamID, rule, reason string) (*domain.EligibilityOverride, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	if !validRule(rule) {
+		return nil, Invalid("rule", "invalid rule")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, Invalid("reason", "a reason is required to override eligibility")
+	}
+	t, err := s.store.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, NotFound("team not found")
+	}
+
+	o := &domain.EligibilityOverride{ID: util.RandID(), SeriesID: seriesID, TeamID: teamID, Rule: rule, Reason: reason, CreatedBy: userID}
+	reg, err := s.store.GetRegistrationBySlot(ctx, seriesID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if reg != nil {
+		overrides, err := s.store.ListTeamEligibilityOverrides(ctx, seriesID, teamID)
+		if err != nil {
+			return nil, err
+		}
+		reg.EligibilityIssues = evaluateEligibility(ser, t, reg, append(overrides, *o))
+	}
+	if err := s.store.SaveEligibilityOverride(ctx, o, reg); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *LeaguesService) ListEligibilityOverrides(ctx context.Context, leagueID, seriesID string) ([]domain.EligibilityOverride, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	return s.store.ListEligibilityOverrides(ctx, seriesID)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"team-manager-leagues/internal/repository"
+)
+
+// Error kinds. Errors returned by the service wrap one of them, so callers
+// can tell them apart with errors.Is; anything else is an internal failure.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrForbidden  = errors.New("forbidden")
+	ErrValidation = errors.New("validation failed")
+	// Conflicts with the current state, including writes rejected by a
+	// storage constraint such as a duplicate name.
+	ErrConflict = repository.ErrConflict
+	// The storage could not be reached; the request may be retried.
+	ErrUnavailable = repository.ErrUnavailable
+)
+
+// Error is a service error of one of the kinds above, with a message that is
+// safe to show to clients.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError // Invalid input fields, for ErrValidation
+}
+
+// FieldError describes why one input field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Kind }
+
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...any) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid reports invalid input. field names the offending input field in
+// its JSON spelling, or is empty when the input is invalid as a whole.
+func Invalid(field, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	e := &Error{Kind: ErrValidation, Message: msg}
+	if field != "" {
+		e.Fields = []FieldError{{Field: field, Message: msg}}
+	}
+	return e
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Fixtures
+
+// pairing is a single generated match before it is persisted.
+type pairing struct {
+	Round int
+	Leg   int
+	Home  string
+	Away  string
+}
+
+// GenerateFixtures builds a round-robin schedule for a series from its approved
+// registrations. With doubleLeg every pairing is played twice, the return leg
+// swapping home and away. Teams without an opponent in a round get a bye.
+func (s *LeaguesService) GenerateFixtures(ctx context.Context, leagueID, seriesID string, doubleLeg bool) ([]domain.Match, []domain.Bye, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, nil, NotFound("series not found")
+	}
+	if ser.Mode != SeriesModeLeague {
+		return nil, nil, Conflict("round-robin fixtures are only available for league series")
+	}
+
+	existing, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(existing) > 0 {
+		return nil, nil, Conflict("fixtures already generated")
+	}
+
+	teamIDs, err := s.approvedTeamIDs(ctx, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(teamIDs) < 2 {
+		return nil, nil, Conflict("at least two approved teams are required")
+	}
+
+	pairings, byes := roundRobin(teamIDs, doubleLeg)
+	matches := make([]domain.Match, len(pairings))
+	for i, p := range pairings {
+		matches[i] = domain.Match{
+			ID:         util.RandID(),
+			SeriesID:   seriesID,
+			Round:      p.Round,
+			Leg:        p.Leg,
+			HomeTeamID: p.Home,
+			AwayTeamID: p.Away,
+			Status:     "scheduled",
+		}
+	}
+	// The store checks again under a lock; the check above only spares
+	// the work when the series already has fixtures
+	created, err := s.store.CreateFixtures(ctx, seriesID, matches)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !created {
+		return nil, nil, Conflict("fixtures already generated")
+	}
+	return matches, byes, nil
+}
+
+func (s *LeaguesService) ListFixtures(ctx context.Context, leagueID, seriesID string) ([]domain.Match, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	return s.loadMatchesWithResults(ctx, seriesID)
+}
+
+// DeleteFixtures discards the series' fixtures, bracket and stages while none
+// of its matches has been played; after that, results and discipline hang on
+// them.
+func (s *LeaguesService) DeleteFixtures(ctx context.Context, leagueID, seriesID string) error {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return NotFound("series not found")
+	}
+	deleted, err := s.store.DeleteMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return Conflict("the series has played matches; its fixtures can no longer be discarded")
+	}
+	return nil
+}
+
+// approvedTeamIDs lists the teams approved into a series in registration
+// order, so that generated schedules are reproducible.
+func (s *LeaguesService) approvedTeamIDs(ctx context.Context, seriesID string) ([]string, error) {
+	regs, err := s.store.ListRegistrationsBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	approved := make([]domain.TeamRegistration, 0, len(regs))
+	for _, r := range regs {
+		if r.Status == RegistrationApproved {
+			approved = append(approved, r)
+		}
+	}
+	sort.SliceStable(approved, func(i, j int) bool {
+		if approved[i].CreatedAt.Equal(approved[j].CreatedAt) {
+			return approved[i].ID < approved[j].ID
+		}
+		return approved[i].CreatedAt.Before(approved[j].CreatedAt)
+	})
+	teamIDs := make([]string, len(approved))
+	for i, r := range approved {
+		teamIDs[i] = r.TeamID
+	}
+	return teamIDs, nil
+}
+
+// roundRobin pairs teams using the circle method: the first team stays fixed
+// while the others rotate one position per round. An odd team count is padded
+// with an empty slot, and whoever meets it sits the round out.
+func roundRobin(teamIDs []string, doubleLeg bool) ([]pairing, []domain.Bye) {
+	slots := append([]string(nil), teamIDs...)
+	if len(slots)%2 == 1 {
+		slots = append(slots, "")
+	}
+	n := len(slots)
+	rounds := n - 1
+
+	var pairings []pairing
+	var byes []domain.Bye
+	for r := 0; r < rounds; r++ {
+		for i := 0; i < n/2; i++ {
+			home, away := slots[i], slots[n-1-i]
+			// Flipping every other round keeps home and away games
+			// alternating, including for the fixed team.
+			if r%2 == 1 {
+				home, away = away, home
+			}
+			switch {
+			case home == "":
+				byes = append(byes, domain.Bye{Round: r + 1, Leg: 1, TeamID: away})
+			case away == "":
+				byes = append(byes, domain.Bye{Round: r + 1, Leg: 1, TeamID: home})
+			default:
+				pairings = append(pairings, pairing{Round: r + 1, Leg: 1, Home: home, Away: away})
+			}
+		}
+		// Rotate every slot but the first one position clockwise.
+		last := slots[n-1]
+		copy(slots[2:], slots[1:n-1])
+		slots[1] = last
+	}
+
+	if doubleLeg {
+		firstLeg, firstByes := len(pairings), len(byes)
+		for _, p := range pairings[:firstLeg] {
+			pairings = append(pairings, pairing{Round: p.Round + rounds, Leg: 2, Home: p.Away, Away: p.Home})
+		}
+		for _, b := range byes[:firstByes] {
+			byes = append(byes, domain.Bye{Round: b.Round + rounds, Leg: 2, TeamID: b.TeamID})
+		}
+	}
+	return pairings, byes
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestRoundRobin(t *testing.T) {
+	tests := []struct {
+		name      string
+		teams     int
+		doubleLeg bool
+		rounds    int
+		byes      int
+	}{
+		{"two teams", 2, false, 1, 0},
+		{"even", 6, false, 5, 0},
+		{"odd", 5, false, 5, 5},
+		{"double leg even", 4, true, 6, 0},
+		{"double leg odd", 3, true, 6, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for i := 1; i <= tt.teams; i++ {
+				ids = append(ids, fmt.Sprintf("t%d", i))
+			}
+			pairings, byes := roundRobin(ids, tt.doubleLeg)
+
+			legs := 1
+			if tt.doubleLeg {
+				legs = 2
+			}
+			if want := legs * tt.teams * (tt.teams - 1) / 2; len(pairings) != want {
+				t.Fatalf("got %d pairings, want %d", len(pairings), want)
+			}
+			if len(byes) != tt.byes {
+				t.Fatalf("got %d byes, want %d", len(byes), tt.byes)
+			}
+
+			// Every team plays or sits out exactly once a round.
+			seen := map[string]int{}
+			rounds := 0
+			for _, p := range pairings {
+				seen[fmt.Sprintf("%d/%s", p.Round, p.Home)]++
+				seen[fmt.Sprintf("%d/%s", p.Round, p.Away)]++
+				rounds = max(rounds, p.Round)
+			}
+			for _, b := range byes {
+				seen[fmt.Sprintf("%d/%s", b.Round, b.TeamID)]++
+			}
+			if rounds != tt.rounds {
+				t.Fatalf("got %d rounds, want %d", rounds, tt.rounds)
+			}
+			for r := 1; r <= tt.rounds; r++ {
+				for _, id := range ids {
+					if n := seen[fmt.Sprintf("%d/%s", r, id)]; n != 1 {
+						t.Errorf("%s appears %d times in round %d", id, n, r)
+					}
+				}
+			}
+
+			// Every pair meets once a leg, hosting one leg each.
+			hosts := map[string]int{}
+			for _, p := range pairings {
+				if p.Leg != 1 && p.Leg != legs {
+					t.Errorf("round %d has leg %d", p.Round, p.Leg)
+				}
+				hosts[p.Home+"-"+p.Away]++
+			}
+			for i, a := range ids {
+				for _, b := range ids[i+1:] {
+					ab, ba := hosts[a+"-"+b], hosts[b+"-"+a]
+					if ab+ba != legs || (legs == 2 && ab != 1) {
+						t.Errorf("%s hosts %s %d times and visits %d times", a, b, ab, ba)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteFixtures(t *testing.T) {
+	tests := []struct {
+		name string
+		play func(tl *testLeague, m domain.Match) error
+		err  error
+	}{
+		{name: "none played", play: func(*testLeague, domain.Match) error { return nil }},
+		{
+			name: "result recorded",
+			play: func(tl *testLeague, m domain.Match) error {
+				_, err := tl.svc.RecordResult(tl.ctx, organizer, tl.league.ID, tl.series.ID, m.ID, ResultInput{Outcome: "played", HomeScore: 1})
+				return err
+			},
+			err: ErrConflict,
+		},
+		{
+			name: "live events",
+			play: func(tl *testLeague, m domain.Match) error {
+				return tl.store.AppendMatchEvent(tl.ctx, &domain.MatchEvent{MatchID: m.ID, Seq: 1, Type: EventPeriodStart, Period: 1})
+			},
+			err: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			tl.approve("t1", "t2", "t3")
+			matches, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, tl.series.ID, false)
+			if err != nil {
+				t.Fatalf("generate fixtures: %v", err)
+			}
+			if err := tt.play(tl, matches[0]); err != nil {
+				t.Fatalf("play: %v", err)
+			}
+
+			checkErr(t, tl.svc.DeleteFixtures(tl.ctx, tl.league.ID, tl.series.ID), tt.err)
+			want := 0
+			if tt.err != nil {
+				want = len(matches)
+			}
+			if got := len(tl.matches()); got != want {
+				t.Fatalf("%d matches left, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository/memory"
+)
+
+const (
+	organizer = "org"   // Creates the league, so owns it
+	clubOwner = "owner" // Owns the club of every team
+	stranger  = "nobody"
+)
+
+// testLeague is a league with a season and one series over the memory
+// store. Teams t1 to t8 each belong to their own club, c1 to c8.
+type testLeague struct {
+	t      *testing.T
+	ctx    context.Context
+	svc    *LeaguesService
+	store  *memory.Store
+	league *domain.League
+	series *domain.Series
+}
+
+func newTestLeague(t *testing.T, settings SeriesSettings) *testLeague {
+	t.Helper()
+	store := memory.NewStore()
+	var teams []domain.Team
+	var memberships []domain.Membership
+	for i := 1; i <= 8; i++ {
+		club := fmt.Sprintf("c%d", i)
+		teams = append(teams, domain.Team{ID: fmt.Sprintf("t%d", i), ClubID: club, Name: fmt.Sprintf("Team %d", i), Format: "11"})
+		memberships = append(memberships, domain.Membership{ID: club, UserID: clubOwner, ClubID: club, Role: "owner", Status: "active"})
+	}
+	store.Seed(teams, memberships)
+
+	tl := &testLeague{t: t, ctx: context.Background(), svc: NewLeaguesService(store), store: store}
+	var err error
+	if tl.league, err = tl.svc.CreateLeague(tl.ctx, organizer, "Test League "+t.Name(), "R"); err != nil {
+		t.Fatalf("create league: %v", err)
+	}
+	if _, err := tl.svc.CreateSeason(tl.ctx, tl.league.ID, SeasonInput{Name: "2027", StartsOn: "2027-03-01", EndsOn: "2027-06-30"}); err != nil {
+		t.Fatalf("create season: %v", err)
+	}
+	if tl.series, err = tl.svc.CreateSeries(tl.ctx, tl.league.ID, "", "A", "11", settings); err != nil {
+		t.Fatalf("create series: %v", err)
+	}
+	return tl
+}
+
+// register enters the teams in the series, in order, as their club owner.
+func (tl *testLeague) register(teamIDs ...string) []*domain.TeamRegistration {
+	tl.t.Helper()
+	var out []*domain.TeamRegistration
+	for _, id := range teamIDs {
+		reg, err := tl.svc.RegisterTeam(tl.ctx, clubOwner, RegistrationInput{TeamID: id, SeriesID: tl.series.ID})
+		if err != nil {
+			tl.t.Fatalf("register %s: %v", id, err)
+		}
+		out = append(out, reg)
+	}
+	return out
+}
+
+// approve registers the teams and approves them.
+func (tl *testLeague) approve(teamIDs ...string) {
+	tl.t.Helper()
+	for _, reg := range tl.register(teamIDs...) {
+		if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, organizer, reg.ID, RegistrationApproved, ""); err != nil {
+			tl.t.Fatalf("approve %s: %v", reg.TeamID, err)
+		}
+	}
+}
+
+// venue adds a venue with one pitch, open on Saturdays from 09:00 to 18:00.
+func (tl *testLeague) venue() *domain.Venue {
+	tl.t.Helper()
+	v, err := tl.svc.CreateVenue(tl.ctx, tl.league.ID, VenueInput{
+		Name:         "Park",
+		Pitches:      []domain.Pitch{{Number: 1, Surface: "grass"}},
+		Availability: []domain.AvailabilityWindow{{Weekday: 6, Opens: "09:00", Closes: "18:00"}},
+	})
+	if err != nil {
+		tl.t.Fatalf("create venue: %v", err)
+	}
+	return v
+}
+
+// scheduled approves t1 to t4, draws their fixtures and books them at a
+// venue.
+func (tl *testLeague) scheduled(doubleLeg bool) []domain.Match {
+	tl.t.Helper()
+	tl.approve("t1", "t2", "t3", "t4")
+	if _, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, tl.series.ID, doubleLeg); err != nil {
+		tl.t.Fatalf("generate fixtures: %v", err)
+	}
+	tl.venue()
+	seed := int64(1)
+	rep, err := tl.svc.ScheduleSeries(tl.ctx, tl.league.ID, tl.series.ID, ScheduleInput{Seed: &seed})
+	if err != nil {
+		tl.t.Fatalf("schedule: %v", err)
+	}
+	if !rep.Feasible {
+		tl.t.Fatalf("schedule: %+v", rep.Issues)
+	}
+	return tl.matches()
+}
+
+func (tl *testLeague) matches() []domain.Match {
+	tl.t.Helper()
+	matches, err := tl.store.ListMatchesBySeries(tl.ctx, tl.series.ID)
+	if err != nil {
+		tl.t.Fatalf("list matches: %v", err)
+	}
+	return matches
+}
+
+// checkErr fails unless err is of the wanted kind, or nil when want is nil.
+func checkErr(t *testing.T, err, want error) {
+	t.Helper()
+	switch {
+	case want == nil && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != nil && !errors.Is(err, want):
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Knockout
+
+// BracketInput configures the generation of a single-elimination bracket.
+type BracketInput struct {
+	// Seeds lists team IDs from the top seed down. Teams left out are
+	// placed after the seeded ones in registration order.
+	Seeds          []string `json:"seeds"`
+	TwoLegged      bool     `json:"twoLegged"`      // Every round but the final over two legs
+	TwoLeggedFinal bool     `json:"twoLeggedFinal"` // The final over two legs
+}
+
+// GenerateBracket draws a single-elimination bracket for a knockout series.
+// The bracket is sized to the next power of two; the top seeds receive the
+// byes and go straight through to the second round.
+func (s *LeaguesService) GenerateBracket(ctx context.Context, leagueID, seriesID string, in BracketInput) ([]domain.KnockoutTie, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	if ser.Mode != SeriesModeKnockout {
+		return nil, Conflict("brackets are only available for knockout series")
+	}
+	existing, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, Conflict("fixtures already generated")
+	}
+
+	registered, err := s.approvedTeamIDs(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if len(registered) < 2 {
+		return nil, Conflict("at least two approved teams are required")
+	}
+	teams, err := seedOrder(registered, in.Seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	ties := buildBracket(seriesID, teams, len(in.Seeds), in.TwoLegged, in.TwoLeggedFinal)
+	var matches []domain.Match
+	for i := range ties {
+		if ties[i].WinnerTeamID == "" && ties[i].HomeTeamID != "" && ties[i].AwayTeamID != "" {
+			ties[i].Matches = tieMatches(&ties[i])
+			matches = append(matches, ties[i].Matches...)
+		}
+	}
+	if err := s.store.CreateBracket(ctx, ties, matches); err != nil {
+		return nil, err
+	}
+	return ties, nil
+}
+
+// GetBracket returns the ties of every round with their matches and results.
+func (s *LeaguesService) GetBracket(ctx context.Context, leagueID, seriesID string) ([]domain.KnockoutTie, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	ties, err := s.store.ListKnockoutTiesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.loadMatchesWithResults(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	byTie := map[string][]domain.Match{}
+	for _, m := range matches {
+		byTie[m.TieID] = append(byTie[m.TieID], m)
+	}
+	for i := range ties {
+		ties[i].Matches = byTie[ties[i].ID]
+	}
+	return ties, nil
+}
+
+// seedOrder puts the seeded teams first followed by the rest of the
+// registered teams, checking every seed is registered exactly once.
+func seedOrder(registered, seeds []string) ([]string, error) {
+	isRegistered := map[string]bool{}
+	for _, id := range registered {
+		isRegistered[id] = true
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(registered))
+	for _, id := range seeds {
+		if !isRegistered[id] {
+			return nil, Invalid("seeds", "seeded team %s is not approved in the series", id)
+		}
+		if seen[id] {
+			return nil, Invalid("seeds", "team %s is seeded twice", id)
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	for _, id := range registered {
+		if !seen[id] {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// bracketSlots returns the seed placed in each first-round slot of a bracket
+// of the given size, so that seeds 1 and 2 can only meet in the final.
+func bracketSlots(size int) []int {
+	order := []int{1}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// seat is a team's place in a first-round tie; Seed is 0 for unseeded teams.
+type seat struct {
+	TeamID string
+	Seed   int
+}
+
+// buildBracket lays out the ties of every round from teams in seed order; the
+// first seeded of them are shown with their seed number.
+func buildBracket(seriesID string, teams []string, seeded int, twoLegged, twoLeggedFinal bool) []domain.KnockoutTie {
+	size := 2
+	for size < len(teams) {
+		size *= 2
+	}
+	slots := bracketSlots(size)
+	seatOf := func(seed int) seat {
+		switch {
+		case seed > len(teams):
+			return seat{}
+		case seed > seeded:
+			return seat{TeamID: teams[seed-1]}
+		default:
+			return seat{TeamID: teams[seed-1], Seed: seed}
+		}
+	}
+	pairs := make([][2]seat, size/2)
+	for p := range pairs {
+		pairs[p] = [2]seat{seatOf(slots[2*p]), seatOf(slots[2*p+1])}
+	}
+	return bracketFromPairs(seriesID, pairs, twoLegged, twoLeggedFinal)
+}
+
+// bracketFromPairs lays out the ties of every round given the first-round
+// pairings, whose count must be a power of two. A pairing with an empty away
+// seat is a bye: the home team goes straight through to the second round.
+func bracketFromPairs(seriesID string, pairs [][2]seat, twoLegged, twoLeggedFinal bool) []domain.KnockoutTie {
+	size := 2 * len(pairs)
+	rounds := 0
+	for n := size; n > 1; n /= 2 {
+		rounds++
+	}
+
+	var ties []domain.KnockoutTie
+	first := map[int]int{} // index of the first tie of each round
+	for r := 1; r <= rounds; r++ {
+		legs := 1
+		if (r < rounds && twoLegged) || (r == rounds && twoLeggedFinal) {
+			legs = 2
+		}
+		first[r] = len(ties)
+		for p := 0; p < size>>r; p++ {
+			ties = append(ties, domain.KnockoutTie{ID: util.RandID(), SeriesID: seriesID, Round: r, Position: p, Legs: legs})
+		}
+	}
+
+	for p, pair := range pairs {
+		t := &ties[p]
+		t.HomeTeamID, t.HomeSeed = pair[0].TeamID, pair[0].Seed
+		t.AwayTeamID, t.AwaySeed = pair[1].TeamID, pair[1].Seed
+		if t.AwayTeamID == "" {
+			t.WinnerTeamID = t.HomeTeamID
+			placeWinner(&ties[first[2]+p/2], p, t.HomeTeamID)
+		}
+	}
+	return ties
+}
+
+// placeWinner seats the winner of the tie at position p in the tie it feeds.
+func placeWinner(next *domain.KnockoutTie, p int, teamID string) {
+	if p%2 == 0 {
+		next.HomeTeamID = teamID
+	} else {
+		next.AwayTeamID = teamID
+	}
+}
+
+// tieMatches creates the legs of a tie whose two teams are known. The second
+// leg swaps home and away.
+func tieMatches(t *domain.KnockoutTie) []domain.Match {
+	out := make([]domain.Match, 0, t.Legs)
+	for leg := 1; leg <= t.Legs; leg++ {
+		home, away := t.HomeTeamID, t.AwayTeamID
+		if leg == 2 {
+			home, away = away, home
+		}
+		out = append(out, domain.Match{
+			ID:         util.RandID(),
+			SeriesID:   t.SeriesID,
+			StageID:    t.StageID,
+			TieID:      t.ID,
+			Round:      t.Round,
+			Leg:        leg,
+			HomeTeamID: home,
+			AwayTeamID: away,
+			Status:     "scheduled",
+		})
+	}
+	return out
+}
+
+// tieWinner decides a tie from its legs: aggregate goals first, then the
+// penalty shoot-out recorded with the last leg. It returns "" while the tie is
+// still open.
+func tieWinner(t *domain.KnockoutTie, matches []domain.Match) string {
+	if t.HomeTeamID == "" || t.AwayTeamID == "" {
+		if t.Round == 1 {
+			return t.HomeTeamID + t.AwayTeamID
+		}
+		return ""
+	}
+	if len(matches) < t.Legs || !allLegsPlayed(matches) {
+		return ""
+	}
+	home, away := aggregate(t, matches)
+	switch {
+	case home > away:
+		return t.HomeTeamID
+	case home < away:
+		return t.AwayTeamID
+	}
+	last := &matches[0]
+	for i := range matches {
+		if matches[i].Leg > last.Leg {
+			last = &matches[i]
+		}
+	}
+	hp, ap := last.Result.HomePenalties, last.Result.AwayPenalties
+	switch {
+	case hp == nil || ap == nil || *hp == *ap:
+		return ""
+	case *hp > *ap:
+		return last.HomeTeamID
+	default:
+		return last.AwayTeamID
+	}
+}
+
+// loadTieMatches returns the legs of a tie with their results attached.
+func (s *LeaguesService) loadTieMatches(ctx context.Context, tieID string) ([]domain.Match, error) {
+	matches, err := s.store.ListMatchesByTie(ctx, tieID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		r, err := s.store.GetMatchResult(ctx, matches[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		matches[i].Result = r
+	}
+	return matches, nil
+}
+
+// nextTie returns the tie the winner of t advances to, or nil for the final.
+func (s *LeaguesService) nextTie(ctx context.Context, t *domain.KnockoutTie) (*domain.KnockoutTie, error) {
+	return s.store.GetKnockoutTieBySlot(ctx, t.SeriesID, t.Round+1, t.Position/2)
+}
+
+// checkKnockoutResult validates a result about to be stored for a knockout
+// match. Results are frozen once the next round has been played, and a
+// deciding leg that leaves the tie level must carry a penalty shoot-out.
+func (s *LeaguesService) checkKnockoutResult(ctx context.Context, m *domain.Match, res *domain.MatchResult) error {
+	tie, err := s.store.GetKnockoutTieByID(ctx, m.TieID)
+	if err != nil {
+		return err
+	}
+	if tie == nil {
+		return NotFound("knockout tie not found")
+	}
+	if err := s.checkTieEditable(ctx, tie); err != nil {
+		return err
+	}
+	if res == nil {
+		return nil
+	}
+
+	hasPenalties := res.HomePenalties != nil || res.AwayPenalties != nil
+	if m.Leg != tie.Legs {
+		if hasPenalties {
+			return Invalid("homePenalties", "penalties can only be recorded for the last leg of a tie")
+		}
+		return nil
+	}
+
+	if hasPenalties && res.Outcome != "played" {
+		return Invalid("homePenalties", "penalties only apply to played matches")
+	}
+	legs, err := s.loadTieMatches(ctx, tie.ID)
+	if err != nil {
+		return err
+	}
+	for i := range legs {
+		if legs[i].ID == m.ID {
+			legs[i].Result = res
+		}
+	}
+	if !allLegsPlayed(legs) {
+		if hasPenalties {
+			return Conflict("penalties can only be recorded once every leg is played")
+		}
+		return nil
+	}
+
+	home, away := aggregate(tie, legs)
+	hp, ap := res.HomePenalties, res.AwayPenalties
+	switch {
+	case home == away && (hp == nil || ap == nil || *hp == *ap):
+		return Invalid("homePenalties", "penalties are required to decide a level tie")
+	case home != away && hasPenalties:
+		return Invalid("homePenalties", "penalties only apply when the tie is level on aggregate")
+	}
+	return nil
+}
+
+func allLegsPlayed(legs []domain.Match) bool {
+	for _, m := range legs {
+		if !countsForStandings(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// aggregate sums the goals of the tie's home and away team over its legs.
+func aggregate(t *domain.KnockoutTie, legs []domain.Match) (home, away int) {
+	goals := map[string]int{}
+	for _, m := range legs {
+		if m.Result == nil {
+			continue
+		}
+		goals[m.HomeTeamID] += m.Result.HomeScore
+		goals[m.AwayTeamID] += m.Result.AwayScore
+	}
+	return goals[t.HomeTeamID], goals[t.AwayTeamID]
+}
+
+// checkTieEditable refuses changes once the tie fed by t has results.
+func (s *LeaguesService) checkTieEditable(ctx context.Context, t *domain.KnockoutTie) error {
+	next, err := s.nextTie(ctx, t)
+	if err != nil || next == nil {
+		return err
+	}
+	legs, err := s.loadTieMatches(ctx, next.ID)
+	if err != nil {
+		return err
+	}
+	for _, m := range legs {
+		if m.Result != nil {
+			return Conflict("cannot change a knockout result once the next round has results")
+		}
+	}
+	return nil
+}
+
+// settleTie re-evaluates a tie after one of its results changed and moves the
+// winner, or the lack of one, into the next round.
+func (s *LeaguesService) settleTie(ctx context.Context, tieID string) error {
+	tie, err := s.store.GetKnockoutTieByID(ctx, tieID)
+	if err != nil || tie == nil {
+		return err
+	}
+	legs, err := s.loadTieMatches(ctx, tie.ID)
+	if err != nil {
+		return err
+	}
+	winner := tieWinner(tie, legs)
+	if winner == tie.WinnerTeamID {
+		return nil
+	}
+	tie.WinnerTeamID = winner
+
+	next, err := s.nextTie(ctx, tie)
+	if err != nil {
+		return err
+	}
+	var nextMatches []domain.Match
+	if next != nil {
+		placeWinner(next, tie.Position, winner)
+		if next.HomeTeamID != "" && next.AwayTeamID != "" {
+			nextMatches = tieMatches(next)
+		}
+	}
+	return s.store.AdvanceKnockoutTie(ctx, tie, next, nextMatches)
+}
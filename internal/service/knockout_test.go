@@ -0,0 +1,149 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestBracketSlots(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{1, []int{1}},
+		{2, []int{1, 2}},
+		{4, []int{1, 4, 2, 3}},
+		{8, []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+	for _, tt := range tests {
+		if got := bracketSlots(tt.size); !slices.Equal(got, tt.want) {
+			t.Errorf("bracketSlots(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+// tieAt returns the tie of the bracket in the round and position.
+func tieAt(t *testing.T, ties []domain.KnockoutTie, round, position int) domain.KnockoutTie {
+	t.Helper()
+	i := slices.IndexFunc(ties, func(x domain.KnockoutTie) bool { return x.Round == round && x.Position == position })
+	if i < 0 {
+		t.Fatalf("no tie in round %d at %d", round, position)
+	}
+	return ties[i]
+}
+
+func TestAdvanceKnockoutTie(t *testing.T) {
+	tests := []struct {
+		name      string
+		twoLegged bool
+		results   []ResultInput // Of the legs of the first semi-final, in order
+		err       error
+		winner    string // Of the first semi-final; seeds 1 and 4 meet there
+	}{
+		{name: "home win", results: []ResultInput{{Outcome: "played", HomeScore: 2, AwayScore: 1}}, winner: "t1"},
+		{name: "away win", results: []ResultInput{{Outcome: "played", HomeScore: 0, AwayScore: 1}}, winner: "t4"},
+		{name: "walkover", results: []ResultInput{{Outcome: "walkover", WalkoverWinnerID: "t4"}}, winner: "t4"},
+		{name: "level without penalties", results: []ResultInput{{Outcome: "played", HomeScore: 1, AwayScore: 1}}, err: ErrValidation},
+		{
+			name:    "level on penalties",
+			results: []ResultInput{{Outcome: "played", HomeScore: 1, AwayScore: 1, HomePenalties: ptr(3), AwayPenalties: ptr(4)}},
+			winner:  "t4",
+		},
+		{
+			name:      "first leg decides nothing",
+			twoLegged: true,
+			results:   []ResultInput{{Outcome: "played", HomeScore: 3, AwayScore: 0}},
+		},
+		{
+			name:      "aggregate",
+			twoLegged: true,
+			results:   []ResultInput{{Outcome: "played", HomeScore: 3, AwayScore: 0}, {Outcome: "played", HomeScore: 2, AwayScore: 0}},
+			winner:    "t1",
+		},
+		{
+			name:      "penalties on the first leg",
+			twoLegged: true,
+			results:   []ResultInput{{Outcome: "played", HomeScore: 1, AwayScore: 1, HomePenalties: ptr(3), AwayPenalties: ptr(4)}},
+			err:       ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{Mode: ptr(SeriesModeKnockout)})
+			tl.approve("t1", "t2", "t3", "t4")
+			ties, err := tl.svc.GenerateBracket(tl.ctx, tl.league.ID, tl.series.ID, BracketInput{Seeds: []string{"t1", "t2", "t3", "t4"}, TwoLegged: tt.twoLegged})
+			if err != nil {
+				t.Fatalf("generate bracket: %v", err)
+			}
+			semi := tieAt(t, ties, 1, 0)
+			if semi.HomeTeamID != "t1" || semi.AwayTeamID != "t4" {
+				t.Fatalf("first semi-final is %s v %s", semi.HomeTeamID, semi.AwayTeamID)
+			}
+
+			for i, in := range tt.results {
+				_, err = tl.svc.RecordResult(tl.ctx, organizer, tl.league.ID, tl.series.ID, semi.Matches[i].ID, in)
+				if err != nil {
+					break
+				}
+			}
+			checkErr(t, err, tt.err)
+
+			ties, err = tl.svc.GetBracket(tl.ctx, tl.league.ID, tl.series.ID)
+			if err != nil {
+				t.Fatalf("get bracket: %v", err)
+			}
+			if got := tieAt(t, ties, 1, 0).WinnerTeamID; got != tt.winner {
+				t.Fatalf("semi-final won by %q, want %q", got, tt.winner)
+			}
+			final := tieAt(t, ties, 2, 0)
+			if final.HomeTeamID != tt.winner || final.AwayTeamID != "" || len(final.Matches) != 0 {
+				t.Fatalf("final is %q v %q with %d matches", final.HomeTeamID, final.AwayTeamID, len(final.Matches))
+			}
+		})
+	}
+}
+
+func TestAdvanceKnockoutTieToFinal(t *testing.T) {
+	tl := newTestLeague(t, SeriesSettings{Mode: ptr(SeriesModeKnockout)})
+	tl.approve("t1", "t2", "t3")
+	ties, err := tl.svc.GenerateBracket(tl.ctx, tl.league.ID, tl.series.ID, BracketInput{Seeds: []string{"t1", "t2", "t3"}})
+	if err != nil {
+		t.Fatalf("generate bracket: %v", err)
+	}
+	// The top seed has the bye and waits in the final.
+	if bye := tieAt(t, ties, 1, 0); bye.WinnerTeamID != "t1" || len(bye.Matches) != 0 {
+		t.Fatalf("bye tie %+v", bye)
+	}
+	semi := tieAt(t, ties, 1, 1)
+	record := func(matchID string, hs, as int) error {
+		_, err := tl.svc.RecordResult(tl.ctx, organizer, tl.league.ID, tl.series.ID, matchID, ResultInput{Outcome: "played", HomeScore: hs, AwayScore: as})
+		return err
+	}
+	if err := record(semi.Matches[0].ID, 0, 2); err != nil {
+		t.Fatalf("record semi-final: %v", err)
+	}
+
+	ties, err = tl.svc.GetBracket(tl.ctx, tl.league.ID, tl.series.ID)
+	if err != nil {
+		t.Fatalf("get bracket: %v", err)
+	}
+	final := tieAt(t, ties, 2, 0)
+	if final.HomeTeamID != "t1" || final.AwayTeamID != semi.AwayTeamID || len(final.Matches) != 1 {
+		t.Fatalf("final is %q v %q with %d matches, want t1 v %s", final.HomeTeamID, final.AwayTeamID, len(final.Matches), semi.AwayTeamID)
+	}
+
+	// The semi-final is frozen once the final has a result.
+	if err := record(final.Matches[0].ID, 1, 0); err != nil {
+		t.Fatalf("record final: %v", err)
+	}
+	checkErr(t, record(semi.Matches[0].ID, 3, 0), ErrConflict)
+	ties, err = tl.svc.GetBracket(tl.ctx, tl.league.ID, tl.series.ID)
+	if err != nil {
+		t.Fatalf("get bracket: %v", err)
+	}
+	if got := tieAt(t, ties, 2, 0).WinnerTeamID; got != "t1" {
+		t.Fatalf("final won by %q, want t1", got)
+	}
+}
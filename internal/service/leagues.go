@@ -2,8 +2,9 @@ package service
 
 import (
 	"context"
-	"errors"
+	"slices"
 	"strings"
+	"time"
 
 	"team-manager-leagues/internal/domain"
 	"team-manager-leagues/internal/repository"
@@ -11,11 +12,12 @@ import (
 )
 
 type LeaguesService struct {
-	store *repository.Store
+	store repository.Repository
+	live  *liveHub
 }
 
-func NewLeaguesService(store *repository.Store) *LeaguesService {
-	return &LeaguesService{store: store}
+func NewLeaguesService(store repository.Repository) *LeaguesService {
+	return &LeaguesService{store: store, live: newLiveHub()}
 }
 
 // Leagues
@@ -23,7 +25,7 @@ func NewLeaguesService(store *repository.Store) *LeaguesService {
 func (s *LeaguesService) CreateLeague(ctx context.Context, userID, name, region string) (*domain.League, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return nil, errors.New("invalid name")
+		return nil, Invalid("name", "invalid name")
 	}
 	slug := util.Slugify(name)
 
@@ -34,8 +36,25 @@ func (s *LeaguesService) CreateLeague(ctx context.Context, userID, name, region
 	return l, nil
 }
 
-func (s *LeaguesService) ListLeagues(ctx context.Context) ([]domain.League, error) {
-	return s.store.ListLeagues(ctx)
+// LeagueQuery filters and pages the league listing.
+type LeagueQuery struct {
+	Region     string `form:"region"`
+	NamePrefix string `form:"name"` // Case-insensitive
+	ListOptions
+}
+
+// ListLeagues returns a page of leagues and the cursor of the next one.
+func (s *LeaguesService) ListLeagues(ctx context.Context, q LeagueQuery) ([]domain.League, string, error) {
+	pg, err := q.pager("createdAt", "name")
+	if err != nil {
+		return nil, "", err
+	}
+	list, err := s.store.ListLeagues(ctx, repository.LeagueFilter{Region: q.Region, NamePrefix: q.NamePrefix}, pg.Page)
+	if err != nil {
+		return nil, "", err
+	}
+	list, next := cut(list, pg, func(l *domain.League) (string, string, time.Time) { return l.ID, l.Name, l.CreatedAt })
+	return list, next, nil
 }
 
 func (s *LeaguesService) GetLeague(ctx context.Context, id string) (*domain.League, error) {
@@ -43,22 +62,20 @@ func (s *LeaguesService) GetLeague(ctx context.Context, id string) (*domain.Leag
 }
 
 func (s *LeaguesService) UpdateLeague(ctx context.Context, id, name, region string) (*domain.League, error) {
-	// TODO: Check permission (e.g. admin or creator). For now MVP allows update if authenticated?
-	// The handler didn't implement UpdateLeague, only Create/List/Get.
-	// But store has UpdateLeague.
-	// I'll implement it assuming creator check or similar if I had the logic.
-	// For now, let's just implement it.
-
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return nil, errors.New("invalid name")
+		return nil, Invalid("name", "invalid name")
 	}
 	slug := util.Slugify(name)
 
-	if err := s.store.UpdateLeague(ctx, id, name, slug, region); err != nil {
+	found, err := s.store.UpdateLeague(ctx, id, name, slug, region)
+	if err != nil {
 		return nil, err
 	}
-	return &domain.League{ID: id, Name: name, Slug: slug, Region: region}, nil
+	if !found {
+		return nil, NotFound("league not found")
+	}
+	return s.store.GetLeagueByID(ctx, id)
 }
 
 func (s *LeaguesService) DeleteLeague(ctx context.Context, id string) error {
@@ -67,46 +84,305 @@ func (s *LeaguesService) DeleteLeague(ctx context.Context, id string) error {
 
 // Series
 
-func (s *LeaguesService) CreateSeries(ctx context.Context, leagueID, name, format string) (*domain.Series, error) {
+const (
+	SeriesModeLeague         = "league"
+	SeriesModeKnockout       = "knockout"
+	SeriesModeGroupsKnockout = "groups_knockout"
+)
+
+// SeriesSettings carries the optional configuration of a series. Nil fields
+// keep the current value, or the default for a new series.
+type SeriesSettings struct {
+	PointsWin   *int     `json:"pointsWin"`
+	PointsDraw  *int     `json:"pointsDraw"`
+	PointsLoss  *int     `json:"pointsLoss"`
+	Tiebreakers []string `json:"tiebreakers"`
+	LotsSeed    *int64   `json:"lotsSeed"`
+	Mode        *string  `json:"mode"`
+	// MaxTeams caps the registrations holding a spot; 0 removes the limit.
+	MaxTeams             *int       `json:"maxTeams"`
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt"`
+	AgeCategory          *string    `json:"ageCategory"`
+	GenderCategory       *string    `json:"genderCategory"`
+	EligibilityPolicy    *string    `json:"eligibilityPolicy"`
+	// Division ranks the series among its season's divisions; 0 removes it.
+	Division *int `json:"division"`
+	// MatchMinutes is how long a match holds its pitch.
+	MatchMinutes *int `json:"matchMinutes"`
+	// MaxRosterSize caps the players per roster; 0 removes the limit.
+	MaxRosterSize *int       `json:"maxRosterSize"`
+	RosterLocksAt *time.Time `json:"rosterLocksAt"`
+	// DisciplineRules replaces the suspension rules; an empty list hands out
+	// no automatic suspensions.
+	DisciplineRules []domain.SuspensionRule `json:"disciplineRules"`
+	// MinRefereeLevel is the lowest level of the officials assigned to its
+	// matches; 0 removes it.
+	MinRefereeLevel *int `json:"minRefereeLevel"`
+}
+
+func (p SeriesSettings) apply(ser *domain.Series) error {
+	if p.PointsWin != nil {
+		ser.PointsWin = *p.PointsWin
+	}
+	if p.PointsDraw != nil {
+		ser.PointsDraw = *p.PointsDraw
+	}
+	if p.PointsLoss != nil {
+		ser.PointsLoss = *p.PointsLoss
+	}
+	if ser.PointsWin < ser.PointsDraw || ser.PointsDraw < ser.PointsLoss {
+		return Invalid("", "invalid points: a win must be worth at least a draw, and a draw at least a loss")
+	}
+
+	if p.Tiebreakers != nil {
+		if err := validateTiebreakers(p.Tiebreakers); err != nil {
+			return err
+		}
+		ser.Tiebreakers = p.Tiebreakers
+	}
+	if p.LotsSeed != nil {
+		ser.LotsSeed = p.LotsSeed
+	}
+	if p.Mode != nil {
+		switch *p.Mode {
+		case SeriesModeLeague, SeriesModeKnockout, SeriesModeGroupsKnockout:
+		default:
+			return Invalid("mode", "invalid mode")
+		}
+		ser.Mode = *p.Mode
+	}
+	if p.MaxTeams != nil {
+		switch {
+		case *p.MaxTeams < 0:
+			return Invalid("maxTeams", "invalid max teams")
+		case *p.MaxTeams == 0:
+			ser.MaxTeams = nil
+		default:
+			ser.MaxTeams = p.MaxTeams
+		}
+	}
+	if p.RegistrationOpensAt != nil {
+		ser.RegistrationOpensAt = p.RegistrationOpensAt
+	}
+	if p.RegistrationClosesAt != nil {
+		ser.RegistrationClosesAt = p.RegistrationClosesAt
+	}
+	if ser.RegistrationOpensAt != nil && ser.RegistrationClosesAt != nil && !ser.RegistrationClosesAt.After(*ser.RegistrationOpensAt) {
+		return Invalid("registrationClosesAt", "registration must close after it opens")
+	}
+	if p.AgeCategory != nil {
+		if !validAgeCategory(*p.AgeCategory) {
+			return Invalid("ageCategory", "invalid age category: use \"U<age>\" or \"open\"")
+		}
+		ser.AgeCategory = *p.AgeCategory
+	}
+	if p.GenderCategory != nil {
+		if !validGenderCategory(*p.GenderCategory) {
+			return Invalid("genderCategory", "invalid gender category")
+		}
+		ser.GenderCategory = *p.GenderCategory
+	}
+	if p.EligibilityPolicy != nil {
+		if *p.EligibilityPolicy != EligibilityReject && *p.EligibilityPolicy != EligibilityFlag {
+			return Invalid("eligibilityPolicy", "invalid eligibility policy")
+		}
+		ser.EligibilityPolicy = *p.EligibilityPolicy
+	}
+	if p.Division != nil {
+		switch {
+		case *p.Division < 0:
+			return Invalid("division", "invalid division")
+		case *p.Division == 0:
+			ser.Division = nil
+		default:
+			ser.Division = p.Division
+		}
+	}
+	if p.MatchMinutes != nil {
+		if *p.MatchMinutes <= 0 || *p.MatchMinutes > 24*60 {
+			return Invalid("matchMinutes", "match minutes must be between 1 and 1440")
+		}
+		ser.MatchMinutes = *p.MatchMinutes
+	}
+	if p.MaxRosterSize != nil {
+		switch {
+		case *p.MaxRosterSize < 0:
+			return Invalid("maxRosterSize", "invalid max roster size")
+		case *p.MaxRosterSize == 0:
+			ser.MaxRosterSize = nil
+		default:
+			ser.MaxRosterSize = p.MaxRosterSize
+		}
+	}
+	if p.RosterLocksAt != nil {
+		ser.RosterLocksAt = p.RosterLocksAt
+	}
+	if p.DisciplineRules != nil {
+		if err := validateDisciplineRules(p.DisciplineRules); err != nil {
+			return err
+		}
+		ser.DisciplineRules = p.DisciplineRules
+	}
+	if p.MinRefereeLevel != nil {
+		if *p.MinRefereeLevel < 0 {
+			return Invalid("minRefereeLevel", "invalid minimum referee level")
+		}
+		ser.MinRefereeLevel = *p.MinRefereeLevel
+	}
+
+	// Record the seed up front so drawing of lots is reproducible.
+	if ser.LotsSeed == nil && slices.Contains(ser.Tiebreakers, TiebreakLots) {
+		seed := randomSeed()
+		ser.LotsSeed = &seed
+	}
+	return nil
+}
+
+// CreateSeries adds a series to a season of the league, by default the
+// current one. Finished seasons take no new series.
+func (s *LeaguesService) CreateSeries(ctx context.Context, leagueID, seasonID, name, format string, settings SeriesSettings) (*domain.Series, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return nil, errors.New("invalid name")
+		return nil, Invalid("name", "invalid name")
+	}
+	var season *domain.Season
+	var err error
+	if seasonID == "" {
+		season, err = s.currentSeason(ctx, leagueID)
+	} else {
+		season, err = s.GetSeason(ctx, leagueID, seasonID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if season.Status == SeasonFinished {
+		return nil, Conflict("season %s is finished", season.Name)
 	}
 
-	ser := &domain.Series{ID: util.RandID(), LeagueID: leagueID, Name: name, Format: format}
+	ser := &domain.Series{
+		ID:          util.RandID(),
+		LeagueID:    leagueID,
+		SeasonID:    season.ID,
+		Name:        name,
+		Format:      format,
+		PointsWin:   3,
+		PointsDraw:  1,
+		PointsLoss:  0,
+		Tiebreakers: append([]string(nil), defaultTiebreakers...),
+		Mode:        SeriesModeLeague,
+		// Booking length of a match unless the series sets its own
+		MatchMinutes: 90,
+		// Ineligible teams are turned away unless the series opts to flag them
+		EligibilityPolicy: EligibilityReject,
+		DisciplineRules:   slices.Clone(defaultDisciplineRules),
+	}
+	if err := settings.apply(ser); err != nil {
+		return nil, err
+	}
 	if err := s.store.CreateSeries(ctx, ser); err != nil {
 		return nil, err
 	}
 	return ser, nil
 }
 
-func (s *LeaguesService) ListSeries(ctx context.Context, leagueID string) ([]domain.Series, error) {
-	return s.store.ListSeriesByLeague(ctx, leagueID)
+// SeriesQuery filters and pages the series of a league.
+type SeriesQuery struct {
+	SeasonID string `form:"seasonId"`
+	Format   string `form:"format"`
+	ListOptions
+}
+
+// ListSeries returns a page of a league's series and the cursor of the next one.
+func (s *LeaguesService) ListSeries(ctx context.Context, leagueID string, q SeriesQuery) ([]domain.Series, string, error) {
+	pg, err := q.pager("createdAt", "name")
+	if err != nil {
+		return nil, "", err
+	}
+	list, err := s.store.ListSeries(ctx, repository.SeriesFilter{LeagueID: leagueID, SeasonID: q.SeasonID, Format: q.Format}, pg.Page)
+	if err != nil {
+		return nil, "", err
+	}
+	list, next := cut(list, pg, func(ser *domain.Series) (string, string, time.Time) { return ser.ID, ser.Name, ser.CreatedAt })
+	return list, next, nil
 }
 
 func (s *LeaguesService) GetSeries(ctx context.Context, id string) (*domain.Series, error) {
 	return s.store.GetSeriesByID(ctx, id)
 }
 
-func (s *LeaguesService) UpdateSeries(ctx context.Context, id, name, format string) error {
+func (s *LeaguesService) UpdateSeries(ctx context.Context, userID, leagueID, id, name, format string, settings SeriesSettings) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return errors.New("invalid name")
+		return Invalid("name", "invalid name")
+	}
+	ser, err := s.store.GetSeriesByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return NotFound("series not found")
+	}
+	mode := ser.Mode
+	rules := ser.DisciplineRules
+	ser.Name = name
+	ser.Format = format
+	if err := settings.apply(ser); err != nil {
+		return err
+	}
+	if ser.Mode != mode {
+		matches, err := s.store.ListMatchesBySeries(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(matches) > 0 {
+			return Conflict("cannot change the mode of a series with fixtures")
+		}
+	}
+	if err := s.store.UpdateSeries(ctx, ser); err != nil {
+		return err
 	}
-	return s.store.UpdateSeries(ctx, id, name, format)
+	if !slices.Equal(rules, ser.DisciplineRules) {
+		if err := s.resyncSuspensions(ctx, ser); err != nil {
+			return err
+		}
+	}
+	// A raised or removed limit frees spots for the waitlist.
+	_, err = s.promoteWaitlisted(ctx, userID, id)
+	return err
 }
 
-func (s *LeaguesService) DeleteSeries(ctx context.Context, id string) error {
+func (s *LeaguesService) DeleteSeries(ctx context.Context, leagueID, id string) error {
+	ser, err := s.store.GetSeriesByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return NotFound("series not found")
+	}
 	return s.store.DeleteSeries(ctx, id)
 }
 
 // Registrations
 
-func (s *LeaguesService) RegisterTeam(ctx context.Context, userID, teamID, seriesID string) (*domain.TeamRegistration, error) {
+// RegistrationInput is a club's entry of a team into a series. The age and
+// gender categories are declared by the club and checked against the series.
+type RegistrationInput struct {
+	TeamID         string `json:"teamId"`
+	SeriesID       string `json:"seriesId"`
+	AgeCategory    string `json:"ageCategory"`
+	GenderCategory string `json:"genderCategory"`
+}
+
+func (s *LeaguesService) RegisterTeam(ctx context.Context, userID string, in RegistrationInput) (*domain.TeamRegistration, error) {
+	teamID, seriesID := in.TeamID, in.SeriesID
 	// Verify team exists
 	t, err := s.store.GetTeamByID(ctx, teamID)
-	if err != nil || t == nil {
-		return nil, errors.New("team not found")
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, NotFound("team not found")
 	}
 
 	// Verify ownership
@@ -115,38 +391,151 @@ func (s *LeaguesService) RegisterTeam(ctx context.Context, userID, teamID, serie
 		return nil, err
 	}
 	if !isOwner {
-		return nil, errors.New("forbidden: only club owner can register teams")
+		return nil, Forbidden("only club owner can register teams")
 	}
 
-	// Create registration
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil {
+		return nil, NotFound("series not found")
+	}
+	season, err := s.store.GetSeasonByID(ctx, ser.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season != nil && season.Status == SeasonFinished {
+		return nil, Conflict("season %s is finished", season.Name)
+	}
+	now := time.Now()
+	if ser.RegistrationOpensAt != nil && now.Before(*ser.RegistrationOpensAt) {
+		return nil, Conflict("registration for this series has not opened yet")
+	}
+	if ser.RegistrationClosesAt != nil && !now.Before(*ser.RegistrationClosesAt) {
+		return nil, Conflict("registration for this series is closed")
+	}
+
+	if !validAgeCategory(in.AgeCategory) {
+		return nil, Invalid("ageCategory", "invalid age category")
+	}
+	if !validGenderCategory(in.GenderCategory) {
+		return nil, Invalid("genderCategory", "invalid gender category")
+	}
+
+	// Create registration, awaiting the organizers' approval. The store
+	// waitlists it instead when the series is full.
 	reg := &domain.TeamRegistration{
-		ID:       util.RandID(),
-		TeamID:   teamID,
-		SeriesID: seriesID,
-		Status:   "active",
+		ID:             util.RandID(),
+		TeamID:         teamID,
+		SeriesID:       seriesID,
+		Status:         RegistrationPending,
+		AgeCategory:    in.AgeCategory,
+		GenderCategory: in.GenderCategory,
 	}
-	if err := s.store.CreateTeamRegistration(ctx, reg); err != nil {
+
+	// Check eligibility; failed rules either turn the team away or are
+	// flagged on the registration for the organizers to review.
+	overrides, err := s.store.ListTeamEligibilityOverrides(ctx, seriesID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	reg.EligibilityIssues = evaluateEligibility(ser, t, reg, overrides)
+	if open := unresolvedIssues(reg.EligibilityIssues); len(open) > 0 && ser.EligibilityPolicy != EligibilityFlag {
+		return nil, ineligible(open)
+	}
+	ev := &domain.RegistrationEvent{ID: util.RandID(), RegistrationID: reg.ID, ToStatus: reg.Status, ActorID: userID}
+	if err := s.store.CreateTeamRegistration(ctx, reg, ev); err != nil {
 		return nil, err
 	}
 	return reg, nil
 }
 
-func (s *LeaguesService) ListRegistrationsByTeam(ctx context.Context, teamID string) ([]domain.TeamRegistration, error) {
-	return s.store.ListRegistrationsByTeam(ctx, teamID)
+// RegistrationQuery filters and pages registrations. A team or a series is
+// required; Status is a comma-separated list of statuses.
+type RegistrationQuery struct {
+	TeamID      string    `form:"teamId"`
+	SeriesID    string    `form:"seriesId"`
+	Status      string    `form:"status"`
+	CreatedFrom time.Time `form:"createdFrom"` // Inclusive
+	CreatedTo   time.Time `form:"createdTo"`   // Exclusive
+	ListOptions
 }
 
-func (s *LeaguesService) ListRegistrationsBySeries(ctx context.Context, seriesID string) ([]domain.TeamRegistration, error) {
-	return s.store.ListRegistrationsBySeries(ctx, seriesID)
+// ListRegistrations returns a page of registrations and the cursor of the
+// next one. A series' registrations are listed to those who may view its
+// league; a team's in every series only to the owners of its club.
+func (s *LeaguesService) ListRegistrations(ctx context.Context, userID string, q RegistrationQuery) ([]domain.TeamRegistration, string, error) {
+	switch {
+	case q.SeriesID != "":
+		ser, err := s.store.GetSeriesByID(ctx, q.SeriesID)
+		if err != nil {
+			return nil, "", err
+		}
+		if ser == nil {
+			return nil, "", NotFound("series not found")
+		}
+		ok, err := s.can(ctx, userID, ser.LeagueID, PermViewLeague)
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			return nil, "", Forbidden("%s requires a league role or a team in the league", PermViewLeague)
+		}
+	case q.TeamID != "":
+		t, err := s.store.GetTeamByID(ctx, q.TeamID)
+		if err != nil {
+			return nil, "", err
+		}
+		if t == nil {
+			return nil, "", NotFound("team not found")
+		}
+		isOwner, err := s.store.IsOwner(ctx, userID, t.ClubID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !isOwner {
+			return nil, "", Forbidden("only the club owner can list a team's registrations")
+		}
+	default:
+		return nil, "", Invalid("", "teamId or seriesId required")
+	}
+	f := repository.RegistrationFilter{TeamID: q.TeamID, SeriesID: q.SeriesID, CreatedFrom: q.CreatedFrom, CreatedTo: q.CreatedTo}
+	if q.Status != "" {
+		for _, st := range strings.Split(q.Status, ",") {
+			st = strings.TrimSpace(st)
+			if _, ok := registrationTransitions[st]; !ok && st != RegistrationArchived {
+				return nil, "", Invalid("status", "invalid status %q", st)
+			}
+			f.Statuses = append(f.Statuses, st)
+		}
+	}
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && !q.CreatedTo.After(q.CreatedFrom) {
+		return nil, "", Invalid("createdTo", "createdTo must be after createdFrom")
+	}
+	pg, err := q.pager("createdAt")
+	if err != nil {
+		return nil, "", err
+	}
+	list, err := s.store.ListRegistrations(ctx, f, pg.Page)
+	if err != nil {
+		return nil, "", err
+	}
+	list, next := cut(list, pg, func(tr *domain.TeamRegistration) (string, string, time.Time) { return tr.ID, "", tr.CreatedAt })
+	return list, next, nil
 }
 
-func (s *LeaguesService) UpdateRegistrationStatus(ctx context.Context, id, status string) error {
+// UpdateRegistrationStatus moves a registration through the approval
+// workflow on behalf of userID, recording who made the change and why.
+func (s *LeaguesService) UpdateRegistrationStatus(ctx context.Context, userID, id, status, reason string) (*domain.TeamRegistration, error) {
 	status = strings.TrimSpace(status)
-	if status == "" {
-		return errors.New("invalid status")
+	if !slices.Contains(registrationStatuses, status) {
+		return nil, Invalid("status", "invalid status %q", status)
 	}
-	return s.store.UpdateRegistrationStatus(ctx, id, status)
+	return s.transitionRegistration(ctx, userID, id, status, strings.TrimSpace(reason))
 }
 
-func (s *LeaguesService) DeleteRegistration(ctx context.Context, id string) error {
-	return s.store.DeleteRegistration(ctx, id)
+// WithdrawRegistration pulls a team out of a series.
+func (s *LeaguesService) WithdrawRegistration(ctx context.Context, userID, id, reason string) (*domain.TeamRegistration, error) {
+	return s.transitionRegistration(ctx, userID, id, RegistrationWithdrawn, strings.TrimSpace(reason))
 }
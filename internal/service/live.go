@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Live match events
+
+const (
+	EventPeriodStart  = "period_start"
+	EventPeriodEnd    = "period_end"
+	EventGoal         = "goal"
+	EventCard         = "card"
+	EventSubstitution = "substitution"
+	EventRetraction   = "retraction" // Withdraws an event posted by mistake
+)
+
+// States of a live match.
+const (
+	LiveNotStarted = "not_started"
+	LiveInPlay     = "in_play"
+	LiveInterval   = "interval"
+	LiveFinished   = "finished"
+)
+
+// liveHub wakes the streams following a match when an event is posted
+// through this instance. Streams also poll on their heartbeat, which picks
+// up events posted through other instances.
+type liveHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{subs: map[string]map[chan struct{}]struct{}{}}
+}
+
+func (h *liveHub) subscribe(matchID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[matchID] == nil {
+		h.subs[matchID] = map[chan struct{}]struct{}{}
+	}
+	h.subs[matchID][ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[matchID], ch)
+		if len(h.subs[matchID]) == 0 {
+			delete(h.subs, matchID)
+		}
+	}
+}
+
+// notify wakes the subscribers of the match without blocking; a subscriber
+// already due to wake up misses nothing, as it reads every event it has not
+// seen.
+func (h *liveHub) notify(matchID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[matchID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WatchMatch returns a channel that receives a value when an event is
+// posted for the match, and a function to stop watching.
+func (s *LeaguesService) WatchMatch(matchID string) (<-chan struct{}, func()) {
+	return s.live.subscribe(matchID)
+}
+
+// liveState works out the state of a match from its events. Each event
+// carries the score after it, so the last one holds the current score.
+func liveState(m *domain.Match, events []domain.MatchEvent) *domain.LiveMatch {
+	live := &domain.LiveMatch{MatchID: m.ID, HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID, State: LiveNotStarted}
+	for _, e := range events {
+		live.LastSeq = e.Seq
+		live.HomeScore, live.AwayScore = e.HomeScore, e.AwayScore
+		switch e.Type {
+		case EventPeriodStart:
+			live.State, live.Period = LiveInPlay, e.Period
+		case EventPeriodEnd:
+			live.State = LiveInterval
+			if e.Final {
+				live.State = LiveFinished
+			}
+		}
+	}
+	return live
+}
+
+// liveCards returns the cards posted during the match and not retracted,
+// as they are recorded for discipline at the final whistle.
+func liveCards(events []domain.MatchEvent) []domain.Card {
+	retracted := map[int64]bool{}
+	for _, e := range events {
+		if e.Type == EventRetraction {
+			retracted[e.Retracts] = true
+		}
+	}
+	var cards []domain.Card
+	for _, e := range events {
+		if e.Type == EventCard && !retracted[e.Seq] {
+			cards = append(cards, domain.Card{
+				MatchID:    e.MatchID,
+				TeamID:     e.TeamID,
+				PlayerID:   e.PlayerID,
+				Type:       e.Card,
+				Minute:     e.Minute,
+				Reason:     e.Note,
+				RecordedBy: e.RecordedBy,
+			})
+		}
+	}
+	return cards
+}
+
+// creditsHome reports whether a goal counts for the home team; own goals
+// count for the other team.
+func creditsHome(m *domain.Match, e *domain.MatchEvent) bool {
+	return (e.TeamID == m.HomeTeamID) != e.OwnGoal
+}
+
+// officiatedMatch loads a match with its series and checks that the user
+// may post its events: a referee assigned to the match, or anyone managing
+// the league's results.
+func (s *LeaguesService) officiatedMatch(ctx context.Context, userID, matchID string) (*domain.Match, *domain.Series, error) {
+	m, ser, err := s.liveMatch(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ok, err := s.officiates(ctx, userID, m.ID)
+	if err == nil && !ok {
+		ok, err = s.can(ctx, userID, ser.LeagueID, PermManageResults)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, Forbidden("only match officials can post match events")
+	}
+	return m, ser, nil
+}
+
+func (s *LeaguesService) liveMatch(ctx context.Context, matchID string) (*domain.Match, *domain.Series, error) {
+	m, err := s.store.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m == nil {
+		return nil, nil, NotFound("match not found")
+	}
+	ser, err := s.store.GetSeriesByID(ctx, m.SeriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ser == nil {
+		return nil, nil, NotFound("match not found")
+	}
+	return m, ser, nil
+}
+
+// MatchTimeline returns the live state of a match and its events after
+// afterSeq to those who may view the match's league.
+func (s *LeaguesService) MatchTimeline(ctx context.Context, userID, matchID string, afterSeq int64) (*domain.LiveMatch, []domain.MatchEvent, error) {
+	m, ser, err := s.liveMatch(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ok, err := s.can(ctx, userID, ser.LeagueID, PermViewLeague)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, Forbidden("%s requires a league role or a team in the league", PermViewLeague)
+	}
+	events, err := s.store.ListMatchEvents(ctx, m.ID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	live := liveState(m, events)
+	for len(events) > 0 && events[0].Seq <= afterSeq {
+		events = events[1:]
+	}
+	return live, events, nil
+}
+
+// MatchEventInput is an event posted by a match official.
+type MatchEventInput struct {
+	Type          string `json:"type" binding:"required"`
+	Minute        int    `json:"minute"`
+	TeamID        string `json:"teamId"`
+	PlayerID      string `json:"playerId"`
+	PlayerOffID   string `json:"playerOffId"`
+	Card          string `json:"card"`
+	OwnGoal       bool   `json:"ownGoal"`
+	Final         bool   `json:"final"`
+	HomePenalties *int   `json:"homePenalties"`
+	AwayPenalties *int   `json:"awayPenalties"`
+	Note          string `json:"note"`
+}
+
+// PostMatchEvent appends an event to a scheduled match. Periods are started
+// and ended in turn; goals are posted while a period is in play, cards and
+// substitutions from kickoff on. The final whistle, a period_end marked
+// final, records the score of the events as the match result, and the cards
+// still standing as the match's discipline cards.
+func (s *LeaguesService) PostMatchEvent(ctx context.Context, userID, matchID string, in MatchEventInput) (*domain.MatchEvent, error) {
+	m, ser, err := s.officiatedMatch(ctx, userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.store.ListMatchEvents(ctx, m.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	live := liveState(m, events)
+	if live.State == LiveFinished {
+		return nil, Conflict("the match has finished; correct its result instead")
+	}
+	if m.Status != "scheduled" {
+		return nil, Conflict("the match is %s; live events are posted while it is scheduled", m.Status)
+	}
+	if in.Minute < 0 || in.Minute > 150 {
+		return nil, Invalid("minute", "minute must be between 0 and 150")
+	}
+	if (in.HomePenalties != nil || in.AwayPenalties != nil) && !(in.Type == EventPeriodEnd && in.Final) {
+		return nil, Invalid("", "penalties are given with the final whistle")
+	}
+
+	e := &domain.MatchEvent{
+		MatchID:    m.ID,
+		Seq:        live.LastSeq + 1,
+		Type:       in.Type,
+		Period:     live.Period,
+		Minute:     in.Minute,
+		Note:       strings.TrimSpace(in.Note),
+		HomeScore:  live.HomeScore,
+		AwayScore:  live.AwayScore,
+		RecordedBy: userID,
+	}
+	var res *domain.MatchResult
+	switch in.Type {
+	case EventPeriodStart:
+		if live.State == LiveInPlay {
+			return nil, Conflict("period %d is still in play", live.Period)
+		}
+		e.Period++
+	case EventPeriodEnd:
+		if live.State != LiveInPlay {
+			return nil, Conflict("no period is in play")
+		}
+		if in.Final {
+			e.Final = true
+			e.HomePenalties, e.AwayPenalties = in.HomePenalties, in.AwayPenalties
+			res = &domain.MatchResult{
+				MatchID:       m.ID,
+				Outcome:       "played",
+				HomeScore:     e.HomeScore,
+				AwayScore:     e.AwayScore,
+				HomePenalties: in.HomePenalties,
+				AwayPenalties: in.AwayPenalties,
+			}
+			if (in.HomePenalties != nil && *in.HomePenalties < 0) || (in.AwayPenalties != nil && *in.AwayPenalties < 0) {
+				return nil, Invalid("", "invalid penalties")
+			}
+			if m.TieID == "" && (in.HomePenalties != nil || in.AwayPenalties != nil) {
+				return nil, Invalid("", "penalties only apply to knockout ties")
+			}
+			// Checked before the whistle is posted, so a level tie cannot
+			// finish without its shoot-out
+			if m.TieID != "" {
+				if err := s.checkKnockoutResult(ctx, m, res); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case EventGoal, EventCard, EventSubstitution:
+		if err := s.matchEventPlayers(ctx, ser, m, live, events, in, e); err != nil {
+			return nil, err
+		}
+	case EventRetraction:
+		return nil, Invalid("type", "delete an event to retract it")
+	default:
+		return nil, Invalid("type", "type must be period_start, period_end, goal, card or substitution")
+	}
+
+	if res == nil {
+		if err := s.store.AppendMatchEvent(ctx, e); err != nil {
+			return nil, err
+		}
+		s.live.notify(m.ID)
+		return e, nil
+	}
+
+	// The whistle and the result are written together, so a match never
+	// finishes without its result
+	res.RecordedBy = userID
+	if err := s.store.FinishMatch(ctx, e, res); err != nil {
+		return nil, err
+	}
+	s.live.notify(m.ID)
+	if m.TieID != "" {
+		if err := s.settleTie(ctx, m.TieID); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range liveCards(events) {
+		in := CardInput{TeamID: c.TeamID, PlayerID: c.PlayerID, Type: c.Type, Minute: c.Minute, Reason: c.Reason}
+		if _, _, err := s.saveCard(ctx, c.RecordedBy, ser, m, in); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// matchEventPlayers checks the team and players of a goal, card or
+// substitution, and scores a goal. A card must follow on from the player's
+// earlier cards in the match, as it will be recorded at the final whistle.
+func (s *LeaguesService) matchEventPlayers(ctx context.Context, ser *domain.Series, m *domain.Match, live *domain.LiveMatch, events []domain.MatchEvent, in MatchEventInput, e *domain.MatchEvent) error {
+	if live.State == LiveNotStarted {
+		return Conflict("the match has not kicked off")
+	}
+	if in.TeamID != m.HomeTeamID && in.TeamID != m.AwayTeamID {
+		return Invalid("teamId", "the team does not play in this match")
+	}
+	e.TeamID, e.PlayerID = in.TeamID, in.PlayerID
+	switch in.Type {
+	case EventGoal:
+		if live.State != LiveInPlay {
+			return Conflict("goals are posted while a period is in play")
+		}
+		e.OwnGoal = in.OwnGoal
+		if creditsHome(m, e) {
+			e.HomeScore++
+		} else {
+			e.AwayScore++
+		}
+	case EventCard:
+		if _, ok := fairPlayPoints[in.Card]; !ok {
+			return Invalid("card", "card must be yellow, second_yellow or red")
+		}
+		if in.PlayerID == "" {
+			return Invalid("playerId", "a card needs the player")
+		}
+		if err := checkCardSequence(liveCards(events), in.PlayerID, in.Card, "card"); err != nil {
+			return err
+		}
+		e.Card = in.Card
+	case EventSubstitution:
+		if in.PlayerID == "" || in.PlayerOffID == "" {
+			return Invalid("", "a substitution needs the players coming on and going off")
+		}
+		if in.PlayerID == in.PlayerOffID {
+			return Invalid("playerOffId", "a player cannot replace themselves")
+		}
+		e.PlayerOffID = in.PlayerOffID
+	}
+	for _, id := range []string{e.PlayerID, e.PlayerOffID} {
+		if id == "" {
+			continue
+		}
+		if err := s.rosteredPlayer(ctx, ser.ID, e.TeamID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RetractMatchEvent withdraws a goal, card or substitution posted by
+// mistake. Events are never removed: a retraction is appended, so followers
+// see the correction and a retracted goal comes off the score.
+func (s *LeaguesService) RetractMatchEvent(ctx context.Context, userID, matchID string, seq int64) (*domain.MatchEvent, error) {
+	m, _, err := s.officiatedMatch(ctx, userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.store.ListMatchEvents(ctx, m.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	live := liveState(m, events)
+	if live.State == LiveFinished {
+		return nil, Conflict("the match has finished; correct its result instead")
+	}
+	var target *domain.MatchEvent
+	for i := range events {
+		switch {
+		case events[i].Seq == seq:
+			target = &events[i]
+		case events[i].Type == EventRetraction && events[i].Retracts == seq:
+			return nil, Conflict("event %d was already retracted", seq)
+		}
+	}
+	if target == nil {
+		return nil, NotFound("match event not found")
+	}
+	switch target.Type {
+	case EventGoal, EventCard, EventSubstitution:
+	default:
+		return nil, Invalid("", "only goals, cards and substitutions can be retracted")
+	}
+
+	e := &domain.MatchEvent{
+		MatchID:    m.ID,
+		Seq:        live.LastSeq + 1,
+		Type:       EventRetraction,
+		Period:     live.Period,
+		TeamID:     target.TeamID,
+		Retracts:   seq,
+		HomeScore:  live.HomeScore,
+		AwayScore:  live.AwayScore,
+		RecordedBy: userID,
+	}
+	if target.Type == EventGoal {
+		if creditsHome(m, target) {
+			e.HomeScore--
+		} else {
+			e.AwayScore--
+		}
+	}
+	if err := s.store.AppendMatchEvent(ctx, e); err != nil {
+		return nil, err
+	}
+	s.live.notify(m.ID)
+	return e, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/repository"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ListOptions page through a listing. Sort names a sort field, prefixed with
+// "-" for descending order; Cursor is the next cursor of the previous page.
+type ListOptions struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+}
+
+// sortFields maps the sort fields clients use to repository sort keys.
+var sortFields = map[string]string{
+	"createdAt": repository.SortCreatedAt,
+	"name":      repository.SortName,
+}
+
+// cursorToken is the content of an opaque cursor. It records the sort it
+// was issued for, so it cannot be replayed against another order.
+type cursorToken struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"i"`
+}
+
+// pager is a validated ListOptions.
+type pager struct {
+	repository.Page
+	sort string // As requested, e.g. "-name"
+}
+
+// pager validates o against the sort fields a listing allows, the first
+// being its default. The page asks for one extra row, which tells whether
+// another page follows.
+func (o ListOptions) pager(allowed ...string) (pager, error) {
+	limit := o.Limit
+	switch {
+	case limit == 0:
+		limit = DefaultPageSize
+	case limit < 0 || limit > MaxPageSize:
+		return pager{}, Invalid("limit", "limit must be between 1 and %d", MaxPageSize)
+	}
+	sort := o.Sort
+	if sort == "" {
+		sort = allowed[0]
+	}
+	field, desc := strings.CutPrefix(sort, "-")
+	if !slices.Contains(allowed, field) {
+		return pager{}, Invalid("sort", "sort must be one of %s, optionally prefixed with -", strings.Join(allowed, ", "))
+	}
+	pg := pager{Page: repository.Page{Limit: limit + 1, Sort: sortFields[field], Desc: desc}, sort: sort}
+	if o.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+		var tok cursorToken
+		if err == nil {
+			err = json.Unmarshal(raw, &tok)
+		}
+		if err != nil || tok.ID == "" {
+			return pager{}, Invalid("cursor", "invalid cursor")
+		}
+		if tok.Sort != sort {
+			return pager{}, Invalid("cursor", "cursor was issued for another sort order")
+		}
+		pg.After = &repository.Cursor{Key: tok.Key, ID: tok.ID}
+	}
+	return pg, nil
+}
+
+// cut trims the extra row fetched for the page and returns the cursor of
+// the next page, or "" on the last one. fields returns the ID, name and
+// creation time of an item.
+func cut[T any](items []T, pg pager, fields func(*T) (id, name string, createdAt time.Time)) ([]T, string) {
+	if len(items) < pg.Limit {
+		return items, ""
+	}
+	items = items[:pg.Limit-1]
+	id, name, createdAt := fields(&items[len(items)-1])
+	tok := cursorToken{Sort: pg.sort, Key: name, ID: id}
+	if pg.Sort == repository.SortCreatedAt {
+		tok.Key = repository.TimeKey(createdAt)
+	}
+	raw, _ := json.Marshal(tok)
+	return items, base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"team-manager-leagues/internal/repository/memory"
+)
+
+func TestListLeaguesPages(t *testing.T) {
+	names := []string{"Delta", "Alpha", "Echo", "Bravo", "Charlie"}
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", names},
+		{"createdAt", names},
+		{"-createdAt", []string{"Charlie", "Bravo", "Echo", "Alpha", "Delta"}},
+		{"name", []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"}},
+		{"-name", []string{"Echo", "Delta", "Charlie", "Bravo", "Alpha"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			ctx := context.Background()
+			svc := NewLeaguesService(memory.NewStore())
+			for _, name := range names {
+				if _, err := svc.CreateLeague(ctx, organizer, name, "R"); err != nil {
+					t.Fatalf("create league %s: %v", name, err)
+				}
+			}
+
+			var got []string
+			q := LeagueQuery{ListOptions: ListOptions{Limit: 2, Sort: tt.sort}}
+			for pages := 1; ; pages++ {
+				list, next, err := svc.ListLeagues(ctx, q)
+				if err != nil {
+					t.Fatalf("page %d: %v", pages, err)
+				}
+				if len(list) > 2 {
+					t.Fatalf("page %d has %d leagues", pages, len(list))
+				}
+				for _, l := range list {
+					got = append(got, l.Name)
+				}
+				if next == "" {
+					if pages != 3 {
+						t.Fatalf("%d pages, want 3", pages)
+					}
+					break
+				}
+				q.Cursor = next
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListLeaguesFullPage(t *testing.T) {
+	ctx := context.Background()
+	svc := NewLeaguesService(memory.NewStore())
+	for _, name := range []string{"A", "B"} {
+		if _, err := svc.CreateLeague(ctx, organizer, name, "R"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A page ending on the last league has no next page.
+	list, next, err := svc.ListLeagues(ctx, LeagueQuery{ListOptions: ListOptions{Limit: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || next != "" {
+		t.Fatalf("got %d leagues and cursor %q", len(list), next)
+	}
+}
+
+func TestListLeaguesCursorStable(t *testing.T) {
+	ctx := context.Background()
+	svc := NewLeaguesService(memory.NewStore())
+	for _, name := range []string{"B", "D", "F"} {
+		if _, err := svc.CreateLeague(ctx, organizer, name, "R"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q := LeagueQuery{ListOptions: ListOptions{Limit: 1, Sort: "name"}}
+	first, next, err := svc.ListLeagues(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Leagues added before and after the cursor: only the later one shows.
+	for _, name := range []string{"A", "E"} {
+		if _, err := svc.CreateLeague(ctx, organizer, name, "R"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := []string{first[0].Name}
+	for q.Cursor = next; q.Cursor != ""; {
+		list, next, err := svc.ListLeagues(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range list {
+			got = append(got, l.Name)
+		}
+		q.Cursor = next
+	}
+	if want := []string{"B", "D", "E", "F"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestListOptionsInvalid(t *testing.T) {
+	ctx := context.Background()
+	svc := NewLeaguesService(memory.NewStore())
+	for _, name := range []string{"A", "B"} {
+		if _, err := svc.CreateLeague(ctx, organizer, name, "R"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, byName, err := svc.ListLeagues(ctx, LeagueQuery{ListOptions: ListOptions{Limit: 1, Sort: "name"}})
+	if err != nil || byName == "" {
+		t.Fatalf("first page: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  ListOptions
+		field string
+	}{
+		{"negative limit", ListOptions{Limit: -1}, "limit"},
+		{"limit too large", ListOptions{Limit: MaxPageSize + 1}, "limit"},
+		{"unknown sort", ListOptions{Sort: "region"}, "sort"},
+		{"garbage cursor", ListOptions{Cursor: "not a cursor"}, "cursor"},
+		{"cursor without id", ListOptions{Cursor: "e30"}, "cursor"}, // {}
+		{"cursor of another sort", ListOptions{Sort: "-name", Cursor: byName}, "cursor"},
+		{"cursor of the default sort", ListOptions{Cursor: byName}, "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := svc.ListLeagues(ctx, LeagueQuery{ListOptions: tt.opts})
+			checkErr(t, err, ErrValidation)
+			if !strings.Contains(err.Error(), tt.field) {
+				t.Fatalf("error %q does not name %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestListSeriesPages(t *testing.T) {
+	tl := newTestLeague(t, SeriesSettings{})
+	want := []string{"A"}
+	for _, name := range []string{"B", "C", "D"} {
+		if _, err := tl.svc.CreateSeries(tl.ctx, tl.league.ID, "", name, "7", SeriesSettings{}); err != nil {
+			t.Fatalf("create series %s: %v", name, err)
+		}
+		want = append(want, name)
+	}
+	var got []string
+	q := SeriesQuery{ListOptions: ListOptions{Limit: 3, Sort: "name"}}
+	for {
+		list, next, err := tl.svc.ListSeries(tl.ctx, tl.league.ID, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ser := range list {
+			got = append(got, ser.Name)
+		}
+		if next == "" {
+			break
+		}
+		q.Cursor = next
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// Filters apply across pages.
+	q = SeriesQuery{Format: "7", ListOptions: ListOptions{Limit: 2, Sort: "-name"}}
+	list, next, err := tl.svc.ListSeries(tl.ctx, tl.league.ID, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Cursor = next
+	rest, next, err := tl.svc.ListSeries(tl.ctx, tl.league.ID, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, ser := range append(list, rest...) {
+		got = append(got, ser.Name)
+	}
+	if want := []string{"D", "C", "B"}; !slices.Equal(got, want) || next != "" {
+		t.Fatalf("got %v and cursor %q, want %v", got, next, want)
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// League roles
+
+const (
+	RoleOwner              = "owner"
+	RoleAdmin              = "admin"
+	RoleScheduler          = "scheduler"
+	RoleRefereeCoordinator = "referee_coordinator"
+	RoleViewer             = "viewer"
+)
+
+// Permission is an action on a league guarded by its roles.
+type Permission string
+
+const (
+	PermViewLeague          Permission = "league:view"
+	PermViewMembers         Permission = "members:view"
+	PermManageMembers       Permission = "members:manage"
+	PermManageLeague        Permission = "league:manage"
+	PermDeleteLeague        Permission = "league:delete"
+	PermManageSeries        Permission = "series:manage"
+	PermManageRegistrations Permission = "registrations:manage"
+	PermManageSchedule      Permission = "schedule:manage"
+	PermManageResults       Permission = "results:manage"
+	PermManageReferees      Permission = "referees:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermViewLeague, PermViewMembers, PermManageMembers, PermManageLeague, PermDeleteLeague, PermManageSeries,
+		PermManageRegistrations, PermManageSchedule, PermManageResults, PermManageReferees,
+	},
+	RoleAdmin: {
+		PermViewLeague, PermViewMembers, PermManageMembers, PermManageLeague, PermManageSeries,
+		PermManageRegistrations, PermManageSchedule, PermManageResults, PermManageReferees,
+	},
+	RoleScheduler:          {PermViewLeague, PermViewMembers, PermManageSchedule, PermManageResults},
+	RoleRefereeCoordinator: {PermViewLeague, PermViewMembers, PermManageResults, PermManageReferees},
+	RoleViewer:             {PermViewLeague, PermViewMembers},
+}
+
+func validRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// can reports whether userID holds a role in the league granting perm.
+// Members of clubs taking part in the league and its referees may view it
+// without a role.
+func (s *LeaguesService) can(ctx context.Context, userID, leagueID string, perm Permission) (bool, error) {
+	m, err := s.store.GetLeagueMember(ctx, leagueID, userID)
+	if err != nil {
+		return false, err
+	}
+	if m != nil && slices.Contains(rolePermissions[m.Role], perm) {
+		return true, nil
+	}
+	if perm == PermViewLeague {
+		return s.store.IsLeagueParticipant(ctx, leagueID, userID)
+	}
+	return false, nil
+}
+
+// Authorize checks that userID may perform perm in the league.
+func (s *LeaguesService) Authorize(ctx context.Context, userID, leagueID string, perm Permission) error {
+	l, err := s.store.GetLeagueByID(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		return NotFound("league not found")
+	}
+	ok, err := s.can(ctx, userID, leagueID, perm)
+	if err != nil {
+		return err
+	}
+	if !ok && perm == PermViewLeague {
+		return Forbidden("%s requires a league role or a team in the league", perm)
+	}
+	if !ok {
+		return Forbidden("%s requires a league role", perm)
+	}
+	return nil
+}
+
+// Members
+
+func (s *LeaguesService) ListMembers(ctx context.Context, leagueID string) ([]domain.LeagueMember, error) {
+	return s.store.ListLeagueMembers(ctx, leagueID)
+}
+
+// SetMemberRole grants a user a role in the league, or changes it. Only
+// owners may grant or take away the owner role, and a league always keeps at
+// least one owner.
+func (s *LeaguesService) SetMemberRole(ctx context.Context, actorID, leagueID, userID, role string) (*domain.LeagueMember, error) {
+	if userID == "" {
+		return nil, Invalid("userId", "invalid user")
+	}
+	if !validRole(role) {
+		return nil, Invalid("role", "invalid role")
+	}
+	current, err := s.store.GetLeagueMember(ctx, leagueID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == RoleOwner || (current != nil && current.Role == RoleOwner) {
+		if err := s.requireOwner(ctx, actorID, leagueID); err != nil {
+			return nil, err
+		}
+	}
+	if current != nil && current.Role == RoleOwner && role != RoleOwner {
+		if err := s.keepAnOwner(ctx, leagueID); err != nil {
+			return nil, err
+		}
+	}
+	m := &domain.LeagueMember{LeagueID: leagueID, UserID: userID, Role: role, AddedBy: actorID}
+	if err := s.store.SaveLeagueMember(ctx, m); err != nil {
+		return nil, err
+	}
+	return s.store.GetLeagueMember(ctx, leagueID, userID)
+}
+
+func (s *LeaguesService) RemoveMember(ctx context.Context, actorID, leagueID, userID string) error {
+	current, err := s.store.GetLeagueMember(ctx, leagueID, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return NotFound("member not found")
+	}
+	if current.Role == RoleOwner {
+		if err := s.requireOwner(ctx, actorID, leagueID); err != nil {
+			return err
+		}
+		if err := s.keepAnOwner(ctx, leagueID); err != nil {
+			return err
+		}
+	}
+	return s.store.DeleteLeagueMember(ctx, leagueID, userID)
+}
+
+func (s *LeaguesService) requireOwner(ctx context.Context, userID, leagueID string) error {
+	m, err := s.store.GetLeagueMember(ctx, leagueID, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.Role != RoleOwner {
+		return Forbidden("only owners can manage owners")
+	}
+	return nil
+}
+
+func (s *LeaguesService) keepAnOwner(ctx context.Context, leagueID string) error {
+	n, err := s.store.CountLeagueOwners(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return Conflict("a league must keep at least one owner")
+	}
+	return nil
+}
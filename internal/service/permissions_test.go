@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// fan is an active member, not an owner, of the club of t1.
+const fan = "fan"
+
+func newFanLeague(t *testing.T) *testLeague {
+	t.Helper()
+	tl := newTestLeague(t, SeriesSettings{})
+	tl.store.Seed(nil, []domain.Membership{{ID: "fan", UserID: fan, ClubID: "c1", Role: "member", Status: "active"}})
+	return tl
+}
+
+func TestLeagueParticipantView(t *testing.T) {
+	tests := []struct {
+		status string
+		view   bool
+	}{
+		{RegistrationPending, true},
+		{RegistrationWaitlisted, true},
+		{RegistrationApproved, true},
+		{RegistrationInvited, false},
+		{RegistrationRejected, false},
+		{RegistrationWithdrawn, false},
+		{RegistrationArchived, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			tl := newFanLeague(t)
+			checkErr(t, tl.svc.Authorize(tl.ctx, fan, tl.league.ID, PermViewLeague), ErrForbidden)
+			reg := tl.register("t1")[0]
+			if tt.status != reg.Status {
+				ev := &domain.RegistrationEvent{ID: util.RandID(), RegistrationID: reg.ID, FromStatus: reg.Status, ToStatus: tt.status}
+				if ok, err := tl.store.UpdateRegistrationStatus(tl.ctx, reg.ID, reg.Status, tt.status, ev); !ok || err != nil {
+					t.Fatalf("set status %s: %v", tt.status, err)
+				}
+			}
+			want := ErrForbidden
+			if tt.view {
+				want = nil
+			}
+			checkErr(t, tl.svc.Authorize(tl.ctx, fan, tl.league.ID, PermViewLeague), want)
+		})
+	}
+}
+
+func TestRejectedClubLosesView(t *testing.T) {
+	tl := newFanLeague(t)
+	reg := tl.register("t1")[0]
+	checkErr(t, tl.svc.Authorize(tl.ctx, fan, tl.league.ID, PermViewLeague), nil)
+	if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, organizer, reg.ID, RegistrationRejected, "incomplete"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	checkErr(t, tl.svc.Authorize(tl.ctx, fan, tl.league.ID, PermViewLeague), ErrForbidden)
+	_, _, err := tl.svc.ListRegistrations(tl.ctx, fan, RegistrationQuery{SeriesID: tl.series.ID})
+	checkErr(t, err, ErrForbidden)
+}
+
+func TestListRegistrationsAccess(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor string
+		query RegistrationQuery
+		err   error
+	}{
+		{name: "unfiltered", actor: organizer, err: ErrValidation},
+		{name: "series by organizer", actor: organizer, query: RegistrationQuery{SeriesID: "series"}},
+		{name: "series by participant", actor: fan, query: RegistrationQuery{SeriesID: "series"}},
+		{name: "series by stranger", actor: stranger, query: RegistrationQuery{SeriesID: "series"}, err: ErrForbidden},
+		{name: "missing series", actor: organizer, query: RegistrationQuery{SeriesID: "nope"}, err: ErrNotFound},
+		{name: "team by owner", actor: clubOwner, query: RegistrationQuery{TeamID: "t1"}},
+		{name: "team by club member", actor: fan, query: RegistrationQuery{TeamID: "t1"}, err: ErrForbidden},
+		{name: "team by organizer", actor: organizer, query: RegistrationQuery{TeamID: "t1"}, err: ErrForbidden},
+		{name: "team in series by organizer", actor: organizer, query: RegistrationQuery{TeamID: "t1", SeriesID: "series"}},
+		{name: "missing team", actor: clubOwner, query: RegistrationQuery{TeamID: "nope"}, err: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newFanLeague(t)
+			tl.register("t1", "t2")
+			if tt.query.SeriesID == "series" {
+				tt.query.SeriesID = tl.series.ID
+			}
+			list, _, err := tl.svc.ListRegistrations(tl.ctx, tt.actor, tt.query)
+			checkErr(t, err, tt.err)
+			if err == nil && len(list) == 0 {
+				t.Fatalf("no registrations listed")
+			}
+		})
+	}
+}
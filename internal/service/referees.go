@@ -0,0 +1,589 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Referees
+
+const (
+	OfficialReferee   = "referee"
+	OfficialAssistant = "assistant"
+)
+
+// maxAssistants is how many assistant referees a match takes.
+const maxAssistants = 2
+
+// RefereeInput describes a referee. Updates replace every field but the
+// user, availability and blackouts included.
+type RefereeInput struct {
+	UserID       string                      `json:"userId"` // Set on create only
+	Name         string                      `json:"name"`
+	Level        int                         `json:"level"`
+	Formats      []string                    `json:"formats"`
+	Timezone     string                      `json:"timezone"` // Defaults to "UTC"
+	Availability []domain.AvailabilityWindow `json:"availability"`
+	Blackouts    []domain.Blackout           `json:"blackouts"`
+}
+
+func (in RefereeInput) apply(r *domain.Referee) error {
+	r.Name = strings.TrimSpace(in.Name)
+	if r.Name == "" {
+		return Invalid("name", "invalid name")
+	}
+	if in.Level <= 0 {
+		return Invalid("level", "level must be at least 1")
+	}
+	r.Level = in.Level
+	r.Formats = []string{}
+	for _, f := range in.Formats {
+		if f = strings.TrimSpace(f); f != "" && !slices.Contains(r.Formats, f) {
+			r.Formats = append(r.Formats, f)
+		}
+	}
+
+	r.Timezone = in.Timezone
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil || r.Timezone == "Local" {
+		return Invalid("timezone", "unknown time zone %q; use an IANA name like \"America/Santiago\"", in.Timezone)
+	}
+
+	r.Availability = []domain.AvailabilityWindow{}
+	for _, w := range in.Availability {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return Invalid("availability", "weekday must be 0 (Sunday) through 6 (Saturday)")
+		}
+		opens, ok1 := parseClock(w.Opens)
+		closes, ok2 := parseClock(w.Closes)
+		if !ok1 || !ok2 {
+			return Invalid("availability", "available hours must be times like 09:00")
+		}
+		if closes <= opens {
+			return Invalid("availability", "a window must close after it opens")
+		}
+		if len(w.Pitches) > 0 {
+			return Invalid("availability", "referee availability has no pitches")
+		}
+		r.Availability = append(r.Availability, w)
+	}
+
+	r.Blackouts = []domain.Blackout{}
+	for _, b := range in.Blackouts {
+		if _, err := time.Parse(time.DateOnly, b.Date); err != nil {
+			return Invalid("blackouts", "blackout dates must be like 2025-12-25")
+		}
+		if len(b.Pitches) > 0 {
+			return Invalid("blackouts", "referee blackouts have no pitches")
+		}
+		b.Reason = strings.TrimSpace(b.Reason)
+		r.Blackouts = append(r.Blackouts, b)
+	}
+	return nil
+}
+
+func (s *LeaguesService) CreateReferee(ctx context.Context, leagueID string, in RefereeInput) (*domain.Referee, error) {
+	r := &domain.Referee{ID: util.RandID(), LeagueID: leagueID, UserID: strings.TrimSpace(in.UserID)}
+	if r.UserID == "" {
+		return nil, Invalid("userId", "a referee needs the user account the clubs know them by")
+	}
+	if err := in.apply(r); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateReferee(ctx, r); err != nil {
+		return nil, err
+	}
+	return s.store.GetReferee(ctx, r.ID)
+}
+
+// RefereeQuery filters the referees of a league.
+type RefereeQuery struct {
+	Format   string `form:"format"`   // Only those officiating the format
+	MinLevel int    `form:"minLevel"` // Only those of this level or above
+}
+
+func (s *LeaguesService) ListReferees(ctx context.Context, leagueID string, q RefereeQuery) ([]domain.Referee, error) {
+	list, err := s.store.ListReferees(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(list, func(r domain.Referee) bool {
+		return r.Level < q.MinLevel || (q.Format != "" && !officiatesFormat(&r, q.Format))
+	}), nil
+}
+
+func (s *LeaguesService) GetReferee(ctx context.Context, leagueID, id string) (*domain.Referee, error) {
+	r, err := s.store.GetReferee(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.LeagueID != leagueID {
+		return nil, NotFound("referee not found")
+	}
+	return r, nil
+}
+
+// UpdateReferee replaces the details of a referee. Assignments already made
+// are kept even if the referee no longer qualifies for them.
+func (s *LeaguesService) UpdateReferee(ctx context.Context, leagueID, id string, in RefereeInput) (*domain.Referee, error) {
+	r, err := s.GetReferee(ctx, leagueID, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.UserID != "" && in.UserID != r.UserID {
+		return nil, Invalid("userId", "the user of a referee cannot change")
+	}
+	if err := in.apply(r); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateReferee(ctx, r); err != nil {
+		return nil, err
+	}
+	return s.store.GetReferee(ctx, id)
+}
+
+// DeleteReferee removes a referee with their assignments.
+func (s *LeaguesService) DeleteReferee(ctx context.Context, leagueID, id string) error {
+	if _, err := s.GetReferee(ctx, leagueID, id); err != nil {
+		return err
+	}
+	return s.store.DeleteReferee(ctx, id)
+}
+
+// RefereeAssignments returns a referee's assignments by kickoff, to the
+// referee themselves or to those managing referees.
+func (s *LeaguesService) RefereeAssignments(ctx context.Context, userID, leagueID, id string) ([]domain.RefereeAssignment, error) {
+	r, err := s.GetReferee(ctx, leagueID, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		ok, err := s.can(ctx, userID, leagueID, PermManageReferees)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, Forbidden("only the referee and referee coordinators can see the referee's assignments")
+		}
+	}
+	return s.store.ListRefereeAssignments(ctx, leagueID, id)
+}
+
+func officiatesFormat(r *domain.Referee, format string) bool {
+	return len(r.Formats) == 0 || slices.Contains(r.Formats, format)
+}
+
+// refereeDesk holds what the assignment checks read, loaded once per
+// request: the league's assignments, and the teams, series and club
+// memberships looked up so far.
+type refereeDesk struct {
+	s           *LeaguesService
+	assignments []domain.RefereeAssignment
+	teams       map[string]*domain.Team
+	series      map[string]*domain.Series
+	clubs       map[string][]string // By user
+}
+
+func (s *LeaguesService) newRefereeDesk(ctx context.Context, leagueID string) (*refereeDesk, error) {
+	assignments, err := s.store.ListRefereeAssignments(ctx, leagueID, "")
+	if err != nil {
+		return nil, err
+	}
+	return &refereeDesk{
+		s:           s,
+		assignments: assignments,
+		teams:       map[string]*domain.Team{},
+		series:      map[string]*domain.Series{},
+		clubs:       map[string][]string{},
+	}, nil
+}
+
+func (d *refereeDesk) team(ctx context.Context, id string) (*domain.Team, error) {
+	if t, ok := d.teams[id]; ok {
+		return t, nil
+	}
+	t, err := d.s.store.GetTeamByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d.teams[id] = t
+	return t, nil
+}
+
+func (d *refereeDesk) seriesByID(ctx context.Context, id string) (*domain.Series, error) {
+	if ser, ok := d.series[id]; ok {
+		return ser, nil
+	}
+	ser, err := d.s.store.GetSeriesByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d.series[id] = ser
+	return ser, nil
+}
+
+func (d *refereeDesk) memberClubs(ctx context.Context, userID string) ([]string, error) {
+	if clubs, ok := d.clubs[userID]; ok {
+		return clubs, nil
+	}
+	clubs, err := d.s.store.ListMemberClubs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	d.clubs[userID] = clubs
+	return clubs, nil
+}
+
+// problem explains why the referee cannot officiate the booked match, or
+// returns "" when they can.
+func (d *refereeDesk) problem(ctx context.Context, r *domain.Referee, ser *domain.Series, m *domain.Match) (string, error) {
+	if !officiatesFormat(r, ser.Format) {
+		return fmt.Sprintf("%s does not officiate format %q", r.Name, ser.Format), nil
+	}
+	if r.Level < ser.MinRefereeLevel {
+		return fmt.Sprintf("%s is level %d; %s needs level %d or above", r.Name, r.Level, ser.Name, ser.MinRefereeLevel), nil
+	}
+	if p, err := refereeAvailable(r, *m.KickoffAt, *m.EndsAt); p != "" || err != nil {
+		return p, err
+	}
+	clubs, err := d.memberClubs(ctx, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range []string{m.HomeTeamID, m.AwayTeamID} {
+		t, err := d.team(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		if t != nil && slices.Contains(clubs, t.ClubID) {
+			return fmt.Sprintf("%s is a member of the club of %s", r.Name, t.Name), nil
+		}
+	}
+	for _, a := range d.assignments {
+		switch {
+		case a.RefereeID != r.ID:
+		case a.MatchID == m.ID:
+			return fmt.Sprintf("%s already officiates this match", r.Name), nil
+		case a.KickoffAt != nil && a.KickoffAt.Before(*m.EndsAt) && m.KickoffAt.Before(*a.EndsAt):
+			return fmt.Sprintf("%s already officiates a match from %s to %s", r.Name, a.KickoffAt.Format(time.RFC3339), a.EndsAt.Format(time.RFC3339)), nil
+		}
+	}
+	return "", nil
+}
+
+// refereeAvailable checks the referee's days off and weekly hours, in the
+// referee's time zone, like checkSlot does for a pitch.
+func refereeAvailable(r *domain.Referee, start, end time.Time) (string, error) {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("referee %s: %w", r.ID, err)
+	}
+	local := start.In(loc)
+	days := []string{local.Format(time.DateOnly), end.Add(-time.Nanosecond).In(loc).Format(time.DateOnly)}
+	for _, b := range r.Blackouts {
+		if slices.Contains(days, b.Date) {
+			return fmt.Sprintf("%s is unavailable on %s%s", r.Name, b.Date, reasonSuffix(b.Reason)), nil
+		}
+	}
+	if len(r.Availability) == 0 {
+		return "", nil
+	}
+	from := local.Hour()*60 + local.Minute()
+	to := from + int(end.Sub(start).Minutes())
+	for _, w := range r.Availability {
+		opens, _ := parseClock(w.Opens)
+		closes, _ := parseClock(w.Closes)
+		if w.Weekday == int(local.Weekday()) && opens <= from && to <= closes {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("%s is not available from %s to %s on %s", r.Name, local.Format("15:04"), end.In(loc).Format("15:04"), local.Weekday()), nil
+}
+
+// AssignmentInput appoints a referee to a match.
+type AssignmentInput struct {
+	RefereeID string `json:"refereeId" binding:"required"`
+	Role      string `json:"role"` // "referee" (default) or "assistant"
+}
+
+// AssignReferee appoints an official to a booked, scheduled match. The
+// referee must officiate the series' format at its minimum level, be
+// available for the whole match, not belong to either club and not be
+// officiating another match at the same time.
+func (s *LeaguesService) AssignReferee(ctx context.Context, userID, leagueID, seriesID, matchID string, in AssignmentInput) (*domain.RefereeAssignment, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	ser, err := s.leagueSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != "scheduled" {
+		return nil, Conflict("the match is %s; officials are assigned to scheduled matches", m.Status)
+	}
+	if m.KickoffAt == nil {
+		return nil, Conflict("the match has no slot yet; book it before assigning officials")
+	}
+	if in.Role == "" {
+		in.Role = OfficialReferee
+	}
+	if in.Role != OfficialReferee && in.Role != OfficialAssistant {
+		return nil, Invalid("role", "role must be referee or assistant")
+	}
+	r, err := s.GetReferee(ctx, leagueID, in.RefereeID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Role == OfficialAssistant {
+		officials, err := s.store.ListMatchAssignments(ctx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+		assistants := slices.DeleteFunc(officials, func(a domain.RefereeAssignment) bool { return a.Role != OfficialAssistant })
+		if len(assistants) >= maxAssistants {
+			return nil, Conflict("the match already has %d assistants", maxAssistants)
+		}
+	}
+	desk, err := s.newRefereeDesk(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	problem, err := desk.problem(ctx, r, ser, m)
+	if err != nil {
+		return nil, err
+	}
+	if problem != "" {
+		return nil, Conflict("%s", problem)
+	}
+
+	a := &domain.RefereeAssignment{
+		ID:         util.RandID(),
+		LeagueID:   leagueID,
+		MatchID:    m.ID,
+		RefereeID:  r.ID,
+		Role:       in.Role,
+		AssignedBy: userID,
+	}
+	if err := s.store.CreateRefereeAssignment(ctx, a); err != nil {
+		return nil, err
+	}
+	a.SeriesID, a.KickoffAt, a.EndsAt = m.SeriesID, m.KickoffAt, m.EndsAt
+	return a, nil
+}
+
+// ListMatchOfficials returns the officials of a match, the main referee
+// first.
+func (s *LeaguesService) ListMatchOfficials(ctx context.Context, leagueID, seriesID, matchID string) ([]domain.RefereeAssignment, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListMatchAssignments(ctx, m.ID)
+}
+
+func (s *LeaguesService) UnassignReferee(ctx context.Context, leagueID, seriesID, matchID, id string) error {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return err
+	}
+	a, err := s.store.GetRefereeAssignment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a == nil || a.MatchID != m.ID {
+		return NotFound("assignment not found")
+	}
+	return s.store.DeleteRefereeAssignment(ctx, id)
+}
+
+// releasedOfficials rechecks the officials of matches about to move against
+// their new slots. It returns the assignments that no longer hold: every
+// official of a match left without a slot, and those the move makes
+// unavailable or puts in two matches at once.
+func (s *LeaguesService) releasedOfficials(ctx context.Context, leagueID string, moved []domain.Match) ([]domain.RefereeAssignment, error) {
+	released := []domain.RefereeAssignment{}
+	if len(moved) == 0 {
+		return released, nil
+	}
+	desk, err := s.newRefereeDesk(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	slot := map[string]*domain.Match{}
+	for i := range moved {
+		slot[moved[i].ID] = &moved[i]
+	}
+	all := desk.assignments
+	for i, a := range all {
+		if m, ok := slot[a.MatchID]; ok {
+			all[i].KickoffAt, all[i].EndsAt = m.KickoffAt, m.EndsAt
+		}
+	}
+	for _, a := range all {
+		m, ok := slot[a.MatchID]
+		if !ok {
+			continue
+		}
+		problem := "the match has no slot"
+		if m.KickoffAt != nil {
+			r, err := s.store.GetReferee(ctx, a.RefereeID)
+			if err != nil {
+				return nil, err
+			}
+			ser, err := desk.seriesByID(ctx, m.SeriesID)
+			if err != nil {
+				return nil, err
+			}
+			// Check against the other assignments still held
+			desk.assignments = slices.DeleteFunc(slices.Clone(all), func(x domain.RefereeAssignment) bool {
+				return x.ID == a.ID || slices.ContainsFunc(released, func(y domain.RefereeAssignment) bool { return y.ID == x.ID })
+			})
+			if r != nil && ser != nil {
+				if problem, err = desk.problem(ctx, r, ser, m); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if problem != "" {
+			released = append(released, a)
+		}
+	}
+	return released, nil
+}
+
+func assignmentIDs(assignments []domain.RefereeAssignment) []string {
+	ids := make([]string, len(assignments))
+	for i, a := range assignments {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+// officiates reports whether the user is one of the match's officials.
+func (s *LeaguesService) officiates(ctx context.Context, userID, matchID string) (bool, error) {
+	officials, err := s.store.ListMatchAssignments(ctx, matchID)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range officials {
+		r, err := s.store.GetReferee(ctx, a.RefereeID)
+		if err != nil {
+			return false, err
+		}
+		if r != nil && r.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SuggestReferees proposes a main referee for every booked, scheduled match
+// of the series still without one, in kickoff order. Each match goes to the
+// eligible referee with the fewest matches in the season so far, counting
+// the proposals already made, so the work is spread evenly. Nothing is
+// assigned; matches no referee can take are reported as issues with the
+// reason each referee was ruled out.
+func (s *LeaguesService) SuggestReferees(ctx context.Context, leagueID, seriesID string) ([]domain.RefereeSuggestion, []domain.ScheduleIssue, error) {
+	ser, err := s.leagueSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches, err := s.store.ListMatchesBySeries(ctx, ser.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	referees, err := s.store.ListReferees(ctx, leagueID)
+	if err != nil {
+		return nil, nil, err
+	}
+	desk, err := s.newRefereeDesk(ctx, leagueID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	load := map[string]int{}
+	refereed := map[string]bool{}
+	for _, a := range desk.assignments {
+		if a.Role == OfficialReferee {
+			refereed[a.MatchID] = true
+		}
+		other, err := desk.seriesByID(ctx, a.SeriesID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if other != nil && other.SeasonID == ser.SeasonID {
+			load[a.RefereeID]++
+		}
+	}
+
+	issues := []domain.ScheduleIssue{}
+	pending := []domain.Match{}
+	for _, m := range matches {
+		switch {
+		case m.Status != "scheduled" || refereed[m.ID]:
+		case m.KickoffAt == nil:
+			issues = append(issues, domain.ScheduleIssue{Constraint: "unbooked", MatchID: m.ID, Message: "the match has no slot yet"})
+		default:
+			pending = append(pending, m)
+		}
+	}
+	slices.SortStableFunc(pending, func(a, b domain.Match) int {
+		return cmp.Or(a.KickoffAt.Compare(*b.KickoffAt), strings.Compare(a.ID, b.ID))
+	})
+
+	suggestions := []domain.RefereeSuggestion{}
+	for i := range pending {
+		m := &pending[i]
+		var best *domain.Referee
+		reasons := []string{}
+		for j := range referees {
+			r := &referees[j]
+			problem, err := desk.problem(ctx, r, ser, m)
+			if err != nil {
+				return nil, nil, err
+			}
+			if problem != "" {
+				reasons = append(reasons, problem)
+				continue
+			}
+			if best == nil || load[r.ID] < load[best.ID] {
+				best = r
+			}
+		}
+		if best == nil {
+			msg := "the league has no referees"
+			if len(reasons) > 0 {
+				msg = "no referee can officiate: " + strings.Join(reasons, "; ")
+			}
+			issues = append(issues, domain.ScheduleIssue{Constraint: "no_referee", MatchID: m.ID, Message: msg})
+			continue
+		}
+		load[best.ID]++
+		desk.assignments = append(desk.assignments, domain.RefereeAssignment{
+			MatchID:   m.ID,
+			RefereeID: best.ID,
+			Role:      OfficialReferee,
+			SeriesID:  m.SeriesID,
+			KickoffAt: m.KickoffAt,
+			EndsAt:    m.EndsAt,
+		})
+		suggestions = append(suggestions, domain.RefereeSuggestion{
+			MatchID:   m.ID,
+			KickoffAt: *m.KickoffAt,
+			RefereeID: best.ID,
+			Name:      best.Name,
+			Load:      load[best.ID],
+		})
+	}
+	return suggestions, issues, nil
+}
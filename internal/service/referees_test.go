@@ -0,0 +1,120 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestSuggestReferees(t *testing.T) {
+	ann := RefereeInput{UserID: "ann", Name: "Ann", Level: 2}
+	bob := RefereeInput{UserID: "bob", Name: "Bob", Level: 1}
+	tests := []struct {
+		name     string
+		referees []RefereeInput
+		settings SeriesSettings
+		assigned bool           // Ann referees the first match already
+		load     map[string]int // Suggestions per referee
+		issues   int
+		reason   string // Part of every issue's message
+	}{
+		{name: "spread evenly", referees: []RefereeInput{ann, bob}, load: map[string]int{"Ann": 3, "Bob": 3}},
+		{name: "refereed match skipped", referees: []RefereeInput{ann, bob}, assigned: true, load: map[string]int{"Ann": 2, "Bob": 3}},
+		{
+			name:     "level too low",
+			referees: []RefereeInput{ann, bob},
+			settings: SeriesSettings{MinRefereeLevel: ptr(2)},
+			load:     map[string]int{"Ann": 6},
+		},
+		{
+			name:     "club member",
+			referees: []RefereeInput{{UserID: "member", Name: "Cid", Level: 1}},
+			load:     map[string]int{"Cid": 3},
+			issues:   3,
+			reason:   "Cid is a member of the club of Team 1",
+		},
+		{
+			name:     "format",
+			referees: []RefereeInput{{UserID: "dee", Name: "Dee", Level: 1, Formats: []string{"7"}}},
+			issues:   6,
+			reason:   `Dee does not officiate format "11"`,
+		},
+		{
+			name:     "day off",
+			referees: []RefereeInput{{UserID: "eve", Name: "Eve", Level: 1, Blackouts: []domain.Blackout{{Date: "2027-03-06"}}}},
+			load:     map[string]int{"Eve": 4},
+			issues:   2,
+			reason:   "Eve is unavailable on 2027-03-06",
+		},
+		{name: "no referees", issues: 6, reason: "the league has no referees"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, tt.settings)
+			tl.store.Seed(nil, []domain.Membership{{ID: "m1", UserID: "member", ClubID: "c1", Role: "member", Status: "active"}})
+			matches := tl.scheduled(false)
+			ids := map[string]string{}
+			for _, in := range tt.referees {
+				r, err := tl.svc.CreateReferee(tl.ctx, tl.league.ID, in)
+				if err != nil {
+					t.Fatalf("create referee %s: %v", in.Name, err)
+				}
+				ids[r.Name] = r.ID
+			}
+			// Load counts the season's matches, those already refereed too
+			season := map[string]int{}
+			if tt.assigned {
+				if _, err := tl.svc.AssignReferee(tl.ctx, organizer, tl.league.ID, tl.series.ID, matches[0].ID, AssignmentInput{RefereeID: ids["Ann"], Role: OfficialReferee}); err != nil {
+					t.Fatalf("assign: %v", err)
+				}
+				season["Ann"]++
+			}
+
+			suggestions, issues, err := tl.svc.SuggestReferees(tl.ctx, tl.league.ID, tl.series.ID)
+			if err != nil {
+				t.Fatalf("suggest: %v", err)
+			}
+			load := map[string]int{}
+			for i, s := range suggestions {
+				if ids[s.Name] != s.RefereeID {
+					t.Errorf("suggested %s as %s", s.RefereeID, s.Name)
+				}
+				load[s.Name]++
+				if want := season[s.Name] + load[s.Name]; s.Load != want {
+					t.Errorf("%s suggested with load %d, want %d", s.Name, s.Load, want)
+				}
+				if i > 0 && s.KickoffAt.Before(suggestions[i-1].KickoffAt) {
+					t.Errorf("suggestions out of kickoff order")
+				}
+			}
+			if len(load) != len(tt.load) {
+				t.Fatalf("got load %v, want %v", load, tt.load)
+			}
+			for name, n := range tt.load {
+				if load[name] != n {
+					t.Fatalf("got load %v, want %v", load, tt.load)
+				}
+			}
+			if len(issues) != tt.issues {
+				t.Fatalf("got %d issues, want %d: %v", len(issues), tt.issues, issues)
+			}
+			for _, is := range issues {
+				if is.Constraint != "no_referee" || !strings.Contains(is.Message, tt.reason) {
+					t.Errorf("issue %+v, want no_referee with %q", is, tt.reason)
+				}
+			}
+
+			// Nothing is assigned.
+			for _, m := range matches {
+				officials, err := tl.store.ListMatchAssignments(tl.ctx, m.ID)
+				if err != nil {
+					t.Fatalf("list officials: %v", err)
+				}
+				if want := tt.assigned && m.ID == matches[0].ID; (len(officials) > 0) != want {
+					t.Errorf("match %s has %d officials", m.ID, len(officials))
+				}
+			}
+		})
+	}
+}
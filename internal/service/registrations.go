@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Registration workflow
+
+const (
+	RegistrationInvited    = "invited"
+	RegistrationPending    = "pending"
+	RegistrationWaitlisted = "waitlisted"
+	RegistrationApproved   = "approved"
+	RegistrationRejected   = "rejected"
+	RegistrationWithdrawn  = "withdrawn"
+	RegistrationArchived   = "archived"
+)
+
+// registrationStatuses are the statuses a registration can be in.
+var registrationStatuses = []string{
+	RegistrationInvited,
+	RegistrationPending,
+	RegistrationWaitlisted,
+	RegistrationApproved,
+	RegistrationRejected,
+	RegistrationWithdrawn,
+	RegistrationArchived,
+}
+
+// holdsSpot reports whether a registration in this status counts towards the
+// capacity of its series.
+func holdsSpot(status string) bool {
+	return status == RegistrationPending || status == RegistrationApproved
+}
+
+// ErrInvalidTransition is returned when a registration or a season cannot
+// move from its current status to the requested one.
+var ErrInvalidTransition error = &Error{Kind: ErrConflict, Message: "invalid status transition"}
+
+// registrationActor is who may perform a transition.
+type registrationActor int
+
+const (
+	actorOrganizer registrationActor = 1 << iota
+	actorClubOwner
+)
+
+// registrationTransitions lists, per current status, the statuses a
+// registration may move to and who may move it there. Organizers decide on
+// entries; club owners can only pull their own team out. Waitlisted entries
+// move to pending only through promotion, when a spot frees up. Invitations
+// to a new season are accepted by moving them to pending, which waitlists
+// them when the series is full, or declined by withdrawing them.
+var registrationTransitions = map[string]map[string]registrationActor{
+	RegistrationInvited: {
+		RegistrationPending:   actorClubOwner,
+		RegistrationWithdrawn: actorOrganizer | actorClubOwner,
+	},
+	RegistrationWaitlisted: {
+		RegistrationRejected:  actorOrganizer,
+		RegistrationWithdrawn: actorOrganizer | actorClubOwner,
+	},
+	RegistrationPending: {
+		RegistrationApproved:  actorOrganizer,
+		RegistrationRejected:  actorOrganizer,
+		RegistrationWithdrawn: actorOrganizer | actorClubOwner,
+	},
+	RegistrationApproved: {
+		RegistrationWithdrawn: actorOrganizer | actorClubOwner,
+		RegistrationArchived:  actorOrganizer,
+	},
+	RegistrationRejected: {
+		RegistrationArchived: actorOrganizer,
+	},
+	RegistrationWithdrawn: {
+		RegistrationArchived: actorOrganizer,
+	},
+}
+
+func (s *LeaguesService) transitionRegistration(ctx context.Context, userID, id, to, reason string) (*domain.TeamRegistration, error) {
+	reg, err := s.store.GetRegistrationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil {
+		return nil, NotFound("registration not found")
+	}
+	allowed, ok := registrationTransitions[reg.Status][to]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, reg.Status, to)
+	}
+	if to == RegistrationRejected && reason == "" {
+		return nil, Invalid("reason", "a reason is required to reject a registration")
+	}
+	if reg.Status == RegistrationInvited && to == RegistrationPending {
+		ser, err := s.store.GetSeriesByID(ctx, reg.SeriesID)
+		if err != nil {
+			return nil, err
+		}
+		if ser != nil && ser.RegistrationClosesAt != nil && !time.Now().Before(*ser.RegistrationClosesAt) {
+			return nil, Conflict("registration for this series is closed")
+		}
+	}
+	if to == RegistrationApproved {
+		if open := unresolvedIssues(reg.EligibilityIssues); len(open) > 0 {
+			return nil, Conflict("registration has unresolved eligibility issues (%s); add an override first", describeIssues(open))
+		}
+	}
+
+	actor, err := s.registrationActor(ctx, userID, reg)
+	if err != nil {
+		return nil, err
+	}
+	if allowed&actor == 0 {
+		return nil, Forbidden("not allowed to move a registration to %s", to)
+	}
+
+	ev := &domain.RegistrationEvent{
+		ID:             util.RandID(),
+		RegistrationID: reg.ID,
+		FromStatus:     reg.Status,
+		ToStatus:       to,
+		ActorID:        userID,
+		Reason:         reason,
+	}
+	applied, err := s.store.UpdateRegistrationStatus(ctx, reg.ID, reg.Status, to, ev)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, fmt.Errorf("%w: registration changed concurrently", ErrInvalidTransition)
+	}
+	from := reg.Status
+	reg.Status = ev.ToStatus
+	if holdsSpot(from) && !holdsSpot(to) {
+		if _, err := s.promoteWaitlisted(ctx, userID, reg.SeriesID); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// promoteWaitlisted fills the free spots of a series from its waitlist, in
+// order of submission. Promoted registrations await approval as pending.
+func (s *LeaguesService) promoteWaitlisted(ctx context.Context, userID, seriesID string) ([]domain.TeamRegistration, error) {
+	return s.store.PromoteWaitlisted(ctx, seriesID, func(tr *domain.TeamRegistration) *domain.RegistrationEvent {
+		return &domain.RegistrationEvent{
+			ID:             util.RandID(),
+			RegistrationID: tr.ID,
+			FromStatus:     tr.Status,
+			ToStatus:       RegistrationPending,
+			ActorID:        userID,
+			Reason:         "promoted from the waitlist",
+		}
+	})
+}
+
+// registrationActor works out in which capacities userID acts on a
+// registration: as an organizer managing registrations of the series' league
+// and/or as an owner of the registered team's club.
+func (s *LeaguesService) registrationActor(ctx context.Context, userID string, reg *domain.TeamRegistration) (registrationActor, error) {
+	var actor registrationActor
+	ser, err := s.store.GetSeriesByID(ctx, reg.SeriesID)
+	if err != nil {
+		return 0, err
+	}
+	if ser != nil {
+		organizer, err := s.can(ctx, userID, ser.LeagueID, PermManageRegistrations)
+		if err != nil {
+			return 0, err
+		}
+		if organizer {
+			actor |= actorOrganizer
+		}
+	}
+	t, err := s.store.GetTeamByID(ctx, reg.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	if t != nil {
+		isOwner, err := s.store.IsOwner(ctx, userID, t.ClubID)
+		if err != nil {
+			return 0, err
+		}
+		if isOwner {
+			actor |= actorClubOwner
+		}
+	}
+	return actor, nil
+}
+
+// RegistrationHistory lists the status changes of a registration. It is
+// visible to the league organizers and the owners of the registered club.
+func (s *LeaguesService) RegistrationHistory(ctx context.Context, userID, id string) ([]domain.RegistrationEvent, error) {
+	reg, err := s.store.GetRegistrationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil {
+		return nil, NotFound("registration not found")
+	}
+	actor, err := s.registrationActor(ctx, userID, reg)
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view this registration")
+	}
+	return s.store.ListRegistrationEvents(ctx, reg.ID)
+}
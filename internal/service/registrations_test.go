@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+func TestRegistrationTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		actor  string
+		reason string
+		err    error
+	}{
+		{name: "organizer approves", from: RegistrationPending, to: RegistrationApproved, actor: organizer},
+		{name: "owner approves", from: RegistrationPending, to: RegistrationApproved, actor: clubOwner, err: ErrForbidden},
+		{name: "reject without reason", from: RegistrationPending, to: RegistrationRejected, actor: organizer, err: ErrValidation},
+		{name: "reject", from: RegistrationPending, to: RegistrationRejected, actor: organizer, reason: "late"},
+		{name: "owner withdraws", from: RegistrationPending, to: RegistrationWithdrawn, actor: clubOwner},
+		{name: "stranger withdraws", from: RegistrationPending, to: RegistrationWithdrawn, actor: stranger, err: ErrForbidden},
+		{name: "owner accepts invitation", from: RegistrationInvited, to: RegistrationPending, actor: clubOwner},
+		{name: "organizer accepts invitation", from: RegistrationInvited, to: RegistrationPending, actor: organizer, err: ErrForbidden},
+		{name: "waitlisted skips promotion", from: RegistrationWaitlisted, to: RegistrationPending, actor: organizer, err: ErrInvalidTransition},
+		{name: "waitlisted approved", from: RegistrationWaitlisted, to: RegistrationApproved, actor: organizer, err: ErrInvalidTransition},
+		{name: "approved withdrawn", from: RegistrationApproved, to: RegistrationWithdrawn, actor: clubOwner},
+		{name: "approved back to pending", from: RegistrationApproved, to: RegistrationPending, actor: organizer, err: ErrInvalidTransition},
+		{name: "withdrawn archived", from: RegistrationWithdrawn, to: RegistrationArchived, actor: organizer},
+		{name: "archived is final", from: RegistrationArchived, to: RegistrationPending, actor: organizer, err: ErrInvalidTransition},
+		{name: "unknown status", from: RegistrationPending, to: "foo", actor: organizer, err: ErrValidation},
+		{name: "no status", from: RegistrationPending, to: "", actor: organizer, err: ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			reg := tl.register("t1")[0]
+			if tt.from != reg.Status {
+				ev := &domain.RegistrationEvent{ID: util.RandID(), RegistrationID: reg.ID, FromStatus: reg.Status, ToStatus: tt.from}
+				if ok, err := tl.store.UpdateRegistrationStatus(tl.ctx, reg.ID, reg.Status, tt.from, ev); !ok || err != nil {
+					t.Fatalf("set status %s: %v", tt.from, err)
+				}
+			}
+
+			got, err := tl.svc.UpdateRegistrationStatus(tl.ctx, tt.actor, reg.ID, tt.to, tt.reason)
+			checkErr(t, err, tt.err)
+			want := tt.to
+			if tt.err != nil {
+				want = tt.from
+			} else if got.Status != tt.to {
+				t.Fatalf("moved to %s, want %s", got.Status, tt.to)
+			}
+			stored, err := tl.store.GetRegistrationByID(tl.ctx, reg.ID)
+			if err != nil {
+				t.Fatalf("get registration: %v", err)
+			}
+			if stored.Status != want {
+				t.Fatalf("stored status %s, want %s", stored.Status, want)
+			}
+		})
+	}
+}
+
+func TestWaitlistPromotion(t *testing.T) {
+	tests := []struct {
+		name     string
+		approve  bool   // Approve t1 and t2 first
+		to       string // What t1 moves to
+		actor    string
+		promoted bool
+	}{
+		{name: "pending withdrawn", to: RegistrationWithdrawn, actor: clubOwner, promoted: true},
+		{name: "pending rejected", to: RegistrationRejected, actor: organizer, promoted: true},
+		{name: "approved withdrawn", approve: true, to: RegistrationWithdrawn, actor: organizer, promoted: true},
+		{name: "pending approved", to: RegistrationApproved, actor: organizer},
+		{name: "approved archived", approve: true, to: RegistrationArchived, actor: organizer, promoted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{MaxTeams: ptr(2)})
+			regs := tl.register("t1", "t2", "t3", "t4")
+			want := []string{RegistrationPending, RegistrationPending, RegistrationWaitlisted, RegistrationWaitlisted}
+			for i, reg := range regs {
+				if reg.Status != want[i] {
+					t.Fatalf("%s registered as %s, want %s", reg.TeamID, reg.Status, want[i])
+				}
+			}
+			if tt.approve {
+				for _, reg := range regs[:2] {
+					if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, organizer, reg.ID, RegistrationApproved, ""); err != nil {
+						t.Fatalf("approve %s: %v", reg.TeamID, err)
+					}
+				}
+			}
+
+			if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, tt.actor, regs[0].ID, tt.to, "reason"); err != nil {
+				t.Fatalf("move t1 to %s: %v", tt.to, err)
+			}
+			// Only the first on the waitlist moves up.
+			want = []string{RegistrationWaitlisted, RegistrationWaitlisted}
+			if tt.promoted {
+				want[0] = RegistrationPending
+			}
+			for i, reg := range regs[2:] {
+				stored, err := tl.store.GetRegistrationByID(tl.ctx, reg.ID)
+				if err != nil {
+					t.Fatalf("get registration: %v", err)
+				}
+				if stored.Status != want[i] {
+					t.Errorf("%s is %s, want %s", reg.TeamID, stored.Status, want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Reschedule requests
+
+const (
+	ReschedulePending   = "pending"
+	RescheduleAccepted  = "accepted"
+	RescheduleConfirmed = "confirmed"
+	RescheduleDeclined  = "declined"
+	RescheduleRejected  = "rejected"
+	RescheduleWithdrawn = "withdrawn"
+	RescheduleExpired   = "expired"
+)
+
+var rescheduleStatuses = []string{ReschedulePending, RescheduleAccepted, RescheduleConfirmed, RescheduleDeclined, RescheduleRejected, RescheduleWithdrawn, RescheduleExpired}
+
+// rescheduleWindow is how long a request stays open unless it sets an
+// earlier deadline.
+const rescheduleWindow = 7 * 24 * time.Hour
+
+// rescheduleActor is who may move a reschedule request.
+type rescheduleActor int
+
+const (
+	actorRequester rescheduleActor = 1 << iota // Owner of the requesting team's club
+	actorOpponent                              // Owner of the other team's club
+	actorScheduler                             // Scheduler of the league
+)
+
+// rescheduleTransitions lists, per open status, the statuses a request may
+// move to and who may move it there. Requests left open past their deadline
+// expire when next read.
+var rescheduleTransitions = map[string]map[string]rescheduleActor{
+	ReschedulePending: {
+		RescheduleAccepted:  actorOpponent,
+		RescheduleDeclined:  actorOpponent,
+		RescheduleWithdrawn: actorRequester,
+	},
+	RescheduleAccepted: {
+		RescheduleConfirmed: actorScheduler,
+		RescheduleRejected:  actorScheduler,
+		RescheduleWithdrawn: actorRequester,
+	},
+}
+
+// RescheduleInput proposes a new slot for a match.
+type RescheduleInput struct {
+	SlotInput
+	// TeamID is the requesting team; it can be left out unless the user
+	// owns the clubs of both teams.
+	TeamID    string     `json:"teamId"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Defaults to a week, or the kickoff if sooner
+}
+
+// RequestReschedule proposes moving a match still to be played to another
+// slot, on behalf of one of its teams. The slot must be free when proposed
+// and is checked again when the request is confirmed.
+func (s *LeaguesService) RequestReschedule(ctx context.Context, userID, leagueID, seriesID, matchID string, in RescheduleInput) (*domain.RescheduleRequest, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != "scheduled" {
+		return nil, Conflict("the match is %s; only matches still to be played can be rescheduled", m.Status)
+	}
+	teamID, err := s.requestingTeam(ctx, userID, m, in.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	planned := *m
+	if err := s.planSlot(ctx, leagueID, &planned, in.SlotInput); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !planned.KickoffAt.After(now) {
+		return nil, Invalid("kickoffAt", "the new kickoff must be in the future")
+	}
+	if m.KickoffAt != nil && m.KickoffAt.Equal(*planned.KickoffAt) && m.VenueID == planned.VenueID && m.Pitch == planned.Pitch {
+		return nil, Invalid("kickoffAt", "the match is already booked into this slot")
+	}
+	// The request must be settled before either kickoff comes around.
+	deadline := *planned.KickoffAt
+	if m.KickoffAt != nil && m.KickoffAt.After(now) && m.KickoffAt.Before(deadline) {
+		deadline = *m.KickoffAt
+	}
+	expires := now.Add(rescheduleWindow)
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(now) {
+			return nil, Invalid("expiresAt", "the deadline must be in the future")
+		}
+		expires = *in.ExpiresAt
+	}
+	if expires.After(deadline) {
+		if in.ExpiresAt != nil {
+			return nil, Invalid("expiresAt", "the deadline must not be after %s", deadline.Format(time.RFC3339))
+		}
+		expires = deadline
+	}
+	r := &domain.RescheduleRequest{
+		ID:          util.RandID(),
+		MatchID:     m.ID,
+		SeriesID:    m.SeriesID,
+		TeamID:      teamID,
+		VenueID:     planned.VenueID,
+		Pitch:       planned.Pitch,
+		KickoffAt:   *planned.KickoffAt,
+		EndsAt:      *planned.EndsAt,
+		Reason:      strings.TrimSpace(in.Reason),
+		Status:      ReschedulePending,
+		ExpiresAt:   expires.UTC(),
+		RequestedBy: userID,
+	}
+	ev := &domain.RescheduleEvent{ID: util.RandID(), RequestID: r.ID, ToStatus: ReschedulePending, ActorID: userID, Reason: r.Reason}
+	if err := s.store.CreateRescheduleRequest(ctx, r, ev); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// requestingTeam works out on behalf of which team of the match the user
+// asks; teamID names it when the user owns both clubs.
+func (s *LeaguesService) requestingTeam(ctx context.Context, userID string, m *domain.Match, teamID string) (string, error) {
+	if teamID != "" {
+		if teamID != m.HomeTeamID && teamID != m.AwayTeamID {
+			return "", Invalid("teamId", "team %s does not play this match", teamID)
+		}
+		owns, err := s.ownsTeam(ctx, userID, teamID)
+		if err != nil {
+			return "", err
+		}
+		if !owns {
+			return "", Forbidden("only the club owner can request a reschedule for the team")
+		}
+		return teamID, nil
+	}
+	var owned []string
+	for _, id := range []string{m.HomeTeamID, m.AwayTeamID} {
+		owns, err := s.ownsTeam(ctx, userID, id)
+		if err != nil {
+			return "", err
+		}
+		if owns {
+			owned = append(owned, id)
+		}
+	}
+	switch len(owned) {
+	case 0:
+		return "", Forbidden("only a club owner of one of the teams can request a reschedule")
+	case 1:
+		return owned[0], nil
+	default:
+		return "", Invalid("teamId", "you own the clubs of both teams; teamId must name the requesting team")
+	}
+}
+
+// ownsTeam reports whether the user owns the club of the team.
+func (s *LeaguesService) ownsTeam(ctx context.Context, userID, teamID string) (bool, error) {
+	t, err := s.store.GetTeamByID(ctx, teamID)
+	if err != nil || t == nil {
+		return false, err
+	}
+	return s.store.IsOwner(ctx, userID, t.ClubID)
+}
+
+// rescheduleActor works out in which capacities userID acts on requests for
+// the match made by teamID. Owners of the clubs of both teams act on both
+// sides.
+func (s *LeaguesService) rescheduleActor(ctx context.Context, userID string, m *domain.Match, teamID string) (rescheduleActor, error) {
+	var actor rescheduleActor
+	for _, id := range []string{m.HomeTeamID, m.AwayTeamID} {
+		owns, err := s.ownsTeam(ctx, userID, id)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case owns && id == teamID:
+			actor |= actorRequester
+		case owns:
+			actor |= actorOpponent
+		}
+	}
+	ser, err := s.store.GetSeriesByID(ctx, m.SeriesID)
+	if err != nil {
+		return 0, err
+	}
+	if ser != nil {
+		scheduler, err := s.can(ctx, userID, ser.LeagueID, PermManageSchedule)
+		if err != nil {
+			return 0, err
+		}
+		if scheduler {
+			actor |= actorScheduler
+		}
+	}
+	return actor, nil
+}
+
+// loadReschedule loads a request with its match, expiring the request if
+// its deadline has passed.
+func (s *LeaguesService) loadReschedule(ctx context.Context, id string) (*domain.RescheduleRequest, *domain.Match, error) {
+	r, err := s.store.GetRescheduleRequest(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r == nil {
+		return nil, nil, NotFound("reschedule request not found")
+	}
+	m, err := s.store.GetMatchByID(ctx, r.MatchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m == nil {
+		return nil, nil, NotFound("reschedule request not found")
+	}
+	if err := s.expireReschedule(ctx, r); err != nil {
+		return nil, nil, err
+	}
+	return r, m, nil
+}
+
+// expireReschedule moves an open request past its deadline to expired.
+func (s *LeaguesService) expireReschedule(ctx context.Context, r *domain.RescheduleRequest) error {
+	if _, open := rescheduleTransitions[r.Status]; !open || time.Now().Before(r.ExpiresAt) {
+		return nil
+	}
+	ev := &domain.RescheduleEvent{
+		ID:         util.RandID(),
+		RequestID:  r.ID,
+		FromStatus: r.Status,
+		ToStatus:   RescheduleExpired,
+		Reason:     "not settled before the deadline",
+	}
+	applied, err := s.store.UpdateRescheduleStatus(ctx, r.ID, r.Status, RescheduleExpired, ev, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		// Settled or expired concurrently; take the stored status.
+		cur, err := s.store.GetRescheduleRequest(ctx, r.ID)
+		if err != nil || cur == nil {
+			return err
+		}
+		*r = *cur
+		return nil
+	}
+	r.Status = RescheduleExpired
+	return nil
+}
+
+// GetRescheduleRequest returns a request to the club owners of either team
+// and the league's schedulers.
+func (s *LeaguesService) GetRescheduleRequest(ctx context.Context, userID, id string) (*domain.RescheduleRequest, error) {
+	r, m, err := s.loadReschedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.rescheduleActor(ctx, userID, m, r.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view this reschedule request")
+	}
+	return r, nil
+}
+
+// UpdateRescheduleStatus moves a request through the workflow. Confirming
+// it books the match into the requested slot, which must still be free, and
+// releases the officials who cannot take the match there.
+func (s *LeaguesService) UpdateRescheduleStatus(ctx context.Context, userID, id, to, reason string) (*domain.RescheduleRequest, error) {
+	if !slices.Contains(rescheduleStatuses, to) {
+		return nil, Invalid("status", "unknown status %q", to)
+	}
+	r, m, err := s.loadReschedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowed, ok := rescheduleTransitions[r.Status][to]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, r.Status, to)
+	}
+	reason = strings.TrimSpace(reason)
+	if (to == RescheduleDeclined || to == RescheduleRejected) && reason == "" {
+		return nil, Invalid("reason", "a reason is required to %s a reschedule request", strings.TrimSuffix(to, "d"))
+	}
+	actor, err := s.rescheduleActor(ctx, userID, m, r.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if allowed&actor == 0 {
+		return nil, Forbidden("not allowed to move a reschedule request to %s", to)
+	}
+
+	var slot *domain.Match
+	var released []domain.RefereeAssignment
+	if to == RescheduleConfirmed {
+		if m.Status != "scheduled" {
+			return nil, Conflict("the match is %s; only matches still to be played can be rescheduled", m.Status)
+		}
+		ser, err := s.store.GetSeriesByID(ctx, m.SeriesID)
+		if err != nil {
+			return nil, err
+		}
+		if ser == nil {
+			return nil, NotFound("series not found")
+		}
+		slot = m
+		if err := s.planSlot(ctx, ser.LeagueID, slot, SlotInput{VenueID: r.VenueID, Pitch: r.Pitch, KickoffAt: r.KickoffAt}); err != nil {
+			return nil, err
+		}
+		if released, err = s.releasedOfficials(ctx, ser.LeagueID, []domain.Match{*slot}); err != nil {
+			return nil, err
+		}
+	}
+	ev := &domain.RescheduleEvent{
+		ID:         util.RandID(),
+		RequestID:  r.ID,
+		FromStatus: r.Status,
+		ToStatus:   to,
+		ActorID:    userID,
+		Reason:     reason,
+	}
+	applied, err := s.store.UpdateRescheduleStatus(ctx, r.ID, r.Status, to, ev, slot, assignmentIDs(released))
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, fmt.Errorf("%w: reschedule request changed concurrently", ErrInvalidTransition)
+	}
+	r.Status = to
+	return r, nil
+}
+
+// RescheduleHistory lists the status changes of a request, oldest first.
+func (s *LeaguesService) RescheduleHistory(ctx context.Context, userID, id string) ([]domain.RescheduleEvent, error) {
+	r, err := s.GetRescheduleRequest(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListRescheduleEvents(ctx, r.ID)
+}
+
+// ListMatchReschedules returns the requests made for a match, oldest first,
+// to the club owners of either team and the league's schedulers.
+func (s *LeaguesService) ListMatchReschedules(ctx context.Context, userID, leagueID, seriesID, matchID string) ([]domain.RescheduleRequest, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.rescheduleActor(ctx, userID, m, "")
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view the match's reschedule requests")
+	}
+	list, err := s.store.ListRescheduleRequestsByMatch(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if err := s.expireReschedule(ctx, &list[i]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// RescheduleQuery filters the reschedule requests of a league.
+type RescheduleQuery struct {
+	Status string `form:"status"` // Comma-separated; e.g. "accepted" for those awaiting confirmation
+}
+
+// ListLeagueReschedules returns the reschedule requests for matches of the
+// league, oldest first.
+func (s *LeaguesService) ListLeagueReschedules(ctx context.Context, leagueID string, q RescheduleQuery) ([]domain.RescheduleRequest, error) {
+	var statuses []string
+	if q.Status != "" {
+		for _, st := range strings.Split(q.Status, ",") {
+			st = strings.TrimSpace(st)
+			if !slices.Contains(rescheduleStatuses, st) {
+				return nil, Invalid("status", "unknown status %q", st)
+			}
+			statuses = append(statuses, st)
+		}
+	}
+	list, err := s.store.ListRescheduleRequestsByLeague(ctx, leagueID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if err := s.expireReschedule(ctx, &list[i]); err != nil {
+			return nil, err
+		}
+	}
+	// Requests that just expired no longer match the filter.
+	return slices.DeleteFunc(list, func(r domain.RescheduleRequest) bool {
+		return len(statuses) > 0 && !slices.Contains(statuses, r.Status)
+	}), nil
+}
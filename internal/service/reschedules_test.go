@@ -0,0 +1,182 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// newSlot is a free Saturday slot at the end of the season.
+var newSlot = time.Date(2027, 6, 26, 12, 0, 0, 0, time.UTC)
+
+// lapsed stores a request for the match, in the given status, whose
+// deadline passed a minute ago.
+func (tl *testLeague) lapsed(m domain.Match, status string) *domain.RescheduleRequest {
+	tl.t.Helper()
+	r := &domain.RescheduleRequest{
+		ID:          util.RandID(),
+		MatchID:     m.ID,
+		SeriesID:    m.SeriesID,
+		TeamID:      m.HomeTeamID,
+		VenueID:     m.VenueID,
+		Pitch:       m.Pitch,
+		KickoffAt:   newSlot,
+		EndsAt:      newSlot.Add(90 * time.Minute),
+		Status:      status,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		RequestedBy: clubOwner,
+	}
+	ev := &domain.RescheduleEvent{ID: util.RandID(), RequestID: r.ID, ToStatus: status, ActorID: clubOwner}
+	if err := tl.store.CreateRescheduleRequest(tl.ctx, r, ev); err != nil {
+		tl.t.Fatalf("create request: %v", err)
+	}
+	return r
+}
+
+func TestRescheduleExpiry(t *testing.T) {
+	tests := []struct {
+		status  string
+		expires bool
+	}{
+		{status: ReschedulePending, expires: true},
+		{status: RescheduleAccepted, expires: true},
+		{status: RescheduleConfirmed},
+		{status: RescheduleDeclined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			m := tl.scheduled(false)[0]
+			r := tl.lapsed(m, tt.status)
+			want := tt.status
+			if tt.expires {
+				want = RescheduleExpired
+			}
+
+			got, err := tl.svc.GetRescheduleRequest(tl.ctx, organizer, r.ID)
+			checkErr(t, err, nil)
+			if got.Status != want {
+				t.Fatalf("status %s, want %s", got.Status, want)
+			}
+			stored, err := tl.store.GetRescheduleRequest(tl.ctx, r.ID)
+			if err != nil || stored.Status != want {
+				t.Fatalf("stored %+v, %v", stored, err)
+			}
+			history, err := tl.svc.RescheduleHistory(tl.ctx, organizer, r.ID)
+			checkErr(t, err, nil)
+			last := history[len(history)-1]
+			if tt.expires != (len(history) == 2) || (tt.expires && (last.FromStatus != tt.status || last.ToStatus != RescheduleExpired || last.Reason != "not settled before the deadline")) {
+				t.Fatalf("history %+v", history)
+			}
+
+			// An expired request is closed, and no longer holds the match.
+			_, err = tl.svc.UpdateRescheduleStatus(tl.ctx, clubOwner, r.ID, RescheduleWithdrawn, "")
+			checkErr(t, err, ErrConflict)
+			_, err = tl.svc.RequestReschedule(tl.ctx, clubOwner, tl.league.ID, tl.series.ID, m.ID, RescheduleInput{SlotInput: SlotInput{VenueID: m.VenueID, Pitch: 1, KickoffAt: newSlot}, TeamID: m.AwayTeamID})
+			checkErr(t, err, nil)
+		})
+	}
+}
+
+func TestListReschedulesExpiry(t *testing.T) {
+	tl := newTestLeague(t, SeriesSettings{})
+	matches := tl.scheduled(false)
+	lapsed := tl.lapsed(matches[0], ReschedulePending)
+	open, err := tl.svc.RequestReschedule(tl.ctx, clubOwner, tl.league.ID, tl.series.ID, matches[1].ID, RescheduleInput{
+		SlotInput: SlotInput{VenueID: matches[1].VenueID, Pitch: 1, KickoffAt: newSlot},
+		TeamID:    matches[1].HomeTeamID,
+	})
+	if err != nil {
+		t.Fatalf("request reschedule: %v", err)
+	}
+
+	// Requests that expire as they are read drop out of a filter on open
+	// statuses.
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{status: ReschedulePending, want: []string{open.ID}},
+		{status: RescheduleExpired, want: []string{lapsed.ID}},
+		{status: "", want: []string{lapsed.ID, open.ID}},
+		{status: "pending,expired", want: []string{lapsed.ID, open.ID}},
+	}
+	for _, tt := range tests {
+		list, err := tl.svc.ListLeagueReschedules(tl.ctx, tl.league.ID, RescheduleQuery{Status: tt.status})
+		checkErr(t, err, nil)
+		var got []string
+		for _, r := range list {
+			got = append(got, r.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("status %q: got %v, want %v", tt.status, got, tt.want)
+		}
+	}
+	_, err = tl.svc.ListLeagueReschedules(tl.ctx, tl.league.ID, RescheduleQuery{Status: "pending,late"})
+	checkErr(t, err, ErrValidation)
+
+	list, err := tl.svc.ListMatchReschedules(tl.ctx, clubOwner, tl.league.ID, tl.series.ID, matches[0].ID)
+	checkErr(t, err, nil)
+	if len(list) != 1 || list[0].Status != RescheduleExpired {
+		t.Fatalf("match requests %+v", list)
+	}
+}
+
+func TestRescheduleDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		expires *time.Time
+		want    time.Time
+		err     error
+	}{
+		{name: "default", want: time.Now().Add(rescheduleWindow)},
+		{name: "earlier", expires: ptr(time.Now().Add(time.Hour)), want: time.Now().Add(time.Hour)},
+		{name: "passed", expires: ptr(time.Now().Add(-time.Hour)), err: ErrValidation},
+		{name: "after the new kickoff", expires: ptr(newSlot.Add(time.Minute)), err: ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			m := tl.scheduled(false)[0]
+			r, err := tl.svc.RequestReschedule(tl.ctx, clubOwner, tl.league.ID, tl.series.ID, m.ID, RescheduleInput{
+				SlotInput: SlotInput{VenueID: m.VenueID, Pitch: 1, KickoffAt: newSlot},
+				TeamID:    m.HomeTeamID,
+				ExpiresAt: tt.expires,
+			})
+			checkErr(t, err, tt.err)
+			if err == nil && r.ExpiresAt.Sub(tt.want).Abs() > time.Minute {
+				t.Fatalf("expires %s, want %s", r.ExpiresAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRescheduleStatusInvalid(t *testing.T) {
+	tests := []struct {
+		status string
+		err    error
+	}{
+		{status: "", err: ErrValidation},
+		{status: "postponed", err: ErrValidation},
+		{status: RescheduleConfirmed, err: ErrConflict}, // Not yet accepted
+		{status: RescheduleAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			m := tl.scheduled(false)[0]
+			r, err := tl.svc.RequestReschedule(tl.ctx, clubOwner, tl.league.ID, tl.series.ID, m.ID, RescheduleInput{
+				SlotInput: SlotInput{VenueID: m.VenueID, Pitch: 1, KickoffAt: newSlot},
+				TeamID:    m.HomeTeamID,
+			})
+			if err != nil {
+				t.Fatalf("request reschedule: %v", err)
+			}
+			_, err = tl.svc.UpdateRescheduleStatus(tl.ctx, clubOwner, r.ID, tt.status, "")
+			checkErr(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Results
+
+// walkoverGoals is the score credited to the winner of a walkover.
+const walkoverGoals = 3
+
+// ResultInput is the result reported for a fixture.
+type ResultInput struct {
+	Outcome          string `json:"outcome"` // "played", "walkover", "abandoned"
+	HomeScore        int    `json:"homeScore"`
+	AwayScore        int    `json:"awayScore"`
+	WalkoverWinnerID string `json:"walkoverWinnerId"`
+	HomeFairPlay     int    `json:"homeFairPlay"`
+	AwayFairPlay     int    `json:"awayFairPlay"`
+	HomePenalties    *int   `json:"homePenalties"` // Shoot-out deciding a level knockout tie
+	AwayPenalties    *int   `json:"awayPenalties"`
+}
+
+// RecordResult stores or replaces the result of a fixture. A walkover credits
+// the winner with a 3-0 win; an abandoned match keeps the score at the time it
+// was stopped but does not count towards the standings. Knockout results
+// advance the winner of the tie once it is decided.
+func (s *LeaguesService) RecordResult(ctx context.Context, userID, leagueID, seriesID, matchID string, in ResultInput) (*domain.MatchResult, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.HomeFairPlay < 0 || in.AwayFairPlay < 0 {
+		return nil, Invalid("", "invalid fair-play points")
+	}
+	res := &domain.MatchResult{
+		MatchID:       m.ID,
+		Outcome:       in.Outcome,
+		HomeFairPlay:  in.HomeFairPlay,
+		AwayFairPlay:  in.AwayFairPlay,
+		HomePenalties: in.HomePenalties,
+		AwayPenalties: in.AwayPenalties,
+		RecordedBy:    userID,
+	}
+	switch in.Outcome {
+	case "played", "abandoned":
+		if in.HomeScore < 0 || in.AwayScore < 0 {
+			return nil, Invalid("", "invalid score")
+		}
+		res.HomeScore, res.AwayScore = in.HomeScore, in.AwayScore
+	case "walkover":
+		switch in.WalkoverWinnerID {
+		case m.HomeTeamID:
+			res.HomeScore, res.AwayScore = walkoverGoals, 0
+		case m.AwayTeamID:
+			res.HomeScore, res.AwayScore = 0, walkoverGoals
+		default:
+			return nil, Invalid("walkoverWinnerId", "walkover winner must be one of the teams in the match")
+		}
+		res.WalkoverWinnerID = in.WalkoverWinnerID
+	default:
+		return nil, Invalid("outcome", "invalid outcome")
+	}
+
+	if (in.HomePenalties != nil && *in.HomePenalties < 0) || (in.AwayPenalties != nil && *in.AwayPenalties < 0) {
+		return nil, Invalid("", "invalid penalties")
+	}
+	if m.TieID != "" {
+		if err := s.checkKnockoutResult(ctx, m, res); err != nil {
+			return nil, err
+		}
+	} else if in.HomePenalties != nil || in.AwayPenalties != nil {
+		return nil, Invalid("", "penalties only apply to knockout ties")
+	}
+
+	if err := s.store.SaveMatchResult(ctx, res); err != nil {
+		return nil, err
+	}
+	if m.TieID != "" {
+		if err := s.settleTie(ctx, m.TieID); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (s *LeaguesService) ClearResult(ctx context.Context, leagueID, seriesID, matchID string) error {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return err
+	}
+	if m.TieID == "" {
+		return s.store.DeleteMatchResult(ctx, m.ID)
+	}
+	if err := s.checkKnockoutResult(ctx, m, nil); err != nil {
+		return err
+	}
+	if err := s.store.DeleteMatchResult(ctx, m.ID); err != nil {
+		return err
+	}
+	return s.settleTie(ctx, m.TieID)
+}
+
+// getSeriesMatch loads a match and checks it belongs to the series and league.
+func (s *LeaguesService) getSeriesMatch(ctx context.Context, leagueID, seriesID, matchID string) (*domain.Match, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	m, err := s.store.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.SeriesID != seriesID {
+		return nil, NotFound("match not found")
+	}
+	return m, nil
+}
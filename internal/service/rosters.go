@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Rosters
+
+const (
+	RosterAdded   = "added"
+	RosterUpdated = "updated"
+	RosterRemoved = "removed"
+	// Written by completed transfers
+	RosterTransferredOut = "transferred_out"
+	RosterTransferredIn  = "transferred_in"
+)
+
+// RosterPlayerInput lists a player on a roster, or updates the listing.
+type RosterPlayerInput struct {
+	Name        string `json:"name"`
+	BirthDate   string `json:"birthDate"` // YYYY-MM-DD
+	ShirtNumber int    `json:"shirtNumber"`
+	Reason      string `json:"reason"` // Required once the roster has locked
+}
+
+// rosterAccess loads a registration with its series and works out in which
+// capacities userID acts on its roster.
+func (s *LeaguesService) rosterAccess(ctx context.Context, userID, registrationID string) (*domain.TeamRegistration, *domain.Series, registrationActor, error) {
+	reg, err := s.store.GetRegistrationByID(ctx, registrationID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if reg == nil {
+		return nil, nil, 0, NotFound("registration not found")
+	}
+	ser, err := s.store.GetSeriesByID(ctx, reg.SeriesID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if ser == nil {
+		return nil, nil, 0, NotFound("series not found")
+	}
+	actor, err := s.registrationActor(ctx, userID, reg)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return reg, ser, actor, nil
+}
+
+// rosterLocked reports whether the series' rosters are past their lock date.
+func rosterLocked(ser *domain.Series) bool {
+	return ser.RosterLocksAt != nil && !time.Now().Before(*ser.RosterLocksAt)
+}
+
+// checkRosterChange lets the club owner change the roster until it locks;
+// after that only league organizers can, giving a reason. The roster of a
+// withdrawn, rejected or archived registration no longer changes.
+func checkRosterChange(reg *domain.TeamRegistration, ser *domain.Series, actor registrationActor, reason string) error {
+	if actor == 0 {
+		return Forbidden("only the club owner or league organizers can manage the roster")
+	}
+	switch reg.Status {
+	case RegistrationInvited, RegistrationPending, RegistrationWaitlisted, RegistrationApproved:
+	default:
+		return Conflict("the registration is %s; its roster can no longer change", reg.Status)
+	}
+	if !rosterLocked(ser) {
+		return nil
+	}
+	if actor&actorOrganizer == 0 {
+		return Forbidden("the roster locked at %s; only league organizers can change it", ser.RosterLocksAt.Format(time.RFC3339))
+	}
+	if reason == "" {
+		return Invalid("reason", "a reason is required to change the roster after it locked")
+	}
+	return nil
+}
+
+// ageOn is the age in whole years, on day, of someone born on birth.
+func ageOn(birth, day time.Time) int {
+	age := day.Year() - birth.Year()
+	if day.Month() < birth.Month() || (day.Month() == birth.Month() && day.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// checkRosterAge requires players of a "U<n>" series to be younger than n on
+// the day the season starts.
+func (s *LeaguesService) checkRosterAge(ctx context.Context, ser *domain.Series, birth time.Time) error {
+	limit, ok := ageLimit(ser.AgeCategory)
+	if !ok {
+		return nil
+	}
+	season, err := s.store.GetSeasonByID(ctx, ser.SeasonID)
+	if err != nil || season == nil {
+		return err
+	}
+	if age := ageOn(birth, season.StartsOn); age >= limit {
+		return Invalid("birthDate", "the player is %d on %s, when the season starts; %s is for players under %d",
+			age, season.StartsOn.Format(time.DateOnly), ser.AgeCategory, limit)
+	}
+	return nil
+}
+
+// SaveRosterPlayer lists a player on the roster of a registration, or
+// updates the listing.
+func (s *LeaguesService) SaveRosterPlayer(ctx context.Context, userID, registrationID, playerID string, in RosterPlayerInput) (*domain.RosterPlayer, error) {
+	reg, ser, actor, err := s.rosterAccess(ctx, userID, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if err := checkRosterChange(reg, ser, actor, reason); err != nil {
+		return nil, err
+	}
+	playerID = strings.TrimSpace(playerID)
+	if playerID == "" {
+		return nil, Invalid("playerId", "invalid player")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, Invalid("name", "invalid name")
+	}
+	birth, err := time.Parse(time.DateOnly, in.BirthDate)
+	if err != nil {
+		return nil, Invalid("birthDate", "birth date must be like 2014-05-31")
+	}
+	if birth.After(time.Now()) {
+		return nil, Invalid("birthDate", "birth date must not be in the future")
+	}
+	if in.ShirtNumber < 1 || in.ShirtNumber > 99 {
+		return nil, Invalid("shirtNumber", "shirt number must be between 1 and 99")
+	}
+	if err := s.checkRosterAge(ctx, ser, birth); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.GetRosterPlayer(ctx, reg.ID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	action := RosterUpdated
+	if existing == nil {
+		action = RosterAdded
+	}
+	late := rosterLocked(ser)
+	p := &domain.RosterPlayer{
+		RegistrationID: reg.ID,
+		SeriesID:       reg.SeriesID,
+		TeamID:         reg.TeamID,
+		PlayerID:       playerID,
+		Name:           name,
+		BirthDate:      birth,
+		ShirtNumber:    in.ShirtNumber,
+		Late:           late,
+		AddedBy:        userID,
+	}
+	ch := &domain.RosterChange{
+		ID:             util.RandID(),
+		RegistrationID: reg.ID,
+		SeriesID:       reg.SeriesID,
+		PlayerID:       playerID,
+		Action:         action,
+		ShirtNumber:    in.ShirtNumber,
+		Late:           late,
+		ActorID:        userID,
+		Reason:         reason,
+	}
+	maxSize := 0
+	if ser.MaxRosterSize != nil {
+		maxSize = *ser.MaxRosterSize
+	}
+	saved, err := s.store.SaveRosterPlayer(ctx, p, ch, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, Conflict("the roster is full: %s allows %d players", ser.Name, maxSize)
+	}
+	return p, nil
+}
+
+// RemoveRosterPlayer takes a player off the roster of a registration.
+func (s *LeaguesService) RemoveRosterPlayer(ctx context.Context, userID, registrationID, playerID, reason string) error {
+	reg, ser, actor, err := s.rosterAccess(ctx, userID, registrationID)
+	if err != nil {
+		return err
+	}
+	reason = strings.TrimSpace(reason)
+	if err := checkRosterChange(reg, ser, actor, reason); err != nil {
+		return err
+	}
+	p, err := s.store.GetRosterPlayer(ctx, reg.ID, playerID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return NotFound("player not on the roster")
+	}
+	ch := &domain.RosterChange{
+		ID:             util.RandID(),
+		RegistrationID: reg.ID,
+		SeriesID:       reg.SeriesID,
+		PlayerID:       playerID,
+		Action:         RosterRemoved,
+		ShirtNumber:    p.ShirtNumber,
+		Late:           rosterLocked(ser),
+		ActorID:        userID,
+		Reason:         reason,
+	}
+	removed, err := s.store.DeleteRosterPlayer(ctx, reg.ID, playerID, ch)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return NotFound("player not on the roster")
+	}
+	return nil
+}
+
+// ListRoster returns the players of a registration by shirt number. Rosters
+// hold birth dates, so only the club owner and league organizers see them.
+func (s *LeaguesService) ListRoster(ctx context.Context, userID, registrationID string) ([]domain.RosterPlayer, error) {
+	reg, _, actor, err := s.rosterAccess(ctx, userID, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view this roster")
+	}
+	return s.store.ListRosterPlayers(ctx, reg.ID)
+}
+
+// RosterHistory lists the changes to a registration's roster, oldest first.
+func (s *LeaguesService) RosterHistory(ctx context.Context, userID, registrationID string) ([]domain.RosterChange, error) {
+	reg, _, actor, err := s.rosterAccess(ctx, userID, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view this roster")
+	}
+	return s.store.ListRosterChanges(ctx, reg.ID)
+}
+
+// ListLateRosterChanges returns the changes made to the series' rosters after
+// they locked, oldest first.
+func (s *LeaguesService) ListLateRosterChanges(ctx context.Context, leagueID, seriesID string) ([]domain.RosterChange, error) {
+	if _, err := s.leagueSeries(ctx, leagueID, seriesID); err != nil {
+		return nil, err
+	}
+	return s.store.ListLateRosterChanges(ctx, seriesID)
+}
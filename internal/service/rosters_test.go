@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRosterLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		locked    bool
+		actor     string
+		reason    string
+		withdrawn bool
+		err       error
+	}{
+		{name: "club owner before lock", actor: clubOwner},
+		{name: "organizer before lock", actor: organizer},
+		{name: "stranger", actor: stranger, err: ErrForbidden},
+		{name: "club owner after lock", locked: true, actor: clubOwner, reason: "injury", err: ErrForbidden},
+		{name: "organizer after lock", locked: true, actor: organizer, reason: "injury"},
+		{name: "organizer after lock without reason", locked: true, actor: organizer, err: ErrValidation},
+		{name: "withdrawn", actor: clubOwner, withdrawn: true, err: ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locksAt := time.Now().Add(time.Hour)
+			if tt.locked {
+				locksAt = time.Now().Add(-time.Hour)
+			}
+			tl := newTestLeague(t, SeriesSettings{RosterLocksAt: &locksAt})
+			reg := tl.register("t1")[0]
+			if tt.withdrawn {
+				if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, clubOwner, reg.ID, RegistrationWithdrawn, ""); err != nil {
+					t.Fatalf("withdraw: %v", err)
+				}
+			}
+			late := func() int {
+				t.Helper()
+				changes, err := tl.svc.ListLateRosterChanges(tl.ctx, tl.league.ID, tl.series.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return len(changes)
+			}
+
+			p, err := tl.svc.SaveRosterPlayer(tl.ctx, tt.actor, reg.ID, "p1", RosterPlayerInput{Name: "Ana", BirthDate: "2000-01-01", ShirtNumber: 9, Reason: tt.reason})
+			checkErr(t, err, tt.err)
+			if err != nil {
+				if n := late(); n != 0 {
+					t.Fatalf("%d late changes after a refused change", n)
+				}
+				return
+			}
+			if p.Late != tt.locked {
+				t.Fatalf("late %v", p.Late)
+			}
+			if err := tl.svc.RemoveRosterPlayer(tl.ctx, tt.actor, reg.ID, "p1", tt.reason); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+			history, err := tl.svc.RosterHistory(tl.ctx, organizer, reg.ID)
+			checkErr(t, err, nil)
+			if len(history) != 2 || history[0].Action != RosterAdded || history[1].Action != RosterRemoved {
+				t.Fatalf("history %+v", history)
+			}
+			for _, ch := range history {
+				if ch.Late != tt.locked || ch.Reason != tt.reason || ch.ActorID != tt.actor {
+					t.Fatalf("change %+v", ch)
+				}
+			}
+			want := 0
+			if tt.locked {
+				want = 2
+			}
+			if n := late(); n != want {
+				t.Fatalf("%d late changes, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestRosterAge(t *testing.T) {
+	// The season starts on 2027-03-01.
+	tests := []struct {
+		name     string
+		category string
+		birth    string
+		err      error
+	}{
+		{name: "under the limit", category: "U12", birth: "2015-03-02"},
+		{name: "birthday on the start", category: "U12", birth: "2015-03-01", err: ErrValidation},
+		{name: "over the limit", category: "U12", birth: "2010-06-15", err: ErrValidation},
+		{name: "open series", birth: "1990-06-15"},
+		{name: "not a date", birth: "15/06/1990", err: ErrValidation},
+		{name: "not born yet", birth: time.Now().AddDate(0, 0, 2).Format(time.DateOnly), err: ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{AgeCategory: &tt.category})
+			reg, err := tl.svc.RegisterTeam(tl.ctx, clubOwner, RegistrationInput{TeamID: "t1", SeriesID: tl.series.ID, AgeCategory: tt.category})
+			if err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			_, err = tl.svc.SaveRosterPlayer(tl.ctx, clubOwner, reg.ID, "p1", RosterPlayerInput{Name: "Ana", BirthDate: tt.birth, ShirtNumber: 9})
+			checkErr(t, err, tt.err)
+			roster, err := tl.svc.ListRoster(tl.ctx, clubOwner, reg.ID)
+			checkErr(t, err, nil)
+			if listed := len(roster) == 1; listed != (tt.err == nil) {
+				t.Fatalf("roster %+v", roster)
+			}
+		})
+	}
+}
+
+func TestAgeOn(t *testing.T) {
+	day := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		birth string
+		want  int
+	}{
+		{"2015-03-01", 12},
+		{"2015-03-02", 11},
+		{"2015-02-28", 12},
+		{"2016-02-29", 11}, // Leap day
+		{"2027-03-01", 0},
+	}
+	for _, tt := range tests {
+		birth, _ := time.Parse(time.DateOnly, tt.birth)
+		if got := ageOn(birth, day); got != tt.want {
+			t.Errorf("born %s: %d, want %d", tt.birth, got, tt.want)
+		}
+	}
+}
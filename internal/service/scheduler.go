@@ -0,0 +1,606 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Scheduling
+
+// Constraints reported by the scheduler.
+const (
+	ConstraintVenueAvailability = "venue_availability" // No open pitch hosts the series' format
+	ConstraintPitchBooked       = "pitch_booked"
+	ConstraintTeamBlackout      = "team_blackout"
+	ConstraintSameDay           = "same_day"
+	ConstraintRestDays          = "rest_days"
+	ConstraintClubClash         = "club_clash"
+	ConstraintHomeAwayBalance   = "home_away_balance"
+)
+
+// slotConstraints lists the constraints a slot is checked against, in the
+// order they are checked.
+var slotConstraints = []string{ConstraintPitchBooked, ConstraintTeamBlackout, ConstraintSameDay, ConstraintRestDays, ConstraintClubClash}
+
+// ScheduleInput configures the automatic scheduling of a series.
+type ScheduleInput struct {
+	// Days to play on, as "YYYY-MM-DD"; default to the season's dates.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// VenueIDs limits the venues booked; by default every venue of the league.
+	VenueIDs []string `json:"venueIds"`
+	// MinRestDays is how many free days a team gets between two matches.
+	MinRestDays int `json:"minRestDays"`
+	// MaxHomeAwayStreak caps the home or away matches a team plays in a
+	// row; defaults to 2.
+	MaxHomeAwayStreak int `json:"maxHomeAwayStreak"`
+	// Reschedule also moves the matches that already have a slot.
+	Reschedule bool   `json:"reschedule"`
+	Seed       *int64 `json:"seed"`
+	DryRun     bool   `json:"dryRun"`
+}
+
+// ScheduleSeries gives the series' matches still to be played a kickoff,
+// venue and pitch. Matches go in round order, each into the earliest slot
+// that an open pitch hosting the series' format offers and that keeps every
+// constraint:
+//   - the pitch is free for the whole match
+//   - neither team has a blackout that day
+//   - neither team plays another match that day, or within MinRestDays
+//   - no other team of either club plays in the league at the same time
+//
+// Rounds keep the home and away alternation the fixtures were drawn with
+// where they can. When a team would play more than MaxHomeAwayStreak home or
+// away matches in a row, the match swaps sides if that shortens the streak,
+// and its return leg swaps with it so each team still hosts one leg.
+// Streaks left over are reported. The seed orders the matches within a round
+// and the venues, so scheduling again with it gives the same slots.
+func (s *LeaguesService) ScheduleSeries(ctx context.Context, leagueID, seriesID string, in ScheduleInput) (*domain.ScheduleReport, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	season, err := s.GetSeason(ctx, leagueID, ser.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := season.StartsOn, season.EndsOn
+	if in.From != "" {
+		if from, err = time.Parse(time.DateOnly, in.From); err != nil {
+			return nil, Invalid("from", "from must be a date like 2025-03-01")
+		}
+	}
+	if in.To != "" {
+		if to, err = time.Parse(time.DateOnly, in.To); err != nil {
+			return nil, Invalid("to", "to must be a date like 2025-06-30")
+		}
+	}
+	switch {
+	case to.Before(from):
+		return nil, Invalid("to", "to must not be before from")
+	case to.Sub(from) > 366*24*time.Hour:
+		return nil, Invalid("to", "schedule at most a year at once")
+	case in.MinRestDays < 0:
+		return nil, Invalid("minRestDays", "invalid rest days")
+	case in.MaxHomeAwayStreak < 0:
+		return nil, Invalid("maxHomeAwayStreak", "invalid streak")
+	}
+	if in.MaxHomeAwayStreak == 0 {
+		in.MaxHomeAwayStreak = 2
+	}
+	venues, err := s.scheduleVenues(ctx, leagueID, in.VenueIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	var toPlace []domain.Match
+	for _, m := range matches {
+		if m.Status == "scheduled" && (m.KickoffAt == nil || in.Reschedule) {
+			toPlace = append(toPlace, m)
+		}
+	}
+
+	seed := randomSeed()
+	if in.Seed != nil {
+		seed = *in.Seed
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	rng.Shuffle(len(venues), func(i, j int) { venues[i], venues[j] = venues[j], venues[i] })
+	slices.SortFunc(toPlace, func(a, b domain.Match) int {
+		return cmp.Or(cmp.Compare(a.Round, b.Round), cmp.Compare(a.Leg, b.Leg), strings.Compare(a.ID, b.ID))
+	})
+	for i := 0; i < len(toPlace); {
+		j := i
+		for j < len(toPlace) && toPlace[j].Round == toPlace[i].Round {
+			j++
+		}
+		round := toPlace[i:j]
+		rng.Shuffle(len(round), func(a, b int) { round[a], round[b] = round[b], round[a] })
+		i = j
+	}
+
+	// Bookings near the dates count towards rest days and clashes.
+	margin := time.Duration(in.MinRestDays+2) * 24 * time.Hour
+	booked, err := s.store.ListMatchesByLeague(ctx, leagueID, from.Add(-margin), to.Add(margin))
+	if err != nil {
+		return nil, err
+	}
+	sc := &scheduler{
+		minRest:   in.MinRestDays,
+		teams:     map[string]*domain.Team{},
+		blackouts: map[string][]string{},
+		byPitch:   map[string][]booking{},
+		byTeam:    map[string][]booking{},
+		byClub:    map[string][]booking{},
+	}
+	teamIDs := []string{}
+	for _, m := range toPlace {
+		for _, id := range []string{m.HomeTeamID, m.AwayTeamID} {
+			if !slices.Contains(teamIDs, id) {
+				teamIDs = append(teamIDs, id)
+			}
+		}
+	}
+	for _, m := range booked {
+		if slices.ContainsFunc(toPlace, func(x domain.Match) bool { return x.ID == m.ID }) {
+			continue
+		}
+		if err := sc.book(ctx, s, m); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range teamIDs {
+		if _, err := sc.team(ctx, s, id); err != nil {
+			return nil, err
+		}
+	}
+	blackouts, err := s.store.ListTeamBlackouts(ctx, teamIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blackouts {
+		sc.blackouts[b.TeamID] = append(sc.blackouts[b.TeamID], b.Date.Format(time.DateOnly))
+	}
+
+	slots, err := openSlots(venues, ser, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.ScheduleReport{Seed: seed, DryRun: in.DryRun, Scheduled: []domain.Match{}, Issues: []domain.ScheduleIssue{}}
+	if len(toPlace) > 0 && len(slots) == 0 {
+		report.Issues = append(report.Issues, domain.ScheduleIssue{
+			Constraint: ConstraintVenueAvailability,
+			Message:    fmt.Sprintf("no venue has a pitch hosting format %q open between %s and %s", ser.Format, from.Format(time.DateOnly), to.Format(time.DateOnly)),
+		})
+	}
+	bal := newBalancer(matches, toPlace, in.MaxHomeAwayStreak)
+	// A team plays its rounds in order: each match comes after the team's
+	// previous one in this schedule.
+	after := map[string]time.Time{}
+	var changed []domain.Match
+	for _, m := range toPlace {
+		earliest := after[m.HomeTeamID]
+		if t := after[m.AwayTeamID]; t.After(earliest) {
+			earliest = t
+		}
+		first, _ := slices.BinarySearchFunc(slots, earliest, func(sl slot, t time.Time) int { return sl.start.Compare(t) })
+		blocked := map[string]int{}
+		placed := false
+		for _, sl := range slots[first:] {
+			if c := sc.violation(&m, sl); c != "" {
+				blocked[c]++
+				continue
+			}
+			start, end := sl.start, sl.end
+			m.VenueID, m.Pitch, m.KickoffAt, m.EndsAt = sl.venue.ID, sl.pitch, &start, &end
+			bal.orient(&m)
+			if err := sc.book(ctx, s, m); err != nil {
+				return nil, err
+			}
+			after[m.HomeTeamID], after[m.AwayTeamID] = end, end
+			report.Scheduled = append(report.Scheduled, m)
+			placed = true
+			break
+		}
+		if !placed {
+			if len(slots) > 0 {
+				report.Issues = append(report.Issues, sc.unplaced(&m, blocked, len(slots[first:])))
+			}
+			had := m.KickoffAt != nil
+			m.VenueID, m.Pitch, m.KickoffAt, m.EndsAt = "", 0, nil, nil
+			// A return leg follows its first leg's swap even without a slot
+			if !bal.orient(&m) && !had {
+				continue
+			}
+		}
+		changed = append(changed, m)
+	}
+
+	final := make([]domain.Match, 0, len(matches))
+	for _, m := range matches {
+		if i := slices.IndexFunc(changed, func(x domain.Match) bool { return x.ID == m.ID }); i >= 0 {
+			m = changed[i]
+		}
+		final = append(final, m)
+	}
+	report.Issues = append(report.Issues, sc.streaks(final, in.MaxHomeAwayStreak)...)
+	report.Feasible = len(report.Issues) == 0
+	if report.Released, err = s.releasedOfficials(ctx, leagueID, changed); err != nil {
+		return nil, err
+	}
+
+	if !in.DryRun && len(changed) > 0 {
+		if err := s.store.UpdateMatchSlots(ctx, changed, assignmentIDs(report.Released)); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// scheduleVenues loads the venues to book, by default all of the league's.
+func (s *LeaguesService) scheduleVenues(ctx context.Context, leagueID string, ids []string) ([]domain.Venue, error) {
+	venues, err := s.store.ListVenues(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return venues, nil
+	}
+	out := make([]domain.Venue, 0, len(ids))
+	for _, id := range ids {
+		i := slices.IndexFunc(venues, func(v domain.Venue) bool { return v.ID == id })
+		if i < 0 {
+			return nil, Invalid("venueIds", "venue %s not found in the league", id)
+		}
+		out = append(out, venues[i])
+	}
+	return out, nil
+}
+
+// slot is a time a pitch is open for one match.
+type slot struct {
+	venue      *domain.Venue
+	loc        *time.Location
+	pitch      int
+	start, end time.Time
+	day        string // Local date at the venue
+}
+
+// openSlots lists back-to-back match slots within the venues' weekly
+// windows from one date to another, for the pitches hosting the series'
+// format, earliest first. Venues without windows offer no slots.
+func openSlots(venues []domain.Venue, ser *domain.Series, from, to time.Time) ([]slot, error) {
+	length := time.Duration(ser.MatchMinutes) * time.Minute
+	var out []slot
+	order := map[string]int{}
+	for vi := range venues {
+		v := &venues[vi]
+		order[v.ID] = vi
+		loc, err := time.LoadLocation(v.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("venue %s: %w", v.ID, err)
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			y, mo, dd := d.Date()
+			for _, w := range v.Availability {
+				if w.Weekday != int(d.Weekday()) {
+					continue
+				}
+				opens, _ := parseClock(w.Opens)
+				closes, _ := parseClock(w.Closes)
+				for _, p := range v.Pitches {
+					if !coversPitch(w.Pitches, p.Number) {
+						continue
+					}
+					for t := opens; t+ser.MatchMinutes <= closes; t += ser.MatchMinutes {
+						start := time.Date(y, mo, dd, t/60, t%60, 0, 0, loc).UTC()
+						end := start.Add(length)
+						if checkSlot(v, p.Number, ser.Format, start, end) != nil {
+							continue
+						}
+						out = append(out, slot{venue: v, loc: loc, pitch: p.Number, start: start, end: end, day: d.Format(time.DateOnly)})
+					}
+				}
+			}
+		}
+	}
+	// Overlapping windows offer the same slot twice.
+	slices.SortFunc(out, func(a, b slot) int {
+		return cmp.Or(a.start.Compare(b.start), cmp.Compare(order[a.venue.ID], order[b.venue.ID]), cmp.Compare(a.pitch, b.pitch))
+	})
+	return slices.CompactFunc(out, func(a, b slot) bool {
+		return a.start.Equal(b.start) && a.venue.ID == b.venue.ID && a.pitch == b.pitch
+	}), nil
+}
+
+// booking is a match holding a pitch, as the scheduler sees it.
+type booking struct {
+	matchID    string
+	teams      []string
+	start, end time.Time
+}
+
+func (b booking) overlaps(start, end time.Time) bool {
+	return b.start.Before(end) && b.end.After(start)
+}
+
+// scheduler tracks the bookings of a league while a schedule is built, by
+// pitch, team and club.
+type scheduler struct {
+	minRest   int
+	teams     map[string]*domain.Team
+	blackouts map[string][]string // Team ID to "YYYY-MM-DD" dates
+	byPitch   map[string][]booking
+	byTeam    map[string][]booking
+	byClub    map[string][]booking
+}
+
+func pitchKey(venueID string, pitch int) string {
+	return fmt.Sprintf("%s/%d", venueID, pitch)
+}
+
+// team loads a team once; teams no longer in the directory belong to no club.
+func (sc *scheduler) team(ctx context.Context, s *LeaguesService, id string) (*domain.Team, error) {
+	if t, ok := sc.teams[id]; ok {
+		return t, nil
+	}
+	t, err := s.store.GetTeamByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		t = &domain.Team{ID: id, Name: id}
+	}
+	sc.teams[id] = t
+	return t, nil
+}
+
+func (sc *scheduler) name(id string) string {
+	if t := sc.teams[id]; t != nil && t.Name != "" {
+		return t.Name
+	}
+	return id
+}
+
+func (sc *scheduler) book(ctx context.Context, s *LeaguesService, m domain.Match) error {
+	b := booking{matchID: m.ID, teams: []string{m.HomeTeamID, m.AwayTeamID}, start: *m.KickoffAt, end: *m.EndsAt}
+	if m.VenueID != "" {
+		key := pitchKey(m.VenueID, m.Pitch)
+		sc.byPitch[key] = append(sc.byPitch[key], b)
+	}
+	clubs := []string{}
+	for _, id := range b.teams {
+		sc.byTeam[id] = append(sc.byTeam[id], b)
+		t, err := sc.team(ctx, s, id)
+		if err != nil {
+			return err
+		}
+		if t.ClubID != "" && !slices.Contains(clubs, t.ClubID) {
+			clubs = append(clubs, t.ClubID)
+			sc.byClub[t.ClubID] = append(sc.byClub[t.ClubID], b)
+		}
+	}
+	return nil
+}
+
+// violation returns the first constraint the slot breaks for the match, or
+// "" when the match can take it.
+func (sc *scheduler) violation(m *domain.Match, sl slot) string {
+	for _, b := range sc.byPitch[pitchKey(sl.venue.ID, sl.pitch)] {
+		if b.overlaps(sl.start, sl.end) {
+			return ConstraintPitchBooked
+		}
+	}
+	teams := []string{m.HomeTeamID, m.AwayTeamID}
+	for _, id := range teams {
+		if slices.Contains(sc.blackouts[id], sl.day) {
+			return ConstraintTeamBlackout
+		}
+	}
+	rest := false
+	for _, id := range teams {
+		for _, b := range sc.byTeam[id] {
+			gap := daysApart(sl.day, b.start.In(sl.loc).Format(time.DateOnly))
+			if gap == 0 {
+				return ConstraintSameDay
+			}
+			if gap <= sc.minRest {
+				rest = true
+			}
+		}
+	}
+	if rest {
+		return ConstraintRestDays
+	}
+	for _, id := range teams {
+		club := sc.teams[id].ClubID
+		if club == "" {
+			continue
+		}
+		for _, b := range sc.byClub[club] {
+			if !b.overlaps(sl.start, sl.end) {
+				continue
+			}
+			for _, other := range b.teams {
+				if !slices.Contains(teams, other) && sc.teams[other] != nil && sc.teams[other].ClubID == club {
+					return ConstraintClubClash
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// unplaced reports a match no slot could take, under the constraint that
+// turned most slots away.
+func (sc *scheduler) unplaced(m *domain.Match, blocked map[string]int, tried int) domain.ScheduleIssue {
+	issue := domain.ScheduleIssue{Constraint: ConstraintVenueAvailability, MatchID: m.ID}
+	var reasons []string
+	for _, c := range slotConstraints {
+		if blocked[c] == 0 {
+			continue
+		}
+		if blocked[c] > blocked[issue.Constraint] {
+			issue.Constraint = c
+		}
+		reasons = append(reasons, fmt.Sprintf("%d %s", blocked[c], c))
+	}
+	match := fmt.Sprintf("round %d, %s v %s", m.Round, sc.name(m.HomeTeamID), sc.name(m.AwayTeamID))
+	if tried == 0 {
+		issue.Message = fmt.Sprintf("no slot for %s: none left after the teams' previous round", match)
+	} else {
+		issue.Message = fmt.Sprintf("no slot for %s: of %d slots, %s", match, tried, strings.Join(reasons, ", "))
+	}
+	return issue
+}
+
+// streaks reports the teams playing more than max home or away matches in
+// a row, in kickoff order.
+func (sc *scheduler) streaks(matches []domain.Match, max int) []domain.ScheduleIssue {
+	booked := slices.DeleteFunc(slices.Clone(matches), func(m domain.Match) bool { return m.KickoffAt == nil })
+	slices.SortStableFunc(booked, func(a, b domain.Match) int { return a.KickoffAt.Compare(*b.KickoffAt) })
+	type run struct {
+		home     bool
+		length   int
+		reported bool
+	}
+	runs := map[string]*run{}
+	out := []domain.ScheduleIssue{}
+	for _, m := range booked {
+		for _, side := range []struct {
+			team string
+			home bool
+		}{{m.HomeTeamID, true}, {m.AwayTeamID, false}} {
+			r := runs[side.team]
+			if r == nil || r.home != side.home {
+				r = &run{home: side.home, reported: r != nil && r.reported}
+				runs[side.team] = r
+			}
+			r.length++
+			if r.length > max && !r.reported {
+				r.reported = true
+				where := "away"
+				if side.home {
+					where = "home"
+				}
+				out = append(out, domain.ScheduleIssue{
+					Constraint: ConstraintHomeAwayBalance,
+					MatchID:    m.ID,
+					TeamID:     side.team,
+					Message:    fmt.Sprintf("%s plays %d %s matches in a row up to %s", sc.name(side.team), r.length, where, m.KickoffAt.Format(time.DateOnly)),
+				})
+			}
+		}
+	}
+	return out
+}
+
+// balancer keeps the home and away runs of the series' teams short while a
+// schedule is built, in kickoff order.
+type balancer struct {
+	max     int
+	booked  []domain.Match    // The series' booked matches, in kickoff order
+	returns map[string]string // First leg to return leg, for swappable pairings
+	swapped map[string]bool   // Return legs whose first leg swapped sides
+}
+
+// newBalancer starts from the matches keeping their slots. A match may swap
+// sides when it is still to be placed, is not part of a knockout tie, and
+// has no return leg or one that is also still to be placed; a return leg
+// only swaps along with its first leg.
+func newBalancer(matches, toPlace []domain.Match, max int) *balancer {
+	b := &balancer{max: max, returns: map[string]string{}, swapped: map[string]bool{}}
+	placing := map[string]bool{}
+	for _, m := range toPlace {
+		placing[m.ID] = true
+	}
+	for _, m := range matches {
+		if m.KickoffAt != nil && !placing[m.ID] {
+			b.booked = append(b.booked, m)
+		}
+	}
+	slices.SortStableFunc(b.booked, func(x, y domain.Match) int { return x.KickoffAt.Compare(*y.KickoffAt) })
+	for _, m := range toPlace {
+		if m.TieID != "" || m.Leg != 1 {
+			continue
+		}
+		ret := slices.IndexFunc(matches, func(x domain.Match) bool {
+			return x.Leg == 2 && x.GroupID == m.GroupID && x.HomeTeamID == m.AwayTeamID && x.AwayTeamID == m.HomeTeamID
+		})
+		switch {
+		case ret < 0:
+			b.returns[m.ID] = ""
+		case placing[matches[ret].ID]:
+			b.returns[m.ID] = matches[ret].ID
+		}
+	}
+	return b
+}
+
+// streak is the run of home (or away) matches the team would be on by
+// playing one more at kickoff.
+func (b *balancer) streak(teamID string, home bool, kickoff time.Time) int {
+	n := 1
+	for i := len(b.booked) - 1; i >= 0; i-- {
+		m := b.booked[i]
+		if !m.KickoffAt.Before(kickoff) || (m.HomeTeamID != teamID && m.AwayTeamID != teamID) {
+			continue
+		}
+		if (m.HomeTeamID == teamID) != home {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// orient swaps the sides of the match when its first leg did, or when that
+// shortens a run longer than max, and books it when it has a slot. It
+// reports whether the match swapped.
+func (b *balancer) orient(m *domain.Match) bool {
+	swap := b.swapped[m.ID]
+	if ret, ok := b.returns[m.ID]; ok && m.KickoffAt != nil {
+		at := *m.KickoffAt
+		keep := max(b.streak(m.HomeTeamID, true, at), b.streak(m.AwayTeamID, false, at))
+		flip := max(b.streak(m.AwayTeamID, true, at), b.streak(m.HomeTeamID, false, at))
+		if keep > b.max && flip < keep {
+			swap = true
+			if ret != "" {
+				b.swapped[ret] = true
+			}
+		}
+	}
+	if swap {
+		m.HomeTeamID, m.AwayTeamID = m.AwayTeamID, m.HomeTeamID
+	}
+	if m.KickoffAt != nil {
+		i, _ := slices.BinarySearchFunc(b.booked, *m.KickoffAt, func(x domain.Match, t time.Time) int { return x.KickoffAt.Compare(t) })
+		b.booked = slices.Insert(b.booked, i, *m)
+	}
+	return swap
+}
+
+// daysApart counts the days between two "YYYY-MM-DD" dates.
+func daysApart(a, b string) int {
+	da, _ := time.Parse(time.DateOnly, a)
+	db, _ := time.Parse(time.DateOnly, b)
+	d := int(da.Sub(db).Hours() / 24)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
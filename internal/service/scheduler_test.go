@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestScheduleSeries(t *testing.T) {
+	tests := []struct {
+		name      string
+		doubleLeg bool
+		in        ScheduleInput
+		scheduled int
+		issues    []string // Constraints reported
+	}{
+		{name: "single leg", scheduled: 6},
+		// Four teams cannot all alternate over two mirrored legs
+		{name: "double leg", doubleLeg: true, in: ScheduleInput{MaxHomeAwayStreak: 3}, scheduled: 12},
+		{
+			name:      "streaks left over",
+			doubleLeg: true,
+			scheduled: 12,
+			issues:    []string{ConstraintHomeAwayBalance, ConstraintHomeAwayBalance},
+		},
+		{name: "rest days", doubleLeg: true, in: ScheduleInput{MinRestDays: 6, MaxHomeAwayStreak: 3}, scheduled: 12},
+		{name: "dry run", in: ScheduleInput{DryRun: true}, scheduled: 6},
+		{
+			name:      "one day",
+			in:        ScheduleInput{From: "2027-03-06", To: "2027-03-06"},
+			scheduled: 2,
+			issues:    []string{ConstraintSameDay, ConstraintSameDay, ConstraintSameDay, ConstraintSameDay},
+		},
+		{
+			name:   "no open pitch",
+			in:     ScheduleInput{From: "2027-03-01", To: "2027-03-05"},
+			issues: []string{ConstraintVenueAvailability},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			tl.approve("t1", "t2", "t3", "t4")
+			if _, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, tl.series.ID, tt.doubleLeg); err != nil {
+				t.Fatalf("generate fixtures: %v", err)
+			}
+			tl.venue()
+			tt.in.Seed = ptr(int64(1))
+			rep, err := tl.svc.ScheduleSeries(tl.ctx, tl.league.ID, tl.series.ID, tt.in)
+			if err != nil {
+				t.Fatalf("schedule: %v", err)
+			}
+
+			var issues []string
+			for _, is := range rep.Issues {
+				issues = append(issues, is.Constraint)
+			}
+			if !slices.Equal(issues, tt.issues) {
+				t.Fatalf("got issues %v, want %v", rep.Issues, tt.issues)
+			}
+			if rep.Feasible != (len(tt.issues) == 0) {
+				t.Fatalf("feasible %v with issues %v", rep.Feasible, issues)
+			}
+			if len(rep.Scheduled) != tt.scheduled {
+				t.Fatalf("scheduled %d matches, want %d", len(rep.Scheduled), tt.scheduled)
+			}
+			checkSchedule(t, rep.Scheduled, tt.in.MinRestDays, max(tt.in.MaxHomeAwayStreak, 3))
+			if tt.doubleLeg {
+				checkLegs(t, rep.Scheduled)
+			}
+
+			// The seed gives the same slots again.
+			again, err := tl.svc.ScheduleSeries(tl.ctx, tl.league.ID, tl.series.ID, ScheduleInput{From: tt.in.From, To: tt.in.To, MinRestDays: tt.in.MinRestDays, MaxHomeAwayStreak: tt.in.MaxHomeAwayStreak, Reschedule: true, Seed: tt.in.Seed, DryRun: true})
+			if err != nil {
+				t.Fatalf("schedule again: %v", err)
+			}
+			if !slices.EqualFunc(again.Scheduled, rep.Scheduled, sameSlot) {
+				t.Fatalf("scheduling again with seed %d moved matches", *tt.in.Seed)
+			}
+
+			booked := 0
+			for _, m := range tl.matches() {
+				if m.KickoffAt != nil {
+					booked++
+				}
+			}
+			if want := len(rep.Scheduled); tt.in.DryRun {
+				if booked != 0 {
+					t.Fatalf("dry run booked %d matches", booked)
+				}
+			} else if booked != want {
+				t.Fatalf("%d matches booked, want %d", booked, want)
+			}
+		})
+	}
+}
+
+func sameSlot(a, b domain.Match) bool {
+	return a.ID == b.ID && a.KickoffAt.Equal(*b.KickoffAt) && a.Pitch == b.Pitch && a.HomeTeamID == b.HomeTeamID
+}
+
+// checkSchedule checks that the pitch hosts one match at a time, that teams
+// play their rounds in order and get their rest days, and that no team plays
+// more than maxStreak home or away matches in a row.
+func checkSchedule(t *testing.T, matches []domain.Match, restDays, maxStreak int) {
+	t.Helper()
+	sorted := slices.Clone(matches)
+	slices.SortFunc(sorted, func(a, b domain.Match) int { return a.KickoffAt.Compare(*b.KickoffAt) })
+	last := map[string]domain.Match{}
+	streak := map[string]string{}
+	for i, m := range sorted {
+		if m.VenueID == "" || m.EndsAt.Sub(*m.KickoffAt) != 90*time.Minute {
+			t.Errorf("match %s has slot %s at %q", m.ID, m.KickoffAt, m.VenueID)
+		}
+		if i > 0 && m.KickoffAt.Before(*sorted[i-1].EndsAt) {
+			t.Errorf("matches %s and %s overlap on the pitch", sorted[i-1].ID, m.ID)
+		}
+		for _, id := range []string{m.HomeTeamID, m.AwayTeamID} {
+			prev, ok := last[id]
+			if ok && prev.Round > m.Round {
+				t.Errorf("%s plays round %d after round %d", id, m.Round, prev.Round)
+			}
+			if ok && days(*prev.KickoffAt, *m.KickoffAt) <= restDays {
+				t.Errorf("%s plays on %s and %s", id, prev.KickoffAt, m.KickoffAt)
+			}
+			last[id] = m
+		}
+		streak[m.HomeTeamID] += "H"
+		streak[m.AwayTeamID] += "A"
+	}
+	for id, s := range streak {
+		if strings.Contains(s, strings.Repeat("H", maxStreak+1)) || strings.Contains(s, strings.Repeat("A", maxStreak+1)) {
+			t.Errorf("%s plays %s", id, s)
+		}
+	}
+}
+
+// days counts the calendar days from one kickoff to the next.
+func days(from, to time.Time) int {
+	y, m, d := from.Date()
+	return int(to.Sub(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)).Hours()) / 24
+}
+
+// checkLegs checks that each team hosts one leg against every opponent.
+func checkLegs(t *testing.T, matches []domain.Match) {
+	t.Helper()
+	hosts := map[string]int{}
+	for _, m := range matches {
+		hosts[m.HomeTeamID+"-"+m.AwayTeamID]++
+	}
+	for i := 1; i <= 4; i++ {
+		for j := 1; j <= 4; j++ {
+			if pair := fmt.Sprintf("t%d-t%d", i, j); i != j && hosts[pair] != 1 {
+				t.Errorf("%s is played %d times", pair, hosts[pair])
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+)
+
+const (
+	SearchLeague = "league"
+	SearchSeries = "series"
+	SearchTeam   = "team"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchTerms     = 10
+)
+
+// SearchQuery is a free-text search. Type optionally restricts the results
+// to a comma-separated list of result types.
+type SearchQuery struct {
+	Q     string `form:"q"`
+	Type  string `form:"type"`
+	Limit int    `form:"limit"`
+}
+
+// Search finds leagues, series and registered teams whose names start with
+// every word of the query, ignoring case and accents, best matches first.
+func (s *LeaguesService) Search(ctx context.Context, q SearchQuery) ([]domain.SearchResult, error) {
+	terms := strings.FieldsFunc(strings.ToLower(q.Q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(terms) == 0 {
+		return nil, Invalid("q", "a search query is required")
+	}
+	if len(terms) > maxSearchTerms {
+		return nil, Invalid("q", "a search query can have at most %d words", maxSearchTerms)
+	}
+	sq := repository.SearchQuery{Terms: terms, Types: []string{SearchLeague, SearchSeries, SearchTeam}, Limit: q.Limit}
+	if q.Type != "" {
+		sq.Types = nil
+		for _, t := range strings.Split(q.Type, ",") {
+			switch t = strings.TrimSpace(t); t {
+			case SearchLeague, SearchSeries, SearchTeam:
+				sq.Types = append(sq.Types, t)
+			default:
+				return nil, Invalid("type", "invalid result type %q", t)
+			}
+		}
+	}
+	switch {
+	case sq.Limit == 0:
+		sq.Limit = defaultSearchLimit
+	case sq.Limit < 0 || sq.Limit > maxSearchLimit:
+		return nil, Invalid("limit", "limit must be between 1 and %d", maxSearchLimit)
+	}
+	return s.store.Search(ctx, sq)
+}
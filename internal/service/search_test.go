@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository/memory"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	store.Seed([]domain.Team{
+		{ID: "nunoa", ClubID: "c1", Name: "Ñuñoa United", Format: "11"},
+		{ID: "real", ClubID: "c2", Name: "Real Santiago", Format: "11"},
+	}, []domain.Membership{{ID: "c1", UserID: clubOwner, ClubID: "c1", Role: "owner", Status: "active"}})
+	svc := NewLeaguesService(store)
+	l, err := svc.CreateLeague(ctx, organizer, "Sunday League", "Santiago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateSeason(ctx, l.ID, SeasonInput{Name: "2027", StartsOn: "2027-03-01", EndsOn: "2027-06-30"}); err != nil {
+		t.Fatal(err)
+	}
+	ser, err := svc.CreateSeries(ctx, l.ID, "", "Gold Cup", "11", SeriesSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only registered teams are found; Real Santiago never registers.
+	if _, err := svc.RegisterTeam(ctx, clubOwner, RegistrationInput{TeamID: "nunoa", SeriesID: ser.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		q    SearchQuery
+		want []string // Type and name of each result, in order
+	}{
+		{"word prefix", SearchQuery{Q: "gol"}, []string{"series Gold Cup"}},
+		{"case and accents", SearchQuery{Q: "NUNOA"}, []string{"team Ñuñoa United"}},
+		{"accented query", SearchQuery{Q: "ñuñ"}, []string{"team Ñuñoa United"}},
+		{"name before league", SearchQuery{Q: "sunday"}, []string{"league Sunday League", "series Gold Cup"}},
+		{"every word", SearchQuery{Q: "gold santiago"}, []string{"series Gold Cup"}},
+		{"region ties by name", SearchQuery{Q: "santiago"}, []string{"series Gold Cup", "league Sunday League"}},
+		{"unregistered team", SearchQuery{Q: "real"}, nil},
+		{"inside a word", SearchQuery{Q: "old"}, nil},
+		{"type", SearchQuery{Q: "sunday", Type: "series"}, []string{"series Gold Cup"}},
+		{"types", SearchQuery{Q: "sunday", Type: "team, league"}, []string{"league Sunday League"}},
+		{"limit", SearchQuery{Q: "sunday", Limit: 1}, []string{"league Sunday League"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := svc.Search(ctx, tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Type+" "+r.Name)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchInvalid(t *testing.T) {
+	svc := NewLeaguesService(memory.NewStore())
+	tests := []struct {
+		name string
+		q    SearchQuery
+	}{
+		{"empty", SearchQuery{}},
+		{"no words", SearchQuery{Q: " -!? "}},
+		{"too many words", SearchQuery{Q: strings.Repeat("a ", maxSearchTerms+1)}},
+		{"unknown type", SearchQuery{Q: "a", Type: "league,club"}},
+		{"negative limit", SearchQuery{Q: "a", Limit: -1}},
+		{"limit too large", SearchQuery{Q: "a", Limit: maxSearchLimit + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Search(context.Background(), tt.q)
+			checkErr(t, err, ErrValidation)
+		})
+	}
+}
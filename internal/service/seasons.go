@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+	"team-manager-leagues/internal/util"
+)
+
+// Seasons
+
+const (
+	SeasonPlanning     = "planning"
+	SeasonRegistration = "registration"
+	SeasonInProgress   = "in_progress"
+	SeasonFinished     = "finished"
+)
+
+// seasonStatuses lists the statuses in the order a season goes through
+// them. A season only moves forward, though it may skip statuses.
+var seasonStatuses = []string{SeasonPlanning, SeasonRegistration, SeasonInProgress, SeasonFinished}
+
+// SeasonInput names a season and the dates it spans, as "YYYY-MM-DD".
+type SeasonInput struct {
+	Name     string `json:"name"`
+	StartsOn string `json:"startsOn"`
+	EndsOn   string `json:"endsOn"`
+}
+
+func (in SeasonInput) apply(se *domain.Season) error {
+	se.Name = strings.TrimSpace(in.Name)
+	if se.Name == "" {
+		return Invalid("name", "invalid name")
+	}
+	var err error
+	if se.StartsOn, err = time.Parse(time.DateOnly, in.StartsOn); err != nil {
+		return Invalid("startsOn", "startsOn must be a date like 2025-03-01")
+	}
+	if se.EndsOn, err = time.Parse(time.DateOnly, in.EndsOn); err != nil {
+		return Invalid("endsOn", "endsOn must be a date like 2025-12-15")
+	}
+	if !se.EndsOn.After(se.StartsOn) {
+		return Invalid("endsOn", "a season must end after it starts")
+	}
+	return nil
+}
+
+// CreateSeason adds a season to a league, in planning.
+func (s *LeaguesService) CreateSeason(ctx context.Context, leagueID string, in SeasonInput) (*domain.Season, error) {
+	se := &domain.Season{ID: util.RandID(), LeagueID: leagueID, Status: SeasonPlanning}
+	if err := in.apply(se); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateSeason(ctx, se, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return se, nil
+}
+
+// ListSeasons returns the seasons of a league, latest first.
+func (s *LeaguesService) ListSeasons(ctx context.Context, leagueID string) ([]domain.Season, error) {
+	return s.store.ListSeasons(ctx, leagueID)
+}
+
+func (s *LeaguesService) GetSeason(ctx context.Context, leagueID, id string) (*domain.Season, error) {
+	se, err := s.store.GetSeasonByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if se == nil || se.LeagueID != leagueID {
+		return nil, NotFound("season not found")
+	}
+	return se, nil
+}
+
+// SeasonUpdate renames or reschedules a season and optionally moves it to
+// a later status.
+type SeasonUpdate struct {
+	SeasonInput
+	Status string `json:"status"` // Empty keeps the current status
+}
+
+func (s *LeaguesService) UpdateSeason(ctx context.Context, leagueID, id string, in SeasonUpdate) (*domain.Season, error) {
+	se, err := s.GetSeason(ctx, leagueID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := in.apply(se); err != nil {
+		return nil, err
+	}
+	if in.Status != "" && in.Status != se.Status {
+		to := slices.Index(seasonStatuses, in.Status)
+		if to < 0 {
+			return nil, Invalid("status", "invalid status %q", in.Status)
+		}
+		if to < slices.Index(seasonStatuses, se.Status) {
+			return nil, fmt.Errorf("%w: a season cannot go back from %s to %s", ErrInvalidTransition, se.Status, in.Status)
+		}
+		se.Status = in.Status
+	}
+	if err := s.store.UpdateSeason(ctx, se); err != nil {
+		return nil, err
+	}
+	return se, nil
+}
+
+// DeleteSeason deletes a season with all of its series.
+func (s *LeaguesService) DeleteSeason(ctx context.Context, leagueID, id string) error {
+	if _, err := s.GetSeason(ctx, leagueID, id); err != nil {
+		return err
+	}
+	return s.store.DeleteSeason(ctx, id)
+}
+
+// currentSeason is the season new series go into when none is named: the
+// latest one that has not finished. A league without any season gets one,
+// so its first series can be created right after the league.
+func (s *LeaguesService) currentSeason(ctx context.Context, leagueID string) (*domain.Season, error) {
+	seasons, err := s.store.ListSeasons(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(seasons) == 0 {
+		se := defaultSeason(leagueID, time.Now())
+		err := s.store.CreateSeason(ctx, se, nil, nil, nil)
+		if err == nil {
+			return se, nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+		// Another first series created it meanwhile.
+		if seasons, err = s.store.ListSeasons(ctx, leagueID); err != nil {
+			return nil, err
+		}
+	}
+	for i := range seasons {
+		if seasons[i].Status != SeasonFinished {
+			return &seasons[i], nil
+		}
+	}
+	return nil, Invalid("seasonId", "the league has no open season; create one first")
+}
+
+// defaultSeason is the season opened for a league's first series. Like the
+// seasons given to existing series by the migration, it is named after the
+// year and lasts one, here from today.
+func defaultSeason(leagueID string, now time.Time) *domain.Season {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return &domain.Season{
+		ID:       util.RandID(),
+		LeagueID: leagueID,
+		Name:     start.Format("2006"),
+		StartsOn: start,
+		EndsOn:   start.AddDate(1, 0, 0),
+		Status:   SeasonPlanning,
+	}
+}
+
+// RollOverInput starts the next season from a previous one.
+type RollOverInput struct {
+	SeasonInput
+	// ReinviteTeams invites the teams approved in each series of the
+	// previous season into its copy.
+	ReinviteTeams bool `json:"reinviteTeams"`
+}
+
+// RollOverSeason creates a season in planning with a copy of every series
+// of season id: same names, formats, points, tiebreakers, modes, limits and
+// eligibility rules. Registration windows move by the gap between the two
+// seasons' starts. Invited teams take part once their club accepts, moving
+// the invitation to pending.
+func (s *LeaguesService) RollOverSeason(ctx context.Context, userID, leagueID, id string, in RollOverInput) (*domain.Season, []domain.Series, []domain.TeamRegistration, error) {
+	prev, err := s.GetSeason(ctx, leagueID, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	se := &domain.Season{ID: util.RandID(), LeagueID: leagueID, Status: SeasonPlanning}
+	if err := in.apply(se); err != nil {
+		return nil, nil, nil, err
+	}
+	if !se.StartsOn.After(prev.StartsOn) {
+		return nil, nil, nil, Invalid("startsOn", "the new season must start after %s", prev.StartsOn.Format(time.DateOnly))
+	}
+	shift := se.StartsOn.Sub(prev.StartsOn)
+
+	prevSeries, err := s.seasonSeries(ctx, leagueID, prev.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	series := make([]domain.Series, 0, len(prevSeries))
+	regs := []domain.TeamRegistration{}
+	events := []domain.RegistrationEvent{}
+	for _, old := range prevSeries {
+		ser := old
+		ser.ID = util.RandID()
+		ser.SeasonID = se.ID
+		ser.CreatedAt, ser.UpdatedAt = time.Time{}, time.Time{}
+		ser.Tiebreakers = slices.Clone(old.Tiebreakers)
+		ser.DisciplineRules = slices.Clone(old.DisciplineRules)
+		ser.LotsSeed = nil
+		if slices.Contains(ser.Tiebreakers, TiebreakLots) {
+			seed := randomSeed()
+			ser.LotsSeed = &seed
+		}
+		ser.RegistrationOpensAt = shiftTime(old.RegistrationOpensAt, shift)
+		ser.RegistrationClosesAt = shiftTime(old.RegistrationClosesAt, shift)
+		ser.RosterLocksAt = shiftTime(old.RosterLocksAt, shift)
+		series = append(series, ser)
+		if !in.ReinviteTeams {
+			continue
+		}
+		invites, err := s.invitations(ctx, userID, &old, &ser, prev.Name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, inv := range invites {
+			regs = append(regs, inv.reg)
+			events = append(events, inv.ev)
+		}
+	}
+	if err := s.store.CreateSeason(ctx, se, series, regs, events); err != nil {
+		return nil, nil, nil, err
+	}
+	return se, series, regs, nil
+}
+
+// seasonSeries lists every series of a season, oldest first.
+func (s *LeaguesService) seasonSeries(ctx context.Context, leagueID, seasonID string) ([]domain.Series, error) {
+	return s.store.ListSeries(ctx, repository.SeriesFilter{LeagueID: leagueID, SeasonID: seasonID}, repository.Page{Sort: repository.SortCreatedAt})
+}
+
+type invitation struct {
+	reg domain.TeamRegistration
+	ev  domain.RegistrationEvent
+}
+
+// invitations invites the teams approved in old into its copy ser.
+// Eligibility is checked again, as the team may have aged out of the
+// series' category; failed rules are flagged for the organizers.
+func (s *LeaguesService) invitations(ctx context.Context, userID string, old, ser *domain.Series, prevSeason string) ([]invitation, error) {
+	prevRegs, err := s.store.ListRegistrationsBySeries(ctx, old.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := []invitation{}
+	for _, pr := range prevRegs {
+		if pr.Status != RegistrationApproved {
+			continue
+		}
+		t, err := s.store.GetTeamByID(ctx, pr.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			continue
+		}
+		reg := domain.TeamRegistration{
+			ID:             util.RandID(),
+			TeamID:         pr.TeamID,
+			SeriesID:       ser.ID,
+			Status:         RegistrationInvited,
+			AgeCategory:    pr.AgeCategory,
+			GenderCategory: pr.GenderCategory,
+		}
+		reg.EligibilityIssues = evaluateEligibility(ser, t, &reg, nil)
+		out = append(out, invitation{reg: reg, ev: domain.RegistrationEvent{
+			ID:             util.RandID(),
+			RegistrationID: reg.ID,
+			ToStatus:       reg.Status,
+			ActorID:        userID,
+			Reason:         "invited back from season " + prevSeason,
+		}})
+	}
+	return out, nil
+}
+
+func shiftTime(t *time.Time, d time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.Add(d)
+	return &v
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/repository/memory"
+)
+
+func TestCreateSeriesDefaultSeason(t *testing.T) {
+	tests := []struct {
+		name    string
+		seasons []SeasonUpdate // Created before the series, with their final status
+		want    string         // Name of the season the series goes into
+		err     error
+	}{
+		{name: "league without seasons", want: time.Now().Format("2006")},
+		{
+			name:    "latest open season",
+			seasons: []SeasonUpdate{{SeasonInput{"2026", "2026-03-01", "2026-12-01"}, ""}, {SeasonInput{"2027", "2027-03-01", "2027-12-01"}, ""}},
+			want:    "2027",
+		},
+		{
+			name:    "latest finished",
+			seasons: []SeasonUpdate{{SeasonInput{"2026", "2026-03-01", "2026-12-01"}, ""}, {SeasonInput{"2027", "2027-03-01", "2027-12-01"}, SeasonFinished}},
+			want:    "2026",
+		},
+		{
+			name:    "every season finished",
+			seasons: []SeasonUpdate{{SeasonInput{"2026", "2026-03-01", "2026-12-01"}, SeasonFinished}},
+			err:     ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := NewLeaguesService(memory.NewStore())
+			l, err := svc.CreateLeague(ctx, organizer, "League", "R")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, in := range tt.seasons {
+				se, err := svc.CreateSeason(ctx, l.ID, in.SeasonInput)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := svc.UpdateSeason(ctx, l.ID, se.ID, in); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ser, err := svc.CreateSeries(ctx, l.ID, "", "A", "11", SeriesSettings{})
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			se, err := svc.GetSeason(ctx, l.ID, ser.SeasonID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if se.Name != tt.want {
+				t.Fatalf("series went into season %s, want %s", se.Name, tt.want)
+			}
+			// Later series join it rather than opening another.
+			next, err := svc.CreateSeries(ctx, l.ID, "", "B", "11", SeriesSettings{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			seasons, err := svc.ListSeasons(ctx, l.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.SeasonID != ser.SeasonID || len(seasons) != max(len(tt.seasons), 1) {
+				t.Fatalf("second series in %s of %d seasons", next.SeasonID, len(seasons))
+			}
+		})
+	}
+}
+
+func TestDefaultSeason(t *testing.T) {
+	se := defaultSeason("l1", time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("", -3*3600)))
+	if se.Name != "2026" || se.LeagueID != "l1" || se.Status != SeasonPlanning {
+		t.Fatalf("got %+v", se)
+	}
+	if got := se.StartsOn.Format(time.DateOnly) + " " + se.EndsOn.Format(time.DateOnly); got != "2026-10-18 2027-10-18" {
+		t.Fatalf("season runs %s", got)
+	}
+}
+
+func TestRollOverSeason(t *testing.T) {
+	opens := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	closes := time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		in       RollOverInput
+		reinvite []string // Teams invited back
+		err      error
+	}{
+		{name: "copy", in: RollOverInput{SeasonInput: SeasonInput{"2028", "2028-03-01", "2028-06-30"}}},
+		{name: "reinvite", in: RollOverInput{SeasonInput: SeasonInput{"2028", "2028-03-01", "2028-06-30"}, ReinviteTeams: true}, reinvite: []string{"t1", "t2"}},
+		{name: "same start", in: RollOverInput{SeasonInput: SeasonInput{"2028", "2027-03-01", "2028-06-30"}}, err: ErrValidation},
+		{name: "same name", in: RollOverInput{SeasonInput: SeasonInput{"2027", "2028-03-01", "2028-06-30"}}, err: ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{
+				Tiebreakers:          []string{TiebreakGoalDifference, TiebreakLots},
+				MaxTeams:             ptr(6),
+				RegistrationOpensAt:  &opens,
+				RegistrationClosesAt: &closes,
+				Division:             ptr(1),
+			})
+			tl.approve("t1", "t2")
+			regs := tl.register("t3", "t4")
+			if _, err := tl.svc.UpdateRegistrationStatus(tl.ctx, organizer, regs[1].ID, RegistrationRejected, "late"); err != nil {
+				t.Fatal(err)
+			}
+			prev, err := tl.svc.GetSeason(tl.ctx, tl.league.ID, tl.series.SeasonID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			se, series, invited, err := tl.svc.RollOverSeason(tl.ctx, organizer, tl.league.ID, prev.ID, tt.in)
+			checkErr(t, err, tt.err)
+			seasons, _ := tl.svc.ListSeasons(tl.ctx, tl.league.ID)
+			if err != nil {
+				if len(seasons) != 1 {
+					t.Fatalf("%d seasons after a failed rollover", len(seasons))
+				}
+				return
+			}
+			if se.Status != SeasonPlanning || len(seasons) != 2 {
+				t.Fatalf("season %s in %s of %d", se.Name, se.Status, len(seasons))
+			}
+
+			// The copy keeps the settings, with the windows moved a season on.
+			if len(series) != 1 {
+				t.Fatalf("%d series copied", len(series))
+			}
+			ser, err := tl.svc.GetSeries(tl.ctx, series[0].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			old := tl.series
+			shift := se.StartsOn.Sub(prev.StartsOn)
+			switch {
+			case ser.SeasonID != se.ID || ser.ID == old.ID:
+				t.Errorf("copy %s in season %s", ser.ID, ser.SeasonID)
+			case ser.Name != old.Name || ser.Format != old.Format || *ser.MaxTeams != 6 || *ser.Division != 1:
+				t.Errorf("settings not copied: %+v", ser)
+			case !slices.Equal(ser.Tiebreakers, old.Tiebreakers) || ser.LotsSeed == nil:
+				t.Errorf("tiebreakers %v, seed %v", ser.Tiebreakers, ser.LotsSeed)
+			case !ser.RegistrationOpensAt.Equal(opens.Add(shift)) || !ser.RegistrationClosesAt.Equal(closes.Add(shift)):
+				t.Errorf("window %v to %v, want shifted by %v", ser.RegistrationOpensAt, ser.RegistrationClosesAt, shift)
+			}
+
+			// Approved teams only are invited, and join once their club accepts.
+			var got []string
+			for _, reg := range invited {
+				if reg.Status != RegistrationInvited || reg.SeriesID != ser.ID {
+					t.Errorf("%s %s in %s", reg.TeamID, reg.Status, reg.SeriesID)
+				}
+				got = append(got, reg.TeamID)
+			}
+			if !slices.Equal(got, tt.reinvite) {
+				t.Fatalf("invited %v, want %v", got, tt.reinvite)
+			}
+			for _, reg := range invited {
+				events, err := tl.store.ListRegistrationEvents(tl.ctx, reg.ID)
+				if err != nil || len(events) != 1 || events[0].Reason != "invited back from season 2027" {
+					t.Fatalf("invitation events %+v: %v", events, err)
+				}
+				got, err := tl.svc.UpdateRegistrationStatus(tl.ctx, clubOwner, reg.ID, RegistrationPending, "")
+				if err != nil || got.Status != RegistrationPending {
+					t.Fatalf("accept invitation: %v", err)
+				}
+			}
+
+			// The previous season is untouched.
+			old, err = tl.svc.GetSeries(tl.ctx, old.ID)
+			if err != nil || old.SeasonID != prev.ID || !old.RegistrationOpensAt.Equal(opens) {
+				t.Fatalf("previous series changed: %+v %v", old, err)
+			}
+			if regs, _ := tl.store.ListRegistrationsBySeries(tl.ctx, old.ID); len(regs) != 4 {
+				t.Fatalf("%d registrations left in the previous series", len(regs))
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sort"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Stages
+
+const (
+	StageKindGroups   = "groups"
+	StageKindKnockout = "knockout"
+)
+
+// GroupDrawInput configures the group phase of a groups_knockout series.
+type GroupDrawInput struct {
+	GroupCount         int  `json:"groupCount"`
+	QualifiersPerGroup int  `json:"qualifiersPerGroup"`
+	DoubleLeg          bool `json:"doubleLeg"`
+	// Seeds lists team IDs dealt first, one per group, so that they are kept
+	// apart. The remaining teams are shuffled with DrawSeed.
+	Seeds    []string `json:"seeds"`
+	DrawSeed *int64   `json:"drawSeed"`
+}
+
+// KnockoutStageInput configures the bracket that follows the group phase.
+type KnockoutStageInput struct {
+	TwoLegged      bool `json:"twoLegged"`
+	TwoLeggedFinal bool `json:"twoLeggedFinal"`
+}
+
+// DrawGroups draws the approved teams of a series into groups and generates the
+// round-robin fixtures of each group. Teams are dealt in snake order (A, B,
+// C, C, B, A, ...) so the seeds are spread across the groups; the draw is
+// reproducible from the recorded seed.
+func (s *LeaguesService) DrawGroups(ctx context.Context, leagueID, seriesID string, in GroupDrawInput) ([]domain.Stage, error) {
+	ser, err := s.getStagedSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListStagesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, Conflict("groups already drawn")
+	}
+
+	registered, err := s.approvedTeamIDs(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case in.GroupCount < 1 || in.GroupCount > 26:
+		return nil, Invalid("groupCount", "group count must be between 1 and 26")
+	case len(registered) < 2*in.GroupCount:
+		return nil, Invalid("groupCount", "every group needs at least two teams")
+	case in.QualifiersPerGroup < 1 || in.QualifiersPerGroup > len(registered)/in.GroupCount:
+		return nil, Invalid("qualifiersPerGroup", "qualifiers per group must be between 1 and the size of the smallest group")
+	case in.GroupCount*in.QualifiersPerGroup < 2:
+		return nil, Invalid("qualifiersPerGroup", "at least two teams must qualify for the knockout stage")
+	}
+	teams, err := seedOrder(registered, in.Seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := randomSeed()
+	if in.DrawSeed != nil {
+		seed = *in.DrawSeed
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	unseeded := teams[len(in.Seeds):]
+	rng.Shuffle(len(unseeded), func(i, j int) { unseeded[i], unseeded[j] = unseeded[j], unseeded[i] })
+
+	groupStage := domain.Stage{
+		ID:                 util.RandID(),
+		SeriesID:           ser.ID,
+		Position:           1,
+		Kind:               StageKindGroups,
+		QualifiersPerGroup: in.QualifiersPerGroup,
+		DrawSeed:           &seed,
+		Groups:             make([]domain.Group, in.GroupCount),
+	}
+	for i := range groupStage.Groups {
+		groupStage.Groups[i] = domain.Group{ID: util.RandID(), StageID: groupStage.ID, Name: string(rune('A' + i))}
+	}
+	for i, teamID := range teams {
+		g := i % in.GroupCount
+		if (i/in.GroupCount)%2 == 1 {
+			g = in.GroupCount - 1 - g
+		}
+		groupStage.Groups[g].TeamIDs = append(groupStage.Groups[g].TeamIDs, teamID)
+	}
+	knockoutStage := domain.Stage{ID: util.RandID(), SeriesID: ser.ID, Position: 2, Kind: StageKindKnockout}
+
+	var matches []domain.Match
+	for _, g := range groupStage.Groups {
+		pairings, _ := roundRobin(g.TeamIDs, in.DoubleLeg)
+		for _, p := range pairings {
+			matches = append(matches, domain.Match{
+				ID:         util.RandID(),
+				SeriesID:   ser.ID,
+				StageID:    groupStage.ID,
+				GroupID:    g.ID,
+				Round:      p.Round,
+				Leg:        p.Leg,
+				HomeTeamID: p.Home,
+				AwayTeamID: p.Away,
+				Status:     "scheduled",
+			})
+		}
+	}
+
+	stages := []domain.Stage{groupStage, knockoutStage}
+	if err := s.store.CreateStages(ctx, stages, matches); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// ListStages returns the stages of a series with the groups, their current
+// standings and the knockout bracket.
+func (s *LeaguesService) ListStages(ctx context.Context, leagueID, seriesID string) ([]domain.Stage, error) {
+	ser, err := s.getStagedSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	stages, groups, matches, err := s.loadStages(ctx, ser.ID)
+	if err != nil {
+		return nil, err
+	}
+	ties, err := s.store.ListKnockoutTiesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ties {
+		for _, m := range matches {
+			if m.TieID == ties[i].ID {
+				ties[i].Matches = append(ties[i].Matches, m)
+			}
+		}
+	}
+	approved, err := s.approvedTeamIDs(ctx, ser.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stages {
+		switch stages[i].Kind {
+		case StageKindGroups:
+			for _, g := range groups {
+				if g.StageID == stages[i].ID {
+					g.Standings = buildStandings(ser, stillApproved(g.TeamIDs, approved), groupMatches(matches, g.ID))
+					stages[i].Groups = append(stages[i].Groups, g)
+				}
+			}
+		case StageKindKnockout:
+			stages[i].Ties = ties
+		}
+	}
+	return stages, nil
+}
+
+// StartKnockoutStage draws the bracket from the teams that qualified out of
+// the finished group phase. When the groups pair up into a full bracket the
+// qualifiers cross over (A1 v B2, B1 v A2, ...) with paired group winners in
+// opposite halves; otherwise they are seeded by group finish and record.
+func (s *LeaguesService) StartKnockoutStage(ctx context.Context, leagueID, seriesID string, in KnockoutStageInput) ([]domain.KnockoutTie, error) {
+	ser, err := s.getStagedSeries(ctx, leagueID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	stages, groups, matches, err := s.loadStages(ctx, ser.ID)
+	if err != nil {
+		return nil, err
+	}
+	var groupStage, knockoutStage *domain.Stage
+	for i := range stages {
+		switch stages[i].Kind {
+		case StageKindGroups:
+			groupStage = &stages[i]
+		case StageKindKnockout:
+			knockoutStage = &stages[i]
+		}
+	}
+	if groupStage == nil || knockoutStage == nil {
+		return nil, Conflict("groups have not been drawn")
+	}
+	existing, err := s.store.ListKnockoutTiesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, Conflict("knockout stage already drawn")
+	}
+	approved, err := s.approvedTeamIDs(ctx, ser.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		// Matches of withdrawn teams are expunged and need no result
+		if m.GroupID != "" && !countsForStandings(m) && slices.Contains(approved, m.HomeTeamID) && slices.Contains(approved, m.AwayTeamID) {
+			return nil, Conflict("the group stage is not finished")
+		}
+	}
+
+	// qualified[g][k] is the team finishing k+1 in group g.
+	k := groupStage.QualifiersPerGroup
+	qualified := make([][]domain.StandingRow, len(groups))
+	for i, g := range groups {
+		table := buildStandings(ser, stillApproved(g.TeamIDs, approved), groupMatches(matches, g.ID))
+		qualified[i] = table[:min(k, len(table))]
+	}
+
+	var ties []domain.KnockoutTie
+	if pairs := crossoverPairs(qualified); pairs != nil {
+		ties = bracketFromPairs(ser.ID, pairs, in.TwoLegged, in.TwoLeggedFinal)
+	} else {
+		seeded := seedQualifiers(qualified)
+		ties = buildBracket(ser.ID, seeded, len(seeded), in.TwoLegged, in.TwoLeggedFinal)
+	}
+	var tieMatchList []domain.Match
+	for i := range ties {
+		ties[i].StageID = knockoutStage.ID
+		if ties[i].WinnerTeamID == "" && ties[i].HomeTeamID != "" && ties[i].AwayTeamID != "" {
+			ties[i].Matches = tieMatches(&ties[i])
+			tieMatchList = append(tieMatchList, ties[i].Matches...)
+		}
+	}
+	if err := s.store.CreateBracket(ctx, ties, tieMatchList); err != nil {
+		return nil, err
+	}
+	return ties, nil
+}
+
+func (s *LeaguesService) getStagedSeries(ctx context.Context, leagueID, seriesID string) (*domain.Series, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	if ser.Mode != SeriesModeGroupsKnockout {
+		return nil, Conflict("stages are only available for %s series", SeriesModeGroupsKnockout)
+	}
+	return ser, nil
+}
+
+func (s *LeaguesService) loadStages(ctx context.Context, seriesID string) ([]domain.Stage, []domain.Group, []domain.Match, error) {
+	stages, err := s.store.ListStagesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	groups, err := s.store.ListGroupsBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	matches, err := s.loadMatchesWithResults(ctx, seriesID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return stages, groups, matches, nil
+}
+
+// stillApproved returns the teams drawn into a group that are still approved
+// into the series, in draw order.
+func stillApproved(teamIDs, approved []string) []string {
+	var out []string
+	for _, id := range teamIDs {
+		if slices.Contains(approved, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func groupMatches(matches []domain.Match, groupID string) []domain.Match {
+	var out []domain.Match
+	for _, m := range matches {
+		if m.GroupID == groupID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// crossoverPairs pairs groups A-B, C-D, ... and within each pair the team
+// finishing i meets the one finishing k+1-i in the other group, the better
+// placed team at home. Ties involving odd finishing places fill the top half
+// of the bracket and the others the bottom half, which keeps the winners of
+// paired groups apart until the final. It returns nil when the groups do not
+// pair up into a full bracket.
+func crossoverPairs(qualified [][]domain.StandingRow) [][2]seat {
+	n := len(qualified)
+	if n < 2 || n%2 == 1 {
+		return nil
+	}
+	k := len(qualified[0])
+	for _, q := range qualified {
+		if len(q) != k {
+			return nil
+		}
+	}
+	total := n * k
+	if total&(total-1) != 0 {
+		return nil
+	}
+
+	var top, bottom [][2]seat
+	for g := 0; g < n; g += 2 {
+		x, y := qualified[g], qualified[g+1]
+		for i := 1; i <= k; i++ {
+			home, away := x[i-1].TeamID, y[k-i].TeamID
+			if i > k+1-i {
+				home, away = away, home
+			}
+			pair := [2]seat{{TeamID: home}, {TeamID: away}}
+			if i%2 == 1 {
+				top = append(top, pair)
+			} else {
+				bottom = append(bottom, pair)
+			}
+		}
+	}
+	return append(top, bottom...)
+}
+
+// seedQualifiers orders the qualifiers by finishing place, then points, goal
+// difference and goals scored, for a standard seeded bracket.
+func seedQualifiers(qualified [][]domain.StandingRow) []string {
+	var all []domain.StandingRow
+	for _, q := range qualified {
+		all = append(all, q...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		switch {
+		case a.Position != b.Position:
+			return a.Position < b.Position
+		case a.Points != b.Points:
+			return a.Points > b.Points
+		case a.GoalDifference != b.GoalDifference:
+			return a.GoalDifference > b.GoalDifference
+		case a.GoalsFor != b.GoalsFor:
+			return a.GoalsFor > b.GoalsFor
+		}
+		return a.TeamID < b.TeamID
+	})
+	out := make([]string, len(all))
+	for i, r := range all {
+		out[i] = r.TeamID
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// Standings
+
+// ComputeStandings builds the league table of a series from its recorded
+// results. Every approved team is listed, including those yet to play;
+// teams withdrawn or otherwise no longer approved are left out along with
+// their results.
+func (s *LeaguesService) ComputeStandings(ctx context.Context, leagueID, seriesID string) ([]domain.StandingRow, error) {
+	ser, err := s.store.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if ser == nil || ser.LeagueID != leagueID {
+		return nil, NotFound("series not found")
+	}
+	if ser.Mode != SeriesModeLeague {
+		return nil, Conflict("standings are only available for league series")
+	}
+	regs, err := s.store.ListRegistrationsBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.loadMatchesWithResults(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamIDs []string
+	for _, r := range regs {
+		if r.Status == RegistrationApproved {
+			teamIDs = append(teamIDs, r.TeamID)
+		}
+	}
+	return buildStandings(ser, teamIDs, matches), nil
+}
+
+// loadMatchesWithResults returns the matches of a series with their results attached.
+func (s *LeaguesService) loadMatchesWithResults(ctx context.Context, seriesID string) ([]domain.Match, error) {
+	matches, err := s.store.ListMatchesBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.store.ListMatchResultsBySeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	byMatch := make(map[string]domain.MatchResult, len(results))
+	for _, r := range results {
+		byMatch[r.MatchID] = r
+	}
+	for i := range matches {
+		if r, ok := byMatch[matches[i].ID]; ok {
+			matches[i].Result = &r
+		}
+	}
+	return matches, nil
+}
+
+// countsForStandings reports whether a match result feeds the table.
+func countsForStandings(m domain.Match) bool {
+	return m.Result != nil && (m.Result.Outcome == "played" || m.Result.Outcome == "walkover")
+}
+
+// buildStandings tallies the counted matches and ranks the table with the
+// series tiebreakers.
+func buildStandings(ser *domain.Series, teamIDs []string, matches []domain.Match) []domain.StandingRow {
+	ranker := &tiebreakRanker{ser: ser, rows: tallyStandings(ser, teamIDs, matches), matches: matches}
+	return ranker.rank()
+}
+
+// tallyStandings accumulates the record of each of the given teams from the
+// counted matches. A match against a team not in the table is expunged, so a
+// withdrawn team's results do not count for its opponents either.
+func tallyStandings(ser *domain.Series, teamIDs []string, matches []domain.Match) map[string]*domain.StandingRow {
+	rows := make(map[string]*domain.StandingRow, len(teamIDs))
+	for _, id := range teamIDs {
+		rows[id] = &domain.StandingRow{TeamID: id}
+	}
+
+	for _, m := range matches {
+		home, away := rows[m.HomeTeamID], rows[m.AwayTeamID]
+		if !countsForStandings(m) || home == nil || away == nil {
+			continue
+		}
+		hs, as := m.Result.HomeScore, m.Result.AwayScore
+		home.Played++
+		away.Played++
+		home.GoalsFor += hs
+		home.GoalsAgainst += as
+		away.GoalsFor += as
+		away.GoalsAgainst += hs
+		home.FairPlay += m.Result.HomeFairPlay
+		away.FairPlay += m.Result.AwayFairPlay
+		switch {
+		case hs > as:
+			home.Won++
+			away.Lost++
+		case hs < as:
+			home.Lost++
+			away.Won++
+		default:
+			home.Drawn++
+			away.Drawn++
+		}
+	}
+
+	for _, r := range rows {
+		r.GoalDifference = r.GoalsFor - r.GoalsAgainst
+		r.Points = r.Won*ser.PointsWin + r.Drawn*ser.PointsDraw + r.Lost*ser.PointsLoss
+	}
+	return rows
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Tiebreakers
+
+const (
+	TiebreakHeadToHeadPoints         = "head_to_head_points"
+	TiebreakHeadToHeadGoalDifference = "head_to_head_goal_difference"
+	TiebreakGoalDifference           = "goal_difference"
+	TiebreakGoalsFor                 = "goals_for"
+	TiebreakFairPlay                 = "fair_play"
+	TiebreakLots                     = "lots"
+)
+
+// defaultTiebreakers is used by series that do not configure their own.
+var defaultTiebreakers = []string{TiebreakGoalDifference, TiebreakGoalsFor}
+
+var knownTiebreakers = map[string]bool{
+	TiebreakHeadToHeadPoints:         true,
+	TiebreakHeadToHeadGoalDifference: true,
+	TiebreakGoalDifference:           true,
+	TiebreakGoalsFor:                 true,
+	TiebreakFairPlay:                 true,
+	TiebreakLots:                     true,
+}
+
+func validateTiebreakers(list []string) error {
+	seen := map[string]bool{}
+	for _, t := range list {
+		if !knownTiebreakers[t] {
+			return Invalid("tiebreakers", "unknown tiebreaker %q", t)
+		}
+		if seen[t] {
+			return Invalid("tiebreakers", "duplicate tiebreaker %q", t)
+		}
+		seen[t] = true
+	}
+	return nil
+}
+
+// randomSeed returns a random non-negative seed for reproducible draws.
+func randomSeed() int64 {
+	return int64(binary.BigEndian.Uint64(util.RandBytes(8)) & math.MaxInt64)
+}
+
+// tiebreakRanker orders teams that are level on points.
+type tiebreakRanker struct {
+	ser     *domain.Series
+	rows    map[string]*domain.StandingRow
+	matches []domain.Match
+}
+
+// rank sorts the rows by points and then resolves each group of teams level
+// on points with the series tiebreakers, recording which criterion decided
+// each position. Teams still level once the list runs out keep team-id order.
+func (t *tiebreakRanker) rank() []domain.StandingRow {
+	ids := make([]string, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := t.rows[ids[i]], t.rows[ids[j]]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.TeamID < b.TeamID
+	})
+
+	var ordered []string
+	for start := 0; start < len(ids); {
+		end := start + 1
+		for end < len(ids) && t.rows[ids[end]].Points == t.rows[ids[start]].Points {
+			end++
+		}
+		ordered = append(ordered, t.resolve(ids[start:end])...)
+		start = end
+	}
+
+	out := make([]domain.StandingRow, len(ordered))
+	for i, id := range ordered {
+		out[i] = *t.rows[id]
+		out[i].Position = i + 1
+	}
+	return out
+}
+
+// resolve applies the criteria in order until the group splits. Each smaller
+// tied subgroup is resolved again from the first criterion, so head-to-head
+// is recomputed among the teams that are still level.
+func (t *tiebreakRanker) resolve(group []string) []string {
+	if len(group) < 2 {
+		return group
+	}
+	for _, criterion := range t.ser.Tiebreakers {
+		keys := t.keys(criterion, group)
+		sorted := append([]string(nil), group...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if keys[sorted[i]] != keys[sorted[j]] {
+				return keys[sorted[i]] > keys[sorted[j]]
+			}
+			return sorted[i] < sorted[j]
+		})
+		if keys[sorted[0]] == keys[sorted[len(sorted)-1]] {
+			continue
+		}
+
+		var out []string
+		for start := 0; start < len(sorted); {
+			end := start + 1
+			for end < len(sorted) && keys[sorted[end]] == keys[sorted[start]] {
+				end++
+			}
+			sub := sorted[start:end]
+			if len(sub) == 1 {
+				t.rows[sub[0]].DecidedBy = criterion
+				out = append(out, sub[0])
+			} else {
+				out = append(out, t.resolve(sub)...)
+			}
+			start = end
+		}
+		return out
+	}
+	return group
+}
+
+// keys computes the value each team is ranked by under a criterion, where a
+// higher value ranks first.
+func (t *tiebreakRanker) keys(criterion string, group []string) map[string]int64 {
+	keys := make(map[string]int64, len(group))
+	switch criterion {
+	case TiebreakHeadToHeadPoints, TiebreakHeadToHeadGoalDifference:
+		in := map[string]bool{}
+		for _, id := range group {
+			in[id] = true
+		}
+		var mini []domain.Match
+		for _, m := range t.matches {
+			if countsForStandings(m) && in[m.HomeTeamID] && in[m.AwayTeamID] {
+				mini = append(mini, m)
+			}
+		}
+		for id, r := range tallyStandings(t.ser, group, mini) {
+			if criterion == TiebreakHeadToHeadPoints {
+				keys[id] = int64(r.Points)
+			} else {
+				keys[id] = int64(r.GoalDifference)
+			}
+		}
+	case TiebreakGoalDifference:
+		for _, id := range group {
+			keys[id] = int64(t.rows[id].GoalDifference)
+		}
+	case TiebreakGoalsFor:
+		for _, id := range group {
+			keys[id] = int64(t.rows[id].GoalsFor)
+		}
+	case TiebreakFairPlay:
+		// Fewer penalty points ranks higher.
+		for _, id := range group {
+			keys[id] = -int64(t.rows[id].FairPlay)
+		}
+	case TiebreakLots:
+		var seed int64
+		if t.ser.LotsSeed != nil {
+			seed = *t.ser.LotsSeed
+		}
+		for _, id := range group {
+			keys[id] = lotsDraw(seed, id)
+		}
+	}
+	return keys
+}
+
+// lotsDraw derives a team's ticket from the recorded seed so that the draw
+// is reproducible for anyone holding the seed.
+func lotsDraw(seed int64, teamID string) int64 {
+	sum, _ := hex.DecodeString(util.HashToken(fmt.Sprintf("%d:%s", seed, teamID)))
+	return int64(binary.BigEndian.Uint64(sum) & math.MaxInt64)
+}
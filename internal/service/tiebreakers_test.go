@@ -0,0 +1,123 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func played(home, away string, hs, as int) domain.Match {
+	return domain.Match{
+		ID:         home + "-" + away,
+		HomeTeamID: home,
+		AwayTeamID: away,
+		Status:     "played",
+		Result:     &domain.MatchResult{Outcome: "played", HomeScore: hs, AwayScore: as},
+	}
+}
+
+func TestTiebreakResolve(t *testing.T) {
+	// a, b and d finish on 4 points. Head to head d beat a, who beat b, and
+	// b drew d; on goal difference b is far ahead.
+	matches := []domain.Match{
+		played("a", "b", 1, 0),
+		played("a", "c", 0, 0),
+		played("d", "a", 1, 0),
+		played("b", "c", 5, 0),
+		played("b", "d", 0, 0),
+	}
+	tests := []struct {
+		name        string
+		tiebreakers []string
+		order       []string
+		decidedBy   map[string]string
+	}{
+		{
+			name:        "goal difference",
+			tiebreakers: []string{TiebreakGoalDifference},
+			order:       []string{"b", "d", "a", "c"},
+			decidedBy:   map[string]string{"b": TiebreakGoalDifference, "d": TiebreakGoalDifference, "a": TiebreakGoalDifference},
+		},
+		{
+			name:        "head to head before goal difference",
+			tiebreakers: []string{TiebreakHeadToHeadPoints, TiebreakGoalDifference},
+			order:       []string{"d", "a", "b", "c"},
+			decidedBy:   map[string]string{"d": TiebreakHeadToHeadPoints, "a": TiebreakHeadToHeadPoints, "b": TiebreakHeadToHeadPoints},
+		},
+		{
+			name:        "still level keeps team-id order",
+			tiebreakers: []string{TiebreakGoalsFor},
+			order:       []string{"b", "a", "d", "c"},
+			decidedBy:   map[string]string{"b": TiebreakGoalsFor},
+		},
+		{
+			name:        "subgroup resolved from the first criterion",
+			tiebreakers: []string{TiebreakGoalsFor, TiebreakHeadToHeadPoints},
+			order:       []string{"b", "d", "a", "c"},
+			decidedBy:   map[string]string{"b": TiebreakGoalsFor, "d": TiebreakHeadToHeadPoints, "a": TiebreakHeadToHeadPoints},
+		},
+		{
+			name:  "no tiebreakers",
+			order: []string{"a", "b", "d", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ser := &domain.Series{PointsWin: 3, PointsDraw: 1, Tiebreakers: tt.tiebreakers}
+			rows := buildStandings(ser, []string{"a", "b", "c", "d"}, matches)
+			var order []string
+			for i, r := range rows {
+				order = append(order, r.TeamID)
+				if r.Position != i+1 {
+					t.Errorf("%s has position %d, want %d", r.TeamID, r.Position, i+1)
+				}
+				if r.DecidedBy != tt.decidedBy[r.TeamID] {
+					t.Errorf("%s decided by %q, want %q", r.TeamID, r.DecidedBy, tt.decidedBy[r.TeamID])
+				}
+			}
+			if !slices.Equal(order, tt.order) {
+				t.Fatalf("got order %v, want %v", order, tt.order)
+			}
+		})
+	}
+}
+
+func TestTiebreakLots(t *testing.T) {
+	teams := []string{"a", "b", "c", "d", "e"}
+	for _, seed := range []int64{0, 1, 42} {
+		ser := &domain.Series{Tiebreakers: []string{TiebreakLots}, LotsSeed: &seed}
+		rows := buildStandings(ser, teams, nil)
+		for i := 1; i < len(rows); i++ {
+			if lotsDraw(seed, rows[i-1].TeamID) < lotsDraw(seed, rows[i].TeamID) {
+				t.Errorf("seed %d: %s drew lower than %s but ranks above", seed, rows[i-1].TeamID, rows[i].TeamID)
+			}
+		}
+	}
+}
+
+func TestLotsDraw(t *testing.T) {
+	tests := []struct {
+		name       string
+		seedA      int64
+		teamA      string
+		seedB      int64
+		teamB      string
+		wantsEqual bool
+	}{
+		{"same seed and team", 7, "t1", 7, "t1", true},
+		{"other team", 7, "t1", 7, "t2", false},
+		{"other seed", 7, "t1", 8, "t1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := lotsDraw(tt.seedA, tt.teamA), lotsDraw(tt.seedB, tt.teamB)
+			if a < 0 || b < 0 {
+				t.Fatalf("negative ticket: %d, %d", a, b)
+			}
+			if (a == b) != tt.wantsEqual {
+				t.Fatalf("tickets %d and %d, want equal %v", a, b, tt.wantsEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,620 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository"
+	"team-manager-leagues/internal/util"
+)
+
+// Transfers
+
+const (
+	TransferPending   = "pending"
+	TransferCompleted = "completed"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+)
+
+// Transfer event actions; the others match the statuses they lead to.
+const (
+	TransferRequested = "requested"
+	TransferApproved  = "approved"
+)
+
+// transferActor is in which capacities a user acts on a transfer.
+type transferActor int
+
+const (
+	actorFromClub  transferActor = 1 << iota // Owner of the club the player leaves
+	actorToClub                              // Owner of the club the player joins
+	actorRegistrar                           // Organizer managing registrations of the league
+)
+
+// TransferWindowInput opens a period of the season for transfers.
+type TransferWindowInput struct {
+	OpensAt             time.Time `json:"opensAt"`
+	ClosesAt            time.Time `json:"closesAt"`
+	MaxTransfersPerTeam *int      `json:"maxTransfersPerTeam"` // Omit for no cap
+}
+
+// CreateTransferWindow adds a window to the season. The windows of a season
+// must not overlap.
+func (s *LeaguesService) CreateTransferWindow(ctx context.Context, userID, leagueID, seasonID string, in TransferWindowInput) (*domain.TransferWindow, error) {
+	se, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if in.OpensAt.IsZero() {
+		return nil, Invalid("opensAt", "opensAt is required")
+	}
+	if !in.ClosesAt.After(in.OpensAt) {
+		return nil, Invalid("closesAt", "the window must close after it opens")
+	}
+	if !in.ClosesAt.After(time.Now()) {
+		return nil, Invalid("closesAt", "the window must close in the future")
+	}
+	if in.MaxTransfersPerTeam != nil && *in.MaxTransfersPerTeam < 1 {
+		return nil, Invalid("maxTransfersPerTeam", "maxTransfersPerTeam must be at least 1")
+	}
+	windows, err := s.store.ListTransferWindows(ctx, se.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range windows {
+		if in.OpensAt.Before(w.ClosesAt) && w.OpensAt.Before(in.ClosesAt) {
+			return nil, Conflict("the window overlaps the transfer window from %s to %s",
+				w.OpensAt.Format(time.RFC3339), w.ClosesAt.Format(time.RFC3339))
+		}
+	}
+	w := &domain.TransferWindow{
+		ID:                  util.RandID(),
+		LeagueID:            leagueID,
+		SeasonID:            se.ID,
+		OpensAt:             in.OpensAt,
+		ClosesAt:            in.ClosesAt,
+		MaxTransfersPerTeam: in.MaxTransfersPerTeam,
+		CreatedBy:           userID,
+	}
+	if err := s.store.CreateTransferWindow(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ListTransferWindows returns the windows of the season, earliest first.
+func (s *LeaguesService) ListTransferWindows(ctx context.Context, leagueID, seasonID string) ([]domain.TransferWindow, error) {
+	se, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListTransferWindows(ctx, se.ID)
+}
+
+// DeleteTransferWindow removes a window that has not opened yet.
+func (s *LeaguesService) DeleteTransferWindow(ctx context.Context, leagueID, seasonID, id string) error {
+	w, err := s.store.GetTransferWindow(ctx, id)
+	if err != nil {
+		return err
+	}
+	if w == nil || w.LeagueID != leagueID || w.SeasonID != seasonID {
+		return NotFound("transfer window not found")
+	}
+	if !time.Now().Before(w.OpensAt) {
+		return Conflict("the transfer window opened at %s and can no longer be deleted", w.OpensAt.Format(time.RFC3339))
+	}
+	return s.store.DeleteTransferWindow(ctx, id)
+}
+
+// openTransferWindow returns the window of the season open at now, or an
+// error naming the next one to open.
+func (s *LeaguesService) openTransferWindow(ctx context.Context, se *domain.Season, now time.Time) (*domain.TransferWindow, error) {
+	windows, err := s.store.ListTransferWindows(ctx, se.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, w := range windows {
+		if now.Before(w.OpensAt) {
+			return nil, Conflict("no transfer window is open for season %s; the next opens at %s", se.Name, w.OpensAt.Format(time.RFC3339))
+		}
+		if now.Before(w.ClosesAt) {
+			return &windows[i], nil
+		}
+	}
+	return nil, Conflict("no transfer window is open for season %s; players cannot move between rosters", se.Name)
+}
+
+// TransferInput asks to move a player from one roster to another.
+type TransferInput struct {
+	PlayerID           string `json:"playerId"`
+	FromRegistrationID string `json:"fromRegistrationId"`
+	ToRegistrationID   string `json:"toRegistrationId"`
+	ShirtNumber        int    `json:"shirtNumber"` // On the new roster; 0 keeps the current number
+	Reason             string `json:"reason"`
+}
+
+// transferSides loads the registrations and series of both sides of a move.
+func (s *LeaguesService) transferSides(ctx context.Context, fromID, toID string) (from, to *domain.TeamRegistration, fromSer, toSer *domain.Series, err error) {
+	for _, side := range []struct {
+		id, field string
+		reg       **domain.TeamRegistration
+		ser       **domain.Series
+	}{{fromID, "fromRegistrationId", &from, &fromSer}, {toID, "toRegistrationId", &to, &toSer}} {
+		reg, err := s.store.GetRegistrationByID(ctx, side.id)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if reg == nil {
+			return nil, nil, nil, nil, Invalid(side.field, "registration not found")
+		}
+		ser, err := s.store.GetSeriesByID(ctx, reg.SeriesID)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if ser == nil {
+			return nil, nil, nil, nil, NotFound("series not found")
+		}
+		*side.reg, *side.ser = reg, ser
+	}
+	return from, to, fromSer, toSer, nil
+}
+
+// transferActor works out in which capacities userID acts on a transfer.
+func (s *LeaguesService) transferActor(ctx context.Context, userID, leagueID string, t *domain.Transfer) (transferActor, error) {
+	var actor transferActor
+	for _, side := range []struct {
+		teamID string
+		as     transferActor
+	}{{t.FromTeamID, actorFromClub}, {t.ToTeamID, actorToClub}} {
+		owns, err := s.ownsTeam(ctx, userID, side.teamID)
+		if err != nil {
+			return 0, err
+		}
+		if owns {
+			actor |= side.as
+		}
+	}
+	registrar, err := s.can(ctx, userID, leagueID, PermManageRegistrations)
+	if err != nil {
+		return 0, err
+	}
+	if registrar {
+		actor |= actorRegistrar
+	}
+	return actor, nil
+}
+
+// RequestTransfer asks to move a player between two rosters of the same
+// season during an open transfer window. The request counts as the approval
+// of the clubs the user owns; once the owners of both clubs have approved,
+// the player moves.
+func (s *LeaguesService) RequestTransfer(ctx context.Context, userID string, in TransferInput) (*domain.Transfer, error) {
+	from, to, fromSer, toSer, err := s.transferSides(ctx, in.FromRegistrationID, in.ToRegistrationID)
+	if err != nil {
+		return nil, err
+	}
+	if from.ID == to.ID {
+		return nil, Invalid("toRegistrationId", "the player is already on this roster")
+	}
+	if fromSer.SeasonID != toSer.SeasonID {
+		return nil, Invalid("toRegistrationId", "players can only move between rosters of the same season")
+	}
+	switch to.Status {
+	case RegistrationInvited, RegistrationPending, RegistrationWaitlisted, RegistrationApproved:
+	default:
+		return nil, Conflict("the registration is %s; players can no longer join its roster", to.Status)
+	}
+	switch from.Status {
+	case RegistrationInvited, RegistrationPending, RegistrationWaitlisted, RegistrationApproved:
+	default:
+		// Its roster is a record now, and its players are free to join
+		// another roster directly
+		return nil, Conflict("the registration is %s; its roster can no longer change", from.Status)
+	}
+	t := &domain.Transfer{
+		ID:                 util.RandID(),
+		SeasonID:           toSer.SeasonID,
+		PlayerID:           strings.TrimSpace(in.PlayerID),
+		FromRegistrationID: from.ID,
+		ToRegistrationID:   to.ID,
+		FromTeamID:         from.TeamID,
+		ToTeamID:           to.TeamID,
+		ShirtNumber:        in.ShirtNumber,
+		Status:             TransferPending,
+		RequestedBy:        userID,
+	}
+	actor, err := s.transferActor(ctx, userID, toSer.LeagueID, t)
+	if err != nil {
+		return nil, err
+	}
+	if actor&(actorFromClub|actorToClub) == 0 {
+		return nil, Forbidden("only an owner of one of the clubs can request a transfer")
+	}
+	p, err := s.store.GetRosterPlayer(ctx, from.ID, t.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, Invalid("playerId", "the player is not on the roster of %s", fromSer.Name)
+	}
+	if t.ShirtNumber == 0 {
+		t.ShirtNumber = p.ShirtNumber
+	}
+	if t.ShirtNumber < 1 || t.ShirtNumber > 99 {
+		return nil, Invalid("shirtNumber", "shirt number must be between 1 and 99")
+	}
+	if err := s.checkRosterAge(ctx, toSer, p.BirthDate); err != nil {
+		return nil, err
+	}
+	se, err := s.store.GetSeasonByID(ctx, t.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+	if se == nil {
+		return nil, NotFound("season not found")
+	}
+	w, err := s.openTransferWindow(ctx, se, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	t.WindowID = w.ID
+	if err := s.checkTransferLimits(ctx, t, w, toSer); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(in.Reason)
+	events := []domain.TransferEvent{{ID: util.RandID(), TransferID: t.ID, Action: TransferRequested, ActorID: userID, Reason: reason}}
+	events = append(events, approveTransfer(t, userID, actor)...)
+	m := repository.RosterMove{}
+	// The user owns both clubs: the move needs no one else, and is stored
+	// with the request.
+	done := t.FromApprovedBy != "" && t.ToApprovedBy != ""
+	if done {
+		m = transferMove(t, fromSer, toSer, p)
+		events = append(events, domain.TransferEvent{ID: util.RandID(), TransferID: t.ID, Action: TransferCompleted})
+	}
+	if w.MaxTransfersPerTeam != nil {
+		m.MaxPerTeam = *w.MaxTransfersPerTeam
+	}
+	created, err := s.store.CreateTransfer(ctx, t, events, m)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, s.transferBlocked(ctx, t, w, toSer)
+	}
+	if done {
+		t.Status = TransferCompleted
+	}
+	return t, nil
+}
+
+// approveTransfer records userID's approval for the clubs it owns that have
+// not approved yet, returning the events to store.
+func approveTransfer(t *domain.Transfer, userID string, actor transferActor) []domain.TransferEvent {
+	var events []domain.TransferEvent
+	for _, side := range []struct {
+		as   transferActor
+		club string
+		by   *string
+	}{{actorFromClub, "from", &t.FromApprovedBy}, {actorToClub, "to", &t.ToApprovedBy}} {
+		if actor&side.as == 0 || *side.by != "" {
+			continue
+		}
+		*side.by = userID
+		events = append(events, domain.TransferEvent{ID: util.RandID(), TransferID: t.ID, Action: TransferApproved, Club: side.club, ActorID: userID})
+	}
+	return events
+}
+
+// checkTransferLimits rejects a transfer that would exceed the window's cap
+// for either team, counting the other transfers pending or completed, or
+// the size limit of the new roster.
+func (s *LeaguesService) checkTransferLimits(ctx context.Context, t *domain.Transfer, w *domain.TransferWindow, toSer *domain.Series) error {
+	if w.MaxTransfersPerTeam != nil {
+		for _, team := range []string{t.FromTeamID, t.ToTeamID} {
+			n, err := s.store.CountWindowTransfers(ctx, w.ID, team, t.ID)
+			if err != nil {
+				return err
+			}
+			if n >= *w.MaxTransfersPerTeam {
+				return Conflict("team %s has used its %d transfers in this window, pending ones included", team, *w.MaxTransfersPerTeam)
+			}
+		}
+	}
+	if toSer.MaxRosterSize != nil {
+		players, err := s.store.ListRosterPlayers(ctx, t.ToRegistrationID)
+		if err != nil {
+			return err
+		}
+		if len(players) >= *toSer.MaxRosterSize {
+			return Conflict("the roster is full: %s allows %d players", toSer.Name, *toSer.MaxRosterSize)
+		}
+	}
+	return nil
+}
+
+// transferMove describes how the transfer moves the player p between the
+// rosters, without the limits.
+func transferMove(t *domain.Transfer, fromSer, toSer *domain.Series, p *domain.RosterPlayer) repository.RosterMove {
+	late := rosterLocked(toSer)
+	reason := "transfer " + t.ID
+	m := repository.RosterMove{
+		Player: &domain.RosterPlayer{
+			RegistrationID: t.ToRegistrationID,
+			SeriesID:       toSer.ID,
+			TeamID:         t.ToTeamID,
+			PlayerID:       t.PlayerID,
+			Name:           p.Name,
+			BirthDate:      p.BirthDate,
+			ShirtNumber:    t.ShirtNumber,
+			Late:           late,
+			AddedBy:        t.RequestedBy,
+		},
+		Out: &domain.RosterChange{
+			ID:             util.RandID(),
+			RegistrationID: t.FromRegistrationID,
+			SeriesID:       fromSer.ID,
+			PlayerID:       t.PlayerID,
+			Action:         RosterTransferredOut,
+			ShirtNumber:    p.ShirtNumber,
+			Late:           rosterLocked(fromSer),
+			ActorID:        t.RequestedBy,
+			Reason:         reason,
+		},
+		In: &domain.RosterChange{
+			ID:             util.RandID(),
+			RegistrationID: t.ToRegistrationID,
+			SeriesID:       toSer.ID,
+			PlayerID:       t.PlayerID,
+			Action:         RosterTransferredIn,
+			ShirtNumber:    t.ShirtNumber,
+			Late:           late,
+			ActorID:        t.RequestedBy,
+			Reason:         reason,
+		},
+	}
+	if toSer.MaxRosterSize != nil {
+		m.MaxRoster = *toSer.MaxRosterSize
+	}
+	return m
+}
+
+// completeTransfer moves the player of an approved transfer to the new
+// roster, storing events with the completion.
+func (s *LeaguesService) completeTransfer(ctx context.Context, t *domain.Transfer, fromSer, toSer *domain.Series, p *domain.RosterPlayer, w *domain.TransferWindow, events []domain.TransferEvent) error {
+	m := transferMove(t, fromSer, toSer, p)
+	if w.MaxTransfersPerTeam != nil {
+		m.MaxPerTeam = *w.MaxTransfersPerTeam
+	}
+	events = append(events, domain.TransferEvent{ID: util.RandID(), TransferID: t.ID, Action: TransferCompleted})
+	done, err := s.store.CompleteTransfer(ctx, t, events, m)
+	if err != nil {
+		return err
+	}
+	if done {
+		t.Status = TransferCompleted
+		return nil
+	}
+	// Something changed since the checks; find out what for the error.
+	cur, err := s.store.GetTransfer(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if cur == nil || cur.Status != TransferPending {
+		return fmt.Errorf("%w: transfer changed concurrently", ErrInvalidTransition)
+	}
+	return s.transferBlocked(ctx, t, w, toSer)
+}
+
+// transferBlocked explains why the store refused to record or complete a
+// transfer whose checks had passed: something changed in between.
+func (s *LeaguesService) transferBlocked(ctx context.Context, t *domain.Transfer, w *domain.TransferWindow, toSer *domain.Series) error {
+	if err := s.checkTransferLimits(ctx, t, w, toSer); err != nil {
+		return err
+	}
+	return Conflict("the player is no longer on the roster the transfer moves them from")
+}
+
+// loadTransfer loads a transfer with its window, expiring the transfer if
+// the window closed while it was pending.
+func (s *LeaguesService) loadTransfer(ctx context.Context, id string) (*domain.Transfer, *domain.TransferWindow, error) {
+	t, err := s.store.GetTransfer(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t == nil {
+		return nil, nil, NotFound("transfer not found")
+	}
+	w, err := s.store.GetTransferWindow(ctx, t.WindowID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if w == nil {
+		return nil, nil, NotFound("transfer not found")
+	}
+	if err := s.expireTransfer(ctx, t, w); err != nil {
+		return nil, nil, err
+	}
+	return t, w, nil
+}
+
+// expireTransfer moves a transfer still pending when its window closed to
+// expired.
+func (s *LeaguesService) expireTransfer(ctx context.Context, t *domain.Transfer, w *domain.TransferWindow) error {
+	if t.Status != TransferPending || time.Now().Before(w.ClosesAt) {
+		return nil
+	}
+	ev := &domain.TransferEvent{ID: util.RandID(), TransferID: t.ID, Action: TransferExpired, Reason: "not approved before the transfer window closed"}
+	applied, err := s.store.UpdateTransferStatus(ctx, t.ID, TransferPending, TransferExpired, ev)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		// Settled or expired concurrently; take the stored status.
+		cur, err := s.store.GetTransfer(ctx, t.ID)
+		if err != nil || cur == nil {
+			return err
+		}
+		*t = *cur
+		return nil
+	}
+	t.Status = TransferExpired
+	return nil
+}
+
+// GetTransfer returns a transfer to the owners of either club and the
+// league's organizers.
+func (s *LeaguesService) GetTransfer(ctx context.Context, userID, id string) (*domain.Transfer, error) {
+	t, w, err := s.loadTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.transferActor(ctx, userID, w.LeagueID, t)
+	if err != nil {
+		return nil, err
+	}
+	if actor == 0 {
+		return nil, Forbidden("not allowed to view this transfer")
+	}
+	return t, nil
+}
+
+// TransferUpdate approves, declines or cancels a pending transfer.
+type TransferUpdate struct {
+	Status string `json:"status" binding:"required"` // "approved", "declined" or "cancelled"
+	Reason string `json:"reason"`                    // Required to decline
+}
+
+// UpdateTransfer moves a pending transfer on. The owner of a club that has
+// not approved yet may approve or decline it; the requester or a league
+// organizer may cancel it. The last approval moves the player.
+func (s *LeaguesService) UpdateTransfer(ctx context.Context, userID, id string, in TransferUpdate) (*domain.Transfer, error) {
+	t, w, err := s.loadTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch in.Status {
+	case TransferApproved, TransferDeclined, TransferCancelled:
+	default:
+		return nil, Invalid("status", "status must be approved, declined or cancelled")
+	}
+	if t.Status != TransferPending {
+		return nil, fmt.Errorf("%w: the transfer is %s", ErrInvalidTransition, t.Status)
+	}
+	reason := strings.TrimSpace(in.Reason)
+	actor, err := s.transferActor(ctx, userID, w.LeagueID, t)
+	if err != nil {
+		return nil, err
+	}
+	// Owners may only act for clubs still to approve.
+	if t.FromApprovedBy != "" {
+		actor &^= actorFromClub
+	}
+	if t.ToApprovedBy != "" {
+		actor &^= actorToClub
+	}
+
+	switch in.Status {
+	case TransferCancelled:
+		if t.RequestedBy != userID && actor&actorRegistrar == 0 {
+			return nil, Forbidden("only the requester or league organizers can cancel a transfer")
+		}
+		return s.setTransferStatus(ctx, t, TransferCancelled, userID, reason)
+	case TransferDeclined:
+		if actor&(actorFromClub|actorToClub) == 0 {
+			return nil, Forbidden("only the owner of a club that has not approved the transfer can decline it")
+		}
+		if reason == "" {
+			return nil, Invalid("reason", "a reason is required to decline a transfer")
+		}
+		return s.setTransferStatus(ctx, t, TransferDeclined, userID, reason)
+	}
+
+	if actor&(actorFromClub|actorToClub) == 0 {
+		return nil, Forbidden("only the owner of a club that has not approved the transfer can approve it")
+	}
+	approvals := approveTransfer(t, userID, actor)
+	if t.FromApprovedBy == "" || t.ToApprovedBy == "" {
+		applied, err := s.store.UpdateTransferApprovals(ctx, t, &approvals[0])
+		if err != nil {
+			return nil, err
+		}
+		if !applied {
+			return nil, fmt.Errorf("%w: transfer changed concurrently", ErrInvalidTransition)
+		}
+		return t, nil
+	}
+	_, _, fromSer, toSer, err := s.transferSides(ctx, t.FromRegistrationID, t.ToRegistrationID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.store.GetRosterPlayer(ctx, t.FromRegistrationID, t.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, Conflict("the player is no longer on the roster the transfer moves them from")
+	}
+	if err := s.checkTransferLimits(ctx, t, w, toSer); err != nil {
+		return nil, err
+	}
+	if err := s.completeTransfer(ctx, t, fromSer, toSer, p, w, approvals); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *LeaguesService) setTransferStatus(ctx context.Context, t *domain.Transfer, to, userID, reason string) (*domain.Transfer, error) {
+	ev := &domain.TransferEvent{ID: util.RandID(), TransferID: t.ID, Action: to, ActorID: userID, Reason: reason}
+	applied, err := s.store.UpdateTransferStatus(ctx, t.ID, TransferPending, to, ev)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, fmt.Errorf("%w: transfer changed concurrently", ErrInvalidTransition)
+	}
+	t.Status = to
+	return t, nil
+}
+
+// TransferHistory lists the steps of a transfer, oldest first.
+func (s *LeaguesService) TransferHistory(ctx context.Context, userID, id string) ([]domain.TransferEvent, error) {
+	t, err := s.GetTransfer(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListTransferEvents(ctx, t.ID)
+}
+
+// ListSeasonTransfers returns the transfers of the season, oldest first;
+// with a teamID only those in or out of that team.
+func (s *LeaguesService) ListSeasonTransfers(ctx context.Context, leagueID, seasonID, teamID string) ([]domain.Transfer, error) {
+	se, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.store.ListTransfers(ctx, se.ID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := s.store.ListTransferWindows(ctx, se.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		for j := range windows {
+			if windows[j].ID == list[i].WindowID {
+				if err := s.expireTransfer(ctx, &list[i], &windows[j]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+// rival owns the club of t9 only, so transfers between t9 and the other
+// teams wait for a second approval.
+const rival = "rival"
+
+func TestTransferCap(t *testing.T) {
+	tl := newTestLeague(t, SeriesSettings{})
+	tl.store.Seed(
+		[]domain.Team{{ID: "t9", ClubID: "c9", Name: "Team 9", Format: "11"}},
+		[]domain.Membership{{ID: "c9", UserID: rival, ClubID: "c9", Role: "owner", Status: "active"}},
+	)
+	regs := map[string]string{}
+	for _, reg := range tl.register("t1", "t2", "t3") {
+		regs[reg.TeamID] = reg.ID
+	}
+	reg, err := tl.svc.RegisterTeam(tl.ctx, rival, RegistrationInput{TeamID: "t9", SeriesID: tl.series.ID})
+	if err != nil {
+		t.Fatalf("register t9: %v", err)
+	}
+	regs["t9"] = reg.ID
+	for i := 1; i <= 3; i++ {
+		if _, err := tl.svc.SaveRosterPlayer(tl.ctx, clubOwner, regs["t1"], fmt.Sprintf("p%d", i), RosterPlayerInput{Name: "Ana", BirthDate: "2000-01-01", ShirtNumber: i}); err != nil {
+			t.Fatalf("add player: %v", err)
+		}
+	}
+	now := time.Now()
+	_, err = tl.svc.CreateTransferWindow(tl.ctx, organizer, tl.league.ID, tl.series.SeasonID, TransferWindowInput{OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(24 * time.Hour), MaxTransfersPerTeam: ptr(0)})
+	checkErr(t, err, ErrValidation)
+	if _, err := tl.svc.CreateTransferWindow(tl.ctx, organizer, tl.league.ID, tl.series.SeasonID, TransferWindowInput{OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(24 * time.Hour), MaxTransfersPerTeam: ptr(2)}); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+	transfer := func(player, from, to string) (*domain.Transfer, error) {
+		return tl.svc.RequestTransfer(tl.ctx, clubOwner, TransferInput{PlayerID: player, FromRegistrationID: regs[from], ToRegistrationID: regs[to]})
+	}
+
+	// Owning both clubs, the owner moves p1 at once.
+	moved, err := transfer("p1", "t1", "t2")
+	checkErr(t, err, nil)
+	if moved.Status != TransferCompleted {
+		t.Fatalf("p1 transfer %s", moved.Status)
+	}
+	// p2 waits for the rival's approval, but counts against the cap.
+	pending, err := transfer("p2", "t1", "t9")
+	checkErr(t, err, nil)
+	if pending.Status != TransferPending {
+		t.Fatalf("p2 transfer %s", pending.Status)
+	}
+	_, err = transfer("p3", "t1", "t3")
+	checkErr(t, err, ErrConflict)
+	_, err = transfer("p1", "t2", "t1") // Back into t1
+	checkErr(t, err, ErrConflict)
+
+	// A cancelled transfer frees its place.
+	if _, err := tl.svc.UpdateTransfer(tl.ctx, clubOwner, pending.ID, TransferUpdate{Status: TransferCancelled}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	moved, err = transfer("p3", "t1", "t3")
+	checkErr(t, err, nil)
+	if moved.Status != TransferCompleted {
+		t.Fatalf("p3 transfer %s", moved.Status)
+	}
+	_, err = transfer("p2", "t1", "t9")
+	checkErr(t, err, ErrConflict)
+
+	list, err := tl.svc.ListSeasonTransfers(tl.ctx, tl.league.ID, tl.series.SeasonID, "t1")
+	checkErr(t, err, nil)
+	counts := map[string]int{}
+	for _, tr := range list {
+		counts[tr.Status]++
+	}
+	if len(list) != 3 || counts[TransferCompleted] != 2 || counts[TransferCancelled] != 1 {
+		t.Fatalf("t1 transfers %v", counts)
+	}
+	roster, err := tl.svc.ListRoster(tl.ctx, clubOwner, regs["t1"])
+	checkErr(t, err, nil)
+	if len(roster) != 1 || roster[0].PlayerID != "p2" {
+		t.Fatalf("t1 roster %+v", roster)
+	}
+}
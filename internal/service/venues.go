@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/util"
+)
+
+// Venues
+
+const (
+	SurfaceGrass      = "grass"
+	SurfaceArtificial = "artificial"
+	SurfaceHybrid     = "hybrid"
+	SurfaceIndoor     = "indoor"
+)
+
+var surfaces = []string{SurfaceGrass, SurfaceArtificial, SurfaceHybrid, SurfaceIndoor}
+
+// endOfTime bounds listings that run to the last booking.
+var endOfTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// VenueInput describes a venue. Updates replace every field, pitches,
+// windows and blackouts included.
+type VenueInput struct {
+	Name         string                      `json:"name"`
+	Address      string                      `json:"address"`
+	Latitude     *float64                    `json:"latitude"`
+	Longitude    *float64                    `json:"longitude"`
+	Timezone     string                      `json:"timezone"` // Defaults to "UTC"
+	Pitches      []domain.Pitch              `json:"pitches"`
+	Availability []domain.AvailabilityWindow `json:"availability"`
+	Blackouts    []domain.Blackout           `json:"blackouts"`
+}
+
+func (in VenueInput) apply(v *domain.Venue) error {
+	v.Name = strings.TrimSpace(in.Name)
+	if v.Name == "" {
+		return Invalid("name", "invalid name")
+	}
+	v.Address = strings.TrimSpace(in.Address)
+
+	if (in.Latitude == nil) != (in.Longitude == nil) {
+		return Invalid("", "give both latitude and longitude, or neither")
+	}
+	if in.Latitude != nil && (*in.Latitude < -90 || *in.Latitude > 90) {
+		return Invalid("latitude", "latitude must be between -90 and 90")
+	}
+	if in.Longitude != nil && (*in.Longitude < -180 || *in.Longitude > 180) {
+		return Invalid("longitude", "longitude must be between -180 and 180")
+	}
+	v.Latitude, v.Longitude = in.Latitude, in.Longitude
+
+	v.Timezone = in.Timezone
+	if v.Timezone == "" {
+		v.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(v.Timezone); err != nil || v.Timezone == "Local" {
+		return Invalid("timezone", "unknown time zone %q; use an IANA name like \"America/Santiago\"", in.Timezone)
+	}
+
+	if len(in.Pitches) == 0 {
+		return Invalid("pitches", "a venue needs at least one pitch")
+	}
+	v.Pitches = make([]domain.Pitch, len(in.Pitches))
+	for i, p := range in.Pitches {
+		if p.Number <= 0 {
+			return Invalid("pitches", "pitch numbers must be positive")
+		}
+		if slices.ContainsFunc(in.Pitches[:i], func(x domain.Pitch) bool { return x.Number == p.Number }) {
+			return Invalid("pitches", "pitch %d is listed twice", p.Number)
+		}
+		if !slices.Contains(surfaces, p.Surface) {
+			return Invalid("pitches", "invalid surface %q for pitch %d: use one of %s", p.Surface, p.Number, strings.Join(surfaces, ", "))
+		}
+		formats := []string{}
+		for _, f := range p.Formats {
+			if f = strings.TrimSpace(f); f != "" && !slices.Contains(formats, f) {
+				formats = append(formats, f)
+			}
+		}
+		v.Pitches[i] = domain.Pitch{Number: p.Number, Surface: p.Surface, Formats: formats}
+	}
+
+	v.Availability = []domain.AvailabilityWindow{}
+	for _, w := range in.Availability {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return Invalid("availability", "weekday must be 0 (Sunday) through 6 (Saturday)")
+		}
+		opens, ok1 := parseClock(w.Opens)
+		closes, ok2 := parseClock(w.Closes)
+		if !ok1 || !ok2 {
+			return Invalid("availability", "opening hours must be times like 09:00")
+		}
+		if closes <= opens {
+			return Invalid("availability", "a window must close after it opens")
+		}
+		if err := checkPitchNumbers(v, "availability", w.Pitches); err != nil {
+			return err
+		}
+		v.Availability = append(v.Availability, w)
+	}
+
+	v.Blackouts = []domain.Blackout{}
+	for _, b := range in.Blackouts {
+		if _, err := time.Parse(time.DateOnly, b.Date); err != nil {
+			return Invalid("blackouts", "blackout dates must be like 2025-12-25")
+		}
+		if err := checkPitchNumbers(v, "blackouts", b.Pitches); err != nil {
+			return err
+		}
+		b.Reason = strings.TrimSpace(b.Reason)
+		v.Blackouts = append(v.Blackouts, b)
+	}
+	return nil
+}
+
+func checkPitchNumbers(v *domain.Venue, field string, numbers []int) error {
+	for _, n := range numbers {
+		if !hasPitch(v, n) {
+			return Invalid(field, "the venue has no pitch %d", n)
+		}
+	}
+	return nil
+}
+
+func hasPitch(v *domain.Venue, number int) bool {
+	return slices.ContainsFunc(v.Pitches, func(p domain.Pitch) bool { return p.Number == number })
+}
+
+// parseClock reads "HH:MM" as minutes since midnight; "24:00" ends the day.
+func parseClock(s string) (int, bool) {
+	if s == "24:00" {
+		return 24 * 60, true
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func (s *LeaguesService) CreateVenue(ctx context.Context, leagueID string, in VenueInput) (*domain.Venue, error) {
+	v := &domain.Venue{ID: util.RandID(), LeagueID: leagueID}
+	if err := in.apply(v); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateVenue(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ListVenues returns the venues of a league by name.
+func (s *LeaguesService) ListVenues(ctx context.Context, leagueID string) ([]domain.Venue, error) {
+	return s.store.ListVenues(ctx, leagueID)
+}
+
+func (s *LeaguesService) GetVenue(ctx context.Context, leagueID, id string) (*domain.Venue, error) {
+	v, err := s.store.GetVenueByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || v.LeagueID != leagueID {
+		return nil, NotFound("venue not found")
+	}
+	return v, nil
+}
+
+// UpdateVenue replaces a venue's details. Pitches with matches still to be
+// played cannot be removed; moving or unbooking those matches comes first.
+func (s *LeaguesService) UpdateVenue(ctx context.Context, leagueID, id string, in VenueInput) (*domain.Venue, error) {
+	v, err := s.GetVenue(ctx, leagueID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := in.apply(v); err != nil {
+		return nil, err
+	}
+	booked, err := s.store.ListMatchesByVenue(ctx, v.ID, time.Now(), endOfTime)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range booked {
+		if m.Status == "scheduled" && !hasPitch(v, m.Pitch) {
+			return nil, Conflict("pitch %d still has matches booked, the first at %s", m.Pitch, m.KickoffAt.Format(time.RFC3339))
+		}
+	}
+	if err := s.store.UpdateVenue(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// DeleteVenue deletes a venue no match was ever booked into; the bookings
+// of played matches are kept as their record.
+func (s *LeaguesService) DeleteVenue(ctx context.Context, leagueID, id string) error {
+	if _, err := s.GetVenue(ctx, leagueID, id); err != nil {
+		return err
+	}
+	booked, err := s.store.ListMatchesByVenue(ctx, id, time.Time{}, endOfTime)
+	if err != nil {
+		return err
+	}
+	if len(booked) > 0 {
+		return Conflict("the venue has %d matches booked; move them elsewhere first", len(booked))
+	}
+	return s.store.DeleteVenue(ctx, id)
+}
+
+// VenueBookingsQuery bounds the bookings listed for a venue. Zero bounds
+// leave the range open.
+type VenueBookingsQuery struct {
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+// ListVenueBookings returns the matches booked at a venue, earliest first.
+func (s *LeaguesService) ListVenueBookings(ctx context.Context, leagueID, id string, q VenueBookingsQuery) ([]domain.Match, error) {
+	if _, err := s.GetVenue(ctx, leagueID, id); err != nil {
+		return nil, err
+	}
+	if q.To.IsZero() {
+		q.To = endOfTime
+	}
+	return s.store.ListMatchesByVenue(ctx, id, q.From, q.To)
+}
+
+// SlotInput books a match into a pitch of a venue.
+type SlotInput struct {
+	VenueID   string    `json:"venueId"`
+	Pitch     int       `json:"pitch"` // May be left out at single-pitch venues
+	KickoffAt time.Time `json:"kickoffAt"`
+}
+
+// ScheduleMatch books a scheduled match into a venue slot, or moves it to
+// another one. The match holds the pitch for the series' match minutes;
+// officials who cannot take it in the new slot are released.
+func (s *LeaguesService) ScheduleMatch(ctx context.Context, leagueID, seriesID, matchID string, in SlotInput) (*domain.Match, error) {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != "scheduled" {
+		return nil, Conflict("the match is %s; only matches still to be played can be booked", m.Status)
+	}
+	if err := s.planSlot(ctx, leagueID, m, in); err != nil {
+		return nil, err
+	}
+	released, err := s.releasedOfficials(ctx, leagueID, []domain.Match{*m})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateMatchSlot(ctx, m, assignmentIDs(released)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// planSlot checks the match can be booked into the slot and sets it on m,
+// without storing it.
+func (s *LeaguesService) planSlot(ctx context.Context, leagueID string, m *domain.Match, in SlotInput) error {
+	ser, err := s.store.GetSeriesByID(ctx, m.SeriesID)
+	if err != nil {
+		return err
+	}
+	if ser == nil {
+		return NotFound("series not found")
+	}
+	v, err := s.GetVenue(ctx, leagueID, in.VenueID)
+	if err != nil {
+		return err
+	}
+	if in.Pitch == 0 && len(v.Pitches) == 1 {
+		in.Pitch = v.Pitches[0].Number
+	}
+	if in.KickoffAt.IsZero() {
+		return Invalid("kickoffAt", "kickoffAt is required")
+	}
+	start := in.KickoffAt.UTC()
+	end := start.Add(time.Duration(ser.MatchMinutes) * time.Minute)
+	if err := checkSlot(v, in.Pitch, ser.Format, start, end); err != nil {
+		return err
+	}
+	if err := s.checkPitchFree(ctx, v, m.ID, in.Pitch, start, end); err != nil {
+		return err
+	}
+	m.VenueID, m.Pitch, m.KickoffAt, m.EndsAt = v.ID, in.Pitch, &start, &end
+	return nil
+}
+
+// UnscheduleMatch frees the slot of a match still to be played, releasing
+// its officials.
+func (s *LeaguesService) UnscheduleMatch(ctx context.Context, leagueID, seriesID, matchID string) error {
+	m, err := s.getSeriesMatch(ctx, leagueID, seriesID, matchID)
+	if err != nil {
+		return err
+	}
+	if m.Status != "scheduled" {
+		return Conflict("the match is %s; only matches still to be played can be unbooked", m.Status)
+	}
+	m.VenueID, m.Pitch, m.KickoffAt, m.EndsAt = "", 0, nil, nil
+	released, err := s.releasedOfficials(ctx, leagueID, []domain.Match{*m})
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateMatchSlot(ctx, m, assignmentIDs(released))
+}
+
+// checkSlot checks that a pitch of the venue can host a match of the format
+// from start to end: the pitch takes the format, one weekly window covers
+// the whole match and no blackout closes the pitch on its days.
+func checkSlot(v *domain.Venue, pitch int, format string, start, end time.Time) error {
+	i := slices.IndexFunc(v.Pitches, func(p domain.Pitch) bool { return p.Number == pitch })
+	if i < 0 {
+		return Invalid("pitch", "%s has no pitch %d", v.Name, pitch)
+	}
+	if formats := v.Pitches[i].Formats; len(formats) > 0 && !slices.Contains(formats, format) {
+		return Invalid("pitch", "pitch %d of %s does not host format %q, only %s", pitch, v.Name, format, strings.Join(formats, ", "))
+	}
+	loc, err := time.LoadLocation(v.Timezone)
+	if err != nil {
+		return fmt.Errorf("venue %s: %w", v.ID, err)
+	}
+	local := start.In(loc)
+	days := []string{local.Format(time.DateOnly), end.Add(-time.Nanosecond).In(loc).Format(time.DateOnly)}
+	for _, b := range v.Blackouts {
+		if slices.Contains(days, b.Date) && coversPitch(b.Pitches, pitch) {
+			return Conflict("pitch %d of %s is closed on %s%s", pitch, v.Name, b.Date, reasonSuffix(b.Reason))
+		}
+	}
+	if len(v.Availability) == 0 {
+		return nil
+	}
+	from := local.Hour()*60 + local.Minute()
+	to := from + int(end.Sub(start).Minutes())
+	for _, w := range v.Availability {
+		opens, _ := parseClock(w.Opens)
+		closes, _ := parseClock(w.Closes)
+		if w.Weekday == int(local.Weekday()) && opens <= from && to <= closes && coversPitch(w.Pitches, pitch) {
+			return nil
+		}
+	}
+	return Conflict("pitch %d of %s is not open from %s to %s on %s", pitch, v.Name, local.Format("15:04"), end.In(loc).Format("15:04"), local.Weekday())
+}
+
+// checkPitchFree rejects a slot overlapping another match on the pitch.
+func (s *LeaguesService) checkPitchFree(ctx context.Context, v *domain.Venue, matchID string, pitch int, start, end time.Time) error {
+	booked, err := s.store.ListMatchesByVenue(ctx, v.ID, start, end)
+	if err != nil {
+		return err
+	}
+	for _, other := range booked {
+		if other.ID != matchID && other.Pitch == pitch {
+			return Conflict("pitch %d of %s is already booked from %s to %s", pitch, v.Name, other.KickoffAt.Format(time.RFC3339), other.EndsAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// coversPitch reports whether a window or blackout listing pitches applies
+// to pitch; an empty list applies to all of them.
+func coversPitch(pitches []int, pitch int) bool {
+	return len(pitches) == 0 || slices.Contains(pitches, pitch)
+}
+
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
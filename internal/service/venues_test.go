@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+)
+
+func TestScheduleMatchDoubleBooking(t *testing.T) {
+	// The first match holds pitch 1 from 12:00 to 13:30.
+	tests := []struct {
+		name    string
+		pitch   int
+		kickoff string // On Saturday 2027-03-06
+		err     error
+	}{
+		{name: "same kickoff", pitch: 1, kickoff: "12:00", err: ErrConflict},
+		{name: "starts during", pitch: 1, kickoff: "13:29", err: ErrConflict},
+		{name: "ends during", pitch: 1, kickoff: "10:31", err: ErrConflict},
+		{name: "ends as it starts", pitch: 1, kickoff: "10:30"},
+		{name: "starts as it ends", pitch: 1, kickoff: "13:30"},
+		{name: "other pitch", pitch: 2, kickoff: "12:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := newTestLeague(t, SeriesSettings{})
+			tl.approve("t1", "t2", "t3", "t4")
+			if _, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, tl.series.ID, false); err != nil {
+				t.Fatalf("generate fixtures: %v", err)
+			}
+			v, err := tl.svc.CreateVenue(tl.ctx, tl.league.ID, VenueInput{
+				Name:         "Park",
+				Pitches:      []domain.Pitch{{Number: 1, Surface: "grass"}, {Number: 2, Surface: "grass"}},
+				Availability: []domain.AvailabilityWindow{{Weekday: 6, Opens: "09:00", Closes: "18:00"}},
+			})
+			if err != nil {
+				t.Fatalf("create venue: %v", err)
+			}
+			at := func(clock string) time.Time {
+				k, err := time.Parse(time.DateTime, "2027-03-06 "+clock+":00")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return k
+			}
+			matches := tl.matches()
+			first, second := matches[0], matches[1]
+			if _, err := tl.svc.ScheduleMatch(tl.ctx, tl.league.ID, tl.series.ID, first.ID, SlotInput{VenueID: v.ID, Pitch: 1, KickoffAt: at("12:00")}); err != nil {
+				t.Fatalf("book first match: %v", err)
+			}
+
+			_, err = tl.svc.ScheduleMatch(tl.ctx, tl.league.ID, tl.series.ID, second.ID, SlotInput{VenueID: v.ID, Pitch: tt.pitch, KickoffAt: at(tt.kickoff)})
+			checkErr(t, err, tt.err)
+			stored, err := tl.store.GetMatchByID(tl.ctx, second.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if booked := stored.KickoffAt != nil; booked != (tt.err == nil) {
+				t.Fatalf("second match booked %v", booked)
+			}
+
+			// A match booked again into its own slot does not clash with itself.
+			if _, err := tl.svc.ScheduleMatch(tl.ctx, tl.league.ID, tl.series.ID, first.ID, SlotInput{VenueID: v.ID, Pitch: 1, KickoffAt: at("12:00")}); err != nil {
+				t.Fatalf("rebook first match: %v", err)
+			}
+		})
+	}
+}
+
+// The store rejects an overlap the service checks let through, e.g. two
+// bookings racing for the same slot.
+func TestPitchBookingExclusion(t *testing.T) {
+	tl := newTestLeague(t, SeriesSettings{})
+	tl.approve("t1", "t2", "t3", "t4")
+	if _, _, err := tl.svc.GenerateFixtures(tl.ctx, tl.league.ID, tl.series.ID, false); err != nil {
+		t.Fatalf("generate fixtures: %v", err)
+	}
+	v := tl.venue()
+	matches := tl.matches()
+	start := time.Date(2027, 3, 6, 12, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	for i := range matches[:2] {
+		matches[i].VenueID, matches[i].Pitch, matches[i].KickoffAt, matches[i].EndsAt = v.ID, 1, &start, &end
+	}
+	checkErr(t, tl.store.UpdateMatchSlots(tl.ctx, matches[:2], nil), ErrConflict)
+	checkErr(t, tl.store.UpdateMatchSlot(tl.ctx, &matches[0], nil), nil)
+	checkErr(t, tl.store.UpdateMatchSlot(tl.ctx, &matches[1], nil), ErrConflict)
+	booked, err := tl.svc.ListVenueBookings(tl.ctx, tl.league.ID, v.ID, VenueBookingsQuery{From: start, To: end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(booked) != 1 || booked[0].ID != matches[0].ID {
+		t.Fatalf("booked %+v", booked)
+	}
+}
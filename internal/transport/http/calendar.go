@@ -0,0 +1,89 @@
+package transporthttp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"team-manager-leagues/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// iCalendar (RFC 5545) rendering of calendar feeds.
+
+const icsTime = "20060102T150405Z"
+
+// writeCalendar renders the calendar as text/calendar. Events carry the
+// match's stable UID and SEQUENCE, so calendar apps move a rescheduled match
+// instead of adding it again, and drop a cancelled one.
+func writeCalendar(c *gin.Context, cal *domain.Calendar) {
+	var b strings.Builder
+	line := func(name, value string) { foldLine(&b, name+":"+value) }
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Team Manager//Leagues//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icsText(cal.Name))
+	for _, ev := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", ev.UID)
+		line("SEQUENCE", strconv.Itoa(ev.Sequence))
+		line("DTSTAMP", ev.UpdatedAt.UTC().Format(icsTime))
+		line("LAST-MODIFIED", ev.UpdatedAt.UTC().Format(icsTime))
+		line("DTSTART", ev.Start.UTC().Format(icsTime))
+		line("DTEND", ev.End.UTC().Format(icsTime))
+		line("SUMMARY", icsText(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION", icsText(ev.Description))
+		}
+		if ev.Location != "" {
+			line("LOCATION", icsText(ev.Location))
+		}
+		if ev.Latitude != nil && ev.Longitude != nil {
+			line("GEO", fmt.Sprintf("%f;%f", *ev.Latitude, *ev.Longitude))
+		}
+		if ev.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsText escapes a TEXT value.
+func icsText(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// foldLine writes a content line, folded so no line exceeds 75 octets
+// without splitting a UTF-8 sequence.
+func foldLine(b *strings.Builder, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // The leading space counts
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+// feedTarget strips the ".ics" extension from the last path segment.
+func feedTarget(c *gin.Context) (string, bool) {
+	return strings.CutSuffix(c.Param("feed"), ".ics")
+}
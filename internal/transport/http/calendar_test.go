@@ -0,0 +1,141 @@
+package transporthttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/service"
+)
+
+// icsEvents unfolds a calendar and returns the properties of each event by
+// UID.
+func icsEvents(t *testing.T, body string) map[string]map[string]string {
+	t.Helper()
+	if !strings.HasSuffix(body, "END:VCALENDAR\r\n") {
+		t.Fatalf("calendar not CRLF terminated: %q", body)
+	}
+	events := map[string]map[string]string{}
+	var ev map[string]string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n ", ""), "\r\n") {
+		name, value, _ := strings.Cut(line, ":")
+		switch {
+		case line == "BEGIN:VEVENT":
+			ev = map[string]string{}
+		case line == "END:VEVENT":
+			events[ev["UID"]] = ev
+			ev = nil
+		case ev != nil:
+			ev[name] = value
+		}
+	}
+	return events
+}
+
+func TestSeriesCalendar(t *testing.T) {
+	ts := newTestServer(t)
+	v, err := ts.svc.CreateVenue(ts.ctx, ts.league.ID, service.VenueInput{
+		Name:         "Park",
+		Pitches:      []domain.Pitch{{Number: 1, Surface: "grass"}},
+		Availability: []domain.AvailabilityWindow{{Weekday: 6, Opens: "09:00", Closes: "18:00"}},
+	})
+	if err != nil {
+		t.Fatalf("create venue: %v", err)
+	}
+	w := ts.do(http.MethodPost, "/leagues/"+ts.league.ID+"/series/"+ts.series.ID+"/calendar", organizer, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("issue feed: %d %s", w.Code, w.Body)
+	}
+	var issued struct{ Feed domain.CalendarFeed }
+	if err := json.Unmarshal(w.Body.Bytes(), &issued); err != nil {
+		t.Fatal(err)
+	}
+
+	m := ts.matches()[0]
+	uid := m.ID + "@team-manager-leagues"
+	slot := "/leagues/" + ts.league.ID + "/series/" + ts.series.ID + "/fixtures/" + m.ID + "/slot"
+	book := func(kickoff string) {
+		t.Helper()
+		at, _ := time.Parse(time.RFC3339, kickoff)
+		if w := ts.do(http.MethodPut, slot, organizer, service.SlotInput{VenueID: v.ID, KickoffAt: at}); w.Code != http.StatusOK {
+			t.Fatalf("book %s: %d %s", kickoff, w.Code, w.Body)
+		}
+	}
+	feed := func() map[string]string {
+		t.Helper()
+		w := ts.do(http.MethodGet, issued.Feed.URL, "", nil)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/calendar") {
+			t.Fatalf("get feed: %d %s", w.Code, w.Header().Get("Content-Type"))
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "\r\nMETHOD:PUBLISH\r\n") || !strings.Contains(body, "\r\nX-WR-CALNAME:League - A\r\n") {
+			t.Fatalf("calendar header: %q", body)
+		}
+		return icsEvents(t, body)[uid]
+	}
+
+	steps := []struct {
+		name     string
+		change   func()
+		start    string
+		status   string
+		sequence string
+	}{
+		{"booked", func() { book("2027-03-06T12:00:00Z") }, "20270306T120000Z", "CONFIRMED", "1"},
+		{"moved", func() { book("2027-03-13T10:00:00Z") }, "20270313T100000Z", "CONFIRMED", "2"},
+		{"same slot", func() { book("2027-03-13T10:00:00Z") }, "20270313T100000Z", "CONFIRMED", "2"},
+		{
+			"unbooked",
+			func() {
+				if w := ts.do(http.MethodDelete, slot, organizer, nil); w.Code != http.StatusOK {
+					t.Fatalf("unbook: %d %s", w.Code, w.Body)
+				}
+			},
+			"20270313T100000Z", "CANCELLED", "3",
+		},
+		{"booked again", func() { book("2027-03-20T09:00:00Z") }, "20270320T090000Z", "CONFIRMED", "4"},
+	}
+	if ev := feed(); ev != nil {
+		t.Fatalf("unbooked match in the feed: %v", ev)
+	}
+	for _, st := range steps {
+		st.change()
+		ev := feed()
+		if ev == nil {
+			t.Fatalf("%s: match missing from the feed", st.name)
+		}
+		if ev["DTSTART"] != st.start || ev["STATUS"] != st.status || ev["SEQUENCE"] != st.sequence {
+			t.Fatalf("%s: got %s %s sequence %s, want %s %s sequence %s", st.name, ev["DTSTART"], ev["STATUS"], ev["SEQUENCE"], st.start, st.status, st.sequence)
+		}
+		if ev["SUMMARY"] == "" || ev["LOCATION"] != "Park" {
+			t.Fatalf("%s: summary %q, location %q", st.name, ev["SUMMARY"], ev["LOCATION"])
+		}
+	}
+
+	// The token is the feed's only credential.
+	wrong := strings.Replace(issued.Feed.URL, issued.Feed.Token, "wrong", 1)
+	if w := ts.do(http.MethodGet, wrong, "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("wrong token: %d", w.Code)
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	var b strings.Builder
+	long := "DESCRIPTION:" + strings.Repeat("ñ", 60)
+	foldLine(&b, long)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("not folded: %q", b.String())
+	}
+	for i, l := range lines {
+		if len(l) > 75 || (i > 0 && !strings.HasPrefix(l, " ")) {
+			t.Fatalf("line %d: %q", i, l)
+		}
+	}
+	if got := strings.ReplaceAll(b.String(), "\r\n ", ""); got != long+"\r\n" {
+		t.Fatalf("unfolds to %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+package transporthttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"team-manager-leagues/internal/config"
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/repository/memory"
+	"team-manager-leagues/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	organizer = "org"   // Creates the league, so owns it
+	clubOwner = "owner" // Owns the club of every team
+	stranger  = "nobody"
+)
+
+// testServer is the API over the memory store, with league "League" whose
+// series "A" has t1 to t4 approved and its fixtures drawn.
+type testServer struct {
+	t      *testing.T
+	ctx    context.Context
+	cfg    config.Config
+	router *gin.Engine
+	svc    *service.LeaguesService
+	store  *memory.Store
+	league *domain.League
+	series *domain.Series
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard // Request logs
+	store := memory.NewStore()
+	var teams []domain.Team
+	var memberships []domain.Membership
+	for i := 1; i <= 4; i++ {
+		club := fmt.Sprintf("c%d", i)
+		teams = append(teams, domain.Team{ID: fmt.Sprintf("t%d", i), ClubID: club, Name: fmt.Sprintf("Team %d", i), Format: "11"})
+		memberships = append(memberships, domain.Membership{ID: club, UserID: clubOwner, ClubID: club, Role: "owner", Status: "active"})
+	}
+	store.Seed(teams, memberships)
+	cfg := config.Config{JWTSecret: "test-secret"}
+	svc := service.NewLeaguesService(store)
+	ts := &testServer{t: t, ctx: context.Background(), cfg: cfg, router: NewRouter(cfg, svc), svc: svc, store: store}
+
+	var err error
+	if ts.league, err = svc.CreateLeague(ts.ctx, organizer, "League", "R"); err != nil {
+		t.Fatalf("create league: %v", err)
+	}
+	if _, err := svc.CreateSeason(ts.ctx, ts.league.ID, service.SeasonInput{Name: "2027", StartsOn: "2027-03-01", EndsOn: "2027-06-30"}); err != nil {
+		t.Fatalf("create season: %v", err)
+	}
+	if ts.series, err = svc.CreateSeries(ts.ctx, ts.league.ID, "", "A", "11", service.SeriesSettings{}); err != nil {
+		t.Fatalf("create series: %v", err)
+	}
+	for _, team := range teams {
+		reg, err := svc.RegisterTeam(ts.ctx, clubOwner, service.RegistrationInput{TeamID: team.ID, SeriesID: ts.series.ID})
+		if err == nil {
+			_, err = svc.UpdateRegistrationStatus(ts.ctx, organizer, reg.ID, service.RegistrationApproved, "")
+		}
+		if err != nil {
+			t.Fatalf("approve %s: %v", team.ID, err)
+		}
+	}
+	if _, _, err := svc.GenerateFixtures(ts.ctx, ts.league.ID, ts.series.ID, false); err != nil {
+		t.Fatalf("generate fixtures: %v", err)
+	}
+	return ts
+}
+
+func (ts *testServer) matches() []domain.Match {
+	ts.t.Helper()
+	matches, err := ts.store.ListMatchesBySeries(ts.ctx, ts.series.ID)
+	if err != nil {
+		ts.t.Fatalf("list matches: %v", err)
+	}
+	return matches
+}
+
+// request builds a request signed in as userID; an empty userID sends no
+// token.
+func (ts *testServer) request(method, path, userID string, body any) *http.Request {
+	ts.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			ts.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if userID != "" {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": userID}).SignedString([]byte(ts.cfg.JWTSecret))
+		if err != nil {
+			ts.t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func (ts *testServer) do(method, path, userID string, body any) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, ts.request(method, path, userID, body))
+	return w
+}
@@ -0,0 +1,79 @@
+package transporthttp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Server-Sent Events stream of a live match.
+
+// liveHeartbeat is how often an idle stream sends a comment, so proxies do
+// not close it, and looks for events posted through other instances.
+const liveHeartbeat = 15 * time.Second
+
+// streamMatch sends the state of the match as a "state" event, then each of
+// its events named after its type, with the event's seq as ID. A
+// reconnecting EventSource sends the last ID it saw as Last-Event-ID and
+// gets only the events after it. The stream ends after the final whistle;
+// reconnecting to a finished match with nothing left to send gets 204,
+// which tells EventSource to stop.
+func streamMatch(c *gin.Context, svc *service.LeaguesService) {
+	ctx := c.Request.Context()
+	var after int64
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			c.Error(service.Invalid("Last-Event-ID", "Last-Event-ID must be the seq of an event"))
+			return
+		}
+		after = n
+	}
+	// Watch before the first read, so no event falls between the two
+	wake, stop := svc.WatchMatch(c.Param("id"))
+	defer stop()
+	live, events, err := svc.MatchTimeline(ctx, c.GetString("userID"), c.Param("id"), after)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if live.State == service.LiveFinished && len(events) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.Header("X-Accel-Buffering", "no") // Keeps nginx from buffering the stream
+	c.Render(-1, sse.Event{Event: "state", Data: live})
+	send := func(events []domain.MatchEvent) {
+		for _, e := range events {
+			c.Render(-1, sse.Event{Id: strconv.FormatInt(e.Seq, 10), Event: e.Type, Data: e})
+			after = e.Seq
+		}
+		c.Writer.Flush()
+	}
+	send(events)
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+	for live.State != service.LiveFinished {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+		}
+		// Headers are sent; a failure can only end the stream, and the
+		// client reconnects from the last event it received
+		if live, events, err = svc.MatchTimeline(ctx, c.GetString("userID"), c.Param("id"), after); err != nil {
+			return
+		}
+		send(events)
+	}
+}
@@ -0,0 +1,183 @@
+package transporthttp
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"team-manager-leagues/internal/domain"
+	"team-manager-leagues/internal/service"
+)
+
+// sseIDs returns the IDs of the events in a stream, in order.
+func sseIDs(body string) []string {
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, ok := strings.CutPrefix(line, "id:"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// liveMatch kicks off the first fixture and plays out its first half: the
+// events are seq 1 to 3, with the second half in play.
+func (ts *testServer) liveMatch() (events, live string) {
+	ts.t.Helper()
+	m := ts.matches()[0]
+	for _, typ := range []string{service.EventPeriodStart, service.EventPeriodEnd, service.EventPeriodStart} {
+		ts.postEvent(m.ID, service.MatchEventInput{Type: typ})
+	}
+	return "/matches/" + m.ID + "/events", "/matches/" + m.ID + "/live"
+}
+
+func (ts *testServer) postEvent(matchID string, in service.MatchEventInput) {
+	ts.t.Helper()
+	if w := ts.do(http.MethodPost, "/matches/"+matchID+"/events", organizer, in); w.Code != http.StatusOK {
+		ts.t.Fatalf("post %s: %d %s", in.Type, w.Code, w.Body)
+	}
+}
+
+func TestMatchStreamResume(t *testing.T) {
+	ts := newTestServer(t)
+	events, live := ts.liveMatch()
+	ts.postEvent(ts.matches()[0].ID, service.MatchEventInput{Type: service.EventPeriodEnd, Final: true})
+
+	tests := []struct {
+		name   string
+		lastID string
+		code   int
+		ids    []string
+	}{
+		{name: "from the start", code: http.StatusOK, ids: []string{"1", "2", "3", "4"}},
+		{name: "resumed", lastID: "2", code: http.StatusOK, ids: []string{"3", "4"}},
+		{name: "nothing left", lastID: "4", code: http.StatusNoContent},
+		{name: "not a seq", lastID: "two", code: http.StatusBadRequest},
+		{name: "negative", lastID: "-1", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := ts.request(http.MethodGet, live, clubOwner, nil)
+			if tt.lastID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastID)
+			}
+			w := httptest.NewRecorder()
+			ts.router.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body, tt.code)
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			body := w.Body.String()
+			if !strings.HasPrefix(body, "event:state\n") {
+				t.Fatalf("stream does not open with the state: %q", body)
+			}
+			if ids := sseIDs(body); !slices.Equal(ids, tt.ids) {
+				t.Fatalf("sent %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+
+	// The timeline resumes the same way.
+	w := ts.do(http.MethodGet, events+"?after=2", clubOwner, nil)
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `"seq":`) != 2 {
+		t.Fatalf("timeline after 2: %d %s", w.Code, w.Body)
+	}
+}
+
+// A stream resumed while the match is in play sends what was missed, then
+// the events as they are posted, and ends with the final whistle.
+func TestMatchStreamLive(t *testing.T) {
+	ts := newTestServer(t)
+	_, live := ts.liveMatch()
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(ts.ctx, 5*time.Second)
+	defer cancel()
+	req := ts.request(http.MethodGet, srv.URL+live, clubOwner, nil).WithContext(ctx)
+	req.RequestURI = ""
+	req.Header.Set("Last-Event-ID", "1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	r := bufio.NewReader(res.Body)
+	var ids []string
+	next := func() string {
+		t.Helper()
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read after %v: %v", ids, err)
+			}
+			if id, ok := strings.CutPrefix(strings.TrimSuffix(line, "\n"), "id:"); ok {
+				ids = append(ids, id)
+				return id
+			}
+		}
+	}
+	for next() != "3" {
+	}
+	ts.postEvent(ts.matches()[0].ID, service.MatchEventInput{Type: service.EventPeriodEnd, Final: true})
+	next()
+	if !slices.Equal(ids, []string{"2", "3", "4"}) {
+		t.Fatalf("sent %v", ids)
+	}
+	// Finished, the stream closes.
+	for {
+		if _, err := r.ReadString('\n'); err != nil {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("stream still open after the final whistle")
+	}
+}
+
+func TestMatchStreamAccess(t *testing.T) {
+	ts := newTestServer(t)
+	events, live := ts.liveMatch()
+	if err := ts.store.SaveLeagueMember(ts.ctx, &domain.LeagueMember{LeagueID: ts.league.ID, UserID: service.RoleViewer, Role: service.RoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		user string
+		code int
+	}{
+		{name: "organizer", user: organizer, code: http.StatusOK},
+		{name: "viewer", user: service.RoleViewer, code: http.StatusOK},
+		{name: "club in the league", user: clubOwner, code: http.StatusOK},
+		{name: "stranger", user: stranger, code: http.StatusForbidden},
+		{name: "signed out", code: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := ts.do(http.MethodGet, events, tt.user, nil); w.Code != tt.code {
+				t.Fatalf("events: got %d %s, want %d", w.Code, w.Body, tt.code)
+			}
+			// An allowed stream stays open while the match is in play, so
+			// only refusals are requested here.
+			if tt.code != http.StatusOK {
+				if w := ts.do(http.MethodGet, live, tt.user, nil); w.Code != tt.code {
+					t.Fatalf("live: got %d %s, want %d", w.Code, w.Body, tt.code)
+				}
+			}
+		})
+	}
+	if w := ts.do(http.MethodGet, "/matches/nope/events", organizer, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("missing match: %d", w.Code)
+	}
+}
@@ -1,7 +1,11 @@
 package transporthttp
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"team-manager-leagues/internal/config"
 	"team-manager-leagues/internal/middleware"
@@ -11,7 +15,11 @@ import (
 )
 
 func NewRouter(cfg config.Config, svc *service.LeaguesService) *gin.Engine {
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Logger(), middleware.Recovery(), middleware.Errors())
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(service.NotFound("no route for %s %s", c.Request.Method, c.Request.URL.Path))
+	})
 
 	// Middleware
 	auth := middleware.AuthMiddleware(cfg)
@@ -26,82 +34,343 @@ func NewRouter(cfg config.Config, svc *service.LeaguesService) *gin.Engine {
 				Region string `json:"region"`
 			}
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
 			userID := c.GetString("userID")
 			l, err := svc.CreateLeague(c.Request.Context(), userID, req.Name, req.Region)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"league": l})
 		})
 
+		// The directory of leagues and their series is open to any signed-in
+		// user, so clubs can find series to enter before they take part.
+		// Every other league route needs at least league:view: a role, a club
+		// with a team registered in the league, or a referee's place.
 		leagues.GET("", func(c *gin.Context) {
-			list, err := svc.ListLeagues(c.Request.Context())
+			var q service.LeagueQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, next, err := svc.ListLeagues(c.Request.Context(), q)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"leagues": list})
+			c.JSON(http.StatusOK, gin.H{"leagues": list, "nextCursor": nextCursor(next)})
 		})
 
 		leagues.GET("/:id", func(c *gin.Context) {
 			id := c.Param("id")
 			l, err := svc.GetLeague(c.Request.Context(), id)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
 			if l == nil {
-				c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
+				c.Error(service.NotFound("league not found"))
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"league": l})
 		})
 
-		leagues.PUT("/:id", func(c *gin.Context) {
+		leagues.PUT("/:id", authorize(svc, service.PermManageLeague), func(c *gin.Context) {
 			id := c.Param("id")
 			var req struct {
 				Name   string `json:"name"`
 				Region string `json:"region"`
 			}
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
 			l, err := svc.UpdateLeague(c.Request.Context(), id, req.Name, req.Region)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"league": l})
 		})
 
-		leagues.DELETE("/:id", func(c *gin.Context) {
+		leagues.DELETE("/:id", authorize(svc, service.PermDeleteLeague), func(c *gin.Context) {
 			id := c.Param("id")
 			if err := svc.DeleteLeague(c.Request.Context(), id); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		// Members
+		leagues.GET("/:id/members", authorize(svc, service.PermViewMembers), func(c *gin.Context) {
+			list, err := svc.ListMembers(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"members": list})
+		})
+
+		leagues.PUT("/:id/members/:userId", authorize(svc, service.PermManageMembers), func(c *gin.Context) {
+			var req struct {
+				Role string `json:"role"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			m, err := svc.SetMemberRole(c.Request.Context(), userID, c.Param("id"), c.Param("userId"), req.Role)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"member": m})
+		})
+
+		leagues.DELETE("/:id/members/:userId", authorize(svc, service.PermManageMembers), func(c *gin.Context) {
+			userID := c.GetString("userID")
+			if err := svc.RemoveMember(c.Request.Context(), userID, c.Param("id"), c.Param("userId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		// Seasons
+		leagues.POST("/:id/seasons", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
+			var req service.SeasonInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			se, err := svc.CreateSeason(c.Request.Context(), c.Param("id"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"season": se})
+		})
+
+		leagues.GET("/:id/seasons", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListSeasons(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"seasons": list})
+		})
+
+		leagues.GET("/:id/seasons/:seasonId", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			se, err := svc.GetSeason(c.Request.Context(), c.Param("id"), c.Param("seasonId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"season": se})
+		})
+
+		leagues.PUT("/:id/seasons/:seasonId", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
+			var req service.SeasonUpdate
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			se, err := svc.UpdateSeason(c.Request.Context(), c.Param("id"), c.Param("seasonId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"season": se})
+		})
+
+		leagues.DELETE("/:id/seasons/:seasonId", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
+			if err := svc.DeleteSeason(c.Request.Context(), c.Param("id"), c.Param("seasonId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.POST("/:id/seasons/:seasonId/rollover", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
+			var req service.RollOverInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			se, series, invited, err := svc.RollOverSeason(c.Request.Context(), userID, c.Param("id"), c.Param("seasonId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"season": se, "series": series, "invitations": invited})
+		})
+
+		leagues.POST("/:id/seasons/:seasonId/promotion", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			var req service.DivisionMovesInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			moves, invited, err := svc.MoveDivisions(c.Request.Context(), userID, c.Param("id"), c.Param("seasonId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"dryRun": req.DryRun, "moves": moves, "invitations": invited})
+		})
+
+		// Transfer windows
+		leagues.POST("/:id/seasons/:seasonId/transfer-windows", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			var req service.TransferWindowInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			w, err := svc.CreateTransferWindow(c.Request.Context(), userID, c.Param("id"), c.Param("seasonId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transferWindow": w})
+		})
+
+		leagues.GET("/:id/seasons/:seasonId/transfer-windows", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListTransferWindows(c.Request.Context(), c.Param("id"), c.Param("seasonId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transferWindows": list})
+		})
+
+		leagues.DELETE("/:id/seasons/:seasonId/transfer-windows/:windowId", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			if err := svc.DeleteTransferWindow(c.Request.Context(), c.Param("id"), c.Param("seasonId"), c.Param("windowId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.GET("/:id/seasons/:seasonId/transfers", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			list, err := svc.ListSeasonTransfers(c.Request.Context(), c.Param("id"), c.Param("seasonId"), c.Query("teamId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transfers": list})
+		})
+
+		// Venues
+		leagues.POST("/:id/venues", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.VenueInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			v, err := svc.CreateVenue(c.Request.Context(), c.Param("id"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"venue": v})
+		})
+
+		leagues.GET("/:id/venues", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListVenues(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"venues": list})
+		})
+
+		leagues.GET("/:id/venues/:venueId", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			v, err := svc.GetVenue(c.Request.Context(), c.Param("id"), c.Param("venueId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"venue": v})
+		})
+
+		leagues.PUT("/:id/venues/:venueId", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.VenueInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			v, err := svc.UpdateVenue(c.Request.Context(), c.Param("id"), c.Param("venueId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"venue": v})
+		})
+
+		leagues.DELETE("/:id/venues/:venueId", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			if err := svc.DeleteVenue(c.Request.Context(), c.Param("id"), c.Param("venueId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.GET("/:id/venues/:venueId/bookings", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			var q service.VenueBookingsQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, err := svc.ListVenueBookings(c.Request.Context(), c.Param("id"), c.Param("venueId"), q)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"bookings": list})
+		})
+
+		leagues.POST("/:id/venues/:venueId/calendar", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			userID := c.GetString("userID")
+			feed, err := svc.IssueVenueCalendar(c.Request.Context(), userID, c.Param("id"), c.Param("venueId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"feed": feed})
+		})
+
+		leagues.DELETE("/:id/venues/:venueId/calendar", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			if err := svc.RevokeVenueCalendar(c.Request.Context(), c.Param("id"), c.Param("venueId")); err != nil {
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"success": true})
 		})
 
 		// Series
-		leagues.POST("/:id/series", func(c *gin.Context) {
+		leagues.POST("/:id/series", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
 			leagueID := c.Param("id")
 			var req struct {
-				Name   string `json:"name"`
-				Format string `json:"format"`
+				SeasonID string `json:"seasonId"` // Defaults to the current season
+				Name     string `json:"name"`
+				Format   string `json:"format"`
+				service.SeriesSettings
 			}
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
-			s, err := svc.CreateSeries(c.Request.Context(), leagueID, req.Name, req.Format)
+			s, err := svc.CreateSeries(c.Request.Context(), leagueID, req.SeasonID, req.Name, req.Format, req.SeriesSettings)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"series": s})
@@ -109,116 +378,949 @@ func NewRouter(cfg config.Config, svc *service.LeaguesService) *gin.Engine {
 
 		leagues.GET("/:id/series", func(c *gin.Context) {
 			leagueID := c.Param("id")
-			list, err := svc.ListSeries(c.Request.Context(), leagueID)
+			var q service.SeriesQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, next, err := svc.ListSeries(c.Request.Context(), leagueID, q)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"series": list})
+			c.JSON(http.StatusOK, gin.H{"series": list, "nextCursor": nextCursor(next)})
 		})
 
-		leagues.PUT("/:id/series/:seriesId", func(c *gin.Context) {
+		leagues.PUT("/:id/series/:seriesId", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
 			var req struct {
 				Name   string `json:"name"`
 				Format string `json:"format"`
+				service.SeriesSettings
 			}
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
 			seriesID := c.Param("seriesId")
-			if err := svc.UpdateSeries(c.Request.Context(), seriesID, req.Name, req.Format); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			userID := c.GetString("userID")
+			if err := svc.UpdateSeries(c.Request.Context(), userID, c.Param("id"), seriesID, req.Name, req.Format, req.SeriesSettings); err != nil {
+				c.Error(err)
 				return
 			}
 			s, err := svc.GetSeries(c.Request.Context(), seriesID)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"series": s})
 		})
 
-		leagues.DELETE("/:id/series/:seriesId", func(c *gin.Context) {
+		leagues.DELETE("/:id/series/:seriesId", authorize(svc, service.PermManageSeries), func(c *gin.Context) {
 			seriesID := c.Param("seriesId")
-			if err := svc.DeleteSeries(c.Request.Context(), seriesID); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			if err := svc.DeleteSeries(c.Request.Context(), c.Param("id"), seriesID); err != nil {
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"success": true})
 		})
-	}
 
-	// Registrations
-	regs := r.Group("/registrations")
-	regs.Use(auth)
-	{
-		regs.POST("", func(c *gin.Context) {
+		leagues.POST("/:id/series/:seriesId/calendar", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			userID := c.GetString("userID")
+			feed, err := svc.IssueSeriesCalendar(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"feed": feed})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/calendar", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			if err := svc.RevokeSeriesCalendar(c.Request.Context(), c.Param("id"), c.Param("seriesId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		// Fixtures
+		leagues.POST("/:id/series/:seriesId/fixtures", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
 			var req struct {
-				TeamID   string `json:"teamId"`
-				SeriesID string `json:"seriesId"`
+				DoubleLeg bool `json:"doubleLeg"`
+			}
+			// The body is optional; an empty one generates a single leg.
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			matches, byes, err := svc.GenerateFixtures(c.Request.Context(), c.Param("id"), c.Param("seriesId"), req.DoubleLeg)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"fixtures": matches, "byes": byes})
+		})
+
+		leagues.GET("/:id/series/:seriesId/fixtures", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListFixtures(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"fixtures": list})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/fixtures", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			if err := svc.DeleteFixtures(c.Request.Context(), c.Param("id"), c.Param("seriesId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.PUT("/:id/series/:seriesId/fixtures/:matchId/slot", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.SlotInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			m, err := svc.ScheduleMatch(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"fixture": m})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/fixtures/:matchId/slot", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			if err := svc.UnscheduleMatch(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.POST("/:id/series/:seriesId/schedule", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.ScheduleInput
+			// The body is optional; an empty one schedules the whole season.
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
 			}
+			report, err := svc.ScheduleSeries(c.Request.Context(), c.Param("id"), c.Param("seriesId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"schedule": report})
+		})
+
+		// Reschedule requests are further checked against club ownership in
+		// the service.
+		leagues.POST("/:id/series/:seriesId/fixtures/:matchId/reschedule-requests", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			var req service.RescheduleInput
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
 			userID := c.GetString("userID")
-			reg, err := svc.RegisterTeam(c.Request.Context(), userID, req.TeamID, req.SeriesID)
+			rr, err := svc.RequestReschedule(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"), req)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"registration": reg})
+			c.JSON(http.StatusOK, gin.H{"rescheduleRequest": rr})
 		})
 
-		regs.GET("", func(c *gin.Context) {
-			teamID := c.Query("teamId")
-			seriesID := c.Query("seriesId")
-			if teamID == "" && seriesID == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"message": "teamId or seriesId required"})
+		leagues.GET("/:id/series/:seriesId/fixtures/:matchId/reschedule-requests", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			userID := c.GetString("userID")
+			list, err := svc.ListMatchReschedules(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"))
+			if err != nil {
+				c.Error(err)
 				return
 			}
-			if teamID != "" {
-				list, err := svc.ListRegistrationsByTeam(c.Request.Context(), teamID)
-				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-					return
-				}
-				c.JSON(http.StatusOK, gin.H{"registrations": list})
+			c.JSON(http.StatusOK, gin.H{"rescheduleRequests": list})
+		})
+
+		leagues.GET("/:id/reschedule-requests", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var q service.RescheduleQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
 				return
 			}
-			list, err := svc.ListRegistrationsBySeries(c.Request.Context(), seriesID)
+			list, err := svc.ListLeagueReschedules(c.Request.Context(), c.Param("id"), q)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"registrations": list})
+			c.JSON(http.StatusOK, gin.H{"rescheduleRequests": list})
 		})
 
-		regs.PUT("/:id", func(c *gin.Context) {
-			var req struct {
-				Status string `json:"status"`
+		// Knockout
+		leagues.POST("/:id/series/:seriesId/bracket", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.BracketInput
+			// The body is optional; an empty one draws an unseeded single-leg bracket.
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			ties, err := svc.GenerateBracket(c.Request.Context(), c.Param("id"), c.Param("seriesId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"ties": ties})
+		})
+
+		leagues.GET("/:id/series/:seriesId/bracket", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			ties, err := svc.GetBracket(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
 			}
+			c.JSON(http.StatusOK, gin.H{"ties": ties})
+		})
+
+		// Stages
+		leagues.POST("/:id/series/:seriesId/stages/groups", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.GroupDrawInput
 			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Error(badRequest(err))
 				return
 			}
-			regID := c.Param("id")
-			if err := svc.UpdateRegistrationStatus(c.Request.Context(), regID, req.Status); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			stages, err := svc.DrawGroups(c.Request.Context(), c.Param("id"), c.Param("seriesId"), req)
+			if err != nil {
+				c.Error(err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"success": true})
+			c.JSON(http.StatusOK, gin.H{"stages": stages})
 		})
 
-		regs.DELETE("/:id", func(c *gin.Context) {
-			regID := c.Param("id")
-			if err := svc.DeleteRegistration(c.Request.Context(), regID); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		leagues.POST("/:id/series/:seriesId/stages/knockout", authorize(svc, service.PermManageSchedule), func(c *gin.Context) {
+			var req service.KnockoutStageInput
+			// The body is optional; an empty one plays single-leg ties.
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			ties, err := svc.StartKnockoutStage(c.Request.Context(), c.Param("id"), c.Param("seriesId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"ties": ties})
+		})
+
+		leagues.GET("/:id/series/:seriesId/stages", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			stages, err := svc.ListStages(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"stages": stages})
+		})
+
+		// Results
+		leagues.PUT("/:id/series/:seriesId/fixtures/:matchId/result", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			var req service.ResultInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			res, err := svc.RecordResult(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"result": res})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/fixtures/:matchId/result", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			if err := svc.ClearResult(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId")); err != nil {
+				c.Error(err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"success": true})
 		})
-	}
-	return r
+
+		// Standings
+		leagues.GET("/:id/series/:seriesId/standings", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			table, err := svc.ComputeStandings(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"standings": table})
+		})
+
+		// Discipline
+		leagues.POST("/:id/series/:seriesId/fixtures/:matchId/cards", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			var req service.CardInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			card, su, err := svc.RecordCard(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"card": card, "suspension": su})
+		})
+
+		leagues.GET("/:id/series/:seriesId/fixtures/:matchId/cards", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListMatchCards(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"cards": list})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/fixtures/:matchId/cards/:cardId", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			if err := svc.DeleteCard(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"), c.Param("cardId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		// Match sheets are submitted by the team's club owner or an organizer,
+		// as checked in the service.
+		leagues.PUT("/:id/series/:seriesId/fixtures/:matchId/sheets/:teamId", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			var req service.MatchSheetInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			sh, err := svc.SaveMatchSheet(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"), c.Param("teamId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"sheet": sh})
+		})
+
+		leagues.GET("/:id/series/:seriesId/fixtures/:matchId/sheets", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListMatchSheets(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"sheets": list})
+		})
+
+		leagues.GET("/:id/series/:seriesId/suspensions", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			var q service.SuspensionQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, err := svc.ListSuspensions(c.Request.Context(), c.Param("id"), c.Param("seriesId"), q)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"suspensions": list})
+		})
+
+		leagues.POST("/:id/series/:seriesId/suspensions", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			var req service.SuspensionInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			su, err := svc.CreateSuspension(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"suspension": su})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/suspensions/:suspensionId", authorize(svc, service.PermManageResults), func(c *gin.Context) {
+			if err := svc.DeleteSuspension(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("suspensionId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.GET("/:id/series/:seriesId/fair-play", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			table, err := svc.FairPlayTable(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"fairPlay": table})
+		})
+
+		leagues.POST("/:id/series/:seriesId/eligibility-overrides", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			var req struct {
+				TeamID string `json:"teamId"`
+				Rule   string `json:"rule"`
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			o, err := svc.OverrideEligibility(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), req.TeamID, req.Rule, req.Reason)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"override": o})
+		})
+
+		leagues.GET("/:id/series/:seriesId/eligibility-overrides", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			list, err := svc.ListEligibilityOverrides(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"overrides": list})
+		})
+
+		leagues.GET("/:id/series/:seriesId/late-roster-changes", authorize(svc, service.PermManageRegistrations), func(c *gin.Context) {
+			changes, err := svc.ListLateRosterChanges(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"changes": changes})
+		})
+		// Referees
+		leagues.POST("/:id/referees", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			var req service.RefereeInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			r, err := svc.CreateReferee(c.Request.Context(), c.Param("id"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"referee": r})
+		})
+
+		leagues.GET("/:id/referees", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			var q service.RefereeQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, err := svc.ListReferees(c.Request.Context(), c.Param("id"), q)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"referees": list})
+		})
+
+		leagues.GET("/:id/referees/:refereeId", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			r, err := svc.GetReferee(c.Request.Context(), c.Param("id"), c.Param("refereeId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"referee": r})
+		})
+
+		leagues.PUT("/:id/referees/:refereeId", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			var req service.RefereeInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			r, err := svc.UpdateReferee(c.Request.Context(), c.Param("id"), c.Param("refereeId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"referee": r})
+		})
+
+		leagues.DELETE("/:id/referees/:refereeId", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			if err := svc.DeleteReferee(c.Request.Context(), c.Param("id"), c.Param("refereeId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		// Referees see only their own assignments; the service checks who asks.
+		leagues.GET("/:id/referees/:refereeId/assignments", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			userID := c.GetString("userID")
+			list, err := svc.RefereeAssignments(c.Request.Context(), userID, c.Param("id"), c.Param("refereeId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"assignments": list})
+		})
+
+		leagues.POST("/:id/series/:seriesId/fixtures/:matchId/officials", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			var req service.AssignmentInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			a, err := svc.AssignReferee(c.Request.Context(), userID, c.Param("id"), c.Param("seriesId"), c.Param("matchId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"assignment": a})
+		})
+
+		leagues.GET("/:id/series/:seriesId/fixtures/:matchId/officials", authorize(svc, service.PermViewLeague), func(c *gin.Context) {
+			list, err := svc.ListMatchOfficials(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"officials": list})
+		})
+
+		leagues.DELETE("/:id/series/:seriesId/fixtures/:matchId/officials/:assignmentId", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			if err := svc.UnassignReferee(c.Request.Context(), c.Param("id"), c.Param("seriesId"), c.Param("matchId"), c.Param("assignmentId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		leagues.GET("/:id/series/:seriesId/referee-suggestions", authorize(svc, service.PermManageReferees), func(c *gin.Context) {
+			suggestions, issues, err := svc.SuggestReferees(c.Request.Context(), c.Param("id"), c.Param("seriesId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"suggestions": suggestions, "issues": issues})
+		})
+	}
+
+	// Registrations
+	regs := r.Group("/registrations")
+	regs.Use(auth)
+	{
+		regs.POST("", func(c *gin.Context) {
+			var req service.RegistrationInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			reg, err := svc.RegisterTeam(c.Request.Context(), userID, req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"registration": reg})
+		})
+
+		regs.GET("", func(c *gin.Context) {
+			var q service.RegistrationQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			list, next, err := svc.ListRegistrations(c.Request.Context(), c.GetString("userID"), q)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"registrations": list, "nextCursor": nextCursor(next)})
+		})
+
+		regs.PUT("/:id", func(c *gin.Context) {
+			var req struct {
+				Status string `json:"status"`
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			reg, err := svc.UpdateRegistrationStatus(c.Request.Context(), userID, c.Param("id"), req.Status, req.Reason)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"registration": reg})
+		})
+
+		regs.DELETE("/:id", func(c *gin.Context) {
+			var req struct {
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			reg, err := svc.WithdrawRegistration(c.Request.Context(), userID, c.Param("id"), req.Reason)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"registration": reg})
+		})
+
+		regs.GET("/:id/history", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			events, err := svc.RegistrationHistory(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"events": events})
+		})
+
+		regs.GET("/:id/roster", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			players, err := svc.ListRoster(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"players": players})
+		})
+
+		regs.GET("/:id/roster/history", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			changes, err := svc.RosterHistory(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"changes": changes})
+		})
+
+		regs.PUT("/:id/roster/:playerId", func(c *gin.Context) {
+			var req service.RosterPlayerInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			p, err := svc.SaveRosterPlayer(c.Request.Context(), userID, c.Param("id"), c.Param("playerId"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"player": p})
+		})
+
+		regs.DELETE("/:id/roster/:playerId", func(c *gin.Context) {
+			var req struct {
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			if err := svc.RemoveRosterPlayer(c.Request.Context(), userID, c.Param("id"), c.Param("playerId"), req.Reason); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+	}
+
+	// Reschedule requests
+	reschedules := r.Group("/reschedule-requests")
+	reschedules.Use(auth)
+	{
+		reschedules.GET("/:id", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			rr, err := svc.GetRescheduleRequest(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"rescheduleRequest": rr})
+		})
+
+		reschedules.PUT("/:id", func(c *gin.Context) {
+			var req struct {
+				Status string `json:"status"`
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			rr, err := svc.UpdateRescheduleStatus(c.Request.Context(), userID, c.Param("id"), req.Status, req.Reason)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"rescheduleRequest": rr})
+		})
+
+		reschedules.GET("/:id/history", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			events, err := svc.RescheduleHistory(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"events": events})
+		})
+	}
+
+	// Transfers
+	transfers := r.Group("/transfers")
+	transfers.Use(auth)
+	{
+		transfers.POST("", func(c *gin.Context) {
+			var req service.TransferInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			t, err := svc.RequestTransfer(c.Request.Context(), userID, req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transfer": t})
+		})
+
+		transfers.GET("/:id", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			t, err := svc.GetTransfer(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transfer": t})
+		})
+
+		transfers.PUT("/:id", func(c *gin.Context) {
+			var req service.TransferUpdate
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			t, err := svc.UpdateTransfer(c.Request.Context(), userID, c.Param("id"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"transfer": t})
+		})
+
+		transfers.GET("/:id/history", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			events, err := svc.TransferHistory(c.Request.Context(), userID, c.Param("id"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"events": events})
+		})
+	}
+
+	// Live matches
+	matches := r.Group("/matches")
+	matches.Use(auth)
+	{
+		matches.POST("/:id/events", func(c *gin.Context) {
+			var req service.MatchEventInput
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			e, err := svc.PostMatchEvent(c.Request.Context(), userID, c.Param("id"), req)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"event": e})
+		})
+
+		matches.GET("/:id/events", func(c *gin.Context) {
+			var q struct {
+				After int64 `form:"after"`
+			}
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			live, events, err := svc.MatchTimeline(c.Request.Context(), c.GetString("userID"), c.Param("id"), q.After)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"live": live, "events": events})
+		})
+
+		matches.DELETE("/:id/events/:seq", func(c *gin.Context) {
+			seq, err := strconv.ParseInt(c.Param("seq"), 10, 64)
+			if err != nil {
+				c.Error(service.NotFound("match event not found"))
+				return
+			}
+			userID := c.GetString("userID")
+			e, err := svc.RetractMatchEvent(c.Request.Context(), userID, c.Param("id"), seq)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"event": e})
+		})
+
+		matches.GET("/:id/live", func(c *gin.Context) {
+			streamMatch(c, svc)
+		})
+	}
+
+	// Teams
+	teams := r.Group("/teams")
+	teams.Use(auth)
+	{
+		teams.GET("/:teamId/blackouts", func(c *gin.Context) {
+			list, err := svc.ListTeamBlackouts(c.Request.Context(), c.GetString("userID"), c.Param("teamId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"blackouts": list})
+		})
+
+		teams.PUT("/:teamId/blackouts/:date", func(c *gin.Context) {
+			var req struct {
+				Reason string `json:"reason"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+				c.Error(badRequest(err))
+				return
+			}
+			userID := c.GetString("userID")
+			b, err := svc.SetTeamBlackout(c.Request.Context(), userID, c.Param("teamId"), c.Param("date"), req.Reason)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"blackout": b})
+		})
+
+		teams.DELETE("/:teamId/blackouts/:date", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			if err := svc.DeleteTeamBlackout(c.Request.Context(), userID, c.Param("teamId"), c.Param("date")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+
+		teams.POST("/:teamId/calendar", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			feed, err := svc.IssueTeamCalendar(c.Request.Context(), userID, c.Param("teamId"))
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"feed": feed})
+		})
+
+		teams.DELETE("/:teamId/calendar", func(c *gin.Context) {
+			userID := c.GetString("userID")
+			if err := svc.RevokeTeamCalendar(c.Request.Context(), userID, c.Param("teamId")); err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"success": true})
+		})
+	}
+
+	// Calendars are fetched by calendar apps, which cannot send a bearer
+	// token; the feed's own token authorizes them.
+	calendars := r.Group("/calendars")
+	{
+		feed := func(kind string) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				id, ok := feedTarget(c)
+				if !ok {
+					c.Error(service.NotFound("no route for %s %s", c.Request.Method, c.Request.URL.Path))
+					return
+				}
+				cal, err := svc.Calendar(c.Request.Context(), kind, id, c.Query("token"))
+				if err != nil {
+					c.Error(err)
+					return
+				}
+				writeCalendar(c, cal)
+			}
+		}
+		calendars.GET("/teams/:feed", feed(service.FeedTeam))
+		calendars.GET("/series/:feed", feed(service.FeedSeries))
+		calendars.GET("/venues/:feed", feed(service.FeedVenue))
+	}
+
+	// Search
+	search := r.Group("/search")
+	search.Use(auth)
+	{
+		search.GET("", func(c *gin.Context) {
+			var q service.SearchQuery
+			if err := c.ShouldBindQuery(&q); err != nil {
+				c.Error(badRequest(err))
+				return
+			}
+			results, err := svc.Search(c.Request.Context(), q)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"results": results})
+		})
+	}
+	return r
+}
+
+// authorize aborts unless the user holds a role in the league (the :id path
+// parameter) granting perm.
+func authorize(svc *service.LeaguesService, perm service.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Authorize(c.Request.Context(), c.GetString("userID"), c.Param("id"), perm); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// badRequest turns a request that cannot be decoded into a validation
+// error, naming the field when the JSON value has the wrong type.
+func badRequest(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return service.Invalid(typeErr.Field, "%s cannot be a JSON %s", typeErr.Field, typeErr.Value)
+	}
+	return service.Invalid("", "invalid request: %v", err)
+}
+
+// nextCursor renders the cursor of the next page, null on the last page.
+func nextCursor(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return cursor
 }